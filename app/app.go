@@ -103,7 +103,7 @@ func SetupApplicationWithAccount(app *tview.Application, account string) {
 			window.RegisterCommand(commandimpls.NewVersionCommand())
 			statusGetCmd := commandimpls.NewStatusGetCommand(discord)
 			statusSetCmd := commandimpls.NewStatusSetCommand(discord)
-			statusSetCustomCmd := commandimpls.NewStatusSetCustomCommand(discord)
+			statusSetCustomCmd := commandimpls.NewStatusSetCustomCommand(window, discord)
 			window.RegisterCommand(statusSetCmd)
 			window.RegisterCommand(statusGetCmd)
 			window.RegisterCommand(statusSetCustomCmd)
@@ -138,6 +138,25 @@ func SetupApplicationWithAccount(app *tview.Application, account string) {
 			window.RegisterCommand(tfaBackupGetCmd)
 			window.RegisterCommand(tfaBackupResetCmd)
 			window.RegisterCommand(commandimpls.NewDMOpenCmd(discord, window))
+			window.RegisterCommand(commandimpls.NewScriptsReloadCommand(window))
+			window.RegisterCommand(commandimpls.NewHistoryCommand(window))
+			window.RegisterCommand(commandimpls.NewAliasCommand())
+			window.RegisterCommand(commandimpls.NewSendCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewPinsCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewChannelCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewModCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewRoleCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewInviteCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewGroupCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewBlockCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewUnblockCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewIgnoreCommand(window, discord))
+			window.RegisterCommand(commandimpls.NewNoteCommand(window, discord))
+
+			//The configuration directory has already been resolved
+			//successfully at this point, therefore this can't fail.
+			rcFile, _ := config.GetRCFile()
+			window.ExecuteCommandFile(rcFile)
 		})
 	}()
 }
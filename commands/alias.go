@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SplitCommands splits the input into separate commands at each semicolon
+// that is neither quoted nor escaped. Empty commands are dropped.
+func SplitCommands(input string) []string {
+	runes := []rune(input)
+	var commands []string
+	var start int
+	for _, index := range append(unquotedIndices(runes, ";"), len(runes)) {
+		if command := strings.TrimSpace(string(runes[start:index])); command != "" {
+			commands = append(commands, command)
+		}
+		start = index + 1
+	}
+
+	return commands
+}
+
+// ExpandAliases splits the input into separate commands and replaces the
+// aliases among them, including the ones inside of pipelines, with their
+// definitions. A definition may consist of
+// multiple commands separated by semicolons and may reference the
+// parameters passed to the alias via $1, $2 and so on, or all of them via
+// $@. If the definition doesn't reference any parameters, they are appended
+// to it. An alias isn't expanded again within its own definition, therefore
+// an alias may have the same name as the command it invokes and aliases
+// referencing each other can't cause endless recursion. An error is
+// returned if any of the commands can't be parsed.
+func ExpandAliases(aliases map[string]string, input string) ([]string, error) {
+	return expandAliases(aliases, input, make(map[string]bool))
+}
+
+func expandAliases(aliases map[string]string, input string, expanding map[string]bool) ([]string, error) {
+	var expanded []string
+	for _, command := range SplitCommands(input) {
+		pipeline, parseError := ParsePipeline(command)
+		if parseError != nil {
+			return nil, parseError
+		}
+
+		//Each command of a pipeline is expanded separately, as the
+		//parameters of an alias end at the next pipe. Similar to a shell,
+		//the commands of a macro are inserted as they are, therefore only
+		//the commands adjacent to a pipe are connected by it.
+		for index, pipelineCommand := range pipeline.Commands {
+			if IsMessageCommand(pipelineCommand) {
+				continue
+			}
+
+			parts, parseError := ParseCommand(pipelineCommand)
+			if parseError != nil {
+				return nil, parseError
+			}
+			if len(parts) == 0 {
+				continue
+			}
+			definition, isAlias := aliases[parts[0]]
+			if !isAlias || expanding[parts[0]] {
+				continue
+			}
+
+			expanding[parts[0]] = true
+			definitionCommands, expandError := expandAliases(aliases, substituteParameters(definition, parts[1:]), expanding)
+			delete(expanding, parts[0])
+			if expandError != nil {
+				return nil, fmt.Errorf("invalid definition of alias '%s': %s", parts[0], expandError)
+			}
+			pipeline.Commands[index] = strings.Join(definitionCommands, "; ")
+		}
+		expanded = append(expanded, SplitCommands(pipeline.String())...)
+	}
+
+	return expanded, nil
+}
+
+// substituteParameters replaces the placeholders in the alias definition
+// with the given parameters. Parameters that haven't been passed are
+// replaced with nothing.
+func substituteParameters(definition string, parameters []string) string {
+	quoted := make([]string, 0, len(parameters))
+	for _, parameter := range parameters {
+		quoted = append(quoted, QuoteParameter(parameter))
+	}
+
+	var result strings.Builder
+	var placeholderFound bool
+	runes := []rune(definition)
+	for index := 0; index < len(runes); index++ {
+		char := runes[index]
+		if char != '$' || index == len(runes)-1 {
+			result.WriteRune(char)
+			continue
+		}
+
+		next := runes[index+1]
+		if next == '@' {
+			placeholderFound = true
+			result.WriteString(strings.Join(quoted, " "))
+			index++
+		} else if isDigit(next) {
+			placeholderFound = true
+			end := index + 1
+			for end < len(runes) && isDigit(runes[end]) {
+				end++
+			}
+			position, _ := strconv.Atoi(string(runes[index+1 : end]))
+			if position >= 1 && position <= len(quoted) {
+				result.WriteString(quoted[position-1])
+			}
+			index = end - 1
+		} else {
+			result.WriteRune(char)
+		}
+	}
+
+	if !placeholderFound && len(quoted) > 0 {
+		result.WriteRune(' ')
+		result.WriteString(strings.Join(quoted, " "))
+	}
+
+	return result.String()
+}
+
+func isDigit(char rune) bool {
+	return char >= '0' && char <= '9'
+}
@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "single command",
+			input: "status get",
+			want:  []string{"status get"},
+		}, {
+			name:  "multiple commands",
+			input: "status set online; dm-open alice",
+			want:  []string{"status set online", "dm-open alice"},
+		}, {
+			name:  "quoted semicolon",
+			input: `alias x "a; b"; status`,
+			want:  []string{`alias x "a; b"`, "status"},
+		}, {
+			name:  "escaped semicolon",
+			input: `friends search a\;b`,
+			want:  []string{`friends search a\;b`},
+		}, {
+			name:  "single quoted semicolon",
+			input: `alias x 'a; "b'; status`,
+			want:  []string{`alias x 'a; "b'`, "status"},
+		}, {
+			name:  "empty commands",
+			input: " ; status;; ",
+			want:  []string{"status"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitCommands(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitCommands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandAliases(t *testing.T) {
+	aliases := map[string]string{
+		"standup":  "status set online; dm-open alice",
+		"greet":    "dm-open $1",
+		"both":     "friends befriend $2; dm-open $1",
+		"all":      "file-send $@",
+		"lf":       "friends list",
+		"user-get": "user-get -n",
+		"ping":     "pong",
+		"pong":     "ping",
+		"morning":  "standup; greet $1",
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "no alias",
+			input: "friends list",
+			want:  []string{"friends list"},
+		}, {
+			name:  "macro",
+			input: "standup",
+			want:  []string{"status set online", "dm-open alice"},
+		}, {
+			name:  "positional placeholder",
+			input: `greet "Marcel Davis"`,
+			want:  []string{`dm-open "Marcel Davis"`},
+		}, {
+			name:  "missing positional parameter",
+			input: "both Marcel",
+			want:  []string{"friends befriend", "dm-open Marcel"},
+		}, {
+			name:  "all parameters",
+			input: "all a.txt b.txt",
+			want:  []string{"file-send a.txt b.txt"},
+		}, {
+			name:  "parameters appended without placeholders",
+			input: "lf extra",
+			want:  []string{"friends list extra"},
+		}, {
+			name:  "alias with the name of its command",
+			input: "user-get",
+			want:  []string{"user-get -n"},
+		}, {
+			name:  "mutual recursion",
+			input: "ping",
+			want:  []string{"ping"},
+		}, {
+			name:  "alias inside of a pipeline",
+			input: "lf | greet > out.txt",
+			want:  []string{"friends list | dm-open > out.txt"},
+		}, {
+			name:  "macro inside of a pipeline",
+			input: "standup | lf",
+			want:  []string{"status set online", "dm-open alice | friends list"},
+		}, {
+			name:  "nested aliases",
+			input: "morning bob; lf",
+			want:  []string{"status set online", "dm-open alice", "dm-open bob", "friends list"},
+		}, {
+			name:  "message commands aren't parsed",
+			input: `send "don't; lf`,
+			want:  []string{`send "don't`, "friends list"},
+		}, {
+			name:    "unterminated quote",
+			input:   `lf; greet "bob`,
+			wantErr: true,
+		}, {
+			name:    "invalid pipeline",
+			input:   "lf |",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandAliases(aliases, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandAliases() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandAliases() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
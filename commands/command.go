@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	"github.com/Bios-Marcel/cordless/config"
@@ -25,67 +26,209 @@ type Command interface {
 	Aliases() []string
 }
 
-// ParseCommand takes an arbitrary input string and splits it into parameters.
-// The first parameter (index 0) will always be the command itself.
-func ParseCommand(input string) []string {
-	if len(input) == 0 || len(strings.TrimSpace(input)) == 0 {
-		return nil
+// escapableCharacters can be escaped via a backslash outside of quotes. A
+// backslash in front of any other character is kept, so that windows paths
+// don't have to be escaped.
+const escapableCharacters = " \t\n\\'\";|>"
+
+// escapableInDoubleQuotes can be escaped via a backslash inside of double
+// quotes.
+const escapableInDoubleQuotes = "\\\""
+
+// ParseCommand takes an arbitrary input string and splits it into parameters
+// similar to a POSIX shell. The first parameter (index 0) will always be the
+// command itself.
+//
+// Text inside of single quotes is taken literally. Single quotes only start
+// a quote at the beginning of a parameter, so that apostrophes such as in
+// "don't" don't have to be escaped. Inside of double quotes, \ and " can be
+// escaped via a backslash. "--" is passed on unchanged, commands using a
+// Spec treat all following parameters as arguments. Neither ~ nor
+// environment variables are expanded, see ExpandPath for arguments that are
+// paths. An error is returned if a quote isn't terminated.
+func ParseCommand(input string) ([]string, error) {
+	var parameters []string
+	var current strings.Builder
+	//Quotes can produce empty parameters, therefore the length of current
+	//doesn't suffice.
+	var inParameter bool
+
+	runes := []rune(input)
+	for index := 0; index < len(runes); index++ {
+		char := runes[index]
+		switch {
+		case isParameterSeparator(char):
+			if inParameter {
+				parameters = append(parameters, current.String())
+				current.Reset()
+				inParameter = false
+			}
+		case char == '\'' && !inParameter:
+			end := indexOfRune(runes, '\'', index+1)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated single quote at position %d", index+1)
+			}
+			current.WriteString(string(runes[index+1 : end]))
+			index = end
+			inParameter = true
+		case char == '"':
+			quoted, end := parseDoubleQuotes(runes, index)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated double quote at position %d", index+1)
+			}
+			current.WriteString(quoted)
+			index = end
+			inParameter = true
+		case char == '\\':
+			if index < len(runes)-1 && strings.ContainsRune(escapableCharacters, runes[index+1]) {
+				index++
+			}
+			current.WriteRune(runes[index])
+			inParameter = true
+		default:
+			current.WriteRune(char)
+			inParameter = true
+		}
 	}
 
-	if !strings.ContainsRune(input, ' ') {
-		return []string{input}
+	if inParameter {
+		parameters = append(parameters, current.String())
 	}
 
-	parameters := make([]string, 0)
+	return parameters, nil
+}
+
+// parseDoubleQuotes returns the unescaped content of the double quotes
+// starting at the given index and the index of the closing quote. If the
+// quote isn't terminated, the index is -1.
+func parseDoubleQuotes(runes []rune, start int) (string, int) {
+	var quoted strings.Builder
+	for index := start + 1; index < len(runes); index++ {
+		switch {
+		case runes[index] == '"':
+			return quoted.String(), index
+		case runes[index] == '\\' && index < len(runes)-1 && strings.ContainsRune(escapableInDoubleQuotes, runes[index+1]):
+			index++
+		}
+		quoted.WriteRune(runes[index])
+	}
+	return "", -1
+}
 
-	trimmedWhiteSpace := []rune(strings.TrimSpace(input))
-	length := len(trimmedWhiteSpace)
+// ExpandPath expands environment variables in the form of $VAR or ${VAR} in
+// the given path argument. Unset variables are replaced with nothing. A
+// leading ~ is resolved by files.ToAbsolutePath. This mustn't be used for
+// arguments that end up in messages, as variables might contain secrets.
+func ExpandPath(path string) string {
+	var expanded strings.Builder
+	runes := []rune(path)
+	for index := 0; index < len(runes); index++ {
+		if value, length := expandVariable(runes[index:]); length > 0 {
+			expanded.WriteString(value)
+			index += length - 1
+		} else {
+			expanded.WriteRune(runes[index])
+		}
+	}
+	return expanded.String()
+}
 
-	lastArgument := make([]rune, 0)
+// QuoteParameter quotes the parameter if necessary, so that ParseCommand
+// returns it unchanged.
+func QuoteParameter(parameter string) string {
+	if parameter != "" && !strings.ContainsAny(parameter, escapableCharacters+"\r") {
+		return parameter
+	}
 
-OUTER_LOOP:
-	for index := 0; index < length; index++ {
-		char := trimmedWhiteSpace[index]
-		if char == ' ' {
-			if len(lastArgument) > 0 {
-				parameters = append(parameters, string(lastArgument))
-				lastArgument = make([]rune, 0)
-			}
-		} else if char == '\\' {
-			if index == length-1 {
-				lastArgument = append(lastArgument, char)
-			} else if trimmedWhiteSpace[index+1] == '"' {
-				lastArgument = append(lastArgument, '"')
-				index++
-				continue OUTER_LOOP
+	var quoted strings.Builder
+	quoted.WriteRune('"')
+	for _, char := range parameter {
+		if strings.ContainsRune(escapableInDoubleQuotes, char) {
+			quoted.WriteRune('\\')
+		}
+		quoted.WriteRune(char)
+	}
+	quoted.WriteRune('"')
+	return quoted.String()
+}
+
+// unquotedIndices returns the indices of all runes that are one of the given
+// characters and are neither quoted nor escaped. Quotes are recognized the
+// same way as by ParseCommand.
+func unquotedIndices(runes []rune, characters string) []int {
+	var indices []int
+	for index := 0; index < len(runes); index++ {
+		char := runes[index]
+		switch {
+		case char == '\\':
+			index++
+		case char == '\'' && (index == 0 || isParameterSeparator(runes[index-1]) || strings.ContainsRune(characters, runes[index-1])):
+			if end := indexOfRune(runes, '\'', index+1); end != -1 {
+				index = end
 			}
-		} else if char == '"' {
-			if index == 0 || trimmedWhiteSpace[index] != '\\' {
-				for index2 := index + 1; index2 < length; index2++ {
-					nextChar := trimmedWhiteSpace[index2]
-					if nextChar == '"' && trimmedWhiteSpace[index2-1] != '\\' {
-						lastArgument = trimmedWhiteSpace[index+1 : index2]
-						replacedEscapedQuotes := strings.Replace(string(lastArgument), "\\\"", "\"", -1)
-						parameters = append(parameters, replacedEscapedQuotes)
-						lastArgument = make([]rune, 0)
-						index = index2
-						continue OUTER_LOOP
-					}
-				}
-
-				//No quoting end found
-				lastArgument = append(lastArgument, char)
+		case char == '"':
+			if _, end := parseDoubleQuotes(runes, index); end != -1 {
+				index = end
 			}
-		} else {
-			lastArgument = append(lastArgument, char)
+		case strings.ContainsRune(characters, char):
+			indices = append(indices, index)
+		}
+	}
+	return indices
+}
+
+func isParameterSeparator(char rune) bool {
+	return char == ' ' || char == '\t' || char == '\n' || char == '\r'
+}
+
+func indexOfRune(runes []rune, char rune, from int) int {
+	for index := from; index < len(runes); index++ {
+		if runes[index] == char {
+			return index
+		}
+	}
+	return -1
+}
+
+// expandVariable checks whether the runes start with $VAR or ${VAR} and
+// returns the value of the environment variable and the amount of runes
+// that it replaces. The length is 0 if there's no variable. Variable names
+// have to start with a letter or an underscore, so that placeholders such
+// as $1 and $@ and prices like $5 are kept as they are.
+func expandVariable(runes []rune) (string, int) {
+	if len(runes) < 2 || runes[0] != '$' {
+		return "", 0
+	}
+
+	if runes[1] == '{' {
+		end := indexOfRune(runes, '}', 2)
+		if end == -1 || !isVariableName(runes[2:end]) {
+			return "", 0
 		}
+		return os.Getenv(string(runes[2:end])), end + 1
 	}
 
-	if len(lastArgument) > 0 {
-		parameters = append(parameters, string(lastArgument))
+	end := 1
+	for end < len(runes) && isVariableName(runes[1:end+1]) {
+		end++
 	}
+	if end == 1 {
+		return "", 0
+	}
+	return os.Getenv(string(runes[1:end])), end
+}
 
-	return parameters
+func isVariableName(name []rune) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for index, char := range name {
+		if char != '_' && !(char >= 'a' && char <= 'z') && !(char >= 'A' && char <= 'Z') &&
+			(index == 0 || !(char >= '0' && char <= '9')) {
+			return false
+		}
+	}
+	return true
 }
 
 // PrintError message writes an error to the given io.Writer in the
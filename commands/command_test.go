@@ -2,15 +2,17 @@ package commands
 
 import (
 	"io"
+	"os"
 	"reflect"
 	"testing"
 )
 
 func TestParseCommand(t *testing.T) {
 	tests := []struct {
-		name  string
-		input string
-		want  []string
+		name    string
+		input   string
+		want    []string
+		wantErr bool
 	}{
 		{
 			name:  "no command",
@@ -33,9 +35,21 @@ func TestParseCommand(t *testing.T) {
 			input: "   ",
 			want:  nil,
 		}, {
-			name:  "A command with a string where the quote in the end was forgotten",
-			input: "hello \"sunny world",
-			want:  []string{"hello", "\"sunny", "world"},
+			name:    "A command with a string where the quote in the end was forgotten",
+			input:   "hello \"sunny world",
+			wantErr: true,
+		}, {
+			name:    "A command with a single quoted string where the quote in the end was forgotten",
+			input:   "hello 'sunny world",
+			wantErr: true,
+		}, {
+			name:    "A command with a string ending in an escaped quote",
+			input:   `hello "sunny world\"`,
+			wantErr: true,
+		}, {
+			name:    "unterminated quote after an apostrophe",
+			input:   `send don't "stop`,
+			wantErr: true,
 		}, {
 			name:  "A command with a backslash in the end",
 			input: "hello world\\",
@@ -80,18 +94,103 @@ func TestParseCommand(t *testing.T) {
 			name:  "command with one simple argument and a string containing an escaped quote",
 			input: "command argument \"argument2 is \\\" long\"",
 			want:  []string{"command", "argument", "argument2 is \" long"},
+		}, {
+			name:  "single quotes are taken literally",
+			input: `command 'a "b" \n $HOME ~'`,
+			want:  []string{"command", `a "b" \n $HOME ~`},
+		}, {
+			name:  "empty quotes",
+			input: `command '' ""`,
+			want:  []string{"command", "", ""},
+		}, {
+			name:  "adjacent quotes form a single parameter",
+			input: `command 'a b'"c d"e`,
+			want:  []string{"command", "a bc de"},
+		}, {
+			name:  "apostrophes inside of words",
+			input: `send don't won't 'quoted'`,
+			want:  []string{"send", "don't", "won't", "quoted"},
+		}, {
+			name:  "escaped whitespace",
+			input: `command my\ file.txt`,
+			want:  []string{"command", "my file.txt"},
+		}, {
+			name:  "escaped backslash and single quote",
+			input: `command a\\b don\'t`,
+			want:  []string{"command", `a\b`, "don't"},
+		}, {
+			name:  "escapes in double quotes",
+			input: `command "a\\b \" \n"`,
+			want:  []string{"command", `a\b " \n`},
+		}, {
+			name:  "windows path",
+			input: `file-send C:\Users\marcel\file.txt`,
+			want:  []string{"file-send", `C:\Users\marcel\file.txt`},
+		}, {
+			name:  "tabs and newlines separate parameters",
+			input: "command\ta\nb",
+			want:  []string{"command", "a", "b"},
+		}, {
+			name:  "home directory and variables are kept",
+			input: `send ~ ~/file.txt $HOME "${HOME}" $5`,
+			want:  []string{"send", "~", "~/file.txt", "$HOME", "${HOME}", "$5"},
+		}, {
+			name:  "double dash is passed on",
+			input: "friends search -- -Marcel",
+			want:  []string{"friends", "search", "--", "-Marcel"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := ParseCommand(tt.input); !reflect.DeepEqual(got, tt.want) {
+			got, err := ParseCommand(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("ParseCommand() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestQuoteParameter(t *testing.T) {
+	for _, parameter := range []string{"Marcel", "Marcel Davis", `say "hi"`, "a;b", `C:\Users`, "", "don't", "$HOME", "~", `\"`, "a|b>c"} {
+		t.Run(parameter, func(t *testing.T) {
+			got, _ := ParseCommand("command " + QuoteParameter(parameter))
+			if want := []string{"command", parameter}; !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseCommand(QuoteParameter(%q)) = %q, want %q", parameter, got, want)
+			}
+		})
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	os.Setenv("CORDLESS_TEST_VAR", "directory")
+	defer os.Unsetenv("CORDLESS_TEST_VAR")
+	os.Unsetenv("CORDLESS_TEST_UNSET")
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "", want: ""},
+		{path: "file.txt", want: "file.txt"},
+		{path: "~/file.txt", want: "~/file.txt"},
+		{path: "$CORDLESS_TEST_VAR/file.txt", want: "directory/file.txt"},
+		{path: "${CORDLESS_TEST_VAR}s/file.txt", want: "directorys/file.txt"},
+		{path: "a$CORDLESS_TEST_UNSET/file.txt", want: "a/file.txt"},
+		{path: "$5 $ ${}", want: "$5 $ ${}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := ExpandPath(tt.path); got != tt.want {
+				t.Errorf("ExpandPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 type cmd struct {
 	name    string
 	aliases []string
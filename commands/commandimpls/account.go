@@ -11,25 +11,11 @@ import (
 	"github.com/Bios-Marcel/cordless/ui/tviewutil"
 )
 
-const accountDocumentation = `[orange][::u]# account command[white]
-
-The account command allows you to manage multiple discord accounts within
-cordless.
-
-Subcommands:
-  * add         - Adds a new account
-  * delete      - Deletes the given account
-  * switch      - Allows you to switch accounts
-  * list        - Lists all available accounts
-  * current     - Displays the current account
-  * add-current - Adds the currently logged in token as a new account
-  * logout      - Logs out of the current account logged into cordless
-`
-
 // Account manages the users account
 type Account struct {
 	window        *ui.Window
 	accountLogout *AccountLogout
+	spec          *commands.Spec
 }
 
 // AccountLogout allows logging out of cordless. This clears the token saved
@@ -37,71 +23,110 @@ type Account struct {
 type AccountLogout struct {
 	window  *ui.Window
 	restart func()
+	spec    *commands.Spec
 }
 
 // NewAccount creates a ready-to-use Account command.
 func NewAccount(accountLogout *AccountLogout, window *ui.Window) *Account {
-	return &Account{window: window, accountLogout: accountLogout}
+	account := &Account{window: window, accountLogout: accountLogout}
+	accountNameArgument := &commands.Argument{
+		Name:        "name",
+		Description: "The name of a saved account.",
+		Completer:   commands.AccountCompleter(),
+	}
+	account.spec = &commands.Spec{
+		Name:    "account",
+		Aliases: []string{"profile"},
+		Summary: "manage multiple discord accounts",
+		Description: `The account command allows you to manage multiple discord accounts within
+cordless.`,
+		Subcommands: []*commands.Spec{
+			{
+				Name:    "add",
+				Aliases: []string{"create", "new"},
+				Summary: "Adds a new account",
+				Arguments: []*commands.Argument{
+					{Name: "name", Description: "The name for the new account."},
+					{Name: "token", Description: "The token used to log in to the account.", Secret: true},
+				},
+				Run: func(writer io.Writer, invocation *commands.Invocation) {
+					account.addAccount(writer, []string{invocation.Argument("name"), invocation.Argument("token")})
+				},
+			}, {
+				Name:      "delete",
+				Aliases:   []string{"remove"},
+				Summary:   "Deletes the given account",
+				Arguments: []*commands.Argument{accountNameArgument},
+				Run: func(writer io.Writer, invocation *commands.Invocation) {
+					deleteAccount(writer, invocation.Argument("name"))
+				},
+			}, {
+				Name:      "switch",
+				Aliases:   []string{"change"},
+				Summary:   "Allows you to switch accounts",
+				Arguments: []*commands.Argument{accountNameArgument},
+				Run: func(writer io.Writer, invocation *commands.Invocation) {
+					account.switchAccount(writer, invocation.Argument("name"))
+				},
+			}, {
+				Name:    "list",
+				Summary: "Lists all available accounts",
+				Run: func(writer io.Writer, invocation *commands.Invocation) {
+					account.listAccounts(writer)
+				},
+			}, {
+				Name:    "current",
+				Summary: "Displays the current account",
+				Run: func(writer io.Writer, invocation *commands.Invocation) {
+					account.currentAccount(writer)
+				},
+			}, {
+				Name:    "add-current",
+				Summary: "Adds the currently logged in token as a new account",
+				Arguments: []*commands.Argument{
+					{Name: "name", Description: "The name for the new account."},
+				},
+				Run: func(writer io.Writer, invocation *commands.Invocation) {
+					account.addCurrentAccount(writer, invocation.Argument("name"))
+				},
+			},
+			accountLogout.spec.WithName("logout", "sign-out", "signout", "logoff"),
+		},
+		Examples: []string{
+			"account add-current main",
+			"account switch alt",
+		},
+	}
+	return account
 }
 
 // NewAccountLogout creates a ready-to-use Logout command.
 func NewAccountLogout(restart func(), window *ui.Window) *AccountLogout {
-	return &AccountLogout{window: window, restart: restart}
+	accountLogout := &AccountLogout{window: window, restart: restart}
+	accountLogout.spec = &commands.Spec{
+		Name:    "account-logout",
+		Aliases: []string{"logout"},
+		Summary: "Logs out of the current account logged into cordless",
+		Run: func(writer io.Writer, invocation *commands.Invocation) {
+			accountLogout.logout(writer)
+		},
+	}
+	return accountLogout
 }
 
-// Execute runs the command piping its output into the supplied writer.
-func (account *Account) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) == 0 {
-		account.PrintHelp(writer)
-	} else {
-		switch parameters[0] {
-		case "add", "create", "new":
-			if len(parameters) != 3 {
-				account.printAccountAddHelp(writer)
-			} else {
-				account.addAccount(writer, parameters[1:])
-			}
-		case "delete", "remove":
-			if len(parameters) != 2 {
-				account.printAccountDeleteHelp(writer)
-			} else {
-				deleteAccount(writer, parameters[1])
-			}
-		case "switch", "change":
-			if len(parameters) != 2 {
-				account.printAccountSwitchHelp(writer)
-			} else {
-				account.switchAccount(writer, parameters[1])
-			}
-		case "list":
-			if len(parameters) != 1 {
-				account.printAccountListHelp(writer)
-			} else {
-				account.listAccounts(writer)
-			}
-		case "current":
-			if len(parameters) != 1 {
-				account.printAccountCurrentHelp(writer)
-			} else {
-				account.currentAccount(writer)
-			}
-		case "add-current":
-			if len(parameters) != 2 {
-				account.printAccountAddCurrentHelp(writer)
-			} else {
-				account.addCurrentAccount(writer, parameters[1])
-			}
-		case "logout", "sign-out", "signout", "logoff":
-			account.accountLogout.Execute(writer, parameters[1:])
-		default:
-			account.PrintHelp(writer)
-		}
+// Spec returns the declaration of the account command.
+func (account *Account) Spec() *commands.Spec {
+	return account.spec
+}
 
-	}
+// Spec returns the declaration of the account-logout command.
+func (accountLogout *AccountLogout) Spec() *commands.Spec {
+	return accountLogout.spec
 }
 
-func (account *Account) printAccountAddHelp(writer io.Writer) {
-	fmt.Fprintln(writer, "Usage: account add <Name> <Token>")
+// Execute runs the command piping its output into the supplied writer.
+func (account *Account) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(account.spec, writer, parameters)
 }
 
 func (account *Account) addAccount(writer io.Writer, parameters []string) {
@@ -123,10 +148,6 @@ func (account *Account) addAccount(writer io.Writer, parameters []string) {
 	fmt.Fprintf(writer, "The account '%s' has been created successfully.\n", newName)
 }
 
-func (account *Account) printAccountDeleteHelp(writer io.Writer) {
-	fmt.Fprintln(writer, "Usage `account delete <Name>`")
-}
-
 func deleteAccount(writer io.Writer, account string) {
 	var deletionSuccessful bool
 
@@ -149,10 +170,6 @@ func deleteAccount(writer io.Writer, account string) {
 
 }
 
-func (account *Account) printAccountSwitchHelp(writer io.Writer) {
-	fmt.Fprintln(writer, "Usage: account switch <Name>")
-}
-
 func (account *Account) switchAccount(writer io.Writer, accountName string) {
 	for _, acc := range config.Current.Accounts {
 		if acc.Name == accountName {
@@ -170,10 +187,6 @@ func (account *Account) switchAccount(writer io.Writer, accountName string) {
 	commands.PrintError(writer, "Error switching accounts", fmt.Sprintf("No account named '%s' was found", accountName))
 }
 
-func (account *Account) printAccountListHelp(writer io.Writer) {
-	fmt.Fprintln(writer, "Usage: account list")
-}
-
 func (account *Account) listAccounts(writer io.Writer) {
 	fmt.Fprintln(writer, "Available accounts:")
 	for _, acc := range config.Current.Accounts {
@@ -185,10 +198,6 @@ func (account *Account) listAccounts(writer io.Writer) {
 	}
 }
 
-func (account *Account) printAccountCurrentHelp(writer io.Writer) {
-	fmt.Fprintln(writer, "Usage: account current")
-}
-
 func (account *Account) currentAccount(writer io.Writer) {
 	var currentAccount *config.Account
 	for _, acc := range config.Current.Accounts {
@@ -205,34 +214,26 @@ func (account *Account) currentAccount(writer io.Writer) {
 	}
 }
 
-func (account *Account) printAccountAddCurrentHelp(writer io.Writer) {
-	fmt.Fprintln(writer, "Usage: account add-current <Name>")
-}
-
 func (account *Account) addCurrentAccount(writer io.Writer, name string) {
 	account.addAccount(writer, []string{name, config.Current.Token})
 }
 
 func (account *Account) Name() string {
-	return "account"
+	return account.spec.Name
 }
 
 func (account *Account) Aliases() []string {
-	return []string{"profile"}
+	return account.spec.Aliases
 }
 
-// PrintHelp prints a static help page for this command
+// PrintHelp prints the help page generated from the commands declaration.
 func (account *Account) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, accountDocumentation)
+	commands.PrintSpecHelp(writer, account.spec)
 }
 
 // Execute runs the command piping its output into the supplied writer.
 func (accountLogout *AccountLogout) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) != 1 {
-		accountLogout.PrintHelp(writer)
-	} else {
-		accountLogout.logout(writer)
-	}
+	commands.Dispatch(accountLogout.spec, writer, parameters)
 }
 
 func (accountLogout *AccountLogout) logout(writer io.Writer) {
@@ -258,18 +259,18 @@ func (accountLogout *AccountLogout) saveAndRestart(writer io.Writer) error {
 	return nil
 }
 
-// PrintHelp prints a static help page for this command
+// PrintHelp prints the help page generated from the commands declaration.
 func (accountLogout *AccountLogout) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, "Usage: account logout")
+	commands.PrintSpecHelp(writer, accountLogout.spec)
 }
 
 // Name returns the primary name for this command. This name will also be
 // used for listing the command in the commandlist.
 func (accountLogout *AccountLogout) Name() string {
-	return "account-logout"
+	return accountLogout.spec.Name
 }
 
 // Aliases are a list of aliases for this command. There might be none.
 func (accountLogout *AccountLogout) Aliases() []string {
-	return []string{"logout"}
+	return accountLogout.spec.Aliases
 }
@@ -0,0 +1,187 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// AliasCmd allows managing the command aliases saved in the configuration.
+type AliasCmd struct {
+	spec *commands.Spec
+}
+
+// NewAliasCommand creates a ready to use command for managing aliases.
+func NewAliasCommand() *AliasCmd {
+	cmd := &AliasCmd{}
+	aliasArgumentDescription := "The name that is typed instead of the actual commands."
+	cmd.spec = &commands.Spec{
+		Name:    "alias",
+		Aliases: []string{"aliases", "macro"},
+		Summary: "define shortcuts for one or more commands",
+		Description: `An alias is a name that is replaced with its definition before a command
+is executed. A definition may consist of multiple commands separated by
+semicolons. The parameters passed to an alias can be referenced via $1, $2
+and so on, or all of them at once via $@. If a definition doesn't reference
+any parameters, they are appended to it instead. Aliases are saved in the
+configuration and may have the same name as the command they invoke.
+
+Definitions containing spaces or semicolons have to be quoted.`,
+		Subcommands: []*commands.Spec{
+			{
+				Name:    "set",
+				Aliases: []string{"add", "create"},
+				Summary: "defines an alias or shows existing ones",
+				Arguments: []*commands.Argument{
+					{
+						Name:        "alias",
+						Description: aliasArgumentDescription + " If left out, all aliases are shown.",
+						Optional:    true,
+						Completer:   commands.AliasCompleter(),
+					}, {
+						Name:        "definition",
+						Description: "The commands that the alias is replaced with. If left out, the current definition is shown.",
+						Optional:    true,
+						Variadic:    true,
+					},
+				},
+				Run: cmd.set,
+			}, {
+				Name:    "list",
+				Aliases: []string{"show"},
+				Summary: "shows all aliases",
+				Run:     cmd.list,
+			}, {
+				Name:    "remove",
+				Aliases: []string{"delete", "unset"},
+				Summary: "removes an alias",
+				Arguments: []*commands.Argument{{
+					Name:        "alias",
+					Description: aliasArgumentDescription,
+					Completer:   commands.AliasCompleter(),
+				}},
+				Run: cmd.remove,
+			},
+		},
+		DefaultSubcommand: "set",
+		Examples: []string{
+			`alias standup "status set online; dm-open alice"`,
+			`alias greet "dm-open $1"`,
+			"alias remove standup",
+		},
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the alias command.
+func (cmd *AliasCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *AliasCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *AliasCmd) set(writer io.Writer, invocation *commands.Invocation) {
+	name := invocation.Argument("alias")
+	if name == "" {
+		cmd.list(writer, invocation)
+		return
+	}
+
+	definitionParts := invocation.Arguments("definition")
+	if len(definitionParts) == 0 {
+		definition, exists := config.Current.CommandAliases[name]
+		if !exists {
+			commands.PrintError(writer, "Error showing alias", fmt.Sprintf("The alias '%s' doesn't exist.", name))
+			return
+		}
+		fmt.Fprintf(writer, "%s = %s\n", name, tviewutil.Escape(definition))
+		return
+	}
+
+	if strings.ContainsAny(name, " \";") {
+		commands.PrintError(writer, "Error setting alias", "An alias name can't contain spaces, quotes or semicolons.")
+		return
+	}
+
+	//A single parameter was most likely quoted by the user. Multiple
+	//parameters have to be quoted again, as joining them would otherwise
+	//split parameters that contain spaces.
+	definition := definitionParts[0]
+	if len(definitionParts) > 1 {
+		quotedParts := make([]string, 0, len(definitionParts))
+		for _, part := range definitionParts {
+			quotedParts = append(quotedParts, commands.QuoteParameter(part))
+		}
+		definition = strings.Join(quotedParts, " ")
+	}
+
+	if config.Current.CommandAliases == nil {
+		config.Current.CommandAliases = make(map[string]string)
+	}
+	config.Current.CommandAliases[name] = definition
+	persistError := config.PersistConfig()
+	if persistError != nil {
+		commands.PrintError(writer, "Error saving configuration", persistError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "The alias '%s' has been set.\n", name)
+}
+
+func (cmd *AliasCmd) list(writer io.Writer, invocation *commands.Invocation) {
+	if len(config.Current.CommandAliases) == 0 {
+		fmt.Fprintln(writer, "There are no aliases.")
+		return
+	}
+
+	names := make([]string, 0, len(config.Current.CommandAliases))
+	for name := range config.Current.CommandAliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(writer, "%s = %s\n", name, tviewutil.Escape(config.Current.CommandAliases[name]))
+	}
+}
+
+func (cmd *AliasCmd) remove(writer io.Writer, invocation *commands.Invocation) {
+	name := invocation.Argument("alias")
+	if _, exists := config.Current.CommandAliases[name]; !exists {
+		commands.PrintError(writer, "Error removing alias", fmt.Sprintf("The alias '%s' doesn't exist.", name))
+		return
+	}
+
+	delete(config.Current.CommandAliases, name)
+	persistError := config.PersistConfig()
+	if persistError != nil {
+		commands.PrintError(writer, "Error saving configuration", persistError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "The alias '%s' has been removed.\n", name)
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *AliasCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *AliasCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *AliasCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
@@ -0,0 +1,200 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// BlockCmd blocks or unblocks users on discord, depending on how it has
+// been created.
+type BlockCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+	block   bool
+	spec    *commands.Spec
+}
+
+// NewBlockCommand creates a ready to use command for blocking users.
+func NewBlockCommand(window *ui.Window, session *discordgo.Session) *BlockCmd {
+	cmd := &BlockCmd{
+		window:  window,
+		session: session,
+		block:   true,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "block",
+		Summary: "blocks a user or lists all blocked users",
+		Description: `The block command blocks a user on discord. Their messages are hidden or
+replaced with a placeholder, see ShowPlaceholderForBlockedMessages, and
+they can't send you direct messages anymore. Blocking a friend removes the
+friend. Without a user, all blocked users are listed.
+
+In order to hide someones messages without discord knowing, use the
+"ignore" command instead.`,
+		Arguments: []*commands.Argument{{
+			Name:        "user",
+			Description: "The name, name#discriminator or ID of a user.",
+			Optional:    true,
+			Completer:   commands.UserCompleter(session.State),
+		}},
+		Examples: []string{
+			"block",
+			"block Marcel#7299",
+			"block 118456055842734083",
+		},
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// NewUnblockCommand creates a ready to use command for unblocking users.
+func NewUnblockCommand(window *ui.Window, session *discordgo.Session) *BlockCmd {
+	cmd := &BlockCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:        "unblock",
+		Summary:     "unblocks a user",
+		Description: "The unblock command unblocks a user that has been blocked on discord.",
+		Arguments: []*commands.Argument{{
+			Name:        "user",
+			Description: "The name, name#discriminator or ID of a blocked user.",
+			Completer:   commands.RelationshipCompleter(session.State, discordgo.RelationTypeBlocked),
+		}},
+		Examples: []string{
+			"unblock Marcel#7299",
+		},
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the block or unblock command.
+func (cmd *BlockCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *BlockCmd) Execute(writer io.Writer, parameters []string) {
+	if cmd.session.State.User.Bot {
+		fmt.Fprintln(writer, "[red]This command can't be used by bots due to Discord API restrictions.")
+		return
+	}
+
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *BlockCmd) run(writer io.Writer, invocation *commands.Invocation) {
+	nameOrID := invocation.Argument("user")
+	if nameOrID == "" {
+		cmd.list(writer)
+		return
+	}
+
+	if cmd.block {
+		cmd.blockUser(writer, nameOrID)
+	} else {
+		cmd.unblockUser(writer, nameOrID)
+	}
+}
+
+func (cmd *BlockCmd) list(writer io.Writer) {
+	var blockedCount int
+	for _, relationship := range cmd.session.State.Relationships {
+		if relationship.Type == discordgo.RelationTypeBlocked {
+			fmt.Fprintf(writer, "%s %s\n", relationship.User.ID, tviewutil.Escape(relationship.User.String()))
+			blockedCount++
+		}
+	}
+
+	if blockedCount == 0 {
+		fmt.Fprintln(writer, "You haven't blocked anyone.")
+	}
+}
+
+func (cmd *BlockCmd) blockUser(writer io.Writer, nameOrID string) {
+	user := findKnownUser(writer, cmd.session, "Error blocking user", nameOrID)
+	if user == nil {
+		return
+	}
+
+	if user.ID == cmd.session.State.User.ID {
+		commands.PrintError(writer, "Error blocking user", "You can't block yourself.")
+		return
+	}
+
+	if discordutil.IsBlocked(cmd.session.State, user) {
+		fmt.Fprintf(writer, "%s is already blocked.\n", tviewutil.Escape(user.String()))
+		return
+	}
+
+	if blockError := cmd.window.SetUserBlocked(user, true); blockError != nil {
+		commands.PrintError(writer, "Error blocking user", blockError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "%s has been blocked.\n", tviewutil.Escape(user.String()))
+}
+
+func (cmd *BlockCmd) unblockUser(writer io.Writer, nameOrID string) {
+	var blockedUsers []*discordgo.User
+	for _, relationship := range cmd.session.State.Relationships {
+		if relationship.Type == discordgo.RelationTypeBlocked {
+			blockedUsers = append(blockedUsers, relationship.User)
+		}
+	}
+
+	user := findUser(writer, "Error unblocking user", blockedUsers, nameOrID, "blocked user")
+	if user == nil {
+		return
+	}
+
+	if unblockError := cmd.window.SetUserBlocked(user, false); unblockError != nil {
+		commands.PrintError(writer, "Error unblocking user", unblockError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "%s has been unblocked.\n", tviewutil.Escape(user.String()))
+}
+
+// findKnownUser looks up a user known to the state, including users that
+// the current user only has a relationship with. Users that aren't known
+// can still be found by their ID. If there's no unique match, an error is
+// printed and nil returned.
+func findKnownUser(writer io.Writer, session *discordgo.Session, errorTitle, nameOrID string) *discordgo.User {
+	users := discordutil.GetKnownUsers(session.State)
+	if isSnowflake(nameOrID) && len(discordutil.FindUsers(users, nameOrID)) == 0 {
+		user, requestError := session.User(nameOrID)
+		if requestError != nil {
+			commands.PrintError(writer, errorTitle, requestError.Error())
+			return nil
+		}
+		return user
+	}
+
+	return findUser(writer, errorTitle, users, nameOrID, "user")
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *BlockCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *BlockCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *BlockCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
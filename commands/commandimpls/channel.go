@@ -0,0 +1,488 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// maxSlowmode is the maximum amount of seconds that discord allows users to
+// be rate limited for in a channel.
+const maxSlowmode = 21600
+
+// ChannelCmd allows administrating the channels and categories of the
+// currently selected server.
+type ChannelCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+	spec    *commands.Spec
+}
+
+// NewChannelCommand creates a ready to use command for administrating
+// channels and categories.
+func NewChannelCommand(window *ui.Window, session *discordgo.Session) *ChannelCmd {
+	cmd := &ChannelCmd{
+		window:  window,
+		session: session,
+	}
+	channelArgument := &commands.Argument{
+		Name:        "channel",
+		Description: "The name or ID of a channel in the current server.",
+		Completer:   commands.ChannelCompleter(session.State, window),
+	}
+	channelOrCategoryArgument := &commands.Argument{
+		Name:        "channel",
+		Description: "The name or ID of a channel or category in the current server.",
+		Completer:   commands.ChannelOrCategoryCompleter(session.State, window),
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "channel",
+		Aliases: []string{"channels"},
+		Summary: "create, edit and delete channels and categories",
+		Description: `The channel command allows administrating the text channels and
+categories of the currently selected server. All subcommands require
+the permission to manage channels.`,
+		Subcommands: []*commands.Spec{
+			{
+				Name:    "create",
+				Aliases: []string{"new"},
+				Summary: "creates a new text channel",
+				Arguments: []*commands.Argument{{
+					Name:        "name",
+					Description: "The name of the new channel.",
+				}},
+				Flags: []*commands.Flag{
+					{
+						Names:       []string{"-c", "--category"},
+						Value:       "category",
+						Description: "The name or ID of the category that the channel is created in.",
+						Completer:   commands.CategoryCompleter(session.State, window),
+					}, {
+						Names:       []string{"-t", "--topic"},
+						Value:       "topic",
+						Description: "The topic of the new channel.",
+					}, {
+						Names:       []string{"--nsfw"},
+						Description: "Marks the new channel as NSFW.",
+					},
+				},
+				Run: cmd.create,
+			}, {
+				Name:    "create-category",
+				Aliases: []string{"new-category"},
+				Summary: "creates a new category",
+				Arguments: []*commands.Argument{{
+					Name:        "name",
+					Description: "The name of the new category.",
+				}},
+				Run: cmd.createCategory,
+			}, {
+				Name:    "rename",
+				Summary: "renames a channel or category",
+				Arguments: []*commands.Argument{
+					channelOrCategoryArgument,
+					{
+						Name:        "name",
+						Description: "The new name.",
+					},
+				},
+				Run: cmd.rename,
+			}, {
+				Name:    "set-topic",
+				Aliases: []string{"topic"},
+				Summary: "changes or removes the topic of a channel",
+				Arguments: []*commands.Argument{
+					channelArgument,
+					{
+						Name:        "topic",
+						Description: "The new topic. Multiple words don't have to be quoted. Leaving it out removes the topic.",
+						Optional:    true,
+						Variadic:    true,
+					},
+				},
+				Run: cmd.setTopic,
+			}, {
+				Name:    "set-slowmode",
+				Aliases: []string{"slowmode"},
+				Summary: "changes how often each user can send a message in a channel",
+				Arguments: []*commands.Argument{
+					channelArgument,
+					{
+						Name:        "seconds",
+						Description: fmt.Sprintf("The amount of seconds between two messages, up to %d. 0 or off disables the slowmode.", maxSlowmode),
+						Completer:   commands.ValuesCompleter("off"),
+					},
+				},
+				Run: cmd.setSlowmode,
+			}, {
+				Name:    "set-nsfw",
+				Aliases: []string{"nsfw"},
+				Summary: "marks a channel as NSFW or not",
+				Arguments: []*commands.Argument{
+					channelArgument,
+					{
+						Name:        "enabled",
+						Description: "Either on or off.",
+						Completer:   commands.ValuesCompleter("on", "off"),
+					},
+				},
+				Run: cmd.setNSFW,
+			}, {
+				Name:    "move",
+				Summary: "moves a channel into a category or out of its category",
+				Arguments: []*commands.Argument{
+					channelArgument,
+					{
+						Name:        "category",
+						Description: "The name or ID of the target category. Leaving it out, removes the channel from its category.",
+						Optional:    true,
+						Completer:   commands.CategoryCompleter(session.State, window),
+					},
+				},
+				Run: cmd.move,
+			}, {
+				Name:    "reorder",
+				Aliases: []string{"position"},
+				Summary: "changes the position of a channel within its category",
+				Arguments: []*commands.Argument{
+					channelOrCategoryArgument,
+					{
+						Name:        "position",
+						Description: "The new position, starting at 1 for the upmost position.",
+					},
+				},
+				Run: cmd.reorder,
+			}, {
+				Name:      "delete",
+				Aliases:   []string{"remove"},
+				Summary:   "deletes a channel or category after asking for confirmation",
+				Arguments: []*commands.Argument{channelOrCategoryArgument},
+				Run:       cmd.delete,
+			},
+		},
+		Examples: []string{
+			"channel create -c Text --topic \"Anything goes\" off-topic",
+			"channel set-slowmode general 30",
+			"channel move off-topic Archive",
+			"channel reorder general 1",
+		},
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the channel command.
+func (cmd *ChannelCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ChannelCmd) Execute(writer io.Writer, parameters []string) {
+	if cmd.window.GetSelectedGuild() == nil {
+		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]In order to use this command, you have to be in a server.")
+		return
+	}
+
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *ChannelCmd) create(writer io.Writer, invocation *commands.Invocation) {
+	guild := cmd.window.GetSelectedGuild()
+	data := discordgo.GuildChannelCreateData{
+		Name: invocation.Argument("name"),
+		Type: discordgo.ChannelTypeGuildText,
+	}
+	data.Topic, _ = invocation.Flag("--topic")
+	_, data.NSFW = invocation.Flag("--nsfw")
+
+	if categoryName, set := invocation.Flag("--category"); set {
+		category := cmd.findChannel(writer, categoryName)
+		if category == nil {
+			return
+		}
+		if category.Type != discordgo.ChannelTypeGuildCategory {
+			commands.PrintError(writer, "Error creating channel", fmt.Sprintf("'%s' isn't a category.", categoryName))
+			return
+		}
+		if !cmd.checkChannelPermission(writer, "Error creating channel", category) {
+			return
+		}
+		data.ParentID = category.ID
+	} else if !discordutil.HasGuildPermission(guild.ID, cmd.session.State, discordgo.PermissionManageChannels) {
+		commands.PrintError(writer, "Error creating channel", "You don't have the permission to manage channels in this server.")
+		return
+	}
+
+	channel, createError := cmd.session.GuildChannelCreateComplex(guild.ID, data)
+	if createError != nil {
+		commands.PrintError(writer, "Error creating channel", createError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "Channel #%s has been created.\n", tviewutil.Escape(channel.Name))
+}
+
+func (cmd *ChannelCmd) createCategory(writer io.Writer, invocation *commands.Invocation) {
+	guild := cmd.window.GetSelectedGuild()
+	if !discordutil.HasGuildPermission(guild.ID, cmd.session.State, discordgo.PermissionManageChannels) {
+		commands.PrintError(writer, "Error creating category", "You don't have the permission to manage channels in this server.")
+		return
+	}
+
+	category, createError := cmd.session.GuildChannelCreate(guild.ID, invocation.Argument("name"), discordgo.ChannelTypeGuildCategory)
+	if createError != nil {
+		commands.PrintError(writer, "Error creating category", createError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "Category %s has been created.\n", tviewutil.Escape(category.Name))
+}
+
+func (cmd *ChannelCmd) rename(writer io.Writer, invocation *commands.Invocation) {
+	channel := cmd.findChannel(writer, invocation.Argument("channel"))
+	if channel == nil || !cmd.checkChannelPermission(writer, "Error renaming channel", channel) {
+		return
+	}
+
+	cmd.edit(writer, "Error renaming channel", channel, map[string]interface{}{
+		"name": invocation.Argument("name"),
+	})
+}
+
+func (cmd *ChannelCmd) setTopic(writer io.Writer, invocation *commands.Invocation) {
+	channel := cmd.findTextChannel(writer, "Error changing topic", invocation.Argument("channel"))
+	if channel == nil {
+		return
+	}
+
+	cmd.edit(writer, "Error changing topic", channel, map[string]interface{}{
+		"topic": strings.Join(invocation.Arguments("topic"), " "),
+	})
+}
+
+func (cmd *ChannelCmd) setSlowmode(writer io.Writer, invocation *commands.Invocation) {
+	channel := cmd.findTextChannel(writer, "Error changing slowmode", invocation.Argument("channel"))
+	if channel == nil {
+		return
+	}
+
+	var seconds int
+	if value := invocation.Argument("seconds"); value != "off" {
+		var parseError error
+		seconds, parseError = strconv.Atoi(value)
+		if parseError != nil || seconds < 0 || seconds > maxSlowmode {
+			commands.PrintError(writer, "Error changing slowmode",
+				fmt.Sprintf("'%s' has to be either off or a number between 0 and %d.", value, maxSlowmode))
+			return
+		}
+	}
+
+	cmd.edit(writer, "Error changing slowmode", channel, map[string]interface{}{
+		"rate_limit_per_user": seconds,
+	})
+}
+
+func (cmd *ChannelCmd) setNSFW(writer io.Writer, invocation *commands.Invocation) {
+	channel := cmd.findTextChannel(writer, "Error changing NSFW setting", invocation.Argument("channel"))
+	if channel == nil {
+		return
+	}
+
+	value := invocation.Argument("enabled")
+	nsfw, valid := parseSwitch(value)
+	if !valid {
+		commands.PrintError(writer, "Error changing NSFW setting", fmt.Sprintf("'%s' has to be either on or off.", tviewutil.Escape(value)))
+		return
+	}
+
+	cmd.edit(writer, "Error changing NSFW setting", channel, map[string]interface{}{
+		"nsfw": nsfw,
+	})
+}
+
+func (cmd *ChannelCmd) move(writer io.Writer, invocation *commands.Invocation) {
+	channel := cmd.findChannel(writer, invocation.Argument("channel"))
+	if channel == nil {
+		return
+	}
+	if channel.Type == discordgo.ChannelTypeGuildCategory {
+		commands.PrintError(writer, "Error moving channel", "Categories can't be moved into other categories.")
+		return
+	}
+	if !cmd.checkChannelPermission(writer, "Error moving channel", channel) {
+		return
+	}
+
+	//A nil parent_id removes the channel from its category.
+	var parentID interface{}
+	if categoryName := invocation.Argument("category"); categoryName != "" {
+		category := cmd.findChannel(writer, categoryName)
+		if category == nil {
+			return
+		}
+		if category.Type != discordgo.ChannelTypeGuildCategory {
+			commands.PrintError(writer, "Error moving channel", fmt.Sprintf("'%s' isn't a category.", categoryName))
+			return
+		}
+		if !cmd.checkChannelPermission(writer, "Error moving channel", category) {
+			return
+		}
+		parentID = category.ID
+	}
+
+	cmd.edit(writer, "Error moving channel", channel, map[string]interface{}{
+		"parent_id": parentID,
+	})
+}
+
+func (cmd *ChannelCmd) reorder(writer io.Writer, invocation *commands.Invocation) {
+	channel := cmd.findChannel(writer, invocation.Argument("channel"))
+	if channel == nil || !cmd.checkChannelPermission(writer, "Error reordering channels", channel) {
+		return
+	}
+
+	position, parseError := strconv.Atoi(invocation.Argument("position"))
+	if parseError != nil || position < 1 {
+		commands.PrintError(writer, "Error reordering channels", "The position has to be a number greater than 0.")
+		return
+	}
+
+	guild := cmd.window.GetSelectedGuild()
+	reordered := discordutil.ReorderChannel(guild.Channels, channel, position-1)
+	reorderError := cmd.session.GuildChannelsReorder(guild.ID, reordered)
+	if reorderError != nil {
+		commands.PrintError(writer, "Error reordering channels", reorderError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "%s has been moved.\n", tviewutil.Escape(channel.Name))
+}
+
+func (cmd *ChannelCmd) delete(writer io.Writer, invocation *commands.Invocation) {
+	channel := cmd.findChannel(writer, invocation.Argument("channel"))
+	if channel == nil || !cmd.checkChannelPermission(writer, "Error deleting channel", channel) {
+		return
+	}
+
+	question := fmt.Sprintf("Do you really want to delete the channel '%s'?", tviewutil.Escape(channel.Name))
+	if channel.Type == discordgo.ChannelTypeGuildCategory {
+		question = fmt.Sprintf("Do you really want to delete the category '%s'? Its channels will be kept.", tviewutil.Escape(channel.Name))
+	}
+	cmd.window.ShowConfirmationDialog(question, func(writer io.Writer) {
+		_, deleteError := cmd.session.ChannelDelete(channel.ID)
+		if deleteError != nil {
+			commands.PrintError(writer, "Error deleting channel", deleteError.Error())
+			return
+		}
+
+		fmt.Fprintf(writer, "%s has been deleted.\n", tviewutil.Escape(channel.Name))
+	})
+}
+
+// parseSwitch parses values such as on or off. The second return value
+// indicates whether the value was valid.
+func parseSwitch(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "on", "true", "yes":
+		return true, true
+	case "off", "false", "no":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// edit applies the changes to the channel and prints the result. The update
+// of the channeltree happens via the channel update event.
+func (cmd *ChannelCmd) edit(writer io.Writer, errorTitle string, channel *discordgo.Channel, changes map[string]interface{}) {
+	edited, editError := discordutil.EditChannel(cmd.session, channel.ID, changes)
+	if editError != nil {
+		commands.PrintError(writer, errorTitle, editError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "%s has been updated.\n", tviewutil.Escape(edited.Name))
+}
+
+// findChannel looks up a channel or category of the selected guild by name
+// or ID. If there's no unique match, an error is printed and nil returned.
+func (cmd *ChannelCmd) findChannel(writer io.Writer, nameOrID string) *discordgo.Channel {
+	return findGuildChannel(writer, cmd.window.GetSelectedGuild(), nameOrID)
+}
+
+// findGuildChannel looks up a channel or category of the given guild by name
+// or ID. If there's no unique match, an error is printed and nil returned.
+func findGuildChannel(writer io.Writer, guild *discordgo.Guild, nameOrID string) *discordgo.Channel {
+	matches := discordutil.FindGuildChannels(guild, nameOrID)
+	if len(matches) == 0 {
+		commands.PrintError(writer, "Error finding channel", fmt.Sprintf("There's no channel or category called '%s'.", tviewutil.Escape(nameOrID)))
+		return nil
+	}
+
+	if len(matches) > 1 {
+		ids := make([]string, 0, len(matches))
+		for _, match := range matches {
+			ids = append(ids, match.ID)
+		}
+		commands.PrintError(writer, "Error finding channel",
+			fmt.Sprintf("There are multiple channels called '%s'. Use one of these IDs instead: %s", tviewutil.Escape(nameOrID), strings.Join(ids, ", ")))
+		return nil
+	}
+
+	return matches[0]
+}
+
+// findTextChannel works like findChannel, but only accepts text channels
+// and checks whether they can be managed by the current user.
+func (cmd *ChannelCmd) findTextChannel(writer io.Writer, errorTitle, nameOrID string) *discordgo.Channel {
+	channel := cmd.findChannel(writer, nameOrID)
+	if channel == nil {
+		return nil
+	}
+
+	if channel.Type != discordgo.ChannelTypeGuildText && channel.Type != discordgo.ChannelTypeGuildNews {
+		commands.PrintError(writer, errorTitle, fmt.Sprintf("'%s' isn't a text channel.", tviewutil.Escape(nameOrID)))
+		return nil
+	}
+
+	if !cmd.checkChannelPermission(writer, errorTitle, channel) {
+		return nil
+	}
+
+	return channel
+}
+
+// checkChannelPermission prints an error if the current user isn't allowed
+// to manage the given channel.
+func (cmd *ChannelCmd) checkChannelPermission(writer io.Writer, errorTitle string, channel *discordgo.Channel) bool {
+	if discordutil.HasPermission(channel.ID, cmd.session.State, discordgo.PermissionManageChannels) {
+		return true
+	}
+
+	commands.PrintError(writer, errorTitle, fmt.Sprintf("You don't have the permission to manage '%s'.", tviewutil.Escape(channel.Name)))
+	return false
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *ChannelCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ChannelCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ChannelCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
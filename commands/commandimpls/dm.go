@@ -4,42 +4,55 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/Bios-Marcel/cordless/commands"
 	"github.com/Bios-Marcel/cordless/discordutil"
 	"github.com/Bios-Marcel/cordless/ui"
 	"github.com/Bios-Marcel/discordgo"
 )
 
-const (
-	dmOpenHelpPage = `[::b]NAME
-	dm-open - open or create a dm channel
-
-[::b]SYNOPSIS
-	[::b]dm-open <Username|Username#NNNN|User-ID>
-
-[::b]DESCRIPTION
-	If the user can be found in your local cache, a new dm channel is created
-	or an existing one loaded.`
-)
-
 // DMOpenCmd allows to open / create DM channels.
 type DMOpenCmd struct {
 	session *discordgo.Session
 	window  *ui.Window
+	spec    *commands.Spec
 }
 
 // NewDMOpenCmd creates a ready to use command to open / create DM channels.
 func NewDMOpenCmd(session *discordgo.Session, window *ui.Window) *DMOpenCmd {
-	return &DMOpenCmd{session, window}
+	cmd := &DMOpenCmd{
+		session: session,
+		window:  window,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "dm-open",
+		Aliases: []string{"dm-start", "dm-new", "dm-show"},
+		Summary: "open or create a dm channel",
+		Description: `If the user can be found in your local cache, a new dm channel is created
+or an existing one loaded.`,
+		Arguments: []*commands.Argument{{
+			Name:        "user",
+			Description: "The users name, name#discriminator or ID.",
+			Completer:   commands.UserCompleter(session.State),
+		}},
+		Examples: []string{
+			"dm-open Marcel#7299",
+		},
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the dm-open command.
+func (cmd *DMOpenCmd) Spec() *commands.Spec {
+	return cmd.spec
 }
 
 // Execute runs the command piping its output into the supplied writer.
 func (cmd *DMOpenCmd) Execute(writer io.Writer, parameters []string) {
-	//We expect exactly one user
-	if len(parameters) != 1 {
-		cmd.PrintHelp(writer)
-		return
-	}
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
 
+func (cmd *DMOpenCmd) run(writer io.Writer, invocation *commands.Invocation) {
 	//FIXME Pretty much copied from friends.go. Can i somehow abstract this away?
 
 	users, err := cmd.session.State.Users()
@@ -48,7 +61,7 @@ func (cmd *DMOpenCmd) Execute(writer io.Writer, parameters []string) {
 		return
 	}
 
-	input := parameters[0]
+	input := invocation.Argument("user")
 	var matches []*discordgo.User
 	for _, user := range users {
 		if user.ID == input || user.Username == input || user.String() == input {
@@ -91,16 +104,16 @@ func (cmd *DMOpenCmd) Execute(writer io.Writer, parameters []string) {
 
 // PrintHelp prints a static help page for this command
 func (cmd *DMOpenCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, dmOpenHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 // Name returns the primary name for this command. This name will also be
 // used for listing the command in the commandlist.
 func (cmd *DMOpenCmd) Name() string {
-	return "dm-open"
+	return cmd.spec.Name
 }
 
 // Aliases are a list of aliases for this command. There might be none.
 func (cmd *DMOpenCmd) Aliases() []string {
-	return []string{"dm-start", "dm-new", "dm-show"}
+	return cmd.spec.Aliases
 }
@@ -20,79 +20,86 @@ import (
 	"github.com/Bios-Marcel/cordless/util/files"
 )
 
-const fileSendDocumentation = `[::b]NAME
-	file-send - send files from your local machine
-
-[::b]SYNOPSIS
-	[::b]file-send [OPTION[]... <FILE_PATH>...
-
-[::b]DESCRIPTION
-	The file-send command allows you to send multiple files to your current channel.
-
-	[::b]OPTIONS
-	[::b]-b, --bulk
-		Zips all files and sends them as a single file.
-		Without this option, the folder structure won't be preserved.
-	[::b]-r, --recursive
-		Allow sending folders as well
-
-[::b]EXAMPLES
-	[gray]$ file-send ~/file.txt
-	[gray]$ file-send -r ~/folder
-	[gray]$ file-send -r -b ~/folder
-	[gray]$ file-send ~/file1.txt ~/file2.txt
-	[gray]$ file-send "~/file one.txt" ~/file2.txt
-	[gray]$ file-send -b "~/file one.txt" ~/file2.txt
-	[gray]$ file-send -b -r "~/file one.txt" ~/folder ~/file2.txt`
-
 // FileSend represents the command used to send multiple files to a channel.
 type FileSend struct {
 	discord *discordgo.Session
 	window  *ui.Window
+	spec    *commands.Spec
 }
 
 // NewFileSendCommand creates a ready to use FileSend instance.
 func NewFileSendCommand(discord *discordgo.Session, window *ui.Window) *FileSend {
-	return &FileSend{
+	cmd := &FileSend{
 		discord: discord,
 		window:  window,
 	}
+	cmd.spec = &commands.Spec{
+		Name:        "file-send",
+		Aliases:     []string{"filesend", "sendfile", "send-file", "file-upload", "upload-file"},
+		Summary:     "send files from your local machine",
+		Description: "The file-send command allows you to send multiple files to your current channel.",
+		Arguments: []*commands.Argument{{
+			Name:        "path",
+			Description: "The files to send. Directories require the '-r' flag.",
+			Optional:    true,
+			Variadic:    true,
+			Completer:   commands.FileCompleter(),
+		}},
+		Flags: []*commands.Flag{
+			{
+				Names: []string{"-b", "--bulk"},
+				Description: `Zips all files and sends them as a single file.
+Without this option, the folder structure won't be preserved.`,
+			}, {
+				Names:       []string{"-r", "--recursive"},
+				Description: "Allow sending folders as well",
+			},
+		},
+		Examples: []string{
+			"file-send ~/file.txt",
+			"file-send -r ~/folder",
+			"file-send -r -b ~/folder",
+			"file-send ~/file1.txt ~/file2.txt",
+			`file-send "~/file one.txt" ~/file2.txt`,
+			`file-send -b "~/file one.txt" ~/file2.txt`,
+			`file-send -b -r "~/file one.txt" ~/folder ~/file2.txt`,
+		},
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the file-send command.
+func (cmd *FileSend) Spec() *commands.Spec {
+	return cmd.spec
 }
 
 // Execute runs the command piping its output into the supplied writer.
 func (cmd *FileSend) Execute(writer io.Writer, parameters []string) {
-	channel := cmd.window.GetSelectedChannel()
-	if channel == nil {
+	if cmd.window.GetSelectedChannel() == nil {
 		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]In order to use this command, you have to be in a channel.")
 		return
 	}
 
-	if len(parameters) == 0 {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *FileSend) run(writer io.Writer, invocation *commands.Invocation) {
+	if len(invocation.Arguments("path")) == 0 {
 		cmd.PrintHelp(writer)
 		return
 	}
 
-	var filteredParameters []string
+	channel := cmd.window.GetSelectedChannel()
 	//Will cause all files in folders to be upload. This counts for subfolders as well.
-	var recursive bool
+	_, recursive := invocation.Flag("-r")
 	//Puts all files into one zip.
-	var bulk bool
-
-	//Parse flags and sort them out for further processing.
-	for _, parameter := range parameters {
-		if parameter == "-r" || parameter == "--recursive" {
-			recursive = true
-		} else if parameter == "-b" || parameter == "--bulk" {
-			bulk = true
-		} else {
-			filteredParameters = append(filteredParameters, parameter)
-		}
-	}
+	_, bulk := invocation.Flag("-b")
 
 	//Assume that all leftofer parameters are paths and convert them to absolute paths.
 	var consumablePaths []string
-	for _, parameter := range filteredParameters {
-		resolvedPath, resolveError := files.ToAbsolutePath(parameter)
+	for _, parameter := range invocation.Arguments("path") {
+		resolvedPath, resolveError := files.ToAbsolutePath(commands.ExpandPath(parameter))
 		if resolveError != nil {
 			commands.PrintError(writer, "Error reading file", resolveError.Error())
 			return
@@ -171,15 +178,15 @@ func (cmd *FileSend) Execute(writer io.Writer, parameters []string) {
 
 // Name represents the main-name of the command.
 func (cmd *FileSend) Name() string {
-	return "file-send"
+	return cmd.spec.Name
 }
 
 // Aliases represents all available aliases this command can be called with.
 func (cmd *FileSend) Aliases() []string {
-	return []string{"filesend", "sendfile", "send-file", "file-upload", "upload-file"}
+	return cmd.spec.Aliases
 }
 
 // PrintHelp prints the help for the FileSend command.
 func (cmd *FileSend) PrintHelp(writer io.Writer) {
-	fmt.Fprint(writer, fileSendDocumentation)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
@@ -0,0 +1,355 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// GroupCmd allows creating and managing groups, which are private chats
+// with multiple users.
+type GroupCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+	spec    *commands.Spec
+}
+
+// NewGroupCommand creates a ready to use command for managing groups.
+func NewGroupCommand(window *ui.Window, session *discordgo.Session) *GroupCmd {
+	cmd := &GroupCmd{
+		window:  window,
+		session: session,
+	}
+	groupFlag := &commands.Flag{
+		Names:       []string{"-g", "--group"},
+		Value:       "group",
+		Description: "The name or ID of a group. By default the current group is used.",
+		Completer:   commands.GroupCompleter(session.State),
+	}
+	friendArgumentDescription := "The name, name#discriminator or ID of a friend."
+	cmd.spec = &commands.Spec{
+		Name:    "group",
+		Aliases: []string{"groups"},
+		Summary: "create, edit and leave groups",
+		Description: `The group command allows creating groups with your friends and managing
+the groups you are a member of. Only the owner of a group can remove its
+members. Groups can also be managed via the private chat list.`,
+		Subcommands: []*commands.Spec{
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Summary: "lists all groups you are a member of",
+				Run:     cmd.list,
+			}, {
+				Name:    "create",
+				Aliases: []string{"new"},
+				Summary: "creates a group with the given friends",
+				Arguments: []*commands.Argument{{
+					Name:        "friends",
+					Description: "The names, name#discriminators or IDs of the friends to create the group with.",
+					Variadic:    true,
+					Completer:   commands.RelationshipCompleter(session.State, discordgo.RelationTypeFriend),
+				}},
+				Run: cmd.create,
+			}, {
+				Name:    "add",
+				Summary: "adds a friend to a group",
+				Arguments: []*commands.Argument{{
+					Name:        "friend",
+					Description: friendArgumentDescription,
+					Completer:   commands.RelationshipCompleter(session.State, discordgo.RelationTypeFriend),
+				}},
+				Flags: []*commands.Flag{groupFlag},
+				Run:   cmd.add,
+			}, {
+				Name:    "remove",
+				Aliases: []string{"kick"},
+				Summary: "removes a member from a group you own",
+				Arguments: []*commands.Argument{{
+					Name:        "user",
+					Description: "The name, name#discriminator or ID of a member of the group.",
+					Completer:   commands.UserCompleter(session.State),
+				}},
+				Flags: []*commands.Flag{groupFlag},
+				Run:   cmd.remove,
+			}, {
+				Name:    "rename",
+				Summary: "changes the name of a group",
+				Arguments: []*commands.Argument{{
+					Name:        "name",
+					Description: "The new name. Multiple words don't have to be quoted. Leaving it out removes the name.",
+					Optional:    true,
+					Variadic:    true,
+				}},
+				Flags: []*commands.Flag{groupFlag},
+				Run:   cmd.rename,
+			}, {
+				Name:    "set-icon",
+				Aliases: []string{"icon"},
+				Summary: "changes the icon of a group",
+				Arguments: []*commands.Argument{{
+					Name:        "file",
+					Description: "A local PNG, JPEG or GIF image. Leaving it out removes the icon.",
+					Optional:    true,
+					Completer:   commands.FileCompleter(),
+				}},
+				Flags: []*commands.Flag{groupFlag},
+				Run:   cmd.setIcon,
+			}, {
+				Name:    "leave",
+				Aliases: []string{"exit", "quit"},
+				Summary: "leaves a group after asking for confirmation",
+				Flags:   []*commands.Flag{groupFlag},
+				Run:     cmd.leave,
+			},
+		},
+		DefaultSubcommand: "list",
+		Examples: []string{
+			"group create Marcel#7299 alice",
+			"group add --group Gophers bob",
+			"group rename Weekend plans",
+			"group set-icon ~/pictures/icon.png",
+		},
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the group command.
+func (cmd *GroupCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *GroupCmd) Execute(writer io.Writer, parameters []string) {
+	if cmd.session.State.User.Bot {
+		fmt.Fprintln(writer, "[red]This command can't be used by bots due to Discord API restrictions.")
+		return
+	}
+
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *GroupCmd) list(writer io.Writer, invocation *commands.Invocation) {
+	var groupCount int
+	for _, channel := range cmd.session.State.PrivateChannels {
+		if channel.Type == discordgo.ChannelTypeGroupDM {
+			//The recipients don't include the current user.
+			fmt.Fprintf(writer, "%s %s (%d members)\n", channel.ID, discordutil.GetPrivateChannelName(channel), len(channel.Recipients)+1)
+			groupCount++
+		}
+	}
+
+	if groupCount == 0 {
+		fmt.Fprintln(writer, "You aren't a member of any group.")
+	}
+}
+
+func (cmd *GroupCmd) create(writer io.Writer, invocation *commands.Invocation) {
+	friends := discordutil.GetFriends(cmd.session.State)
+	var recipientIDs []string
+	for _, name := range invocation.Arguments("friends") {
+		friend := findUser(writer, "Error creating group", friends, name, "friend")
+		if friend == nil {
+			return
+		}
+		recipientIDs = append(recipientIDs, friend.ID)
+	}
+
+	//The group is added to the private chat list via the channel create event.
+	group, createError := discordutil.CreateGroupChannel(cmd.session, recipientIDs)
+	if createError != nil {
+		commands.PrintError(writer, "Error creating group", createError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "The group %s has been created.\n", discordutil.GetPrivateChannelName(group))
+}
+
+func (cmd *GroupCmd) add(writer io.Writer, invocation *commands.Invocation) {
+	group := cmd.findGroup(writer, "Error adding friend", invocation)
+	if group == nil {
+		return
+	}
+
+	friend := findUser(writer, "Error adding friend", discordutil.GetFriends(cmd.session.State), invocation.Argument("friend"), "friend")
+	if friend == nil {
+		return
+	}
+
+	addError := discordutil.AddGroupRecipient(cmd.session, group.ID, friend.ID)
+	if addError != nil {
+		commands.PrintError(writer, "Error adding friend", addError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "%s has been added to %s.\n", tviewutil.Escape(friend.String()), discordutil.GetPrivateChannelName(group))
+}
+
+func (cmd *GroupCmd) remove(writer io.Writer, invocation *commands.Invocation) {
+	group := cmd.findGroup(writer, "Error removing member", invocation)
+	if group == nil {
+		return
+	}
+
+	member := findUser(writer, "Error removing member", group.Recipients, invocation.Argument("user"), "member of this group")
+	if member == nil {
+		return
+	}
+
+	removeError := discordutil.RemoveGroupRecipient(cmd.session, group.ID, member.ID)
+	if removeError != nil {
+		commands.PrintError(writer, "Error removing member", removeError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "%s has been removed from %s.\n", tviewutil.Escape(member.String()), discordutil.GetPrivateChannelName(group))
+}
+
+func (cmd *GroupCmd) rename(writer io.Writer, invocation *commands.Invocation) {
+	group := cmd.findGroup(writer, "Error renaming group", invocation)
+	if group == nil {
+		return
+	}
+
+	//Without a name, the names of the members are shown instead.
+	var name interface{}
+	if words := invocation.Arguments("name"); len(words) > 0 {
+		name = strings.Join(words, " ")
+	}
+
+	cmd.edit(writer, "Error renaming group", group, map[string]interface{}{
+		"name": name,
+	})
+}
+
+func (cmd *GroupCmd) setIcon(writer io.Writer, invocation *commands.Invocation) {
+	group := cmd.findGroup(writer, "Error changing icon", invocation)
+	if group == nil {
+		return
+	}
+
+	var icon interface{}
+	if path := invocation.Argument("file"); path != "" {
+		encodedIcon, loadError := discordutil.LoadImage(commands.ExpandPath(path))
+		if loadError != nil {
+			commands.PrintError(writer, "Error changing icon", loadError.Error())
+			return
+		}
+		icon = encodedIcon
+	}
+
+	cmd.edit(writer, "Error changing icon", group, map[string]interface{}{
+		"icon": icon,
+	})
+}
+
+func (cmd *GroupCmd) leave(writer io.Writer, invocation *commands.Invocation) {
+	group := cmd.findGroup(writer, "Error leaving group", invocation)
+	if group == nil {
+		return
+	}
+
+	name := discordutil.GetPrivateChannelName(group)
+	cmd.window.ShowConfirmationDialog(fmt.Sprintf("Do you really want to leave '%s'?", name), func(writer io.Writer) {
+		//The group is removed from the private chat list via the channel
+		//delete event.
+		_, leaveError := cmd.session.ChannelDelete(group.ID)
+		if leaveError != nil {
+			commands.PrintError(writer, "Error leaving group", leaveError.Error())
+			return
+		}
+
+		fmt.Fprintf(writer, "You have left %s.\n", name)
+	})
+}
+
+// edit applies the changes to the group and prints the result. The update
+// of the private chat list happens via the channel update event.
+func (cmd *GroupCmd) edit(writer io.Writer, errorTitle string, group *discordgo.Channel, changes map[string]interface{}) {
+	edited, editError := discordutil.EditChannel(cmd.session, group.ID, changes)
+	if editError != nil {
+		commands.PrintError(writer, errorTitle, editError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "%s has been updated.\n", discordutil.GetPrivateChannelName(edited))
+}
+
+// findGroup returns the group passed via the group flag or the currently
+// selected group. If there's no unique match, an error is printed and nil
+// returned.
+func (cmd *GroupCmd) findGroup(writer io.Writer, errorTitle string, invocation *commands.Invocation) *discordgo.Channel {
+	nameOrID, set := invocation.Flag("--group")
+	if !set {
+		channel := cmd.window.GetSelectedChannel()
+		if channel == nil || channel.Type != discordgo.ChannelTypeGroupDM {
+			commands.PrintError(writer, errorTitle, "In order to use this command without --group, you have to be in a group.")
+			return nil
+		}
+		return channel
+	}
+
+	matches := discordutil.FindGroupChannels(cmd.session.State, nameOrID)
+	if len(matches) == 0 {
+		commands.PrintError(writer, errorTitle, fmt.Sprintf("There's no group called '%s'.", tviewutil.Escape(nameOrID)))
+		return nil
+	}
+
+	if len(matches) > 1 {
+		ids := make([]string, 0, len(matches))
+		for _, match := range matches {
+			ids = append(ids, match.ID)
+		}
+		commands.PrintError(writer, errorTitle,
+			fmt.Sprintf("There are multiple groups called '%s'. Use one of these IDs instead: %s", tviewutil.Escape(nameOrID), strings.Join(ids, ", ")))
+		return nil
+	}
+
+	return matches[0]
+}
+
+// findUser looks up a user by name, name#discriminator or ID. If there's no
+// unique match, an error is printed and nil returned. The kind describes the
+// users that are searched, for example "friend".
+func findUser(writer io.Writer, errorTitle string, users []*discordgo.User, nameOrID, kind string) *discordgo.User {
+	matches := discordutil.FindUsers(users, nameOrID)
+	if len(matches) == 0 {
+		commands.PrintError(writer, errorTitle, fmt.Sprintf("There's no %s called '%s'.", kind, tviewutil.Escape(nameOrID)))
+		return nil
+	}
+
+	if len(matches) > 1 {
+		names := make([]string, 0, len(matches))
+		for _, match := range matches {
+			names = append(names, match.String())
+		}
+		commands.PrintError(writer, errorTitle,
+			fmt.Sprintf("There are multiple users called '%s'. Use one of these instead: %s", tviewutil.Escape(nameOrID), tviewutil.Escape(strings.Join(names, ", "))))
+		return nil
+	}
+
+	return matches[0]
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *GroupCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *GroupCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *GroupCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
@@ -0,0 +1,149 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/ui"
+)
+
+// HistoryCmd allows viewing, re-running and clearing the command history.
+type HistoryCmd struct {
+	window *ui.Window
+	spec   *commands.Spec
+}
+
+// NewHistoryCommand creates a ready to use command for accessing the
+// command history of the given window.
+func NewHistoryCommand(window *ui.Window) *HistoryCmd {
+	cmd := &HistoryCmd{
+		window: window,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "history",
+		Summary: "view and re-run previously entered commands",
+		Description: `The history command allows you to list the commands that you've previously
+entered, run one of them again or clear the history. Commands containing
+secrets, such as passwords, tokens or TFA codes, are never part of the
+history.`,
+		Subcommands: []*commands.Spec{
+			{
+				Name:    "list",
+				Aliases: []string{"show"},
+				Summary: "lists all entries, the most recent one last",
+				Run:     cmd.list,
+			}, {
+				Name:    "run",
+				Aliases: []string{"exec", "execute"},
+				Summary: "runs an entry again",
+				Arguments: []*commands.Argument{{
+					Name:        "index",
+					Description: "The index shown by 'history list'. Defaults to the most recent entry.",
+					Optional:    true,
+				}},
+				Run: cmd.run,
+			}, {
+				Name:    "clear",
+				Aliases: []string{"delete", "reset"},
+				Summary: "removes all entries",
+				Run:     cmd.clear,
+			},
+		},
+		DefaultSubcommand: "list",
+		Examples: []string{
+			"history",
+			"history run 12",
+		},
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the history command.
+func (cmd *HistoryCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *HistoryCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *HistoryCmd) list(writer io.Writer, invocation *commands.Invocation) {
+	history := cmd.window.GetCommandHistory()
+	if history.Len() == 0 {
+		fmt.Fprintln(writer, "The history is empty.")
+		return
+	}
+
+	for index := 0; index < history.Len(); index++ {
+		fmt.Fprintf(writer, "%5d  %s\n", index+1, history.Get(index))
+	}
+}
+
+func (cmd *HistoryCmd) run(writer io.Writer, invocation *commands.Invocation) {
+	history := cmd.window.GetCommandHistory()
+
+	var entry string
+	if indexParameter := invocation.Argument("index"); indexParameter != "" {
+		index, parseError := strconv.Atoi(indexParameter)
+		if parseError != nil || index < 1 || index > history.Len() {
+			commands.PrintError(writer, "Error running history entry",
+				fmt.Sprintf("'%s' isn't a valid index, see 'history list'.", indexParameter))
+			return
+		}
+		entry = history.Get(index - 1)
+	} else {
+		//Previous invocations of the history command are skipped, as
+		//running them again would most likely just run this command again.
+		for index := history.Len() - 1; index >= 0; index-- {
+			if !cmd.isHistoryCommand(history.Get(index)) {
+				entry = history.Get(index)
+				break
+			}
+		}
+		if entry == "" {
+			fmt.Fprintln(writer, "There is no entry that could be run again.")
+			return
+		}
+	}
+
+	if cmd.isHistoryCommand(entry) {
+		commands.PrintError(writer, "Error running history entry", "The history command can't run itself.")
+		return
+	}
+
+	cmd.window.ExecuteCommand(entry)
+}
+
+func (cmd *HistoryCmd) isHistoryCommand(entry string) bool {
+	parts, parseError := commands.ParseCommand(entry)
+	return parseError == nil && len(parts) > 0 && commands.CommandEquals(cmd, parts[0])
+}
+
+func (cmd *HistoryCmd) clear(writer io.Writer, invocation *commands.Invocation) {
+	clearError := cmd.window.GetCommandHistory().Clear()
+	if clearError != nil {
+		commands.PrintError(writer, "Error clearing the history", clearError.Error())
+		return
+	}
+
+	fmt.Fprintln(writer, "The history has been cleared.")
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *HistoryCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *HistoryCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *HistoryCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
@@ -0,0 +1,219 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// IgnoreCmd manages the users whose messages are hidden without letting
+// discord know.
+type IgnoreCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+	spec    *commands.Spec
+}
+
+// NewIgnoreCommand creates a ready to use command for ignoring users.
+func NewIgnoreCommand(window *ui.Window, session *discordgo.Session) *IgnoreCmd {
+	cmd := &IgnoreCmd{
+		window:  window,
+		session: session,
+	}
+	serverFlag := &commands.Flag{
+		Names:       []string{"-s", "--server"},
+		Description: "Only applies to the selected server instead of all servers and private chats.",
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "ignore",
+		Aliases: []string{"ignored"},
+		Summary: "hides messages of users without discord knowing",
+		Description: `The ignore command manages a list of users whose messages are hidden or
+replaced with a placeholder, see ShowPlaceholderForBlockedMessages. Unlike
+blocking, discord doesn't know about ignored users. Users can be ignored
+everywhere or only in the selected server. The list is saved in the
+configuration.`,
+		Subcommands: []*commands.Spec{
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Summary: "lists all ignored users",
+				Run:     cmd.list,
+			}, {
+				Name:    "add",
+				Summary: "ignores a user",
+				Arguments: []*commands.Argument{{
+					Name:        "user",
+					Description: "The name, name#discriminator or ID of a user.",
+					Completer:   commands.UserCompleter(session.State),
+				}},
+				Flags: []*commands.Flag{serverFlag},
+				Run:   cmd.add,
+			}, {
+				Name:    "remove",
+				Aliases: []string{"delete", "unignore"},
+				Summary: "stops ignoring a user",
+				Arguments: []*commands.Argument{{
+					Name:        "user",
+					Description: "The name, name#discriminator or ID of an ignored user.",
+					Completer:   commands.IgnoredUserCompleter(),
+				}},
+				Flags: []*commands.Flag{serverFlag},
+				Run:   cmd.remove,
+			},
+		},
+		DefaultSubcommand: "list",
+		Examples: []string{
+			"ignore add Marcel#7299",
+			"ignore add --server Marcel",
+			"ignore remove Marcel#7299",
+		},
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the ignore command.
+func (cmd *IgnoreCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *IgnoreCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *IgnoreCmd) list(writer io.Writer, invocation *commands.Invocation) {
+	if len(config.Current.IgnoredUsers) == 0 {
+		fmt.Fprintln(writer, "You aren't ignoring anyone.")
+		return
+	}
+
+	for _, ignoredUser := range config.Current.IgnoredUsers {
+		fmt.Fprintf(writer, "%s %s (%s)\n", ignoredUser.UserID, tviewutil.Escape(ignoredUser.Name), cmd.describeScope(ignoredUser.GuildID))
+	}
+}
+
+func (cmd *IgnoreCmd) add(writer io.Writer, invocation *commands.Invocation) {
+	guildID, ok := cmd.getScope(writer, "Error ignoring user", invocation)
+	if !ok {
+		return
+	}
+
+	user := findKnownUser(writer, cmd.session, "Error ignoring user", invocation.Argument("user"))
+	if user == nil {
+		return
+	}
+
+	if user.ID == cmd.session.State.User.ID {
+		commands.PrintError(writer, "Error ignoring user", "You can't ignore yourself.")
+		return
+	}
+
+	if !config.Current.IgnoreUser(user.ID, user.String(), guildID) {
+		fmt.Fprintf(writer, "%s is already ignored %s.\n", tviewutil.Escape(user.String()), cmd.describeScope(guildID))
+		return
+	}
+
+	if !cmd.persist(writer, "Error ignoring user") {
+		return
+	}
+
+	fmt.Fprintf(writer, "%s is now ignored %s.\n", tviewutil.Escape(user.String()), cmd.describeScope(guildID))
+}
+
+func (cmd *IgnoreCmd) remove(writer io.Writer, invocation *commands.Invocation) {
+	guildID, ok := cmd.getScope(writer, "Error unignoring user", invocation)
+	if !ok {
+		return
+	}
+
+	nameOrID := invocation.Argument("user")
+	var ignoredUser *config.IgnoredUser
+	for _, candidate := range config.Current.IgnoredUsers {
+		if candidate.GuildID != guildID {
+			continue
+		}
+
+		username := strings.SplitN(candidate.Name, "#", 2)[0]
+		if candidate.UserID == nameOrID || candidate.Name == nameOrID || username == nameOrID {
+			ignoredUser = candidate
+			break
+		}
+	}
+
+	if ignoredUser == nil {
+		commands.PrintError(writer, "Error unignoring user",
+			fmt.Sprintf("There's no user called '%s' that is ignored %s.", tviewutil.Escape(nameOrID), cmd.describeScope(guildID)))
+		return
+	}
+
+	config.Current.UnignoreUser(ignoredUser.UserID, guildID)
+	if !cmd.persist(writer, "Error unignoring user") {
+		return
+	}
+
+	fmt.Fprintf(writer, "%s is no longer ignored %s.\n", tviewutil.Escape(ignoredUser.Name), cmd.describeScope(guildID))
+}
+
+// getScope returns the ID of the selected guild if the server flag has been
+// set. If no guild is selected, an error is printed and false returned.
+func (cmd *IgnoreCmd) getScope(writer io.Writer, errorTitle string, invocation *commands.Invocation) (string, bool) {
+	if _, set := invocation.Flag("--server"); !set {
+		return "", true
+	}
+
+	guild := cmd.window.GetSelectedGuild()
+	if guild == nil {
+		commands.PrintError(writer, errorTitle, "Please select a server first.")
+		return "", false
+	}
+
+	return guild.ID, true
+}
+
+// describeScope describes where users with the given guild ID are ignored.
+func (cmd *IgnoreCmd) describeScope(guildID string) string {
+	if guildID == "" {
+		return "everywhere"
+	}
+
+	if guild, stateError := cmd.session.State.Guild(guildID); stateError == nil {
+		return "in " + tviewutil.Escape(guild.Name)
+	}
+
+	return "in server " + guildID
+}
+
+// persist saves the ignored users and reloads the messages in the chatview.
+func (cmd *IgnoreCmd) persist(writer io.Writer, errorTitle string) bool {
+	if persistError := config.PersistConfig(); persistError != nil {
+		commands.PrintError(writer, errorTitle, persistError.Error())
+		return false
+	}
+
+	cmd.window.RefreshMessages()
+	return true
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *IgnoreCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *IgnoreCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *IgnoreCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
@@ -0,0 +1,341 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/atotto/clipboard"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/times"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+const (
+	// defaultInviteMaxAge is the duration that invites are valid for, unless
+	// specified otherwise. It's the same default as in the official client.
+	defaultInviteMaxAge = 24 * time.Hour
+	// maxInviteMaxAge is the maximum duration an invite can be valid for,
+	// unless it never expires.
+	maxInviteMaxAge = 7 * 24 * time.Hour
+	// maxInviteUses is the maximum limit of uses for an invite.
+	maxInviteUses = 100
+
+	inviteTimeFormat = "2006-01-02 15:04"
+)
+
+// InviteCmd allows creating, inspecting and revoking invites.
+type InviteCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+	spec    *commands.Spec
+}
+
+// NewInviteCommand creates a ready to use command for managing invites.
+func NewInviteCommand(window *ui.Window, session *discordgo.Session) *InviteCmd {
+	cmd := &InviteCmd{
+		window:  window,
+		session: session,
+	}
+	channelFlag := &commands.Flag{
+		Names:       []string{"-c", "--channel"},
+		Value:       "channel",
+		Description: "The name or ID of a channel in the current server. By default the current channel is used.",
+		Completer:   commands.ChannelCompleter(session.State, window),
+	}
+	inviteArgument := &commands.Argument{
+		Name:        "invite",
+		Description: "The invite code or the invite URL.",
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "invite",
+		Aliases: []string{"invites"},
+		Summary: "create, list, inspect and revoke invites",
+		Description: `The invite command allows creating invites for the currently selected
+server and managing existing ones. Invites can also be inspected before
+joining the server via 'server join'.`,
+		Subcommands: []*commands.Spec{
+			{
+				Name:    "create",
+				Aliases: []string{"new"},
+				Summary: "creates an invite and copies its link into the clipboard",
+				Flags: []*commands.Flag{
+					channelFlag,
+					{
+						Names:       []string{"-a", "--max-age"},
+						Value:       "duration",
+						Description: fmt.Sprintf("How long the invite is valid for, for example 30m, 12h or 7d, up to %dd. never creates an invite that doesn't expire. Defaults to %dh.", int(maxInviteMaxAge.Hours()/24), int(defaultInviteMaxAge.Hours())),
+						Completer:   commands.ValuesCompleter("never"),
+					}, {
+						Names:       []string{"-u", "--max-uses"},
+						Value:       "uses",
+						Description: fmt.Sprintf("How often the invite can be used, up to %d. By default there's no limit.", maxInviteUses),
+					}, {
+						Names:       []string{"-t", "--temporary"},
+						Description: "Members that joined via the invite are kicked once they go offline, unless they've been given a role.",
+					}, {
+						Names:       []string{"--unique"},
+						Description: "Always creates a new invite, instead of reusing an existing one with the same settings.",
+					},
+				},
+				Run: cmd.create,
+			}, {
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Summary: "lists the invites of the current channel or server",
+				Flags: []*commands.Flag{
+					channelFlag,
+					{
+						Names:       []string{"-s", "--server"},
+						Description: "Lists the invites of all channels in the current server.",
+					},
+				},
+				Run: cmd.list,
+			}, {
+				Name:      "revoke",
+				Aliases:   []string{"delete"},
+				Summary:   "revokes an invite, so that it can't be used anymore",
+				Arguments: []*commands.Argument{inviteArgument},
+				Run:       cmd.revoke,
+			}, {
+				Name:      "info",
+				Aliases:   []string{"preview"},
+				Summary:   "shows information about an invite and the server behind it",
+				Arguments: []*commands.Argument{inviteArgument},
+				Run:       cmd.info,
+			},
+		},
+		Examples: []string{
+			"invite create --max-age 7d --max-uses 10",
+			"invite create -c general -a never",
+			"invite list --server",
+			"invite info https://discord.gg/JDScUK",
+			"invite revoke JDScUK",
+		},
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the invite command.
+func (cmd *InviteCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *InviteCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *InviteCmd) create(writer io.Writer, invocation *commands.Invocation) {
+	channel := cmd.findChannel(writer, invocation)
+	if channel == nil {
+		return
+	}
+
+	if !discordutil.HasPermission(channel.ID, cmd.session.State, discordgo.PermissionCreateInstantInvite) {
+		commands.PrintError(writer, "Error creating invite", fmt.Sprintf("You don't have the permission to create invites for '%s'.", tviewutil.Escape(channel.Name)))
+		return
+	}
+
+	maxAge := defaultInviteMaxAge
+	if value, set := invocation.Flag("--max-age"); set {
+		if value == "never" {
+			maxAge = 0
+		} else {
+			var parseError error
+			maxAge, parseError = times.ParseDuration(value)
+			if parseError != nil || maxAge < time.Second || maxAge > maxInviteMaxAge {
+				commands.PrintError(writer, "Error creating invite",
+					fmt.Sprintf("'%s' has to be a duration such as 30m, 12h or 7d, up to %dd.", tviewutil.Escape(value), int(maxInviteMaxAge.Hours()/24)))
+				return
+			}
+		}
+	}
+
+	var maxUses int
+	if value, set := invocation.Flag("--max-uses"); set {
+		var parseError error
+		maxUses, parseError = strconv.Atoi(value)
+		if parseError != nil || maxUses < 0 || maxUses > maxInviteUses {
+			commands.PrintError(writer, "Error creating invite", fmt.Sprintf("The amount of uses has to be a number between 0 and %d.", maxInviteUses))
+			return
+		}
+	}
+
+	_, temporary := invocation.Flag("--temporary")
+	_, unique := invocation.Flag("--unique")
+	invite, createError := cmd.session.ChannelInviteCreate(channel.ID, discordgo.Invite{
+		MaxAge:    int(maxAge.Seconds()),
+		MaxUses:   maxUses,
+		Temporary: temporary,
+		Unique:    unique,
+	})
+	if createError != nil {
+		commands.PrintError(writer, "Error creating invite", createError.Error())
+		return
+	}
+
+	link := discordutil.InviteBaseURL + invite.Code
+	if copyError := clipboard.WriteAll(link); copyError != nil {
+		fmt.Fprintf(writer, "The invite %s has been created, but couldn't be copied into the clipboard:\n\t%s\n", link, tviewutil.Escape(copyError.Error()))
+		return
+	}
+
+	fmt.Fprintf(writer, "The invite %s has been created and copied into the clipboard.\n", link)
+}
+
+func (cmd *InviteCmd) list(writer io.Writer, invocation *commands.Invocation) {
+	var invites []*discordgo.Invite
+	var loadError error
+	if _, server := invocation.Flag("--server"); server {
+		guild := cmd.window.GetSelectedGuild()
+		if guild == nil {
+			commands.PrintError(writer, "Error loading invites", "In order to list the invites of a server, you have to be in a server.")
+			return
+		}
+		if !discordutil.HasGuildPermission(guild.ID, cmd.session.State, discordgo.PermissionManageServer) {
+			commands.PrintError(writer, "Error loading invites", "You don't have the permission to manage this server.")
+			return
+		}
+
+		invites, loadError = cmd.session.GuildInvites(guild.ID)
+	} else {
+		channel := cmd.findChannel(writer, invocation)
+		if channel == nil {
+			return
+		}
+		if !discordutil.HasPermission(channel.ID, cmd.session.State, discordgo.PermissionManageChannels) {
+			commands.PrintError(writer, "Error loading invites", fmt.Sprintf("You don't have the permission to manage '%s'.", tviewutil.Escape(channel.Name)))
+			return
+		}
+
+		invites, loadError = cmd.session.ChannelInvites(channel.ID)
+	}
+
+	if loadError != nil {
+		commands.PrintError(writer, "Error loading invites", loadError.Error())
+		return
+	}
+
+	if len(invites) == 0 {
+		fmt.Fprintln(writer, "There are no invites.")
+		return
+	}
+
+	for _, invite := range invites {
+		fmt.Fprintln(writer, formatInvite(invite))
+	}
+}
+
+func (cmd *InviteCmd) revoke(writer io.Writer, invocation *commands.Invocation) {
+	code := discordutil.ParseInviteCode(invocation.Argument("invite"))
+	//The API decides whether we are allowed to revoke the invite, since the
+	//invite might belong to a server that hasn't been loaded.
+	invite, revokeError := cmd.session.InviteDelete(code)
+	if revokeError != nil {
+		commands.PrintError(writer, "Error revoking invite", revokeError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "The invite %s has been revoked.\n", tviewutil.Escape(invite.Code))
+}
+
+func (cmd *InviteCmd) info(writer io.Writer, invocation *commands.Invocation) {
+	code := discordutil.ParseInviteCode(invocation.Argument("invite"))
+	invite, loadError := cmd.session.InviteWithCounts(code)
+	if loadError != nil {
+		commands.PrintError(writer, "Error loading invite", loadError.Error())
+		return
+	}
+
+	if invite.Guild != nil {
+		fmt.Fprintf(writer, "Server: %s\n", tviewutil.Escape(invite.Guild.Name))
+		if _, stateError := cmd.session.State.Guild(invite.Guild.ID); stateError == nil {
+			fmt.Fprintln(writer, "You are already a member of this server.")
+		}
+	}
+	if invite.Channel != nil {
+		fmt.Fprintf(writer, "Channel: #%s\n", tviewutil.Escape(invite.Channel.Name))
+	}
+	if invite.Inviter != nil {
+		fmt.Fprintf(writer, "Invited by: %s\n", tviewutil.Escape(invite.Inviter.String()))
+	}
+	fmt.Fprintf(writer, "Members: %d (%d online)\n", invite.ApproximateMemberCount, invite.ApproximatePresenceCount)
+	if expiry, expires := discordutil.GetInviteExpiry(invite); expires {
+		fmt.Fprintf(writer, "Expires: %s\n", expiry.Local().Format(inviteTimeFormat))
+	}
+	fmt.Fprintf(writer, "Link: %s%s\n", discordutil.InviteBaseURL, invite.Code)
+}
+
+// findChannel returns the channel passed via the channel flag or the
+// currently selected channel. If there's no such channel in the current
+// server, an error is printed and nil returned.
+func (cmd *InviteCmd) findChannel(writer io.Writer, invocation *commands.Invocation) *discordgo.Channel {
+	guild := cmd.window.GetSelectedGuild()
+	if guild == nil {
+		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]In order to use this command, you have to be in a server.")
+		return nil
+	}
+
+	if nameOrID, set := invocation.Flag("--channel"); set {
+		return findGuildChannel(writer, guild, nameOrID)
+	}
+
+	channel := cmd.window.GetSelectedChannel()
+	if channel == nil || channel.GuildID != guild.ID {
+		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]In order to use this command, you have to be in a channel or pass one via --channel.")
+		return nil
+	}
+
+	return channel
+}
+
+// formatInvite creates a single line summary of the invite, containing its
+// channel, creator, uses and expiry.
+func formatInvite(invite *discordgo.Invite) string {
+	var details []string
+	if invite.Channel != nil {
+		details = append(details, "#"+tviewutil.Escape(invite.Channel.Name))
+	}
+	if invite.Inviter != nil {
+		details = append(details, "by "+tviewutil.Escape(invite.Inviter.String()))
+	}
+	if invite.MaxUses > 0 {
+		details = append(details, fmt.Sprintf("%d/%d uses", invite.Uses, invite.MaxUses))
+	} else {
+		details = append(details, fmt.Sprintf("%d uses", invite.Uses))
+	}
+	if expiry, expires := discordutil.GetInviteExpiry(invite); expires {
+		details = append(details, "expires "+expiry.Local().Format(inviteTimeFormat))
+	} else {
+		details = append(details, "never expires")
+	}
+	if invite.Temporary {
+		details = append(details, "temporary")
+	}
+
+	return invite.Code + " " + strings.Join(details, ", ")
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *InviteCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *InviteCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *InviteCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
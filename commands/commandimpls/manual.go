@@ -6,6 +6,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/Bios-Marcel/cordless/commands"
 	"github.com/Bios-Marcel/cordless/config"
 	"github.com/Bios-Marcel/cordless/ui"
 	"github.com/Bios-Marcel/cordless/ui/tviewutil"
@@ -31,8 +32,7 @@ const manualDocumentation = `[::b]NAME
 [::b]EXAMPLES
 	[gray]$ man user
 	[white][::b]NAME
-		user - manipulate and retrieve your ...
-`
+		user - manipulate and retrieve your ...`
 
 // Manual is the command that displays the application manual.
 type Manual struct {
@@ -51,7 +51,11 @@ func (manual *Manual) getPredefinedTopicPage(name string) string {
 	case "commands":
 		var commandList string
 		for _, cmd := range manual.window.GetRegisteredCommands() {
-			commandList += fmt.Sprintf("\t\t- %s\n", cmd.Name())
+			if specCommand, ok := cmd.(commands.SpecCommand); ok {
+				commandList += fmt.Sprintf("\t\t- %s - %s\n", cmd.Name(), specCommand.Spec().Summary)
+			} else {
+				commandList += fmt.Sprintf("\t\t- %s\n", cmd.Name())
+			}
 		}
 		return fmt.Sprintf(commandsDocumentation, commandList)
 	case "configuration", "config", "conf":
@@ -96,6 +100,19 @@ func (manual *Manual) Execute(writer io.Writer, parameters []string) {
 			}
 		}
 
+		//Commands with a declaration also offer pages for their subcommands,
+		//for example "manual friends accept".
+		for _, cmd := range manual.window.GetRegisteredCommands() {
+			specCommand, ok := cmd.(commands.SpecCommand)
+			if !ok || !commands.CommandEquals(cmd, strings.ToLower(parameters[0])) {
+				continue
+			}
+
+			if commands.PrintSpecHelp(writer, specCommand.Spec(), parameters[1:]...) == nil {
+				return
+			}
+		}
+
 		inputSpaces := strings.ToLower(strings.Join(parameters, " "))
 		fmt.Fprintf(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]No manual entry for '%s' found.\n", inputSpaces)
 
@@ -144,12 +161,44 @@ const chatViewDocumentation = `[::b]TOPIC
 	| Reply with mention          | r          |
 	| Quote message               | q          |
 	| Hide / show spoiler content | s          |
+	| Pin / unpin message         | i          |
+	| Show pinned messages        | P          |
+	| Jump to pinned message      | Enter      |
+	| Add / remove reaction       | +          |
+	| Show author's profile       | u          |
+	| Show mentioned profile      | U          |
+	| Block / unblock author      | b          |
+	| Ignore / unignore author    | x          |
 	| Selection up                | ArrowUp    |
 	| Selection down              | ArrowDown  |
 	| Selection to top            | Home       |
 	| Selection to bottom         | End        |
 	--------------------------------------------
 
+	Blocking a user is known to discord, while ignoring a user only hides
+	their messages in cordless. Messages of ignored users are treated like
+	messages of blocked users, see [::b]ShowPlaceholderForBlockedMessages[::-].
+	The shortcut ignores users everywhere, while the "ignore" command also
+	allows ignoring users in a single server.
+
+	Pinning and unpinning messages in servers requires the permission to
+	manage messages. The pinned messages are shown using the same formatting
+	as the chatview. Selecting one of them and hitting Enter jumps to the
+	message, as long as it's still loaded in the chatview. Hitting Enter
+	on a "pinned a message" notice does the same. The pins can also be
+	managed using the "pins" command.
+
+	The reaction picker allows searching for emoji by their name. Your
+	recently used emoji are shown first, while the existing reactions are
+	shown if nothing has been searched for yet. Choosing an emoji that you
+	have already reacted with, removes your reaction. Hitting Ctrl+L shows
+	who has reacted with the selected emoji.
+
+	The profile of a user shows their status, roles and mutual servers
+	and allows messaging, mentioning, befriending and blocking them. If a
+	message mentions multiple users, you will be asked whose profile to
+	show. Profiles can also be opened by hitting Enter in the user list.
+
 	Keep in mind, that those shortcuts might differ from your settings, as
 	those are just the defaults.`
 
@@ -157,8 +206,9 @@ const commandsDocumentation = `[::b]TOPIC
 	commands - commands allow you to execute certain actions within cordless
 
 [::b]DESCRIPTION
-	Commands can only be entered via the command-input component.
-	Commands can't be called from outside the application or on startup.
+	Commands can be entered via the command-input component or run on
+	startup, see below. Commands can't be called from outside the
+	application.
 
 	All commands follow a certain semantics pattern:
 		COMMAND SUBCOMMAND --SETTING "Some setting value" MAIN_VALUE
@@ -167,23 +217,67 @@ const commandsDocumentation = `[::b]TOPIC
 	command may have zero or more subcommands and zero or more settings.
 	There may also be settings that do not require you passing a value.
 	If a value contains spaces it needs to be quoted beforehand, otherwise
-	the input will be separated at each given space. Some commands require
+	the input will be separated at each given space. Similar to a shell,
+	text in single quotes is taken literally, while inside of double
+	quotes only \ and " can be escaped. Single quotes only start a quote
+	at the beginning of a value, so apostrophes like in "don't" can be
+	typed as they are. Outside of quotes, a backslash escapes spaces,
+	quotes and the characters \ ; | and >, other backslashes are kept as
+	they are. Values that are file paths may start with ~ for your home
+	directory and may contain environment variables such as $HOME or
+	${HOME}. All other values, for example message text, are taken as
+	they are. Values starting with a dash, such as -5, are only treated
+	as settings if the command knows a setting with that name. Passing --
+	causes all following values to be treated as main values, even if
+	they are the name of a setting. Some commands require
 	some main value, which is basically the non-optional input for that
 	command. That value doesn't require a setting-name to be prepended in
 	front of it.
 
 	After typing a command, it will be added to your history. The history
-	doesn't persist between cordless sessions, it will be forgotten every
-	time you close the application. The history can be travelled through
-	by using the arrow up and down keys. An exception for historization
-	are secret inputs like passwords, those aren't directly typed into the
-	command-input. Instead cordless shows an extra dialog as soon as it
-	requires you to input sensitive information like passwords.
+	is saved in the configuration directory, so it persists between cordless
+	sessions. Only the most recent entries are kept, the amount can be
+	changed via the [::b]CommandHistorySize[::-] setting. The history can be
+	travelled through by using the arrow up and down keys. Pressing Ctrl-R
+	starts an incremental reverse search, each further Ctrl-R jumps to the
+	next older match. The [::b]history[::-] command lists entries and can run
+	them again. Commands that contain secrets, such as tokens or TFA codes,
+	are never added to the history. Passwords aren't directly typed into
+	the command-input either. Instead cordless shows an extra dialog as
+	soon as it requires you to input sensitive information like passwords.
 
 	Since the command-input component uses the same underlying component
 	as the message-input, you can use the same shortcuts for editing
 	your input.
 
+	Multiple commands can be run at once by separating them with
+	semicolons. Frequently used commands can be given a shorter name
+	via the [::b]alias[::-] command. Aliases may consist of multiple commands
+	and reference the parameters passed to them via $1, $2 ... or $@.
+
+	The output of a command can be written into a file instead of being
+	shown, by appending [::b]> file[::-]. Using [::b]>> file[::-] appends the
+	output to the file instead of replacing its content. Colors are
+	removed from the output in both cases. Using [::b]|[::-] passes the output
+	of a command to the next one, for example [::b]friends list | send[::-]
+	sends your friend list into the current channel as a code block. Not
+	all commands can receive the output of other commands. The operators
+	have to be separated by spaces, so [::b]a>b[::-] is just text. Everything
+	following [::b]send[::-] belongs to the message and is sent exactly as
+	it has been typed, therefore [::b]send 3 > 2[::-] sends "3 > 2" instead
+	of writing into a file.
+
+	On startup, cordless runs the commands in the file [::b]cordlessrc[::-]
+	inside of the configuration directory, one command per line. A
+	different file can be passed via the [::b]-rc[::-] parameter. Empty lines
+	and lines starting with # are skipped. Errors are shown together with
+	the line number and don't stop the remaining lines from running.
+
+	Pressing tab completes the word in front of the cursor. This works for
+	command names, subcommands and many arguments, such as users, servers,
+	accounts or file paths. If there are multiple candidates, they are
+	shown above the command-input and can be chosen via enter.
+
 	Available commands:
 %s
 [::b]EXAMPLES
@@ -361,6 +455,16 @@ const configurationDocumentation = `[::b]TOPIC
 		Type:    boolean
 		Default: true
 		
+	[::b]AutoIdleThreshold
+		Determines after how many minutes without any input your status is
+		changed to idle. The previous status is restored on the next input.
+		Only the status online is changed, dnd and invisible are kept. If
+		cordless is closed while idle, the status stays idle. A value of
+		zero disables this. This setting has no effect for bots.
+		
+		Type:    int
+		Default: 0
+		
 	[::b]ShowPlaceholderForBlockedMessages
 		Determines whether blocked messages are hidden or a placeholder is
 		shown instead, so that you know that someone sent a message. This
@@ -370,6 +474,14 @@ const configurationDocumentation = `[::b]TOPIC
 		Type:    boolean
 		Default: true
 
+	[::b]IgnoredUsers
+		Holds the users whose messages are hidden without letting discord
+		know. Each user can be ignored everywhere or in specific servers.
+		This setting is usually changed via the [::b]ignore[::-] command.
+
+		Type:    array
+		Default: EMPTY
+
 	[::b]Accounts
 		This settings holds an array of so called accounts, also referred to
 		as profiles. Those allow you to let cordless know of multiple discord
@@ -389,7 +501,21 @@ const configurationDocumentation = `[::b]TOPIC
 		here at some point.
 
 	[::b]ShowNicknames
-		Decides whether a users nickname is displayed throughout cordless.`
+		Decides whether a users nickname is displayed throughout cordless.
+
+	[::b]CommandHistorySize
+		Determines how many of the most recently entered commands are kept
+		in the command history. Setting this to 0 disables the history.
+
+		Type:    int
+		Default: 500
+
+	[::b]CommandAliases
+		Maps alias names to the commands they are replaced with. This
+		setting is usually changed via the [::b]alias[::-] command.
+
+		Type:    object
+		Default: EMPTY`
 
 const messageEditorDocumentation = `[::b]TOPIC
 	message-editor - the component that allows you to input text for a message.
@@ -449,6 +575,8 @@ const navigationDocumentation = `[::b]TOPIC
 	| Toggle command view     | Alt+Dot  | Everywhere                  |
 	| Focus command output    | Ctrl+O   | Everywhere                  |
 	| Focus command input     | Ctrl+I   | Everywhere                  |
+	| Create group            | Ctrl+G   | Private chat list           |
+	| Edit selected group     | Ctrl+E   | Private chat list           |
 	| Edit last message       | ArrowUp  | In empty message input      |
 	| Leave message edit mode | Esc      | When editing message        |
 	--------------------------------------------------------------------
@@ -0,0 +1,462 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/times"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+const (
+	// maxBanDeleteDays is the maximum amount of days that the messages of a
+	// banned user can be deleted for.
+	maxBanDeleteDays = 7
+	// maxPurgeScan is the maximum amount of messages that are searched
+	// through when purging the messages of a specific user.
+	maxPurgeScan = 500
+)
+
+// ModCmd offers moderation actions for the currently selected server, such
+// as kicking and banning members or deleting messages in bulk.
+type ModCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+	spec    *commands.Spec
+}
+
+// NewModCommand creates a ready to use command for moderating servers.
+func NewModCommand(window *ui.Window, session *discordgo.Session) *ModCmd {
+	cmd := &ModCmd{
+		window:  window,
+		session: session,
+	}
+	memberArgument := &commands.Argument{
+		Name:        "user",
+		Description: "The name, Username#NNNN, mention or ID of a member of the current server.",
+		Completer:   commands.MemberCompleter(session.State, window),
+	}
+	reasonFlag := &commands.Flag{
+		Names:       []string{"-r", "--reason"},
+		Value:       "reason",
+		Description: "The reason that will be shown in the audit log.",
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "mod",
+		Aliases: []string{"moderate"},
+		Summary: "kick, ban, time out members and purge messages",
+		Description: `The mod command offers moderation actions for the currently selected
+server. Each action requires the respective permission and has to be
+confirmed before it's executed.`,
+		Subcommands: []*commands.Spec{
+			{
+				Name:      "kick",
+				Summary:   "removes a member from the server",
+				Arguments: []*commands.Argument{memberArgument},
+				Flags:     []*commands.Flag{reasonFlag},
+				Run:       cmd.kick,
+			}, {
+				Name:    "ban",
+				Summary: "bans a user from the server",
+				Arguments: []*commands.Argument{{
+					Name:        "user",
+					Description: "The name, Username#NNNN, mention or ID of a member. Users that aren't a member can be banned by ID.",
+					Completer:   commands.MemberCompleter(session.State, window),
+				}},
+				Flags: []*commands.Flag{
+					reasonFlag,
+					{
+						Names:       []string{"-d", "--delete-days"},
+						Value:       "days",
+						Description: fmt.Sprintf("Deletes the messages the user has sent in the given amount of days, up to %d.", maxBanDeleteDays),
+					},
+				},
+				Run: cmd.ban,
+			}, {
+				Name:    "unban",
+				Summary: "lifts the ban of a user",
+				Arguments: []*commands.Argument{{
+					Name:        "user",
+					Description: "The Username#NNNN or ID of a banned user.",
+				}},
+				Run: cmd.unban,
+			}, {
+				Name:    "bans",
+				Summary: "lists the banned users and the reasons for their ban",
+				Run:     cmd.listBans,
+			}, {
+				Name:    "timeout",
+				Aliases: []string{"mute"},
+				Summary: "prevents a member from sending messages and joining voice channels for a while",
+				Arguments: []*commands.Argument{
+					memberArgument,
+					{
+						Name:        "duration",
+						Description: fmt.Sprintf("How long the timeout lasts, for example 10m, 2h or 7d, up to %dd. off lifts an existing timeout.", int(discordutil.MaxTimeout.Hours()/24)),
+						Completer:   commands.ValuesCompleter("off"),
+					},
+				},
+				Run: cmd.timeout,
+			}, {
+				Name:    "purge",
+				Aliases: []string{"prune"},
+				Summary: "deletes the latest messages in the current channel",
+				Arguments: []*commands.Argument{{
+					Name:        "amount",
+					Description: fmt.Sprintf("The amount of messages to delete, up to %d. Messages older than two weeks can't be purged.", discordutil.BulkDeleteLimit),
+				}},
+				Flags: []*commands.Flag{{
+					Names:       []string{"-u", "--user"},
+					Value:       "user",
+					Description: "Only deletes messages sent by this member.",
+					Completer:   commands.MemberCompleter(session.State, window),
+				}},
+				Run: cmd.purge,
+			},
+		},
+		Examples: []string{
+			"mod kick Marcel#7299 --reason \"Spamming\"",
+			"mod ban -d 1 -r \"Advertising\" 123456789012345678",
+			"mod timeout Marcel 1h",
+			"mod purge 20 --user Marcel#7299",
+		},
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the mod command.
+func (cmd *ModCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ModCmd) Execute(writer io.Writer, parameters []string) {
+	if cmd.window.GetSelectedGuild() == nil {
+		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]In order to use this command, you have to be in a server.")
+		return
+	}
+
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *ModCmd) kick(writer io.Writer, invocation *commands.Invocation) {
+	if !cmd.checkGuildPermission(writer, "Error kicking member", discordgo.PermissionKickMembers, "kick members") {
+		return
+	}
+
+	guildID := cmd.window.GetSelectedGuild().ID
+	member := findMember(writer, cmd.session.State, guildID, "Error kicking member", invocation.Argument("user"))
+	if member == nil {
+		return
+	}
+
+	reason, _ := invocation.Flag("--reason")
+	cmd.window.ShowConfirmationDialog(fmt.Sprintf("Do you really want to kick %s?", tviewutil.Escape(member.User.String())), func(writer io.Writer) {
+		kickError := cmd.session.GuildMemberDeleteWithReason(guildID, member.User.ID, reason)
+		if kickError != nil {
+			commands.PrintError(writer, "Error kicking member", kickError.Error())
+			return
+		}
+
+		fmt.Fprintf(writer, "%s has been kicked.\n", tviewutil.Escape(member.User.String()))
+	})
+}
+
+func (cmd *ModCmd) ban(writer io.Writer, invocation *commands.Invocation) {
+	if !cmd.checkGuildPermission(writer, "Error banning user", discordgo.PermissionBanMembers, "ban members") {
+		return
+	}
+
+	var deleteDays int
+	if value, set := invocation.Flag("--delete-days"); set {
+		var parseError error
+		deleteDays, parseError = strconv.Atoi(value)
+		if parseError != nil || deleteDays < 0 || deleteDays > maxBanDeleteDays {
+			commands.PrintError(writer, "Error banning user", fmt.Sprintf("The amount of days has to be a number between 0 and %d.", maxBanDeleteDays))
+			return
+		}
+	}
+
+	//Users that aren't a member can still be banned by their ID.
+	var user *discordgo.User
+	input := invocation.Argument("user")
+	guildID := cmd.window.GetSelectedGuild().ID
+	members, _ := cmd.session.State.Members(guildID)
+	if matches := discordutil.FindMembers(members, input); len(matches) == 0 && isSnowflake(input) {
+		user = &discordgo.User{ID: input}
+	} else if member := findMember(writer, cmd.session.State, guildID, "Error banning user", input); member != nil {
+		user = member.User
+	} else {
+		return
+	}
+
+	name := user.ID
+	if user.Username != "" {
+		name = user.String()
+	}
+	reason, _ := invocation.Flag("--reason")
+	cmd.window.ShowConfirmationDialog(fmt.Sprintf("Do you really want to ban %s?", tviewutil.Escape(name)), func(writer io.Writer) {
+		banError := cmd.session.GuildBanCreateWithReason(guildID, user.ID, reason, deleteDays)
+		if banError != nil {
+			commands.PrintError(writer, "Error banning user", banError.Error())
+			return
+		}
+
+		fmt.Fprintf(writer, "%s has been banned.\n", tviewutil.Escape(name))
+	})
+}
+
+func (cmd *ModCmd) unban(writer io.Writer, invocation *commands.Invocation) {
+	if !cmd.checkGuildPermission(writer, "Error lifting ban", discordgo.PermissionBanMembers, "ban members") {
+		return
+	}
+
+	guildID := cmd.window.GetSelectedGuild().ID
+	bans, loadError := cmd.session.GuildBans(guildID)
+	if loadError != nil {
+		commands.PrintError(writer, "Error loading bans", loadError.Error())
+		return
+	}
+
+	input := invocation.Argument("user")
+	for _, ban := range bans {
+		if ban.User.ID == input || strings.EqualFold(ban.User.String(), input) {
+			unbanError := cmd.session.GuildBanDelete(guildID, ban.User.ID)
+			if unbanError != nil {
+				commands.PrintError(writer, "Error lifting ban", unbanError.Error())
+				return
+			}
+
+			fmt.Fprintf(writer, "The ban of %s has been lifted.\n", tviewutil.Escape(ban.User.String()))
+			return
+		}
+	}
+
+	commands.PrintError(writer, "Error lifting ban", fmt.Sprintf("There's no banned user called '%s'.", tviewutil.Escape(input)))
+}
+
+func (cmd *ModCmd) listBans(writer io.Writer, invocation *commands.Invocation) {
+	if !cmd.checkGuildPermission(writer, "Error loading bans", discordgo.PermissionBanMembers, "ban members") {
+		return
+	}
+
+	bans, loadError := cmd.session.GuildBans(cmd.window.GetSelectedGuild().ID)
+	if loadError != nil {
+		commands.PrintError(writer, "Error loading bans", loadError.Error())
+		return
+	}
+
+	if len(bans) == 0 {
+		fmt.Fprintln(writer, "Nobody has been banned from this server.")
+		return
+	}
+
+	for _, ban := range bans {
+		reason := ban.Reason
+		if reason == "" {
+			reason = "No reason given"
+		}
+		fmt.Fprintf(writer, "%s %s: %s\n", ban.User.ID, tviewutil.Escape(ban.User.String()), tviewutil.Escape(reason))
+	}
+}
+
+func (cmd *ModCmd) timeout(writer io.Writer, invocation *commands.Invocation) {
+	//The permission to time out members can't be represented by the library
+	//on all platforms, therefore discord is left to check it.
+	member := findMember(writer, cmd.session.State, cmd.window.GetSelectedGuild().ID, "Error timing out member", invocation.Argument("user"))
+	if member == nil {
+		return
+	}
+
+	guildID := cmd.window.GetSelectedGuild().ID
+	name := tviewutil.Escape(member.User.String())
+	value := invocation.Argument("duration")
+	if value == "off" {
+		timeoutError := discordutil.TimeoutMember(cmd.session, guildID, member.User.ID, nil)
+		if timeoutError != nil {
+			commands.PrintError(writer, "Error lifting timeout", timeoutError.Error())
+			return
+		}
+
+		fmt.Fprintf(writer, "The timeout of %s has been lifted.\n", name)
+		return
+	}
+
+	duration, parseError := times.ParseDuration(value)
+	if parseError != nil || duration <= 0 || duration > discordutil.MaxTimeout {
+		commands.PrintError(writer, "Error timing out member",
+			fmt.Sprintf("'%s' has to be a duration such as 10m, 2h or 7d, up to %dd.", tviewutil.Escape(value), int(discordutil.MaxTimeout.Hours()/24)))
+		return
+	}
+
+	cmd.window.ShowConfirmationDialog(fmt.Sprintf("Do you really want to time out %s for %s?", name, value), func(writer io.Writer) {
+		until := time.Now().Add(duration)
+		timeoutError := discordutil.TimeoutMember(cmd.session, guildID, member.User.ID, &until)
+		if timeoutError != nil {
+			commands.PrintError(writer, "Error timing out member", timeoutError.Error())
+			return
+		}
+
+		fmt.Fprintf(writer, "%s has been timed out until %s.\n", name, until.Format("2006-01-02 15:04"))
+	})
+}
+
+func (cmd *ModCmd) purge(writer io.Writer, invocation *commands.Invocation) {
+	channel := cmd.window.GetSelectedChannel()
+	if channel == nil || channel.GuildID != cmd.window.GetSelectedGuild().ID {
+		commands.PrintError(writer, "Error purging messages", "In order to purge messages, you have to be in a channel of the current server.")
+		return
+	}
+
+	if !discordutil.HasPermission(channel.ID, cmd.session.State, discordgo.PermissionManageMessages) {
+		commands.PrintError(writer, "Error purging messages", "You don't have the permission to manage messages in this channel.")
+		return
+	}
+
+	amount, parseError := strconv.Atoi(invocation.Argument("amount"))
+	if parseError != nil || amount < 1 || amount > discordutil.BulkDeleteLimit {
+		commands.PrintError(writer, "Error purging messages", fmt.Sprintf("The amount has to be a number between 1 and %d.", discordutil.BulkDeleteLimit))
+		return
+	}
+
+	var authorID string
+	if userName, set := invocation.Flag("--user"); set {
+		member := findMember(writer, cmd.session.State, cmd.window.GetSelectedGuild().ID, "Error purging messages", userName)
+		if member == nil {
+			return
+		}
+		authorID = member.User.ID
+	}
+
+	messages, loadError := cmd.loadPurgeCandidates(channel.ID, authorID, amount)
+	if loadError != nil {
+		commands.PrintError(writer, "Error loading messages", loadError.Error())
+		return
+	}
+
+	messageIDs, tooOld := discordutil.SelectMessagesForPurge(messages, authorID, amount, time.Now())
+	if tooOld > 0 {
+		fmt.Fprintf(writer, "%d message(s) are older than two weeks and can't be purged.\n", tooOld)
+	}
+	if len(messageIDs) == 0 {
+		fmt.Fprintln(writer, "There are no messages to purge.")
+		return
+	}
+
+	cmd.window.ShowConfirmationDialog(fmt.Sprintf("Do you really want to delete %d message(s) in #%s?", len(messageIDs), tviewutil.Escape(channel.Name)), func(writer io.Writer) {
+		//The chatview is updated via the bulk delete event.
+		deleteError := cmd.session.ChannelMessagesBulkDelete(channel.ID, messageIDs)
+		if deleteError != nil {
+			commands.PrintError(writer, "Error purging messages", deleteError.Error())
+			return
+		}
+
+		fmt.Fprintf(writer, "%d message(s) have been deleted.\n", len(messageIDs))
+	})
+}
+
+// loadPurgeCandidates loads the latest messages of the channel. If only
+// the messages of a specific author are to be purged, more messages are
+// loaded, until enough messages have been found or maxPurgeScan is reached.
+func (cmd *ModCmd) loadPurgeCandidates(channelID, authorID string, amount int) ([]*discordgo.Message, error) {
+	var messages []*discordgo.Message
+	var matches int
+	var beforeID string
+	for len(messages) < maxPurgeScan {
+		page, loadError := cmd.session.ChannelMessages(channelID, discordutil.BulkDeleteLimit, beforeID, "", "")
+		if loadError != nil {
+			return nil, loadError
+		}
+
+		messages = append(messages, page...)
+		for _, message := range page {
+			if authorID == "" || (message.Author != nil && message.Author.ID == authorID) {
+				matches++
+			}
+		}
+
+		if matches >= amount || len(page) < discordutil.BulkDeleteLimit {
+			break
+		}
+
+		//Messages are returned newest first.
+		oldest := page[len(page)-1]
+		if created, parseError := discordgo.SnowflakeTimestamp(oldest.ID); parseError != nil ||
+			time.Since(created) >= discordutil.BulkDeleteMaxAge {
+			break
+		}
+		beforeID = oldest.ID
+	}
+
+	return messages, nil
+}
+
+// checkGuildPermission prints an error if the current user doesn't have the
+// given permission in the selected server.
+func (cmd *ModCmd) checkGuildPermission(writer io.Writer, errorTitle string, permission int, action string) bool {
+	if discordutil.HasGuildPermission(cmd.window.GetSelectedGuild().ID, cmd.session.State, permission) {
+		return true
+	}
+
+	commands.PrintError(writer, errorTitle, fmt.Sprintf("You don't have the permission to %s in this server.", action))
+	return false
+}
+
+// findMember looks up a member of the given guild. If there's no unique
+// match, an error is printed and nil returned.
+func findMember(writer io.Writer, state *discordgo.State, guildID, errorTitle, nameOrID string) *discordgo.Member {
+	members, stateError := state.Members(guildID)
+	if stateError != nil {
+		commands.PrintError(writer, errorTitle, stateError.Error())
+		return nil
+	}
+
+	matches := discordutil.FindMembers(members, nameOrID)
+	if len(matches) == 0 {
+		commands.PrintError(writer, errorTitle, fmt.Sprintf("There's no member called '%s'.", tviewutil.Escape(nameOrID)))
+		return nil
+	}
+
+	if len(matches) > 1 {
+		names := make([]string, 0, len(matches))
+		for _, match := range matches {
+			names = append(names, match.User.String())
+		}
+		commands.PrintError(writer, errorTitle,
+			fmt.Sprintf("There are multiple members called '%s'. Use one of these instead: %s", tviewutil.Escape(nameOrID), tviewutil.Escape(strings.Join(names, ", "))))
+		return nil
+	}
+
+	return matches[0]
+}
+
+// isSnowflake checks whether the value could be a discord ID.
+func isSnowflake(value string) bool {
+	_, parseError := strconv.ParseUint(value, 10, 64)
+	return parseError == nil
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *ModCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ModCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ModCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
@@ -0,0 +1,151 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// NoteCmd shows and edits the private notes on other users.
+type NoteCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+	spec    *commands.Spec
+}
+
+// NewNoteCommand creates a ready to use command for editing notes.
+func NewNoteCommand(window *ui.Window, session *discordgo.Session) *NoteCmd {
+	cmd := &NoteCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "note",
+		Aliases: []string{"notes"},
+		Summary: "shows or changes the note on a user",
+		Description: fmt.Sprintf(`The note command manages private notes on other users. Only you can see
+them. Notes are saved on discord and in the configuration directory, so
+they are also available for bot accounts and while being offline. A note
+may contain up to %d characters.
+
+Without any text, the note on the user is shown. Without a user, all notes
+are listed. The notes are also shown in the profile popup and the title of
+the user list.`, discordutil.MaxNoteLength),
+		Arguments: []*commands.Argument{
+			{
+				Name:        "user",
+				Description: "The name, name#discriminator or ID of a user.",
+				Optional:    true,
+				Completer:   commands.UserCompleter(session.State),
+			}, {
+				Name:        "text",
+				Description: "The new note. Multiple words don't have to be quoted.",
+				Optional:    true,
+				Variadic:    true,
+			},
+		},
+		Flags: []*commands.Flag{{
+			Names:       []string{"-d", "--delete"},
+			Description: "Removes the note on the user.",
+		}},
+		Examples: []string{
+			"note",
+			"note Marcel#7299",
+			"note Marcel#7299 Wrote most of cordless",
+			"note --delete Marcel#7299",
+		},
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the note command.
+func (cmd *NoteCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *NoteCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *NoteCmd) run(writer io.Writer, invocation *commands.Invocation) {
+	nameOrID := invocation.Argument("user")
+	if nameOrID == "" {
+		cmd.list(writer)
+		return
+	}
+
+	user := findKnownUser(writer, cmd.session, "Error changing note", nameOrID)
+	if user == nil {
+		return
+	}
+
+	text := strings.Join(invocation.Arguments("text"), " ")
+	_, deleteNote := invocation.Flag("--delete")
+	if deleteNote && text != "" {
+		commands.PrintError(writer, "Error changing note", "A note can't be removed and changed at once.")
+		return
+	}
+
+	if !deleteNote && text == "" {
+		if note := cmd.window.GetNotes().Get(user.ID); note != "" {
+			fmt.Fprintf(writer, "Note on %s: %s\n", tviewutil.Escape(user.String()), tviewutil.Escape(note))
+		} else {
+			fmt.Fprintf(writer, "There's no note on %s.\n", tviewutil.Escape(user.String()))
+		}
+		return
+	}
+
+	if noteError := cmd.window.SetUserNote(user.ID, text); noteError != nil {
+		commands.PrintError(writer, "Error changing note", noteError.Error())
+		return
+	}
+
+	if deleteNote {
+		fmt.Fprintf(writer, "The note on %s has been removed.\n", tviewutil.Escape(user.String()))
+	} else {
+		fmt.Fprintf(writer, "The note on %s has been changed.\n", tviewutil.Escape(user.String()))
+	}
+}
+
+func (cmd *NoteCmd) list(writer io.Writer) {
+	notes := cmd.window.GetNotes()
+	userIDs := notes.UserIDs()
+	if len(userIDs) == 0 {
+		fmt.Fprintln(writer, "You haven't written any notes.")
+		return
+	}
+
+	users := discordutil.GetKnownUsers(cmd.session.State)
+	for _, userID := range userIDs {
+		name := userID
+		if matches := discordutil.FindUsers(users, userID); len(matches) == 1 {
+			name = matches[0].String()
+		}
+		fmt.Fprintf(writer, "%s: %s\n", tviewutil.Escape(name), tviewutil.Escape(notes.Get(userID)))
+	}
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *NoteCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *NoteCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *NoteCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
@@ -0,0 +1,156 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// PinsCmd allows viewing and managing the pinned messages of the currently
+// loaded channel.
+type PinsCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+	spec    *commands.Spec
+}
+
+// NewPinsCommand creates a ready to use command for viewing and managing
+// pinned messages.
+func NewPinsCommand(window *ui.Window, session *discordgo.Session) *PinsCmd {
+	cmd := &PinsCmd{
+		window:  window,
+		session: session,
+	}
+	messageIDArgument := &commands.Argument{
+		Name:        "message-id",
+		Description: "The ID of a message in the current channel. It can be copied from a message link.",
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "pins",
+		Aliases: []string{"pin", "pinned"},
+		Summary: "view, pin and unpin messages in the current channel",
+		Description: `The pins command shows the pinned messages of the currently loaded channel.
+Inside of the view, a pin can be selected in order to jump to it. Pinning
+and unpinning messages in servers requires the permission to manage
+messages.`,
+		Subcommands: []*commands.Spec{
+			{
+				Name:    "show",
+				Aliases: []string{"view"},
+				Summary: "shows the pinned messages in a separate view",
+				Run:     cmd.show,
+			}, {
+				Name:    "list",
+				Summary: "prints the ID, author and content of each pinned message",
+				Run:     cmd.list,
+			}, {
+				Name:      "add",
+				Aliases:   []string{"pin"},
+				Summary:   "pins a message",
+				Arguments: []*commands.Argument{messageIDArgument},
+				Run:       cmd.pin,
+			}, {
+				Name:      "remove",
+				Aliases:   []string{"unpin", "delete"},
+				Summary:   "unpins a message",
+				Arguments: []*commands.Argument{messageIDArgument},
+				Run:       cmd.unpin,
+			},
+		},
+		DefaultSubcommand: "show",
+		Examples: []string{
+			"pins",
+			"pins list | send",
+			"pins add 123456789012345678",
+		},
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the pins command.
+func (cmd *PinsCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *PinsCmd) Execute(writer io.Writer, parameters []string) {
+	if cmd.window.GetSelectedChannel() == nil {
+		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]In order to use this command, you have to be in a channel.")
+		return
+	}
+
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *PinsCmd) show(writer io.Writer, invocation *commands.Invocation) {
+	showError := cmd.window.ShowPinnedMessages(cmd.window.GetSelectedChannel())
+	if showError != nil {
+		commands.PrintError(writer, "Error loading pinned messages", showError.Error())
+	}
+}
+
+func (cmd *PinsCmd) list(writer io.Writer, invocation *commands.Invocation) {
+	pinnedMessages, loadError := cmd.session.ChannelMessagesPinned(cmd.window.GetSelectedChannel().ID)
+	if loadError != nil {
+		commands.PrintError(writer, "Error loading pinned messages", loadError.Error())
+		return
+	}
+
+	if len(pinnedMessages) == 0 {
+		fmt.Fprintln(writer, "There are no pinned messages in this channel.")
+		return
+	}
+
+	discordutil.SortMessagesByTimestamp(pinnedMessages)
+	for _, message := range pinnedMessages {
+		content := strings.Replace(discordutil.MessageToPlainText(message), "\n", " ", -1)
+		fmt.Fprintf(writer, "%s %s: %s\n", message.ID,
+			tviewutil.Escape(discordutil.GetUserName(message.Author)), tviewutil.Escape(content))
+	}
+}
+
+func (cmd *PinsCmd) pin(writer io.Writer, invocation *commands.Invocation) {
+	cmd.setPinned(writer, invocation.Argument("message-id"), true)
+}
+
+func (cmd *PinsCmd) unpin(writer io.Writer, invocation *commands.Invocation) {
+	cmd.setPinned(writer, invocation.Argument("message-id"), false)
+}
+
+func (cmd *PinsCmd) setPinned(writer io.Writer, messageID string, pinned bool) {
+	pinError := cmd.window.SetMessagePinned(cmd.window.GetSelectedChannel(), messageID, pinned)
+	if pinError != nil {
+		commands.PrintError(writer, "Error changing pin", pinError.Error())
+		return
+	}
+
+	if pinned {
+		fmt.Fprintf(writer, "Message %s has been pinned.\n", messageID)
+	} else {
+		fmt.Fprintf(writer, "Message %s has been unpinned.\n", messageID)
+	}
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *PinsCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *PinsCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *PinsCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
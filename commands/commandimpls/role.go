@@ -0,0 +1,441 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// RoleCmd allows listing and administrating the roles of the currently
+// selected server.
+type RoleCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+	spec    *commands.Spec
+}
+
+// NewRoleCommand creates a ready to use command for administrating roles.
+func NewRoleCommand(window *ui.Window, session *discordgo.Session) *RoleCmd {
+	cmd := &RoleCmd{
+		window:  window,
+		session: session,
+	}
+	roleArgument := &commands.Argument{
+		Name:        "role",
+		Description: "The name, mention or ID of a role in the current server.",
+		Completer:   commands.RoleCompleter(session.State, window),
+	}
+	memberArgument := &commands.Argument{
+		Name:        "user",
+		Description: "The name, Username#NNNN, mention or ID of a member of the current server.",
+		Completer:   commands.MemberCompleter(session.State, window),
+	}
+	colorFlag := &commands.Flag{
+		Names:       []string{"-c", "--color"},
+		Value:       "color",
+		Description: "The color of the role in the format #RRGGBB or none.",
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "role",
+		Aliases: []string{"roles"},
+		Summary: "list, create, edit, delete and assign roles",
+		Description: `The role command allows administrating the roles of the currently
+selected server. Listing roles is always possible, while all other
+subcommands require the permission to manage roles. Discord only allows
+managing roles that are lower than your own highest role.`,
+		Subcommands: []*commands.Spec{
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Summary: "lists all roles with their color and amount of members",
+				Run:     cmd.list,
+			}, {
+				Name:    "create",
+				Aliases: []string{"new"},
+				Summary: "creates a new role without any permissions",
+				Arguments: []*commands.Argument{{
+					Name:        "name",
+					Description: "The name of the new role.",
+				}},
+				Flags: []*commands.Flag{
+					colorFlag,
+					{
+						Names:       []string{"--hoist"},
+						Description: "Shows the members of the role separately in the user list.",
+					}, {
+						Names:       []string{"--mentionable"},
+						Description: "Allows everyone to mention the role.",
+					},
+				},
+				Run: cmd.create,
+			}, {
+				Name:      "edit",
+				Summary:   "changes the name, color or settings of a role",
+				Arguments: []*commands.Argument{roleArgument},
+				Flags: []*commands.Flag{
+					{
+						Names:       []string{"-n", "--name"},
+						Value:       "name",
+						Description: "The new name of the role.",
+					},
+					colorFlag,
+					{
+						Names:       []string{"--hoist"},
+						Value:       "on|off",
+						Description: "Whether the members of the role are shown separately in the user list.",
+						Completer:   commands.ValuesCompleter("on", "off"),
+					}, {
+						Names:       []string{"--mentionable"},
+						Value:       "on|off",
+						Description: "Whether everyone may mention the role.",
+						Completer:   commands.ValuesCompleter("on", "off"),
+					},
+				},
+				Run: cmd.edit,
+			}, {
+				Name:      "delete",
+				Summary:   "deletes a role after asking for confirmation",
+				Arguments: []*commands.Argument{roleArgument},
+				Run:       cmd.delete,
+			}, {
+				Name:      "add",
+				Aliases:   []string{"give"},
+				Summary:   "assigns a role to a member",
+				Arguments: []*commands.Argument{memberArgument, roleArgument},
+				Run:       cmd.add,
+			}, {
+				Name:      "remove",
+				Aliases:   []string{"take"},
+				Summary:   "removes a role from a member",
+				Arguments: []*commands.Argument{memberArgument, roleArgument},
+				Run:       cmd.remove,
+			},
+		},
+		DefaultSubcommand: "list",
+		Examples: []string{
+			"role",
+			"role create --color #3498db --hoist Moderators",
+			"role edit Moderators --name Mods --mentionable on",
+			"role add Marcel#7299 Mods",
+		},
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the role command.
+func (cmd *RoleCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *RoleCmd) Execute(writer io.Writer, parameters []string) {
+	if cmd.window.GetSelectedGuild() == nil {
+		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]In order to use this command, you have to be in a server.")
+		return
+	}
+
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *RoleCmd) list(writer io.Writer, invocation *commands.Invocation) {
+	guild := cmd.window.GetSelectedGuild()
+	roles := make([]*discordgo.Role, 0, len(guild.Roles))
+	for _, role := range guild.Roles {
+		//The "@everyone" role implicitly applies to all members.
+		if role.ID != guild.ID {
+			roles = append(roles, role)
+		}
+	}
+
+	if len(roles) == 0 {
+		fmt.Fprintln(writer, "This server has no roles.")
+		return
+	}
+
+	sort.Slice(roles, func(a, b int) bool {
+		return roles[a].Position > roles[b].Position
+	})
+
+	//Only members that have been loaded are counted.
+	memberCounts := make(map[string]int, len(roles))
+	members, _ := cmd.session.State.Members(guild.ID)
+	for _, member := range members {
+		for _, roleID := range member.Roles {
+			memberCounts[roleID]++
+		}
+	}
+
+	for _, role := range roles {
+		var details []string
+		if role.Hoist {
+			details = append(details, "hoisted")
+		}
+		if role.Mentionable {
+			details = append(details, "mentionable")
+		}
+		if role.Managed {
+			details = append(details, "managed")
+		}
+
+		name := tviewutil.Escape(role.Name)
+		if roleColor := discordutil.GetRoleColor(role); roleColor != "" {
+			name = "[" + roleColor + "]" + name + "[-]"
+		}
+		fmt.Fprintf(writer, "%s (%d members)", name, memberCounts[role.ID])
+		if len(details) > 0 {
+			fmt.Fprintf(writer, " %s", strings.Join(details, ", "))
+		}
+		fmt.Fprintln(writer)
+	}
+}
+
+func (cmd *RoleCmd) create(writer io.Writer, invocation *commands.Invocation) {
+	if !cmd.checkPermission(writer, "Error creating role") {
+		return
+	}
+
+	var color int
+	if value, set := invocation.Flag("--color"); set {
+		var parseError error
+		color, parseError = discordutil.ParseRoleColor(value)
+		if parseError != nil {
+			commands.PrintError(writer, "Error creating role", tviewutil.Escape(parseError.Error()))
+			return
+		}
+	}
+	_, hoist := invocation.Flag("--hoist")
+	_, mentionable := invocation.Flag("--mentionable")
+
+	guildID := cmd.window.GetSelectedGuild().ID
+	role, createError := cmd.session.GuildRoleCreate(guildID)
+	if createError != nil {
+		commands.PrintError(writer, "Error creating role", createError.Error())
+		return
+	}
+
+	//Roles are always created with default values and have to be edited
+	//afterwards. The permissions are kept as they are.
+	edited, editError := cmd.session.GuildRoleEdit(guildID, role.ID, invocation.Argument("name"), color, hoist, role.Permissions, mentionable)
+	if editError != nil {
+		commands.PrintError(writer, "Error creating role", editError.Error())
+		//Otherwise an unnamed role would be left behind.
+		if deleteError := cmd.session.GuildRoleDelete(guildID, role.ID); deleteError != nil {
+			commands.PrintError(writer, "Error deleting incomplete role", deleteError.Error())
+		}
+		return
+	}
+
+	fmt.Fprintf(writer, "%s has been created.\n", tviewutil.Escape(edited.Name))
+}
+
+func (cmd *RoleCmd) edit(writer io.Writer, invocation *commands.Invocation) {
+	if !cmd.checkPermission(writer, "Error editing role") {
+		return
+	}
+
+	role := cmd.findRole(writer, "Error editing role", invocation.Argument("role"))
+	if role == nil {
+		return
+	}
+
+	if len(invocation.FlagsInOrder()) == 0 {
+		commands.PrintError(writer, "Error editing role", "Nothing to change, use at least one of the flags.")
+		return
+	}
+
+	name, color, hoist, mentionable := role.Name, role.Color, role.Hoist, role.Mentionable
+	if value, set := invocation.Flag("--name"); set {
+		if role.ID == cmd.window.GetSelectedGuild().ID {
+			commands.PrintError(writer, "Error editing role", "The @everyone role can't be renamed.")
+			return
+		}
+		name = value
+	}
+	if value, set := invocation.Flag("--color"); set {
+		var parseError error
+		color, parseError = discordutil.ParseRoleColor(value)
+		if parseError != nil {
+			commands.PrintError(writer, "Error editing role", tviewutil.Escape(parseError.Error()))
+			return
+		}
+	}
+	if value, set := invocation.Flag("--hoist"); set {
+		var valid bool
+		if hoist, valid = parseSwitch(value); !valid {
+			commands.PrintError(writer, "Error editing role", fmt.Sprintf("'%s' has to be either on or off.", tviewutil.Escape(value)))
+			return
+		}
+	}
+	if value, set := invocation.Flag("--mentionable"); set {
+		var valid bool
+		if mentionable, valid = parseSwitch(value); !valid {
+			commands.PrintError(writer, "Error editing role", fmt.Sprintf("'%s' has to be either on or off.", tviewutil.Escape(value)))
+			return
+		}
+	}
+
+	//The usertree is updated via the role update event.
+	edited, editError := cmd.session.GuildRoleEdit(cmd.window.GetSelectedGuild().ID, role.ID, name, color, hoist, role.Permissions, mentionable)
+	if editError != nil {
+		commands.PrintError(writer, "Error editing role", editError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "%s has been updated.\n", tviewutil.Escape(edited.Name))
+}
+
+func (cmd *RoleCmd) delete(writer io.Writer, invocation *commands.Invocation) {
+	if !cmd.checkPermission(writer, "Error deleting role") {
+		return
+	}
+
+	role := cmd.findAssignableRole(writer, "Error deleting role", invocation.Argument("role"))
+	if role == nil {
+		return
+	}
+
+	guildID := cmd.window.GetSelectedGuild().ID
+	question := fmt.Sprintf("Do you really want to delete the role '%s'?", tviewutil.Escape(role.Name))
+	cmd.window.ShowConfirmationDialog(question, func(writer io.Writer) {
+		deleteError := cmd.session.GuildRoleDelete(guildID, role.ID)
+		if deleteError != nil {
+			commands.PrintError(writer, "Error deleting role", deleteError.Error())
+			return
+		}
+
+		fmt.Fprintf(writer, "%s has been deleted.\n", tviewutil.Escape(role.Name))
+	})
+}
+
+func (cmd *RoleCmd) add(writer io.Writer, invocation *commands.Invocation) {
+	member, role := cmd.findMemberAndRole(writer, "Error assigning role", invocation)
+	if member == nil || role == nil {
+		return
+	}
+
+	for _, roleID := range member.Roles {
+		if roleID == role.ID {
+			commands.PrintError(writer, "Error assigning role", fmt.Sprintf("%s already has the role '%s'.",
+				tviewutil.Escape(member.User.String()), tviewutil.Escape(role.Name)))
+			return
+		}
+	}
+
+	//The usertree is updated via the member update event.
+	addError := cmd.session.GuildMemberRoleAdd(cmd.window.GetSelectedGuild().ID, member.User.ID, role.ID)
+	if addError != nil {
+		commands.PrintError(writer, "Error assigning role", addError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "%s has been given the role '%s'.\n", tviewutil.Escape(member.User.String()), tviewutil.Escape(role.Name))
+}
+
+func (cmd *RoleCmd) remove(writer io.Writer, invocation *commands.Invocation) {
+	member, role := cmd.findMemberAndRole(writer, "Error removing role", invocation)
+	if member == nil || role == nil {
+		return
+	}
+
+	removeError := cmd.session.GuildMemberRoleRemove(cmd.window.GetSelectedGuild().ID, member.User.ID, role.ID)
+	if removeError != nil {
+		commands.PrintError(writer, "Error removing role", removeError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "The role '%s' has been removed from %s.\n", tviewutil.Escape(role.Name), tviewutil.Escape(member.User.String()))
+}
+
+// findMemberAndRole resolves the user and role arguments of the invocation.
+// If either can't be resolved, an error is printed.
+func (cmd *RoleCmd) findMemberAndRole(writer io.Writer, errorTitle string, invocation *commands.Invocation) (*discordgo.Member, *discordgo.Role) {
+	if !cmd.checkPermission(writer, errorTitle) {
+		return nil, nil
+	}
+
+	member := findMember(writer, cmd.session.State, cmd.window.GetSelectedGuild().ID, errorTitle, invocation.Argument("user"))
+	if member == nil {
+		return nil, nil
+	}
+
+	return member, cmd.findAssignableRole(writer, errorTitle, invocation.Argument("role"))
+}
+
+// findRole looks up a role of the selected guild by name or ID. If there's
+// no unique match, an error is printed and nil returned.
+func (cmd *RoleCmd) findRole(writer io.Writer, errorTitle, nameOrID string) *discordgo.Role {
+	matches := discordutil.FindRoles(cmd.window.GetSelectedGuild().Roles, nameOrID)
+	if len(matches) == 0 {
+		commands.PrintError(writer, errorTitle, fmt.Sprintf("There's no role called '%s'.", tviewutil.Escape(nameOrID)))
+		return nil
+	}
+
+	if len(matches) > 1 {
+		ids := make([]string, 0, len(matches))
+		for _, match := range matches {
+			ids = append(ids, match.ID)
+		}
+		commands.PrintError(writer, errorTitle,
+			fmt.Sprintf("There are multiple roles called '%s'. Use one of these IDs instead: %s", tviewutil.Escape(nameOrID), strings.Join(ids, ", ")))
+		return nil
+	}
+
+	return matches[0]
+}
+
+// findAssignableRole works like findRole, but doesn't accept the "@everyone"
+// role and roles managed by integrations, such as bot roles.
+func (cmd *RoleCmd) findAssignableRole(writer io.Writer, errorTitle, nameOrID string) *discordgo.Role {
+	role := cmd.findRole(writer, errorTitle, nameOrID)
+	if role == nil {
+		return nil
+	}
+
+	if role.ID == cmd.window.GetSelectedGuild().ID {
+		commands.PrintError(writer, errorTitle, "The @everyone role applies to all members and can't be changed this way.")
+		return nil
+	}
+
+	if role.Managed {
+		commands.PrintError(writer, errorTitle, fmt.Sprintf("The role '%s' is managed by an integration.", tviewutil.Escape(role.Name)))
+		return nil
+	}
+
+	return role
+}
+
+// checkPermission prints an error if the current user isn't allowed to
+// manage the roles of the selected server.
+func (cmd *RoleCmd) checkPermission(writer io.Writer, errorTitle string) bool {
+	if discordutil.HasGuildPermission(cmd.window.GetSelectedGuild().ID, cmd.session.State, discordgo.PermissionManageRoles) {
+		return true
+	}
+
+	commands.PrintError(writer, errorTitle, "You don't have the permission to manage roles in this server.")
+	return false
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *RoleCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *RoleCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *RoleCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
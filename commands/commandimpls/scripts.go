@@ -0,0 +1,66 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/ui"
+)
+
+const scriptsReloadDocumentation = `[::b]NAME
+	scripts-reload - Loads all scripts again
+
+[::b]SYNOPSIS
+	[::b]scripts-reload
+
+[::b]DESCRIPTION
+	This command unloads all scripts and loads them from the script
+	directory again. This way changes to scripts can be applied without
+	restarting cordless. All timers that the scripts have scheduled via
+	setTimeout or setInterval are cancelled. The data that scripts have
+	saved in their storage is kept.`
+
+// ScriptsReloadCmd allows reloading all scripts without restarting.
+type ScriptsReloadCmd struct {
+	window *ui.Window
+}
+
+// NewScriptsReloadCommand creates a ready-to-use ScriptsReloadCmd.
+func NewScriptsReloadCommand(window *ui.Window) *ScriptsReloadCmd {
+	return &ScriptsReloadCmd{
+		window: window,
+	}
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *ScriptsReloadCmd) Execute(writer io.Writer, parameters []string) {
+	if len(parameters) != 0 {
+		cmd.PrintHelp(writer)
+		return
+	}
+
+	if reloadError := cmd.window.ReloadScripts(); reloadError != nil {
+		commands.PrintError(writer, "Error reloading scripts", reloadError.Error())
+		return
+	}
+
+	fmt.Fprintf(writer, "Scripts have been reloaded from '%s'.\n", config.GetScriptDirectory())
+}
+
+// PrintHelp prints a static help page for this command
+func (cmd *ScriptsReloadCmd) PrintHelp(writer io.Writer) {
+	fmt.Fprintln(writer, scriptsReloadDocumentation)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *ScriptsReloadCmd) Name() string {
+	return "scripts-reload"
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *ScriptsReloadCmd) Aliases() []string {
+	return []string{"reload-scripts"}
+}
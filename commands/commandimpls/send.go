@@ -0,0 +1,127 @@
+package commandimpls
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/ui"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// maxMessageLength is the maximum amount of characters that discord accepts
+// for a single message.
+const maxMessageLength = 2000
+
+// SendCmd sends a message into the currently loaded channel. Output piped
+// into it is sent as a code block.
+type SendCmd struct {
+	window  *ui.Window
+	session *discordgo.Session
+	spec    *commands.Spec
+}
+
+// NewSendCommand creates a ready to use command for sending messages.
+func NewSendCommand(window *ui.Window, session *discordgo.Session) *SendCmd {
+	cmd := &SendCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "send",
+		Aliases: []string{"say"},
+		Summary: "sends a message into the current channel",
+		Description: `The send command sends the given text into the currently loaded channel.
+If the output of another command is piped into it, that output is sent as
+a code block below the text.`,
+		Arguments: []*commands.Argument{{
+			Name:        "text",
+			Description: "The text to send. It's sent exactly as it has been typed, quotes don't have to be escaped.",
+			Optional:    true,
+			Variadic:    true,
+		}},
+		Examples: []string{
+			"send Hello there",
+			"friends list | send",
+			`friends requests | send "My open friend requests:"`,
+		},
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the send command.
+func (cmd *SendCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Execute runs the command piping its output into the supplied writer.
+func (cmd *SendCmd) Execute(writer io.Writer, parameters []string) {
+	if cmd.window.GetSelectedChannel() == nil {
+		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]In order to use this command, you have to be in a channel.")
+		return
+	}
+
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+// ExecuteWithInput works like Execute, but additionally sends the output of
+// the previous command as a code block.
+func (cmd *SendCmd) ExecuteWithInput(writer io.Writer, input string, parameters []string) {
+	if cmd.window.GetSelectedChannel() == nil {
+		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]In order to use this command, you have to be in a channel.")
+		return
+	}
+
+	commands.DispatchWithInput(cmd.spec, writer, input, parameters)
+}
+
+func (cmd *SendCmd) run(writer io.Writer, invocation *commands.Invocation) {
+	message := strings.Join(invocation.Arguments("text"), " ")
+	if input, piped := invocation.Input(); piped && strings.TrimSpace(input) != "" {
+		//Backticks inside of the code block would end it prematurely,
+		//therefore a zero width space is put between them.
+		codeBlock := "```\n" + strings.Replace(strings.TrimRight(input, "\n"), "```", "`\u200b``", -1) + "\n```"
+		if message == "" {
+			message = codeBlock
+		} else {
+			message = message + "\n" + codeBlock
+		}
+	}
+
+	if strings.TrimSpace(message) == "" {
+		commands.PrintError(writer, "Error sending message", "There is nothing to send.")
+		return
+	}
+
+	if overlength := len([]rune(message)) - maxMessageLength; overlength > 0 {
+		commands.PrintError(writer, "Error sending message",
+			fmt.Sprintf("The message is %d characters too long. Try redirecting the output into a file and sending it via file-send instead.", overlength))
+		return
+	}
+
+	_, sendError := cmd.session.ChannelMessageSend(cmd.window.GetSelectedChannel().ID, message)
+	if sendError != nil {
+		commands.PrintError(writer, "Error sending message", sendError.Error())
+	}
+}
+
+// PrintHelp prints the help page generated from the commands declaration.
+func (cmd *SendCmd) PrintHelp(writer io.Writer) {
+	commands.PrintSpecHelp(writer, cmd.spec)
+}
+
+// Name returns the primary name for this command. This name will also be
+// used for listing the command in the commandlist.
+func (cmd *SendCmd) Name() string {
+	return cmd.spec.Name
+}
+
+// Aliases are a list of aliases for this command. There might be none.
+func (cmd *SendCmd) Aliases() []string {
+	return cmd.spec.Aliases
+}
@@ -3,118 +3,164 @@ package commandimpls
 import (
 	"fmt"
 	"io"
-	"strings"
 
 	"github.com/Bios-Marcel/discordgo"
 
 	"github.com/Bios-Marcel/cordless/commands"
 	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
 	"github.com/Bios-Marcel/cordless/ui"
 	"github.com/Bios-Marcel/cordless/ui/tviewutil"
 )
 
-const serverHelpPage = `[::b]NAME
-	server - allows you to join or leave a server
-
-[::b]SYNOPSIS
-	[::b]server[::-] <subcommand <args>>
-
-[::b]DESCRIPTION
-	The server command allows you to join a new server or leave one that you
-	are already a part of. What this command can't do is administrating a
-	server in any way.
-
-[::b]SUBCOMMANDS
-	[::b]server-join
-		joins the server using the given invitation
-	[::b]server-leave
-		leaves the given server`
-
-const serverJoinHelpPage = `[::b]NAME
-	server-join - allows you to join a server
-
-[::b]SYNOPSIS
-	[::b]server-join[::-] <InviteCode|InviteURL>
-
-[::b]DESCRIPTION
-	This command will take a invite code or an invite URl and attempt joining
-	the server behind it.
-
-[::b]EXAMPLES
-	[gray]$ server-join https://discord.gg/JDScUK
-	[gray]$ server-join discord.gg/JDScUK
-	[gray]$ server-join JDScUK`
-
-const serverLeaveHelpPage = `[::b]NAME
-	server-leave - allows you to leave a server
-
-[::b]SYNOPSIS
-	[::b]server-leave[::-] <ID|Name>
-
-[::b]DESCRIPTION
-	This command will take a server ID or it's name and leave that server.
-
-[::b]EXAMPLES
-	[gray]$ server-leave 118456055842734083
-	[gray]$ server-leave "Discord Gophers"
-	[gray]$ server-leave Nirvana`
-
-const serverCreateHelpPage = `[::b]NAME
-	server-create - allows you to create a new server
-
-[::b]SYNOPSIS
-	[::b]server-create[::-] <Name>
-
-[::b]DESCRIPTION
-	This command will take a name and create a server using that name. You'll
-	be told the name and the ID on successful creation.
-
-[::b]EXAMPLES
-	[gray]$ server-create "Hello world"
-	[gray]$ server-create MyServerName`
-
 type ServerCmd struct {
 	serverJoinCmd   *ServerJoinCmd
 	serverLeaveCmd  *ServerLeaveCmd
 	serverCreateCmd *ServerCreateCmd
+	spec            *commands.Spec
 }
 
 type ServerJoinCmd struct {
 	window  *ui.Window
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
 type ServerLeaveCmd struct {
 	window  *ui.Window
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
 type ServerCreateCmd struct {
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
 func NewServerCommand(serverJoinCmd *ServerJoinCmd, serverLeaveCmd *ServerLeaveCmd, serverCreateCmd *ServerCreateCmd) *ServerCmd {
-	return &ServerCmd{serverJoinCmd, serverLeaveCmd, serverCreateCmd}
+	return &ServerCmd{
+		serverJoinCmd:   serverJoinCmd,
+		serverLeaveCmd:  serverLeaveCmd,
+		serverCreateCmd: serverCreateCmd,
+		spec: &commands.Spec{
+			Name:    "server",
+			Aliases: []string{"guild"},
+			Summary: "allows you to join, leave or create a server",
+			Description: `The server command allows you to join a new server or leave one that you
+are already a part of. What this command can't do is administrating a
+server in any way.`,
+			Subcommands: []*commands.Spec{
+				serverJoinCmd.spec.WithName("join", "accept", "enter"),
+				serverLeaveCmd.spec.WithName("leave", "exit", "quit"),
+				serverCreateCmd.spec.WithName("create", "new"),
+			},
+			Examples: []string{
+				"server join JDScUK",
+				"server leave Nirvana",
+			},
+		},
+	}
 }
 
 func NewServerJoinCommand(window *ui.Window, session *discordgo.Session) *ServerJoinCmd {
-	return &ServerJoinCmd{window, session}
+	cmd := &ServerJoinCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "server-join",
+		Aliases: []string{"guild-join", "guild-accept", "guild-enter", "server-accept", "server-enter"},
+		Summary: "joins the server using the given invitation",
+		Description: `This command will take a invite code or an invite URl and attempt joining
+the server behind it.`,
+		Arguments: []*commands.Argument{{
+			Name:        "invite",
+			Description: "The invite code or the invite URL.",
+		}},
+		Examples: []string{
+			"server-join https://discord.gg/JDScUK",
+			"server-join discord.gg/JDScUK",
+			"server-join JDScUK",
+		},
+		Run: cmd.run,
+	}
+	return cmd
 }
 
 func NewServerLeaveCommand(window *ui.Window, session *discordgo.Session) *ServerLeaveCmd {
-	return &ServerLeaveCmd{window, session}
+	cmd := &ServerLeaveCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:        "server-leave",
+		Aliases:     []string{"guild-leave", "guild-exit", "guild-quit", "server-exit", "server-quit"},
+		Summary:     "leaves the given server",
+		Description: "This command will take a server ID or it's name and leave that server.",
+		Arguments: []*commands.Argument{{
+			Name:        "server",
+			Description: "The ID or the name of the server.",
+			Completer:   commands.GuildCompleter(session.State),
+		}},
+		Examples: []string{
+			"server-leave 118456055842734083",
+			`server-leave "Discord Gophers"`,
+			"server-leave Nirvana",
+		},
+		Run: cmd.run,
+	}
+	return cmd
 }
 
 func NewServerCreateCommand(session *discordgo.Session) *ServerCreateCmd {
-	return &ServerCreateCmd{session}
+	cmd := &ServerCreateCmd{
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "server-create",
+		Aliases: []string{"guild-create", "guild-new", "server-new"},
+		Summary: "creates a new server",
+		Description: `This command will take a name and create a server using that name. You'll
+be told the name and the ID on successful creation.`,
+		Arguments: []*commands.Argument{{
+			Name:        "name",
+			Description: "The name of the new server.",
+		}},
+		Examples: []string{
+			`server-create "Hello world"`,
+			"server-create MyServerName",
+		},
+		Run: cmd.run,
+	}
+	return cmd
 }
+
+// Spec returns the declaration of the server command.
+func (cmd *ServerCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Spec returns the declaration of the server-join command.
+func (cmd *ServerJoinCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Spec returns the declaration of the server-leave command.
+func (cmd *ServerLeaveCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Spec returns the declaration of the server-create command.
+func (cmd *ServerCreateCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
 func (cmd *ServerCreateCmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) != 1 {
-		cmd.PrintHelp(writer)
-		return
-	}
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
 
-	newGuild, createError := cmd.session.GuildCreate(parameters[0])
+func (cmd *ServerCreateCmd) run(writer io.Writer, invocation *commands.Invocation) {
+	newGuild, createError := cmd.session.GuildCreate(invocation.Argument("name"))
 	if createError != nil {
 		commands.PrintError(writer, "Couldn't create server", createError.Error())
 	} else {
@@ -123,61 +169,40 @@ func (cmd *ServerCreateCmd) Execute(writer io.Writer, parameters []string) {
 }
 
 func (cmd *ServerCreateCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, serverCreateHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *ServerCreateCmd) Name() string {
-	return "server-create"
+	return cmd.spec.Name
 }
 
 func (cmd *ServerCreateCmd) Aliases() []string {
-	return []string{"guild-create", "guild-new", "server-new"}
+	return cmd.spec.Aliases
 }
 
 func (cmd *ServerCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, serverHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *ServerCmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) == 0 {
-		cmd.PrintHelp(writer)
-	} else {
-		combinedName := cmd.Name() + "-" + parameters[0]
-		if commands.CommandEquals(cmd.serverJoinCmd, combinedName) {
-			cmd.serverJoinCmd.Execute(writer, parameters[1:])
-		} else if commands.CommandEquals(cmd.serverLeaveCmd, combinedName) {
-			cmd.serverLeaveCmd.Execute(writer, parameters[1:])
-		} else if commands.CommandEquals(cmd.serverCreateCmd, combinedName) {
-			cmd.serverCreateCmd.Execute(writer, parameters[1:])
-		} else {
-			cmd.PrintHelp(writer)
-		}
-	}
+	commands.Dispatch(cmd.spec, writer, parameters)
 }
 
 func (cmd *ServerJoinCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, serverJoinHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *ServerJoinCmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) != 1 {
-		cmd.PrintHelp(writer)
-		return
-	}
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
 
+func (cmd *ServerJoinCmd) run(writer io.Writer, invocation *commands.Invocation) {
 	if cmd.session.State.User.Bot {
 		fmt.Fprintln(writer, "[red]This command can't be used by bots due to Discord API restrictions.")
 		return
 	}
 
-	input := parameters[0]
-	lastSlash := strings.LastIndex(input, "/")
-	var inviteID string
-	if lastSlash == -1 {
-		inviteID = input
-	} else {
-		inviteID = input[lastSlash+1:]
-	}
+	inviteID := discordutil.ParseInviteCode(invocation.Argument("invite"))
 
 	invite, err := cmd.session.InviteAccept(inviteID)
 	if err != nil {
@@ -188,16 +213,15 @@ func (cmd *ServerJoinCmd) Execute(writer io.Writer, parameters []string) {
 }
 
 func (cmd *ServerLeaveCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, serverLeaveHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *ServerLeaveCmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) != 1 {
-		cmd.PrintHelp(writer)
-		return
-	}
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
 
-	input := parameters[0]
+func (cmd *ServerLeaveCmd) run(writer io.Writer, invocation *commands.Invocation) {
+	input := invocation.Argument("server")
 	matches := make([]*discordgo.Guild, 0)
 	for _, guild := range cmd.session.State.Guilds {
 		if guild.ID == input || guild.Name == input {
@@ -230,25 +254,25 @@ func (cmd *ServerLeaveCmd) Execute(writer io.Writer, parameters []string) {
 }
 
 func (cmd *ServerCmd) Name() string {
-	return "server"
+	return cmd.spec.Name
 }
 
 func (cmd *ServerJoinCmd) Name() string {
-	return "server-join"
+	return cmd.spec.Name
 }
 
 func (cmd *ServerLeaveCmd) Name() string {
-	return "server-leave"
+	return cmd.spec.Name
 }
 
 func (cmd *ServerCmd) Aliases() []string {
-	return []string{"guild"}
+	return cmd.spec.Aliases
 }
 
 func (cmd *ServerJoinCmd) Aliases() []string {
-	return []string{"guild-join", "guild-accept", "guild-enter", "server-accept", "server-enter"}
+	return cmd.spec.Aliases
 }
 
 func (cmd *ServerLeaveCmd) Aliases() []string {
-	return []string{"guild-leave", "guild-exit", "guild-quit", "server-exit", "server-quit"}
+	return cmd.spec.Aliases
 }
@@ -3,109 +3,41 @@ package commandimpls
 import (
 	"fmt"
 	"io"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Bios-Marcel/discordemojimap"
 	"github.com/Bios-Marcel/discordgo"
 
+	"github.com/Bios-Marcel/cordless/commands"
 	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/times"
+	"github.com/Bios-Marcel/cordless/ui"
 	"github.com/Bios-Marcel/cordless/ui/tviewutil"
 )
 
-const (
-	statusHelpPage = `[::b]NAME
-	status - view your or others status or update your own
-
-[::b]SYNOPSIS
-	[::b]status [subcommand]
-
-[::b]DESCRIPTION
-	This command allows to either update your status or view a users status.
-	For more information check the help pages of the subcommands.
-
-[::b]SUBCOMMANDS
-	[::b]status-get (default)
-		prints the status of the given user or yourself
-	[::b]status-set
-		updates your current status
-	[::b]status-set-custom
-		set your status to a custom text`
-
-	statusSetHelpPage = `[::b]NAME
-	status-set - allows updating your own status
-
-[::b]SYNOPSIS
-	[::b]status-set[::-] <online|idle|dnd|invisible>
-
-[::b]DESCRIPTION
-	This command can be used to set your current online status to the
-	value passed as the first parameter. Other users will immediately
-	see your status update.
-
-[::b]EXAMPLES
-	[gray]$ status-set invisible`
-
-	statusGetHelpPage = `[::b]NAME
-	status-get - prints your current status or the status of the given user
-
-[::b]SYNOPSIS
-	[::b]status-get[::-] [Username|Username#NNNN|UserID[]
-
-[::b]DESCRIPTION
-	This command prints either your current status of no value was passed
-	or the status of the passed user, if the presence for that user could
-	be found. Due to a problem with the presences, this command might randomly
-	fail when trying to query specific users.
-
-[::b]EXAMPLES
-	[gray]$ status-get
-	[yellow]idle
-
-	[gray]$ status-get Marcel#7299
-	[red]Do not disturb`
-
-	statusSetCustomHelpPage = `[::b]NAME
-	status-set-custom - set a custom status text
-
-[::b]SYNOPSIS
-	[::b]status-set-custom[::-] [OPTION[]...
-
-[::b]DESCRIPTION
-	This command allows you to set a custom status.
-
-[::b]OPTIONS
-	[::b]-s, --status
-		status message
-	[::b]-e, --emoji
-		emoji in your status
-	[::b]-i, --expire, --expiry <s|m|h>
-		time that the status expires after
-
-[::b]EXAMPLES
-	[gray]$ status-set-custom -s "shining bright" -e :sun:
-	[gray]$ status-set-custom -s "shining bright" -e 🌞
-	[gray]$ status-set-custom -s test -i 1h`
-)
-
 type StatusCmd struct {
 	statusGetCmd       *StatusGetCmd
 	statusSetCmd       *StatusSetCmd
 	statusSetCustomCmd *StatusSetCustomCmd
+	spec               *commands.Spec
 }
 
 type StatusGetCmd struct {
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
 type StatusSetCmd struct {
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
 type StatusSetCustomCmd struct {
+	window  *ui.Window
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
 func NewStatusCommand(statusGetCmd *StatusGetCmd, statusSetCmd *StatusSetCmd, statusSetCustomCmd *StatusSetCustomCmd) *StatusCmd {
@@ -113,38 +45,137 @@ func NewStatusCommand(statusGetCmd *StatusGetCmd, statusSetCmd *StatusSetCmd, st
 		statusGetCmd:       statusGetCmd,
 		statusSetCmd:       statusSetCmd,
 		statusSetCustomCmd: statusSetCustomCmd,
+		spec: &commands.Spec{
+			Name:    "status",
+			Summary: "view your or others status or update your own",
+			Description: `This command allows to either update your status or view a users status.
+For more information check the help pages of the subcommands.`,
+			Subcommands: []*commands.Spec{
+				statusGetCmd.spec.WithName("get"),
+				statusSetCmd.spec.WithName("set", "update"),
+				statusSetCustomCmd.spec.WithName("set-custom", "custom-set"),
+			},
+			DefaultSubcommand: "get",
+			Examples: []string{
+				"status",
+				"status Marcel#7299",
+				"status set idle",
+			},
+		},
 	}
 }
 
 func NewStatusGetCommand(session *discordgo.Session) *StatusGetCmd {
-	return &StatusGetCmd{session}
+	cmd := &StatusGetCmd{
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "status-get",
+		Summary: "prints your current status or the status of the given user",
+		Description: `This command prints either your current status of no value was passed
+or the status of the passed user, if the presence for that user could
+be found. Due to a problem with the presences, this command might randomly
+fail when trying to query specific users.`,
+		Arguments: []*commands.Argument{{
+			Name:        "user",
+			Description: "The users name, name#discriminator or ID.",
+			Optional:    true,
+			Completer:   commands.UserCompleter(session.State),
+		}},
+		Examples: []string{
+			"status-get",
+			"status-get Marcel#7299",
+		},
+		Run: cmd.run,
+	}
+	return cmd
 }
 
 func NewStatusSetCommand(session *discordgo.Session) *StatusSetCmd {
-	return &StatusSetCmd{session}
+	cmd := &StatusSetCmd{
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "status-set",
+		Aliases: []string{"status-update"},
+		Summary: "updates your current status",
+		Description: `This command can be used to set your current online status to the
+value passed as the first parameter. Other users will immediately
+see your status update.`,
+		Arguments: []*commands.Argument{{
+			Name:        "status",
+			Description: "One of online, idle, dnd or invisible.",
+			Completer:   commands.ValuesCompleter("online", "idle", "dnd", "invisible"),
+		}},
+		Examples: []string{
+			"status-set invisible",
+		},
+		Run: cmd.run,
+	}
+	return cmd
 }
 
-func NewStatusSetCustomCommand(session *discordgo.Session) *StatusSetCustomCmd {
-	return &StatusSetCustomCmd{session}
+func NewStatusSetCustomCommand(window *ui.Window, session *discordgo.Session) *StatusSetCustomCmd {
+	cmd := &StatusSetCustomCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "status-set-custom",
+		Aliases: []string{"status-custom"},
+		Summary: "set your status to a custom text",
+		Description: `This command allows you to set a custom status. Without an expiry, the
+status lasts until it is changed. Expired statuses are cleared by cordless,
+even if they expired while cordless wasn't running.`,
+		Flags: []*commands.Flag{
+			{
+				Names:       []string{"-s", "--status"},
+				Value:       "text",
+				Description: "status message",
+			}, {
+				Names:       []string{"-e", "--emoji"},
+				Value:       "emoji",
+				Description: "emoji in your status",
+			}, {
+				Names:       []string{"-i", "--expire", "--expiry"},
+				Value:       "expiry",
+				Description: "when the status expires, for example 30m, 4h, today or \"until 17:00\"",
+				Completer:   commands.ValuesCompleter("30m", "1h", "4h", "today"),
+			},
+		},
+		Examples: []string{
+			`status-set-custom -s "shining bright" -e :sun:`,
+			`status-set-custom -s "shining bright" -e 🌞`,
+			"status-set-custom -s test -i 1h",
+			`status-set-custom -s "in a meeting" -i "until 17:00"`,
+		},
+		Run: cmd.run,
+	}
+	return cmd
 }
 
-func statusColor(status discordgo.Status) string {
-	switch status {
-	case discordgo.StatusOnline:
-		return "green"
-	case discordgo.StatusDoNotDisturb:
-		return "red"
-	case discordgo.StatusIdle:
-		return "yellow"
-	case discordgo.StatusInvisible, discordgo.StatusOffline:
-		return "gray"
-	default:
-		return "gray"
-	}
+// Spec returns the declaration of the status command.
+func (cmd *StatusCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Spec returns the declaration of the status-get command.
+func (cmd *StatusGetCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Spec returns the declaration of the status-set command.
+func (cmd *StatusSetCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Spec returns the declaration of the status-set-custom command.
+func (cmd *StatusSetCustomCmd) Spec() *commands.Spec {
+	return cmd.spec
 }
 
 func statusToString(status discordgo.Status) string {
-	statusString := "[" + statusColor(status) + "]"
+	statusString := "[" + discordutil.GetStatusColor(status) + "]"
 	switch status {
 	case discordgo.StatusOnline:
 		statusString += "Online"
@@ -163,13 +194,12 @@ func statusToString(status discordgo.Status) string {
 }
 
 func (cmd *StatusGetCmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) > 1 {
-		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]Invalid parameters")
-		cmd.PrintHelp(writer)
-		return
-	}
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
 
-	if len(parameters) == 0 {
+func (cmd *StatusGetCmd) run(writer io.Writer, invocation *commands.Invocation) {
+	input := invocation.Argument("user")
+	if input == "" {
 		fmt.Fprintf(writer, statusToString(cmd.session.State.Settings.Status))
 
 		customStatus := cmd.session.State.Settings.CustomStatus
@@ -180,7 +210,6 @@ func (cmd *StatusGetCmd) Execute(writer io.Writer, parameters []string) {
 		return
 	}
 
-	input := parameters[0]
 	var matches []*discordgo.Presence
 	for _, presence := range cmd.session.State.Presences {
 		user := presence.User
@@ -203,21 +232,20 @@ func (cmd *StatusGetCmd) Execute(writer io.Writer, parameters []string) {
 }
 
 func (cmd *StatusSetCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *StatusSetCmd) run(writer io.Writer, invocation *commands.Invocation) {
 	if cmd.session.State.User.Bot {
 		fmt.Fprintln(writer, "[red]This command can't be used by bots due to Discord API restrictions.")
 		return
 	}
 
-	if len(parameters) == 0 || len(parameters) > 1 {
-		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]Invalid parameters")
-		cmd.PrintHelp(writer)
-		return
-	}
-
 	var settingStatusError error
 	var updatedSettings *discordgo.Settings
 
-	switch strings.ToLower(parameters[0]) {
+	status := strings.ToLower(invocation.Argument("status"))
+	switch status {
 	case "online", "available":
 		updatedSettings, settingStatusError = cmd.session.UserUpdateStatus(discordgo.StatusOnline)
 	case "dnd", "donotdisturb", "busy":
@@ -227,40 +255,32 @@ func (cmd *StatusSetCmd) Execute(writer io.Writer, parameters []string) {
 	case "invisible":
 		updatedSettings, settingStatusError = cmd.session.UserUpdateStatus(discordgo.StatusInvisible)
 	default:
-		fmt.Fprintf(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]Invalid status: '%s'\n", strings.ToLower(parameters[0]))
+		fmt.Fprintf(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]Invalid status: '%s'\n", status)
 		cmd.PrintHelp(writer)
 	}
 
 	if settingStatusError != nil {
 		fmt.Fprintf(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]Error setting status:\n\t["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]'%s'\n", settingStatusError.Error())
 	} else if updatedSettings != nil {
-		cmd.session.State.Settings = updatedSettings
+		discordutil.SetSettings(cmd.session.State, updatedSettings)
 	}
 }
 
 func (cmd *StatusCmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) >= 1 {
-		if parameters[0] == "set" || parameters[0] == "update" {
-			cmd.statusSetCmd.Execute(writer, parameters[1:])
-		} else if parameters[0] == "get" {
-			cmd.statusGetCmd.Execute(writer, parameters[1:])
-		} else if parameters[0] == "set-custom" || parameters[0] == "custom-set" {
-			cmd.statusSetCustomCmd.Execute(writer, parameters[1:])
-		} else {
-			cmd.statusGetCmd.Execute(writer, parameters)
-		}
-	} else {
-		cmd.statusGetCmd.Execute(writer, parameters)
-	}
+	commands.Dispatch(cmd.spec, writer, parameters)
 }
 
 func (cmd *StatusSetCustomCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *StatusSetCustomCmd) run(writer io.Writer, invocation *commands.Invocation) {
 	if cmd.session.State.User.Bot {
 		fmt.Fprintln(writer, "[red]This command can't be used by bots due to Discord API restrictions.")
 		return
 	}
 
-	if len(parameters) == 0 {
+	if len(invocation.FlagsInOrder()) == 0 {
 		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]Invalid parameters")
 		cmd.PrintHelp(writer)
 		return
@@ -268,105 +288,81 @@ func (cmd *StatusSetCustomCmd) Execute(writer io.Writer, parameters []string) {
 
 	errorColor := tviewutil.ColorToHex(config.GetTheme().ErrorColor)
 	var customStatus discordgo.CustomStatus
-	for index, param := range parameters {
-		switch param {
-		case "-s", "--status":
-			if index != len(parameters)-1 {
-				customStatus.Text = parameters[index+1]
-			} else {
-				fmt.Fprintf(writer, "[%s]Error, you didn't supply a status\n", errorColor)
-				return
-			}
-		case "-e", "--emoji":
-			if index != len(parameters)-1 {
-				if discordemojimap.ContainsEmoji(parameters[index+1]) {
-					customStatus.EmojiName = parameters[index+1]
-				} else if emoji := discordemojimap.Replace(parameters[index+1]); emoji != parameters[index+1] {
-					customStatus.EmojiName = emoji
-				} else {
-					fmt.Fprintf(writer, "[%s]Invalid emoji\n", errorColor)
-					return
-				}
-			}
-		case "-i", "--expire", "--expiry":
-			if m, _ := regexp.MatchString(`\d+(s|m|h)`, parameters[index+1]); m {
-				lastIndex := len(parameters[index+1]) - 1
-				num, err := strconv.Atoi(parameters[index+1][:lastIndex])
-				if err != nil {
-					fmt.Fprintf(writer, "[%s]Invalid expiry\n", errorColor)
-					return
-				}
-
-				now := time.Now().UTC()
-				switch parameters[index+1][lastIndex] {
-				case 's':
-					now = now.Add(time.Second * time.Duration(num))
-				case 'm':
-					now = now.Add(time.Minute * time.Duration(num))
-				case 'h':
-					now = now.Add(time.Hour * time.Duration(num))
-				default:
-					fmt.Fprintf(writer, "[%s]Invalid time character: %c != <s|m|h>\n", errorColor, parameters[index+1][lastIndex])
-					return
-				}
-				customStatus.ExpiresAt = now.Format(time.RFC3339Nano)
-			}
+	customStatus.Text, _ = invocation.Flag("-s")
+	if emojiParameter, set := invocation.Flag("-e"); set {
+		if discordemojimap.ContainsEmoji(emojiParameter) {
+			customStatus.EmojiName = emojiParameter
+		} else if emoji := discordemojimap.Replace(emojiParameter); emoji != emojiParameter {
+			customStatus.EmojiName = emoji
+		} else {
+			fmt.Fprintf(writer, "[%s]Invalid emoji\n", errorColor)
+			return
 		}
 	}
+	if expiry, set := invocation.Flag("-i"); set {
+		expiryTime, parseError := times.ParseExpiry(expiry, time.Now())
+		if parseError != nil {
+			fmt.Fprintf(writer, "[%s]Invalid expiry: %s\n", errorColor, tviewutil.Escape(parseError.Error()))
+			return
+		}
+		customStatus.ExpiresAt = expiryTime.UTC().Format(time.RFC3339Nano)
+	}
 
 	settings, err := cmd.session.UserUpdateStatusCustom(customStatus)
 	if err != nil {
 		fmt.Fprintf(writer, "[%s]Error updating custom status:\n\t[%s]'%s'\n", errorColor, errorColor, err.Error())
 		return
 	} else if settings != nil {
-		cmd.session.State.Settings = settings
+		discordutil.SetSettings(cmd.session.State, settings)
 	}
+
+	cmd.window.ScheduleCustomStatusExpiry()
 }
 
 func (cmd *StatusCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, statusHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *StatusSetCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, statusSetHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *StatusGetCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, statusGetHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *StatusSetCustomCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, statusSetCustomHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *StatusSetCustomCmd) Name() string {
-	return "status-set-custom"
+	return cmd.spec.Name
 }
 
 func (cmd *StatusSetCmd) Name() string {
-	return "status-set"
+	return cmd.spec.Name
 }
 
 func (cmd *StatusGetCmd) Name() string {
-	return "status-get"
+	return cmd.spec.Name
 }
 
 func (cmd *StatusCmd) Name() string {
-	return "status"
+	return cmd.spec.Name
 }
 
 func (cmd *StatusSetCustomCmd) Aliases() []string {
-	return []string{"status-custom"}
+	return cmd.spec.Aliases
 }
 
 func (cmd *StatusSetCmd) Aliases() []string {
-	return []string{"status-update"}
+	return cmd.spec.Aliases
 }
 
 func (cmd *StatusGetCmd) Aliases() []string {
-	return nil
+	return cmd.spec.Aliases
 }
 
 func (cmd *StatusCmd) Aliases() []string {
-	return nil
+	return cmd.spec.Aliases
 }
@@ -13,130 +13,90 @@ import (
 	"github.com/Bios-Marcel/cordless/util/text"
 )
 
-const tfaHelpPage = `[::b]NAME
-	tfa - allows you to manage two-factor-authentication on your account
-
-[::b]SYNOPSIS
-	[::b]tfa[::-] <subcommand [args[]>
-
-[::b]DESCRIPTION
-	The tfa command allows you to enable / disable TFA and retrieve or
-	reset your TFA.backup codes. Some actions require you to either input a
-	valid TFA code or your current password.
-
-[::b]SUBCOMMANDS
-	[::b]tfa-enable
-		enables two-factor-authentication on your account
-	[::b]tfa-disable
-		disables two-factor-authentication on your account
-	[::b]tfa-backup-get
-		retrieves your TFA backup codes from discord
-	[::b]tfa-backup-reset
-		resets and retrieves your TFA backup codes from discord`
-
-const tfaEnableHelpPage = `[::b]NAME
-	tfa-enable - enables two-factor-authentication on your account
-
-[::b]SYNOPSIS
-	[::b]tfa-enable[::-]
-
-[::b]DESCRIPTION
-	This command will open a view that shows a QR-code and instructions
-    on how to proceed in order to enable TFA on your discord account.`
-
-const tfaDisableHelpPage = `[::b]NAME
-	tfa-disable - disables two-factor-authentication on your account
-
-[::b]SYNOPSIS
-	[::b]tfa-disable[::-] <TFA Code>
-
-[::b]DESCRIPTION
-	This command will disable TFA on your discord account. In order to disable
-	TFA, you need to pass a valid TFA code for confirming that you actually own
-	the currently registered TFA secret.
-
-[::b]EXAMPLES
-	[gray]$ tfa-disable 123456
-	[gray]$ tfa-disable "123 456"`
-
-const tfaBackupGetHelpPage = `[::b]NAME
-	tfa-backup-get - retrieves your TFA backup codes from discord
-
-[::b]SYNOPSIS
-	[::b]tfa-backup-get[::-]
-
-[::b]DESCRIPTION
-	This command will retrieve your TFA backup codes from discord. Those can
-	be used in order to recover your account in case you've	lost your active
-	TFA device. In order to retrieve the codes, you need to	supply your
-	current password.`
-
-const tfaBackupResetHelpPage = `[::b]NAME
-	tfa-backup-reset - resets and retrieves your TFA backup codes from discord
-
-[::b]SYNOPSIS
-	[::b]tfa-backup-reset[::-]
-
-[::b]DESCRIPTION
-	This command will reset and retrieve your TFA backup codes from discord.
-	Those can be used in order to recover your account in case you've lost
-	your active TFA device. In order to retrieve the codes, you need to
-	supply your current password. If you still have unused backup codes
-	lying around, those will be invalidated and only the newly returned ones
-	can be used.`
-
 type TFACmd struct {
 	tfaEnable      *TFAEnableCmd
 	tfaDisable     *TFADisableCmd
 	tfaBackupGet   *TFABackupGetCmd
 	tfaBackupReset *TFABackupResetCmd
+	spec           *commands.Spec
 }
 
 func NewTFACommand(tfaEnable *TFAEnableCmd, tfaDisable *TFADisableCmd, tfaBackupGet *TFABackupGetCmd, tfaBackupReset *TFABackupResetCmd) *TFACmd {
-	return &TFACmd{tfaEnable, tfaDisable, tfaBackupGet, tfaBackupReset}
+	return &TFACmd{
+		tfaEnable:      tfaEnable,
+		tfaDisable:     tfaDisable,
+		tfaBackupGet:   tfaBackupGet,
+		tfaBackupReset: tfaBackupReset,
+		spec: &commands.Spec{
+			Name:    "tfa",
+			Aliases: []string{"mfa", "2fa", "totp"},
+			Summary: "allows you to manage two-factor-authentication on your account",
+			Description: `The tfa command allows you to enable / disable TFA and retrieve or
+reset your TFA.backup codes. Some actions require you to either input a
+valid TFA code or your current password.`,
+			Subcommands: []*commands.Spec{
+				tfaEnable.spec.WithName("enable", "activate"),
+				tfaDisable.spec.WithName("disable", "deactivate"),
+				tfaBackupGet.spec.WithName("backup-get"),
+				tfaBackupReset.spec.WithName("backup-reset"),
+			},
+		},
+	}
+}
+
+// Spec returns the declaration of the tfa command.
+func (cmd *TFACmd) Spec() *commands.Spec {
+	return cmd.spec
 }
 
 func (cmd *TFACmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) == 0 {
-		cmd.PrintHelp(writer)
-	} else {
-		combinedName := cmd.Name() + "-" + parameters[0]
-		if commands.CommandEquals(cmd.tfaEnable, combinedName) {
-			cmd.tfaEnable.Execute(writer, parameters[1:])
-		} else if commands.CommandEquals(cmd.tfaDisable, combinedName) {
-			cmd.tfaDisable.Execute(writer, parameters[1:])
-		} else if commands.CommandEquals(cmd.tfaBackupGet, combinedName) {
-			cmd.tfaBackupGet.Execute(writer, parameters[1:])
-		} else if commands.CommandEquals(cmd.tfaBackupReset, combinedName) {
-			cmd.tfaBackupReset.Execute(writer, parameters[1:])
-		} else {
-			cmd.PrintHelp(writer)
-		}
-	}
+	commands.Dispatch(cmd.spec, writer, parameters)
 }
 
 func (cmd *TFACmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, tfaHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *TFACmd) Name() string {
-	return "tfa"
+	return cmd.spec.Name
 }
 
 func (cmd *TFACmd) Aliases() []string {
-	return []string{"mfa", "2fa", "totp"}
+	return cmd.spec.Aliases
 }
 
 type TFAEnableCmd struct {
 	window  *ui.Window
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
 func NewTFAEnableCommand(window *ui.Window, session *discordgo.Session) *TFAEnableCmd {
-	return &TFAEnableCmd{window, session}
+	cmd := &TFAEnableCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "tfa-enable",
+		Aliases: []string{"mfa-enable", "totp-enable", "2fa-enable", "mfa-activate", "totp-activate", "2fa-activate"},
+		Summary: "enables two-factor-authentication on your account",
+		Description: `This command will open a view that shows a QR-code and instructions
+on how to proceed in order to enable TFA on your discord account.`,
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the tfa-enable command.
+func (cmd *TFAEnableCmd) Spec() *commands.Spec {
+	return cmd.spec
 }
 
 func (cmd *TFAEnableCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *TFAEnableCmd) run(writer io.Writer, invocation *commands.Invocation) {
 	if cmd.session.MFA {
 		fmt.Fprintln(writer, "TFA is already enabled on this account.")
 	} else {
@@ -148,72 +108,125 @@ func (cmd *TFAEnableCmd) Execute(writer io.Writer, parameters []string) {
 }
 
 func (cmd *TFAEnableCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, tfaEnableHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *TFAEnableCmd) Name() string {
-	return "tfa-enable"
+	return cmd.spec.Name
 }
 
 func (cmd *TFAEnableCmd) Aliases() []string {
-	return []string{"mfa-enable", "totp-enable", "2fa-enable", "mfa-activate", "totp-activate", "2fa-activate"}
+	return cmd.spec.Aliases
 }
 
 type TFADisableCmd struct {
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
 func NewTFADisableCommand(session *discordgo.Session) *TFADisableCmd {
-	return &TFADisableCmd{session}
+	cmd := &TFADisableCmd{
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "tfa-disable",
+		Aliases: []string{"mfa-disable", "totp-disable", "2fa-disable", "mfa-deactivate", "totp-deactivate", "2fa-deactivate"},
+		Summary: "disables two-factor-authentication on your account",
+		Description: `This command will disable TFA on your discord account. In order to disable
+TFA, you need to pass a valid TFA code for confirming that you actually own
+the currently registered TFA secret.`,
+		Arguments: []*commands.Argument{{
+			Name:        "code",
+			Description: "A TFA code generated by your authenticator. Spaces are allowed.",
+			Secret:      true,
+		}},
+		Examples: []string{
+			"tfa-disable 123456",
+			`tfa-disable "123 456"`,
+		},
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the tfa-disable command.
+func (cmd *TFADisableCmd) Spec() *commands.Spec {
+	return cmd.spec
 }
 
 func (cmd *TFADisableCmd) Execute(writer io.Writer, parameters []string) {
-	if cmd.session.MFA {
-		if len(parameters) != 1 {
-			fmt.Fprintln(writer, "Usage: tfa-disable <TFA Token>")
-		} else {
-			code, parseError := text.ParseTFACode(parameters[0])
-			if parseError != nil {
-				commands.PrintError(writer, "Error disabling Two-Factor-Authentication", parseError.Error())
-			}
-			disableError := cmd.session.TwoFactorDisable(code)
-			if disableError != nil {
-				commands.PrintError(writer, "Error disabling Two-Factor-Authentication", disableError.Error())
-			} else {
-				config.UpdateCurrentToken(cmd.session.Token)
-				configError := config.PersistConfig()
-				if configError != nil {
-					commands.PrintError(writer, "Error updating access token in configuration. You might have to log in again.", configError.Error())
-				}
-			}
-		}
-	} else {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *TFADisableCmd) run(writer io.Writer, invocation *commands.Invocation) {
+	if !cmd.session.MFA {
 		fmt.Fprintln(writer, "TFA isn't enabled on this account.")
+		return
+	}
+
+	code, parseError := text.ParseTFACode(invocation.Argument("code"))
+	if parseError != nil {
+		commands.PrintError(writer, "Error disabling Two-Factor-Authentication", parseError.Error())
+		return
+	}
+	disableError := cmd.session.TwoFactorDisable(code)
+	if disableError != nil {
+		commands.PrintError(writer, "Error disabling Two-Factor-Authentication", disableError.Error())
+	} else {
+		config.UpdateCurrentToken(cmd.session.Token)
+		configError := config.PersistConfig()
+		if configError != nil {
+			commands.PrintError(writer, "Error updating access token in configuration. You might have to log in again.", configError.Error())
+		}
 	}
 }
 
 func (cmd *TFADisableCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, tfaDisableHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *TFADisableCmd) Name() string {
-	return "tfa-disable"
+	return cmd.spec.Name
 }
 
 func (cmd *TFADisableCmd) Aliases() []string {
-	return []string{"mfa-disable", "totp-disable", "2fa-disable", "mfa-activate", "totp-activate", "2fa-activate"}
+	return cmd.spec.Aliases
 }
 
 type TFABackupGetCmd struct {
 	window  *ui.Window
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
 func NewTFABackupGetCmd(session *discordgo.Session, window *ui.Window) *TFABackupGetCmd {
-	return &TFABackupGetCmd{window, session}
+	cmd := &TFABackupGetCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "tfa-backup-get",
+		Aliases: []string{"mfa-backup-get", "2fa-backup-get", "totp-backup-get"},
+		Summary: "retrieves your TFA backup codes from discord",
+		Description: `This command will retrieve your TFA backup codes from discord. Those can
+be used in order to recover your account in case you've lost your active
+TFA device. In order to retrieve the codes, you need to supply your
+current password.`,
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the tfa-backup-get command.
+func (cmd *TFABackupGetCmd) Spec() *commands.Spec {
+	return cmd.spec
 }
 
 func (cmd *TFABackupGetCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *TFABackupGetCmd) run(writer io.Writer, invocation *commands.Invocation) {
 	if !cmd.session.MFA {
 		fmt.Fprintln(writer, "Two-Factor-Authentication isn't enabled on this account.")
 	} else {
@@ -238,27 +251,53 @@ func (cmd *TFABackupGetCmd) Execute(writer io.Writer, parameters []string) {
 }
 
 func (cmd *TFABackupGetCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, tfaBackupGetHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *TFABackupGetCmd) Name() string {
-	return "tfa-backup-get"
+	return cmd.spec.Name
 }
 
 func (cmd *TFABackupGetCmd) Aliases() []string {
-	return []string{"mfa-backup-get", "2fa-backup-get", "totp-backup-get"}
+	return cmd.spec.Aliases
 }
 
 type TFABackupResetCmd struct {
 	window  *ui.Window
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
 func NewTFABackupResetCmd(session *discordgo.Session, window *ui.Window) *TFABackupResetCmd {
-	return &TFABackupResetCmd{window, session}
+	cmd := &TFABackupResetCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "tfa-backup-reset",
+		Aliases: []string{"mfa-backup-reset", "2fa-backup-reset", "totp-backup-reset"},
+		Summary: "resets and retrieves your TFA backup codes from discord",
+		Description: `This command will reset and retrieve your TFA backup codes from discord.
+Those can be used in order to recover your account in case you've lost
+your active TFA device. In order to retrieve the codes, you need to
+supply your current password. If you still have unused backup codes
+lying around, those will be invalidated and only the newly returned ones
+can be used.`,
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the tfa-backup-reset command.
+func (cmd *TFABackupResetCmd) Spec() *commands.Spec {
+	return cmd.spec
 }
 
 func (cmd *TFABackupResetCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *TFABackupResetCmd) run(writer io.Writer, invocation *commands.Invocation) {
 	if !cmd.session.MFA {
 		fmt.Fprintln(writer, "Two-Factor-Authentication isn't enabled on this account.")
 	} else {
@@ -284,13 +323,13 @@ func (cmd *TFABackupResetCmd) Execute(writer io.Writer, parameters []string) {
 }
 
 func (cmd *TFABackupResetCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, tfaBackupResetHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *TFABackupResetCmd) Name() string {
-	return "tfa-backup-Reset"
+	return cmd.spec.Name
 }
 
 func (cmd *TFABackupResetCmd) Aliases() []string {
-	return []string{"mfa-backup-reset", "2fa-backup-reset", "totp-backup-reset"}
+	return cmd.spec.Aliases
 }
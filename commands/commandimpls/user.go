@@ -14,192 +14,205 @@ import (
 
 	"github.com/Bios-Marcel/discordgo"
 
+	"github.com/Bios-Marcel/cordless/commands"
 	"github.com/Bios-Marcel/cordless/config"
 	"github.com/Bios-Marcel/cordless/ui"
 	"github.com/Bios-Marcel/cordless/ui/tviewutil"
 )
 
-const (
-	userHelpPage = `[::b]NAME
-	user - manipulate and retrieve your user information
-
-[::b]SYNOPSIS
-	[::b]user <command>
-
-[::b]DESCRIPTION
-	This command allows you to manipulate and retrieve your user information.
-
-	This command is split into multiple subcommands. The default subcommand
-	is [::b]user-get[::-] and will be used if no other command was supplied.
-
-[::b]SUBCOMMANDS
-	[::b]user-get (default)
-		prints the current user information
-	[::b]user-set
-		updates the current user information`
-
-	userSetHelpPage = `[::b]NAME
-	user-set - updates your accounts user information
-
-[::b]SYNOPSIS
-	[::b]user-set[::-] [OPTION[]...
-
-[::b]DESCRIPTION
-	This command allows you to set all or single values of your user
-	information. Every value has a specific parameter and you'll always
-	be asked for your password when trying to change any data.
-
-[::b]OPTIONS
-	TODO
-	[::b]-n, --name
-		change your nickname
-	[::b]-e, --email
-		change the e-mail address associated with your account
-	[::b]-a, --avatar
-		change your avatar to a new local file of yours
-	[::b]-np, --new-password
-		changes the password you use to log in to your account
-
-[::b]EXAMPLES
-	[gray]$ user-set -n "My new nickname"
-	[gray]$ user-set -n NewName
-	[gray]$ user-set -n NewName -a /home/pics/avatar.png`
-
-	userGetHelpPage = `[::b]NAME
-	user-get - prints your accounts user information
-
-[::b]SYNOPSIS
-	[::b]user-get[::-] [OPTION[]...
-
-[::b]DESCRIPTION
-	This command prints your accounts user information to the
-	commandline in a human readable format. If no options were
-	supplied, then "-n", "-e" and "-a" are chosen as the default
-	options.
-
-[::b]OPTIONS
-	[::b]-n, --name
-		Prints nickname and discriminator
-	[::b]-e, --email
-		Prints your e-mail address
-	[::b]-a, --avatar
-		Prints the URL of your avatar
-	[::b]-t, --tfa
-		Prints whether you have two-factor authentication enabled
-
-[::b]EXAMPLES
-	[gray]$ user
-	Nick: Example#1234
-	E-Mail: example@provider.com
-	Avatar: https://discordapp.com/XXX/YYY.png
-
-	[gray]$ user -a
-	Avatar: https://discordapp.com/XXX/YYY.png`
-)
-
+// UserCmd combines UserGetCmd and UserSetCmd as subcommands.
 type UserCmd struct {
 	userSetCmd *UserSetCmd
 	userGetCmd *UserGetCmd
+	spec       *commands.Spec
 }
 
+// UserSetCmd updates the information of the currently logged in user.
 type UserSetCmd struct {
 	window  *ui.Window
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
+// UserGetCmd prints the information of the currently logged in user.
 type UserGetCmd struct {
 	window  *ui.Window
 	session *discordgo.Session
+	spec    *commands.Spec
 }
 
+var (
+	userNameFlagNames   = []string{"-n", "--name", "--nick", "-u", "--username"}
+	userEmailFlagNames  = []string{"-e", "--email", "--e-mail", "--mail"}
+	userAvatarFlagNames = []string{"-a", "--avatar", "--profile-picture"}
+)
+
 func NewUserCommand(userSetCmd *UserSetCmd, userGetCmd *UserGetCmd) *UserCmd {
-	return &UserCmd{userSetCmd, userGetCmd}
+	return &UserCmd{
+		userSetCmd: userSetCmd,
+		userGetCmd: userGetCmd,
+		spec: &commands.Spec{
+			Name:    "user",
+			Summary: "manipulate and retrieve your user information",
+			Description: `This command allows you to manipulate and retrieve your user information.
+
+This command is split into multiple subcommands. The default subcommand
+is [::b]get[::-] and will be used if no other command was supplied.`,
+			Subcommands: []*commands.Spec{
+				userGetCmd.spec.WithName("get"),
+				userSetCmd.spec.WithName("set", "update"),
+			},
+			DefaultSubcommand: "get",
+			Examples: []string{
+				"user",
+				"user -a",
+				"user set -n NewName",
+			},
+		},
+	}
 }
 
 func NewUserSetCommand(window *ui.Window, session *discordgo.Session) *UserSetCmd {
-	return &UserSetCmd{window, session}
+	cmd := &UserSetCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "user-set",
+		Aliases: []string{"user-update"},
+		Summary: "updates your accounts user information",
+		Description: `This command allows you to set all or single values of your user
+information. Every value has a specific parameter and you'll always
+be asked for your password when trying to change any data.`,
+		Flags: []*commands.Flag{
+			{
+				Names:       userNameFlagNames,
+				Value:       "name",
+				Description: "change your nickname",
+			}, {
+				Names:       userEmailFlagNames,
+				Value:       "e-mail",
+				Description: "change the e-mail address associated with your account",
+			}, {
+				Names:         userAvatarFlagNames,
+				Value:         "file",
+				OptionalValue: true,
+				Description:   "change your avatar to a new local file of yours,\nleaving out the file removes your avatar",
+				Completer:     commands.FileCompleter(),
+			}, {
+				Names:       []string{"-np", "--new-password"},
+				Description: "changes the password you use to log in to your account",
+			},
+		},
+		Examples: []string{
+			`user-set -n "My new nickname"`,
+			"user-set -n NewName",
+			"user-set -n NewName -a /home/pics/avatar.png",
+		},
+		Run: cmd.run,
+	}
+	return cmd
 }
 
 func NewUserGetCommand(window *ui.Window, session *discordgo.Session) *UserGetCmd {
-	return &UserGetCmd{window, session}
+	cmd := &UserGetCmd{
+		window:  window,
+		session: session,
+	}
+	cmd.spec = &commands.Spec{
+		Name:    "user-get",
+		Summary: "prints your accounts user information",
+		Description: `This command prints your accounts user information to the
+commandline in a human readable format. If no options were
+supplied, then "-n", "-e" and "-a" are chosen as the default
+options. The information is printed in the order of the options.`,
+		Flags: []*commands.Flag{
+			{
+				Names:       userNameFlagNames,
+				Description: "Prints nickname and discriminator",
+			}, {
+				Names:       userEmailFlagNames,
+				Description: "Prints your e-mail address",
+			}, {
+				Names:       userAvatarFlagNames,
+				Description: "Prints the URL of your avatar",
+			}, {
+				Names:       []string{"-m", "--mfa", "--tfa", "--2fa"},
+				Description: "Prints whether you have two-factor authentication enabled",
+			},
+		},
+		Examples: []string{
+			"user-get",
+			"user-get -a -m",
+		},
+		Run: cmd.run,
+	}
+	return cmd
+}
+
+// Spec returns the declaration of the user command.
+func (cmd *UserCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Spec returns the declaration of the user-set command.
+func (cmd *UserSetCmd) Spec() *commands.Spec {
+	return cmd.spec
+}
+
+// Spec returns the declaration of the user-get command.
+func (cmd *UserGetCmd) Spec() *commands.Spec {
+	return cmd.spec
 }
 
 func (cmd *UserCmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) >= 1 {
-		if parameters[0] == "set" || parameters[0] == "update" {
-			cmd.userSetCmd.Execute(writer, parameters[1:])
-		} else if parameters[0] == "get" {
-			cmd.userGetCmd.Execute(writer, parameters[1:])
-		} else {
-			cmd.userGetCmd.Execute(writer, parameters)
-		}
-	} else {
-		cmd.userGetCmd.Execute(writer, parameters)
-	}
+	commands.Dispatch(cmd.spec, writer, parameters)
 }
 
 func (cmd *UserGetCmd) Execute(writer io.Writer, parameters []string) {
-	if len(parameters) == 0 {
-		//Calling get with defaults
-		cmd.Execute(writer, []string{"-n", "-e", "-a"})
-	} else {
-		userInformation := ""
-		for _, param := range parameters {
-			switch param {
-			case "-n", "--name", "--nick", "-u", "--username":
-				userInformation += fmt.Sprintf("Nick: %s#%s\n", cmd.session.State.User.Username, cmd.session.State.User.Discriminator)
-			case "-e", "--email", "--e-mail", "--mail":
-				userInformation += fmt.Sprintf("E-Mail: %s\n", cmd.session.State.User.Email)
-			case "-a", "--avatar", "--profile-picture":
-				// FIXME Potential bug if jpeg is uploaded?
-				userInformation += fmt.Sprintf("Avatar: https://cdn.discordapp.com/avatars/%s/%s.png\n", cmd.session.State.User.ID, cmd.session.State.User.Avatar)
-			case "-m", "--mfa", "--tfa", "--2fa":
-				userInformation += fmt.Sprintf("Two-Factor Authentication : %v\n", cmd.session.State.User.MFAEnabled)
-			default:
-				fmt.Fprintf(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]Invalid parameter '%s'\n", param)
-				cmd.PrintHelp(writer)
-				return
-			}
-		}
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
 
-		fmt.Fprint(writer, userInformation)
+func (cmd *UserGetCmd) run(writer io.Writer, invocation *commands.Invocation) {
+	flags := invocation.FlagsInOrder()
+	if len(flags) == 0 {
+		//Calling get with defaults
+		flags = []string{"-n", "-e", "-a"}
+	}
 
+	user := cmd.session.State.User
+	for _, flag := range flags {
+		switch flag {
+		case "-n":
+			fmt.Fprintf(writer, "Nick: %s#%s\n", user.Username, user.Discriminator)
+		case "-e":
+			fmt.Fprintf(writer, "E-Mail: %s\n", user.Email)
+		case "-a":
+			// FIXME Potential bug if jpeg is uploaded?
+			fmt.Fprintf(writer, "Avatar: https://cdn.discordapp.com/avatars/%s/%s.png\n", user.ID, user.Avatar)
+		case "-m":
+			fmt.Fprintf(writer, "Two-Factor Authentication : %v\n", user.MFAEnabled)
+		}
 	}
 }
 
 func (cmd *UserSetCmd) Execute(writer io.Writer, parameters []string) {
+	commands.Dispatch(cmd.spec, writer, parameters)
+}
+
+func (cmd *UserSetCmd) run(writer io.Writer, invocation *commands.Invocation) {
 	if cmd.session.State.User.Bot {
 		fmt.Fprintln(writer, "[red]This command can't be used by bots due to Discord API restrictions.")
 		return
 	}
 
-	var newName, newEmail string
+	newName, _ := invocation.Flag("-n")
+	newEmail, _ := invocation.Flag("-e")
 	newAvatar := cmd.session.State.User.Avatar
-	var askForNewPassword bool
-	for index, param := range parameters {
-		switch param {
-		case "-n", "--name", "--nick", "-u", "--username":
-			if index != len(parameters)-1 {
-				newName = parameters[index+1]
-			} else {
-				fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]Error, you didn't supply a new name.")
-			}
-		case "-e", "--email", "--e-mail", "--mail":
-			if index != len(parameters)-1 {
-				newEmail = parameters[index+1]
-			} else {
-				fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]Error, you didn't supply a new e-mail address.")
-			}
-		case "-a", "--avatar", "--profile-picture":
-			if index != len(parameters)-1 && !strings.HasPrefix(parameters[index+1], "-") {
-				newAvatar = parameters[index+1]
-			} else {
-				newAvatar = ""
-			}
-		case "--new-password", "-np":
-			askForNewPassword = true
-		}
+	if avatar, set := invocation.Flag("-a"); set {
+		newAvatar = avatar
 	}
+	_, askForNewPassword := invocation.Flag("-np")
 
 	if newName == "" && !askForNewPassword && newEmail == "" && newAvatar == cmd.session.State.User.Avatar {
 		fmt.Fprintln(writer, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]No valid parameters were supplied.")
@@ -288,37 +301,37 @@ func (cmd *UserSetCmd) Execute(writer io.Writer, parameters []string) {
 }
 
 func (cmd *UserCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, userHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *UserSetCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, userSetHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *UserGetCmd) PrintHelp(writer io.Writer) {
-	fmt.Fprintln(writer, userGetHelpPage)
+	commands.PrintSpecHelp(writer, cmd.spec)
 }
 
 func (cmd *UserCmd) Name() string {
-	return "user"
+	return cmd.spec.Name
 }
 
 func (cmd *UserSetCmd) Name() string {
-	return "user-set"
+	return cmd.spec.Name
 }
 
 func (cmd *UserGetCmd) Name() string {
-	return "user-get"
+	return cmd.spec.Name
 }
 
 func (cmd *UserCmd) Aliases() []string {
-	return nil
+	return cmd.spec.Aliases
 }
 
 func (cmd *UserSetCmd) Aliases() []string {
-	return []string{"user-update"}
+	return cmd.spec.Aliases
 }
 
 func (cmd *UserGetCmd) Aliases() []string {
-	return nil
+	return cmd.spec.Aliases
 }
@@ -0,0 +1,386 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+)
+
+// Completer returns all values that start with the given, possibly empty,
+// prefix and are valid for the argument or flag it belongs to.
+type Completer func(prefix string) []string
+
+// Complete returns the candidates for the last of the given parameters,
+// which is the one that is currently being typed. The parameters don't
+// contain the command name itself. Depending on the position, subcommands,
+// flags or values suggested by the Completer of an argument or flag are
+// returned.
+func Complete(spec *Spec, parameters []string) []string {
+	if len(parameters) == 0 {
+		return nil
+	}
+
+	//Unlike Resolve, this doesn't fall back to the default subcommand if
+	//the parameter that is currently being typed might still become the
+	//name of a subcommand.
+	target, completed := spec, parameters[:len(parameters)-1]
+	current := parameters[len(parameters)-1]
+	for len(target.Subcommands) > 0 && len(completed) > 0 {
+		next := target.findSubcommand(completed[0])
+		if next != nil {
+			completed = completed[1:]
+		} else if next = target.findSubcommand(target.DefaultSubcommand); next == nil {
+			return nil
+		}
+		target = next
+	}
+
+	if len(target.Subcommands) == 0 {
+		return target.complete(append(completed, current))
+	}
+
+	var candidates []string
+	for _, subcommand := range target.Subcommands {
+		candidates = append(candidates, filterByPrefix(current, append([]string{subcommand.Name}, subcommand.Aliases...)...)...)
+	}
+	if defaultSubcommand := target.findSubcommand(target.DefaultSubcommand); defaultSubcommand != nil {
+		candidates = append(candidates, Complete(defaultSubcommand, []string{current})...)
+	}
+	return candidates
+}
+
+// complete handles completion for Specs that don't have subcommands.
+func (spec *Spec) complete(parameters []string) []string {
+	current := parameters[len(parameters)-1]
+	var argumentIndex int
+	var valueOf *Flag
+	for _, parameter := range parameters[:len(parameters)-1] {
+		if valueOf != nil {
+			valueOf = nil
+			continue
+		}
+
+		if flag := spec.findFlag(parameter); flag != nil {
+			if flag.Value != "" {
+				valueOf = flag
+			}
+			continue
+		}
+
+		argumentIndex++
+	}
+
+	if valueOf != nil && !(valueOf.OptionalValue && looksLikeFlag(current)) {
+		if valueOf.Completer == nil {
+			return nil
+		}
+		return valueOf.Completer(current)
+	}
+
+	if looksLikeFlag(current) {
+		var candidates []string
+		for _, flag := range spec.Flags {
+			candidates = append(candidates, filterByPrefix(current, flag.Names...)...)
+		}
+		return candidates
+	}
+
+	if len(spec.Arguments) == 0 {
+		return nil
+	}
+
+	var argument *Argument
+	if argumentIndex < len(spec.Arguments) {
+		argument = spec.Arguments[argumentIndex]
+	} else if lastArgument := spec.Arguments[len(spec.Arguments)-1]; lastArgument.Variadic {
+		argument = lastArgument
+	}
+
+	if argument == nil || argument.Completer == nil {
+		return nil
+	}
+	return argument.Completer(current)
+}
+
+// filterByPrefix returns all values that start with the given prefix,
+// ignoring the case.
+func filterByPrefix(prefix string, values ...string) []string {
+	lowerPrefix := strings.ToLower(prefix)
+	var matches []string
+	for _, value := range values {
+		if strings.HasPrefix(strings.ToLower(value), lowerPrefix) {
+			matches = append(matches, value)
+		}
+	}
+	return matches
+}
+
+// sortedMatches sorts the values matching the prefix alphabetically and
+// removes duplicates.
+func sortedMatches(prefix string, values []string) []string {
+	matches := filterByPrefix(prefix, values...)
+	sort.Strings(matches)
+
+	deduplicated := matches[:0]
+	for index, match := range matches {
+		if index == 0 || match != matches[index-1] {
+			deduplicated = append(deduplicated, match)
+		}
+	}
+	return deduplicated
+}
+
+// ValuesCompleter suggests a fixed set of values.
+func ValuesCompleter(values ...string) Completer {
+	return func(prefix string) []string {
+		return filterByPrefix(prefix, values...)
+	}
+}
+
+// UserCompleter suggests all users known to the state in the format
+// "Username#NNNN".
+func UserCompleter(state *discordgo.State) Completer {
+	return func(prefix string) []string {
+		users, stateError := state.Users()
+		if stateError != nil {
+			return nil
+		}
+
+		names := make([]string, 0, len(users))
+		for _, user := range users {
+			names = append(names, user.String())
+		}
+		return sortedMatches(prefix, names)
+	}
+}
+
+// RelationshipCompleter suggests all users that the current user has a
+// relationship of one of the given types with, in the format
+// "Username#NNNN".
+func RelationshipCompleter(state *discordgo.State, relationTypes ...int) Completer {
+	return func(prefix string) []string {
+		state.RLock()
+		defer state.RUnlock()
+
+		var names []string
+		for _, relationship := range state.Relationships {
+			for _, relationType := range relationTypes {
+				if relationship.Type == relationType {
+					names = append(names, relationship.User.String())
+					break
+				}
+			}
+		}
+		return sortedMatches(prefix, names)
+	}
+}
+
+// GroupCompleter suggests the names of all groups the current user is a
+// member of.
+func GroupCompleter(state *discordgo.State) Completer {
+	return func(prefix string) []string {
+		state.RLock()
+		defer state.RUnlock()
+
+		var names []string
+		for _, channel := range state.PrivateChannels {
+			if channel.Type == discordgo.ChannelTypeGroupDM {
+				names = append(names, discordutil.GetPrivateChannelNameUnescaped(channel))
+			}
+		}
+		return sortedMatches(prefix, names)
+	}
+}
+
+// GuildCompleter suggests the names of all guilds the current user is a
+// member of.
+func GuildCompleter(state *discordgo.State) Completer {
+	return func(prefix string) []string {
+		state.RLock()
+		defer state.RUnlock()
+
+		names := make([]string, 0, len(state.Guilds))
+		for _, guild := range state.Guilds {
+			names = append(names, guild.Name)
+		}
+		return sortedMatches(prefix, names)
+	}
+}
+
+// ChannelCompleter suggests the names of all channels of the currently
+// selected guild, excluding categories.
+func ChannelCompleter(state *discordgo.State, clientState ClientState) Completer {
+	return guildChannelCompleter(state, clientState, func(channel *discordgo.Channel) bool {
+		return channel.Type != discordgo.ChannelTypeGuildCategory
+	})
+}
+
+// CategoryCompleter suggests the names of all categories of the currently
+// selected guild.
+func CategoryCompleter(state *discordgo.State, clientState ClientState) Completer {
+	return guildChannelCompleter(state, clientState, func(channel *discordgo.Channel) bool {
+		return channel.Type == discordgo.ChannelTypeGuildCategory
+	})
+}
+
+// ChannelOrCategoryCompleter suggests the names of all channels and
+// categories of the currently selected guild.
+func ChannelOrCategoryCompleter(state *discordgo.State, clientState ClientState) Completer {
+	return guildChannelCompleter(state, clientState, func(channel *discordgo.Channel) bool {
+		return true
+	})
+}
+
+func guildChannelCompleter(state *discordgo.State, clientState ClientState, filter func(channel *discordgo.Channel) bool) Completer {
+	return func(prefix string) []string {
+		guild := clientState.GetSelectedGuild()
+		if guild == nil {
+			return nil
+		}
+
+		state.RLock()
+		defer state.RUnlock()
+
+		names := make([]string, 0, len(guild.Channels))
+		for _, channel := range guild.Channels {
+			if filter(channel) {
+				names = append(names, channel.Name)
+			}
+		}
+		return sortedMatches(prefix, names)
+	}
+}
+
+// MemberCompleter suggests all members of the currently selected guild in
+// the format "Username#NNNN".
+func MemberCompleter(state *discordgo.State, clientState ClientState) Completer {
+	return func(prefix string) []string {
+		guild := clientState.GetSelectedGuild()
+		if guild == nil {
+			return nil
+		}
+
+		state.RLock()
+		defer state.RUnlock()
+
+		names := make([]string, 0, len(guild.Members))
+		for _, member := range guild.Members {
+			names = append(names, member.User.String())
+		}
+		return sortedMatches(prefix, names)
+	}
+}
+
+// RoleCompleter suggests the names of all roles of the currently selected
+// guild, except for the "@everyone" role.
+func RoleCompleter(state *discordgo.State, clientState ClientState) Completer {
+	return func(prefix string) []string {
+		guild := clientState.GetSelectedGuild()
+		if guild == nil {
+			return nil
+		}
+
+		state.RLock()
+		defer state.RUnlock()
+
+		names := make([]string, 0, len(guild.Roles))
+		for _, role := range guild.Roles {
+			if role.ID != guild.ID {
+				names = append(names, role.Name)
+			}
+		}
+		return sortedMatches(prefix, names)
+	}
+}
+
+// AccountCompleter suggests the names of all saved accounts.
+func AccountCompleter() Completer {
+	return func(prefix string) []string {
+		names := make([]string, 0, len(config.Current.Accounts))
+		for _, account := range config.Current.Accounts {
+			names = append(names, account.Name)
+		}
+		return sortedMatches(prefix, names)
+	}
+}
+
+// IgnoredUserCompleter suggests the names of all ignored users.
+func IgnoredUserCompleter() Completer {
+	return func(prefix string) []string {
+		names := make([]string, 0, len(config.Current.IgnoredUsers))
+		for _, ignoredUser := range config.Current.IgnoredUsers {
+			names = append(names, ignoredUser.Name)
+		}
+		return sortedMatches(prefix, names)
+	}
+}
+
+// AliasCompleter suggests the names of all configured command aliases.
+func AliasCompleter() Completer {
+	return func(prefix string) []string {
+		names := make([]string, 0, len(config.Current.CommandAliases))
+		for name := range config.Current.CommandAliases {
+			names = append(names, name)
+		}
+		return sortedMatches(prefix, names)
+	}
+}
+
+// FileCompleter suggests files and directories. The prefix is treated as a
+// path, where the last element is incomplete. Directories are suggested with
+// a trailing separator, so that completion can continue inside of them.
+// Hidden files are only suggested if the incomplete element starts with a
+// dot.
+func FileCompleter() Completer {
+	return func(prefix string) []string {
+		directory, incomplete := filepath.Split(prefix)
+
+		lookupDirectory := directory
+		if strings.HasPrefix(lookupDirectory, "~") {
+			currentUser, userError := user.Current()
+			if userError != nil {
+				return nil
+			}
+			lookupDirectory = filepath.Join(currentUser.HomeDir, strings.TrimPrefix(lookupDirectory, "~"))
+		}
+		if lookupDirectory == "" {
+			lookupDirectory = "."
+		}
+
+		entries, readError := ioutil.ReadDir(lookupDirectory)
+		if readError != nil {
+			return nil
+		}
+
+		var candidates []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, incomplete) ||
+				(strings.HasPrefix(name, ".") && !strings.HasPrefix(incomplete, ".")) {
+				continue
+			}
+
+			candidate := directory + name
+			if entry.IsDir() || (entry.Mode()&os.ModeSymlink != 0 && isDirectory(filepath.Join(lookupDirectory, name))) {
+				candidate += string(filepath.Separator)
+			}
+			candidates = append(candidates, candidate)
+		}
+		sort.Strings(candidates)
+
+		return candidates
+	}
+}
+
+func isDirectory(path string) bool {
+	info, statError := os.Stat(path)
+	return statError == nil && info.IsDir()
+}
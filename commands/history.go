@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/util/files"
+)
+
+// History holds the input that has been entered into the command view. If a
+// file has been supplied, the history is written to it after every change,
+// so that it survives restarts. Only the most recent entries are kept.
+type History struct {
+	file    string
+	limit   int
+	entries []string
+}
+
+// NewHistory creates an empty History that keeps at most limit entries and
+// persists them into the given file. If the file is empty, the History only
+// lives in memory.
+func NewHistory(file string, limit int) *History {
+	return &History{
+		file:  file,
+		limit: limit,
+	}
+}
+
+// Load reads the entries from the file of the History. A file that doesn't
+// exist yet results in an empty History.
+func (history *History) Load() error {
+	if history.file == "" {
+		return nil
+	}
+
+	data, readError := ioutil.ReadFile(history.file)
+	if readError != nil {
+		if os.IsNotExist(readError) {
+			return nil
+		}
+		return readError
+	}
+
+	var entries []string
+	if jsonError := json.Unmarshal(data, &entries); jsonError != nil {
+		return jsonError
+	}
+
+	history.entries = entries
+	history.trim()
+	return nil
+}
+
+// Add appends the entry and persists the History. Empty entries and entries
+// that equal the most recent one are ignored.
+func (history *History) Add(entry string) error {
+	if strings.TrimSpace(entry) == "" ||
+		(len(history.entries) > 0 && history.entries[len(history.entries)-1] == entry) {
+		return nil
+	}
+
+	history.entries = append(history.entries, entry)
+	history.trim()
+	return history.persist()
+}
+
+// Clear removes all entries and persists the History.
+func (history *History) Clear() error {
+	history.entries = nil
+	return history.persist()
+}
+
+// Len returns the amount of entries.
+func (history *History) Len() int {
+	return len(history.entries)
+}
+
+// Get returns the entry at the given index. The oldest entry has the index
+// zero.
+func (history *History) Get(index int) string {
+	return history.entries[index]
+}
+
+// Search looks for the most recent entry that contains the query and is
+// older than the entry at the given index. The index of the found entry is
+// returned, or -1 if there is no such entry.
+func (history *History) Search(query string, before int) int {
+	if before > len(history.entries) {
+		before = len(history.entries)
+	}
+
+	for index := before - 1; index >= 0; index-- {
+		if strings.Contains(history.entries[index], query) {
+			return index
+		}
+	}
+
+	return -1
+}
+
+func (history *History) trim() {
+	if history.limit < 0 {
+		history.limit = 0
+	}
+	if len(history.entries) > history.limit {
+		history.entries = history.entries[len(history.entries)-history.limit:]
+	}
+}
+
+func (history *History) persist() error {
+	if history.file == "" {
+		return nil
+	}
+
+	data, jsonError := json.Marshal(history.entries)
+	if jsonError != nil {
+		return jsonError
+	}
+
+	//The history may contain private information, such as channel or user
+	//names, therefore only the owner may read the file. Writing atomically
+	//makes sure that a crash can't cause the whole history to be lost.
+	return files.WriteFileAtomically(history.file, data, 0600)
+}
+
+// ContainsSecret checks whether the input invokes one of the given commands
+// with an argument or flag that has been declared as Secret. The configured
+// aliases are expanded beforehand. Such input mustn't be added to the
+// History.
+func ContainsSecret(registeredCommands []Command, input string) bool {
+	expanded, expandError := ExpandAliases(config.Current.CommandAliases, input)
+	//Invalid input can't be checked reliably, therefore it's treated as if
+	//it contained secrets.
+	if expandError != nil {
+		return true
+	}
+
+	for _, command := range expanded {
+		pipeline, parseError := ParsePipeline(command)
+		if parseError != nil {
+			return true
+		}
+
+		for _, pipelineCommand := range pipeline.Commands {
+			//Messages are sent to discord anyway.
+			if IsMessageCommand(pipelineCommand) {
+				continue
+			}
+
+			parts, parseError := ParseCommand(pipelineCommand)
+			if parseError != nil || commandContainsSecret(registeredCommands, parts) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func commandContainsSecret(registeredCommands []Command, parts []string) bool {
+	if len(parts) == 0 {
+		return false
+	}
+
+	for _, command := range registeredCommands {
+		if CommandEquals(command, parts[0]) {
+			specCommand, ok := command.(SpecCommand)
+			return ok && specCommand.Spec().containsSecret(parts[1:])
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func historyEntries(history *History) []string {
+	entries := make([]string, 0, history.Len())
+	for index := 0; index < history.Len(); index++ {
+		entries = append(entries, history.Get(index))
+	}
+	return entries
+}
+
+func TestHistoryAdd(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		add   []string
+		want  []string
+	}{
+		{
+			name:  "entries in order",
+			limit: 10,
+			add:   []string{"status", "friends list"},
+			want:  []string{"status", "friends list"},
+		}, {
+			name:  "empty entries are ignored",
+			limit: 10,
+			add:   []string{"status", "", "   "},
+			want:  []string{"status"},
+		}, {
+			name:  "consecutive duplicates are ignored",
+			limit: 10,
+			add:   []string{"status", "status", "friends list", "status"},
+			want:  []string{"status", "friends list", "status"},
+		}, {
+			name:  "oldest entries are dropped",
+			limit: 2,
+			add:   []string{"a", "b", "c"},
+			want:  []string{"b", "c"},
+		}, {
+			name:  "disabled",
+			limit: 0,
+			add:   []string{"a", "b"},
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := NewHistory("", tt.limit)
+			for _, entry := range tt.add {
+				if addError := history.Add(entry); addError != nil {
+					t.Fatalf("Add() error = %v", addError)
+				}
+			}
+			if got := historyEntries(history); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("History entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistorySearch(t *testing.T) {
+	history := NewHistory("", 10)
+	for _, entry := range []string{"status set online", "friends list", "status get", "user-get"} {
+		history.Add(entry)
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		before int
+		want   int
+	}{
+		{name: "most recent match", query: "status", before: history.Len(), want: 2},
+		{name: "older match", query: "status", before: 2, want: 0},
+		{name: "no older match", query: "status", before: 0, want: -1},
+		{name: "no match at all", query: "server", before: history.Len(), want: -1},
+		{name: "index out of range", query: "user", before: 100, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := history.Search(tt.query, tt.before); got != tt.want {
+				t.Errorf("Search() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryPersistence(t *testing.T) {
+	directory, tempDirError := ioutil.TempDir("", "cordless-history")
+	if tempDirError != nil {
+		t.Fatalf("error creating temporary directory: %s", tempDirError)
+	}
+	defer os.RemoveAll(directory)
+
+	file := filepath.Join(directory, "command-history.json")
+	history := NewHistory(file, 2)
+	if loadError := history.Load(); loadError != nil {
+		t.Fatalf("Load() of non existent file error = %v", loadError)
+	}
+	for _, entry := range []string{"a", "b", "c"} {
+		if addError := history.Add(entry); addError != nil {
+			t.Fatalf("Add() error = %v", addError)
+		}
+	}
+
+	loaded := NewHistory(file, 1)
+	if loadError := loaded.Load(); loadError != nil {
+		t.Fatalf("Load() error = %v", loadError)
+	}
+	if got, want := historyEntries(loaded), []string{"c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded History entries = %v, want %v", got, want)
+	}
+
+	if clearError := history.Clear(); clearError != nil {
+		t.Fatalf("Clear() error = %v", clearError)
+	}
+	if loadError := loaded.Load(); loadError != nil {
+		t.Fatalf("Load() error = %v", loadError)
+	}
+	if loaded.Len() != 0 {
+		t.Errorf("loaded History has %d entries after Clear(), want none", loaded.Len())
+	}
+}
+
+type secretTestCommand struct {
+	spec *Spec
+}
+
+func (cmd *secretTestCommand) Execute(writer io.Writer, parameters []string) {}
+func (cmd *secretTestCommand) PrintHelp(writer io.Writer)                    {}
+func (cmd *secretTestCommand) Name() string                                  { return cmd.spec.Name }
+func (cmd *secretTestCommand) Aliases() []string                             { return cmd.spec.Aliases }
+func (cmd *secretTestCommand) Spec() *Spec                                   { return cmd.spec }
+
+func TestContainsSecret(t *testing.T) {
+	registeredCommands := []Command{
+		&secretTestCommand{&Spec{
+			Name: "account",
+			Subcommands: []*Spec{
+				{
+					Name: "add",
+					Arguments: []*Argument{
+						{Name: "name"},
+						{Name: "token", Secret: true},
+					},
+				}, {
+					Name:      "switch",
+					Arguments: []*Argument{{Name: "name"}},
+				},
+			},
+		}},
+		&secretTestCommand{&Spec{
+			Name: "login",
+			Flags: []*Flag{
+				{Names: []string{"-u", "--user"}, Value: "user"},
+				{Names: []string{"-p", "--password"}, Value: "password", Secret: true},
+			},
+		}},
+		&secretTestCommand{newTestSpec(new([]string))},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "secret argument", input: "account add main abc.def", want: true},
+		{name: "secret argument left out", input: "account add main", want: true},
+		{name: "subcommand without secrets", input: "account switch main", want: false},
+		{name: "secret flag", input: "login -u Marcel --password hunter2", want: true},
+		{name: "secret flag not set", input: "login -u Marcel", want: false},
+		{name: "secret inside of a pipeline", input: "test get | login -p hunter2", want: true},
+		{name: "command without secrets", input: "test get Marcel", want: false},
+		{name: "unknown command", input: "unknown abc", want: false},
+		{name: "empty input", input: "", want: false},
+		{name: "invalid input", input: `login -p "hunter2`, want: true},
+		{name: "message with a quote", input: `send "hi`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContainsSecret(registeredCommands, tt.input); got != tt.want {
+				t.Errorf("ContainsSecret(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// InputCommand is an optional extension of Command for commands that can
+// process the output of the previous command in a pipeline, for example
+// "friends list | send".
+type InputCommand interface {
+	Command
+
+	// ExecuteWithInput runs the command like Execute, but additionally
+	// receives the output of the previous command with all color tags
+	// stripped.
+	ExecuteWithInput(writer io.Writer, input string, parameters []string)
+}
+
+// Pipeline is a sequence of commands, where the output of each command is
+// passed to the next one. The output of the last command is either shown in
+// the command view or written to a file.
+type Pipeline struct {
+	// Commands contains the input for each command of the pipeline.
+	Commands []string
+	// File is the path that the output of the last command is written to.
+	// If it's empty, the output isn't redirected.
+	File string
+	// Append decides whether the output is appended to the File instead of
+	// replacing its content.
+	Append bool
+}
+
+// messageCommands take chat messages as their parameters. Their input is
+// passed on as it has been typed, so that for example "send 3 > 2" doesn't
+// write into a file and quotes aren't removed from the message.
+var messageCommands = []string{"send", "say"}
+
+// ParsePipeline splits the input at each pipe ("|") that is neither quoted
+// nor escaped. The last command may be followed by "> file" or ">> file" in
+// order to redirect its output into a file. Operators have to be separated
+// from the surrounding text by whitespace, otherwise they are part of a
+// parameter. Everything following a message command, such as send, belongs
+// to that command.
+func ParsePipeline(input string) (*Pipeline, error) {
+	runes := []rune(input)
+	pipeline := &Pipeline{}
+	var start int
+	for _, index := range unquotedIndices(runes, "|>") {
+		operator := string(runes[index])
+		if runes[index] == '>' && index < len(runes)-1 && runes[index+1] == '>' {
+			operator = ">>"
+		}
+		if !isStandaloneOperator(runes, index, len(operator)) {
+			continue
+		}
+
+		command := strings.TrimSpace(string(runes[start:index]))
+		if command == "" {
+			return nil, fmt.Errorf("missing command in front of '%s'", operator)
+		}
+		if IsMessageCommand(command) {
+			break
+		}
+		pipeline.Commands = append(pipeline.Commands, command)
+
+		if operator == "|" {
+			start = index + 1
+			continue
+		}
+
+		target, parseError := ParseCommand(string(runes[index+len(operator):]))
+		if parseError != nil {
+			return nil, parseError
+		}
+		if len(target) != 1 {
+			return nil, fmt.Errorf("'%s' has to be followed by exactly one file", operator)
+		}
+		pipeline.File = target[0]
+		pipeline.Append = operator == ">>"
+		return pipeline, nil
+	}
+
+	command := strings.TrimSpace(string(runes[start:]))
+	if command == "" {
+		if len(pipeline.Commands) > 0 {
+			return nil, errors.New("missing command after '|'")
+		}
+		return pipeline, nil
+	}
+	pipeline.Commands = append(pipeline.Commands, command)
+
+	return pipeline, nil
+}
+
+func isStandaloneOperator(runes []rune, index, length int) bool {
+	return (index == 0 || isParameterSeparator(runes[index-1])) &&
+		(index+length == len(runes) || isParameterSeparator(runes[index+length]))
+}
+
+// IsMessageCommand checks whether the input starts with a message command.
+// Only the name is looked at, as the message itself doesn't have to be valid
+// input for ParseCommand.
+func IsMessageCommand(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return false
+	}
+
+	for _, name := range messageCommands {
+		if fields[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseMessageCommand splits the input of a message command into the name
+// of the command and the parameters. Instead of being parsed, the message is
+// passed on as a single parameter, exactly as it has been typed. It's
+// preceded by "--", so that it's never mistaken for a flag.
+func ParseMessageCommand(command string) []string {
+	command = strings.TrimLeftFunc(command, unicode.IsSpace)
+	nameEnd := strings.IndexFunc(command, unicode.IsSpace)
+	if nameEnd == -1 {
+		return []string{command}
+	}
+
+	message := strings.TrimSpace(command[nameEnd:])
+	if message == "" {
+		return []string{command[:nameEnd]}
+	}
+	return []string{command[:nameEnd], "--", message}
+}
+
+// String turns the Pipeline back into input that ParsePipeline understands.
+func (pipeline *Pipeline) String() string {
+	text := strings.Join(pipeline.Commands, " | ")
+	if pipeline.File != "" {
+		operator := " > "
+		if pipeline.Append {
+			operator = " >> "
+		}
+		text += operator + QuoteParameter(pipeline.File)
+	}
+	return text
+}
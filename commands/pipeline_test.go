@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePipeline(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		want      *Pipeline
+		wantError bool
+	}{
+		{
+			name:  "single command",
+			input: "friends list",
+			want:  &Pipeline{Commands: []string{"friends list"}},
+		}, {
+			name:  "pipe",
+			input: "friends list | send",
+			want:  &Pipeline{Commands: []string{"friends list", "send"}},
+		}, {
+			name:  "redirect",
+			input: "friends list > friends.txt",
+			want:  &Pipeline{Commands: []string{"friends list"}, File: "friends.txt"},
+		}, {
+			name:  "append",
+			input: `friends list >> "my friends.txt"`,
+			want:  &Pipeline{Commands: []string{"friends list"}, File: "my friends.txt", Append: true},
+		}, {
+			name:  "pipe and redirect",
+			input: "friends list | history > history.txt",
+			want:  &Pipeline{Commands: []string{"friends list", "history"}, File: "history.txt"},
+		}, {
+			name:  "operators that aren't separated by whitespace",
+			input: `status set-custom -s a>b a>>b a|b >b a|`,
+			want:  &Pipeline{Commands: []string{`status set-custom -s a>b a>>b a|b >b a|`}},
+		}, {
+			name:  "quotes are kept for send",
+			input: `send "3 > 2 | 1`,
+			want:  &Pipeline{Commands: []string{`send "3 > 2 | 1`}},
+		}, {
+			name:  "operators are kept for send",
+			input: "send 3 > 2 | 1",
+			want:  &Pipeline{Commands: []string{"send 3 > 2 | 1"}},
+		}, {
+			name:  "operators are kept after piping into send",
+			input: "friends list | say my friends > yours",
+			want:  &Pipeline{Commands: []string{"friends list", "say my friends > yours"}},
+		}, {
+			name:  "quoted and escaped operators",
+			input: `status set-custom -s "a | b > c" \| \>`,
+			want:  &Pipeline{Commands: []string{`status set-custom -s "a | b > c" \| \>`}},
+		}, {
+			name:  "single quoted operators",
+			input: `status set-custom -s 'a | b > c\' > status.txt`,
+			want:  &Pipeline{Commands: []string{`status set-custom -s 'a | b > c\'`}, File: "status.txt"},
+		}, {
+			name:      "missing command in front of pipe",
+			input:     "| send",
+			wantError: true,
+		}, {
+			name:      "missing command after pipe",
+			input:     "friends list |",
+			wantError: true,
+		}, {
+			name:      "missing file",
+			input:     "friends list >",
+			wantError: true,
+		}, {
+			name:      "unterminated quote in file",
+			input:     `friends list > "a.txt`,
+			wantError: true,
+		}, {
+			name:      "multiple files",
+			input:     "friends list > a.txt b.txt",
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePipeline(tt.input)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("ParsePipeline() error = %v, wantError %v", err, tt.wantError)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePipeline() = %+v, want %+v", got, tt.want)
+			}
+			if got != nil {
+				if reparsed, _ := ParsePipeline(got.String()); !reflect.DeepEqual(reparsed, got) {
+					t.Errorf("ParsePipeline(String()) = %+v, want %+v", reparsed, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMessageCommand(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "send", want: []string{"send"}},
+		{input: "  send   ", want: []string{"send"}},
+		{input: `send don't "quote" a\b`, want: []string{"send", "--", `don't "quote" a\b`}},
+		{input: "say  two  spaces\nand a newline", want: []string{"say", "--", "two  spaces\nand a newline"}},
+		{input: "send -_- lol", want: []string{"send", "--", "-_- lol"}},
+		{input: `send "unterminated`, want: []string{"send", "--", `"unterminated`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParseMessageCommand(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMessageCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,500 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SpecCommand is an optional extension of Command. Instead of hand-parsing
+// its parameters and writing its own help page, a SpecCommand declares its
+// subcommands, arguments and flags. This declaration is used to validate the
+// input, generate the help pages and complete arguments. The Execute method
+// of such a command is usually just a call to Dispatch.
+type SpecCommand interface {
+	Command
+
+	// Spec returns the declaration of this command. The name of the root
+	// Spec has to match the name of the command.
+	Spec() *Spec
+}
+
+// Spec declares the structure of a command or one of its subcommands.
+// A Spec either has subcommands or arguments and flags, but not both.
+type Spec struct {
+	// Name is the word that has to be typed in order to invoke this command
+	// or subcommand.
+	Name string
+	// Aliases are alternative names for this command or subcommand.
+	Aliases []string
+	// Summary is a short sentence that describes what this command does.
+	Summary string
+	// Description is a longer explanation, which is shown on the help page.
+	// Lines are automatically indented.
+	Description string
+	// Examples are complete invocations of this command.
+	Examples []string
+
+	// Subcommands are the commands that can be invoked by passing their
+	// name as the first parameter.
+	Subcommands []*Spec
+	// DefaultSubcommand is the name of the subcommand that is used if the
+	// first parameter isn't the name of a subcommand. If this is empty, help
+	// is printed instead.
+	DefaultSubcommand string
+
+	// Arguments are the positional parameters of this command.
+	Arguments []*Argument
+	// Flags are the optional named parameters of this command.
+	Flags []*Flag
+
+	// Run executes the command after the input has been validated.
+	Run func(writer io.Writer, invocation *Invocation)
+}
+
+// Argument declares a positional parameter.
+type Argument struct {
+	// Name is shown in the synopsis and in error messages.
+	Name string
+	// Description explains what this argument is used for.
+	Description string
+	// Optional arguments may be left out. Only trailing arguments may be
+	// optional.
+	Optional bool
+	// Variadic arguments consume all remaining parameters. Only the last
+	// argument may be variadic.
+	Variadic bool
+	// Completer suggests values for this argument. It may be nil.
+	Completer Completer
+	// Secret arguments, such as tokens, mustn't be written to the history.
+	Secret bool
+}
+
+// Flag declares a named parameter, that is either a switch or followed by a
+// value.
+type Flag struct {
+	// Names contains all spellings of this flag including the leading
+	// dashes, for example "-n" and "--name".
+	Names []string
+	// Value is the placeholder for the value shown in the help page. Flags
+	// without a value are switches.
+	Value string
+	// OptionalValue allows leaving out the value. This is only the case if
+	// the flag is the last parameter or the next parameter is a flag.
+	OptionalValue bool
+	// Description explains what this flag does.
+	Description string
+	// Completer suggests values for this flag. It may be nil.
+	Completer Completer
+	// Secret flags, such as passwords, mustn't be written to the history.
+	Secret bool
+}
+
+// Invocation holds the validated parameters that have been passed to a
+// command.
+type Invocation struct {
+	spec      *Spec
+	arguments map[*Argument][]string
+	flags     map[*Flag]string
+	// flagOrder keeps the order in which the flags have been passed.
+	flagOrder []*Flag
+	// input is the output of the previous command in a pipeline.
+	input *string
+}
+
+// Input returns the output of the previous command in a pipeline and whether
+// the command is part of a pipeline at all.
+func (invocation *Invocation) Input() (string, bool) {
+	if invocation.input == nil {
+		return "", false
+	}
+	return *invocation.input, true
+}
+
+// Argument returns the value of the argument with the given name. If the
+// argument has been omitted, an empty string is returned.
+func (invocation *Invocation) Argument(name string) string {
+	values := invocation.Arguments(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Arguments returns all values of the variadic argument with the given name.
+func (invocation *Invocation) Arguments(name string) []string {
+	for _, argument := range invocation.spec.Arguments {
+		if argument.Name == name {
+			return invocation.arguments[argument]
+		}
+	}
+	return nil
+}
+
+// Flag returns the value of the flag that can be spelled with the given name
+// and whether the flag has been passed at all.
+func (invocation *Invocation) Flag(name string) (string, bool) {
+	flag := invocation.spec.findFlag(name)
+	if flag == nil {
+		return "", false
+	}
+	value, set := invocation.flags[flag]
+	return value, set
+}
+
+// FlagsInOrder returns the primary names of all passed flags in the order
+// they have been passed in.
+func (invocation *Invocation) FlagsInOrder() []string {
+	names := make([]string, 0, len(invocation.flagOrder))
+	for _, flag := range invocation.flagOrder {
+		names = append(names, flag.Names[0])
+	}
+	return names
+}
+
+// Matches checks whether the given text is the name or an alias of this
+// Spec.
+func (spec *Spec) Matches(text string) bool {
+	if spec.Name == text {
+		return true
+	}
+	for _, alias := range spec.Aliases {
+		if alias == text {
+			return true
+		}
+	}
+	return false
+}
+
+// WithName returns a copy of the Spec that uses the given name and aliases.
+// This allows reusing the Spec of a standalone command, such as
+// "status-set", as the subcommand "set" of another command.
+func (spec *Spec) WithName(name string, aliases ...string) *Spec {
+	renamed := *spec
+	renamed.Name = name
+	renamed.Aliases = aliases
+	return &renamed
+}
+
+func (spec *Spec) findSubcommand(name string) *Spec {
+	for _, subcommand := range spec.Subcommands {
+		if subcommand.Matches(name) {
+			return subcommand
+		}
+	}
+	return nil
+}
+
+func (spec *Spec) findFlag(name string) *Flag {
+	for _, flag := range spec.Flags {
+		for _, flagName := range flag.Names {
+			if flagName == name {
+				return flag
+			}
+		}
+	}
+	return nil
+}
+
+// Resolve follows the given parameters through the subcommands of the Spec
+// and returns the Spec that will handle the remaining parameters. The
+// returned path contains the names of all Specs that have been passed.
+func (spec *Spec) Resolve(parameters []string) (target *Spec, path []string, remaining []string) {
+	target, path, remaining = spec, []string{spec.Name}, parameters
+	for len(target.Subcommands) > 0 {
+		var next *Spec
+		if len(remaining) > 0 {
+			next = target.findSubcommand(remaining[0])
+		}
+
+		if next != nil {
+			remaining = remaining[1:]
+		} else if target.DefaultSubcommand != "" {
+			next = target.findSubcommand(target.DefaultSubcommand)
+		}
+
+		if next == nil {
+			break
+		}
+
+		target = next
+		path = append(path, next.Name)
+	}
+
+	return
+}
+
+// Dispatch validates the parameters according to the Spec and runs the
+// matching subcommand. If the input is invalid, an error and the usage of
+// the command are printed instead.
+func Dispatch(spec *Spec, writer io.Writer, parameters []string) {
+	dispatch(spec, writer, nil, parameters)
+}
+
+// DispatchWithInput works like Dispatch, but additionally passes the output
+// of the previous command in a pipeline to the Invocation.
+func DispatchWithInput(spec *Spec, writer io.Writer, input string, parameters []string) {
+	dispatch(spec, writer, &input, parameters)
+}
+
+func dispatch(spec *Spec, writer io.Writer, input *string, parameters []string) {
+	target, path, remaining := spec.Resolve(parameters)
+	if len(target.Subcommands) > 0 {
+		if len(remaining) > 0 {
+			PrintError(writer, "Invalid input", fmt.Sprintf("'%s' isn't a subcommand of '%s'", remaining[0], strings.Join(path, " ")))
+		}
+		PrintSpecHelp(writer, spec, path[1:]...)
+		return
+	}
+
+	invocation, parseError := target.parse(remaining)
+	if parseError != nil {
+		PrintError(writer, "Invalid input", parseError.Error())
+		fmt.Fprintf(writer, "Usage: %s\n", target.synopsis(strings.Join(path, " ")))
+		return
+	}
+
+	if target.Run == nil {
+		PrintSpecHelp(writer, spec, path[1:]...)
+		return
+	}
+
+	invocation.input = input
+	target.Run(writer, invocation)
+}
+
+// parse validates the given parameters against the arguments and flags of
+// the Spec. Parameters that aren't declared flags and everything following
+// "--" are treated as arguments.
+func (spec *Spec) parse(parameters []string) (*Invocation, error) {
+	invocation := &Invocation{
+		spec:      spec,
+		arguments: make(map[*Argument][]string),
+		flags:     make(map[*Flag]string),
+	}
+
+	var positional []string
+	for index := 0; index < len(parameters); index++ {
+		parameter := parameters[index]
+		if parameter == "--" {
+			positional = append(positional, parameters[index+1:]...)
+			break
+		}
+
+		flag := spec.findFlag(parameter)
+		if flag == nil {
+			positional = append(positional, parameter)
+			continue
+		}
+
+		var value string
+		if flag.Value != "" {
+			if index+1 < len(parameters) && !spec.isFlag(parameters[index+1]) {
+				index++
+				value = parameters[index]
+			} else if !flag.OptionalValue {
+				return nil, fmt.Errorf("flag '%s' requires a value", parameter)
+			}
+		}
+
+		if _, alreadySet := invocation.flags[flag]; !alreadySet {
+			invocation.flagOrder = append(invocation.flagOrder, flag)
+		}
+		invocation.flags[flag] = value
+	}
+
+	for index, argument := range spec.Arguments {
+		if index >= len(positional) {
+			if !argument.Optional {
+				return nil, fmt.Errorf("missing argument <%s>", argument.Name)
+			}
+			break
+		}
+
+		if argument.Variadic {
+			invocation.arguments[argument] = positional[index:]
+			positional = positional[:index]
+			break
+		}
+
+		invocation.arguments[argument] = positional[index : index+1]
+	}
+
+	if len(positional) > len(spec.Arguments) {
+		return nil, fmt.Errorf("too many arguments, unexpected '%s'", positional[len(spec.Arguments)])
+	}
+
+	return invocation, nil
+}
+
+// containsSecret checks whether any of the given parameters is passed for
+// an argument or flag that has been declared as Secret. If the parameters
+// can't be parsed, they are assumed to contain a secret, as long as the Spec
+// declares any secrets at all.
+func (spec *Spec) containsSecret(parameters []string) bool {
+	target, _, remaining := spec.Resolve(parameters)
+
+	var secretArguments []*Argument
+	for _, argument := range target.Arguments {
+		if argument.Secret {
+			secretArguments = append(secretArguments, argument)
+		}
+	}
+	var secretFlags []*Flag
+	for _, flag := range target.Flags {
+		if flag.Secret {
+			secretFlags = append(secretFlags, flag)
+		}
+	}
+	if len(secretArguments) == 0 && len(secretFlags) == 0 {
+		return false
+	}
+
+	invocation, parseError := target.parse(remaining)
+	if parseError != nil {
+		return true
+	}
+
+	for _, argument := range secretArguments {
+		if len(invocation.arguments[argument]) > 0 {
+			return true
+		}
+	}
+	for _, flag := range secretFlags {
+		if _, set := invocation.flags[flag]; set {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isFlag decides whether a parameter is one of the flags declared by the
+// Spec or the "--" terminator. Other parameters starting with a dash, such
+// as negative numbers or "-_-", are arguments.
+func (spec *Spec) isFlag(parameter string) bool {
+	return parameter == "--" || spec.findFlag(parameter) != nil
+}
+
+// looksLikeFlag decides whether a parameter that is still being typed could
+// become a flag. A single dash isn't a flag, as it's commonly used to
+// represent stdin or similar.
+func looksLikeFlag(parameter string) bool {
+	return len(parameter) > 1 && parameter[0] == '-'
+}
+
+// synopsis generates the usage line of the Spec, using the given text as the
+// name.
+func (spec *Spec) synopsis(name string) string {
+	var builder strings.Builder
+	builder.WriteString("[::b]" + name + "[::-]")
+
+	if len(spec.Subcommands) > 0 {
+		if spec.DefaultSubcommand != "" {
+			builder.WriteString(" [subcommand[]")
+		} else {
+			builder.WriteString(" <subcommand>")
+		}
+		return builder.String()
+	}
+
+	if len(spec.Flags) > 0 {
+		builder.WriteString(" [OPTION[]...")
+	}
+
+	for _, argument := range spec.Arguments {
+		placeholder := argument.Name
+		if argument.Variadic {
+			placeholder += "..."
+		}
+		if argument.Optional {
+			builder.WriteString(" [" + placeholder + "[]")
+		} else {
+			builder.WriteString(" <" + placeholder + ">")
+		}
+	}
+
+	return builder.String()
+}
+
+// ErrNoSuchSubcommand means that the help page for a subcommand that doesn't
+// exist has been requested.
+var ErrNoSuchSubcommand = errors.New("no such subcommand")
+
+// PrintSpecHelp generates the help page for the given Spec or one of its
+// subcommands and writes it into the writer. The subcommand is found by
+// following the given names.
+func PrintSpecHelp(writer io.Writer, spec *Spec, subcommands ...string) error {
+	target := spec
+	path := []string{spec.Name}
+	for _, name := range subcommands {
+		target = target.findSubcommand(name)
+		if target == nil {
+			return ErrNoSuchSubcommand
+		}
+		path = append(path, target.Name)
+	}
+
+	name := strings.Join(path, " ")
+	fmt.Fprintf(writer, "[::b]NAME\n\t%s - %s\n", name, target.Summary)
+
+	fmt.Fprintf(writer, "\n[::b]SYNOPSIS\n\t%s\n", target.synopsis(name))
+
+	if len(target.Aliases) > 0 {
+		fmt.Fprintf(writer, "\n[::b]ALIASES\n\t%s\n", strings.Join(target.Aliases, ", "))
+	}
+
+	if target.Description != "" {
+		fmt.Fprintf(writer, "\n[::b]DESCRIPTION\n%s\n", indent(target.Description, "\t"))
+	}
+	if len(target.Subcommands) > 0 {
+		fmt.Fprint(writer, "\n[::b]SUBCOMMANDS\n")
+		for _, subcommand := range target.Subcommands {
+			subcommandName := subcommand.Name
+			if subcommandName == target.DefaultSubcommand {
+				subcommandName += " (default)"
+			}
+			fmt.Fprintf(writer, "\t[::b]%s[::-]\n\t\t%s\n", subcommandName, subcommand.Summary)
+		}
+	}
+
+	if len(target.Arguments) > 0 {
+		fmt.Fprint(writer, "\n[::b]ARGUMENTS\n")
+		for _, argument := range target.Arguments {
+			fmt.Fprintf(writer, "\t[::b]%s[::-]\n%s\n", argument.Name, indent(argument.Description, "\t\t"))
+		}
+	}
+
+	if len(target.Flags) > 0 {
+		fmt.Fprint(writer, "\n[::b]OPTIONS\n")
+		for _, flag := range target.Flags {
+			names := strings.Join(flag.Names, ", ")
+			if flag.Value != "" && flag.OptionalValue {
+				names += " [" + flag.Value + "[]"
+			} else if flag.Value != "" {
+				names += " <" + flag.Value + ">"
+			}
+			fmt.Fprintf(writer, "\t[::b]%s[::-]\n%s\n", names, indent(flag.Description, "\t\t"))
+		}
+	}
+
+	if len(target.Examples) > 0 {
+		fmt.Fprint(writer, "\n[::b]EXAMPLES\n")
+		for _, example := range target.Examples {
+			fmt.Fprintf(writer, "\t[gray]$ %s[white]\n", example)
+		}
+	}
+
+	return nil
+}
+
+func indent(text, prefix string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for index, line := range lines {
+		if line != "" {
+			lines[index] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
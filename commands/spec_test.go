@@ -0,0 +1,321 @@
+package commands
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newTestSpec creates a Spec that records the invocation it was run with.
+func newTestSpec(invocations *[]string) *Spec {
+	record := func(name string) func(io.Writer, *Invocation) {
+		return func(writer io.Writer, invocation *Invocation) {
+			recorded := []string{name}
+			recorded = append(recorded, invocation.FlagsInOrder()...)
+			if value, set := invocation.Flag("--target"); set {
+				recorded = append(recorded, "target="+value)
+			}
+			recorded = append(recorded, invocation.Arguments("user")...)
+			recorded = append(recorded, invocation.Arguments("files")...)
+			*invocations = append(*invocations, strings.Join(recorded, " "))
+		}
+	}
+
+	return &Spec{
+		Name:    "test",
+		Summary: "command for testing",
+		Subcommands: []*Spec{
+			{
+				Name:    "get",
+				Summary: "gets a user",
+				Arguments: []*Argument{{
+					Name:      "user",
+					Optional:  true,
+					Completer: ValuesCompleter("Marcel", "Mario", "Luigi"),
+				}},
+				Flags: []*Flag{
+					{Names: []string{"-v", "--verbose"}},
+					{Names: []string{"-t", "--target"}, Value: "target", Completer: ValuesCompleter("stdout", "file")},
+					{Names: []string{"-a", "--avatar"}, Value: "file", OptionalValue: true},
+				},
+				Run: record("get"),
+			}, {
+				Name:    "upload",
+				Aliases: []string{"send"},
+				Summary: "uploads files",
+				Arguments: []*Argument{
+					{Name: "user"},
+					{Name: "files", Variadic: true, Completer: ValuesCompleter("a.txt", "b.txt")},
+				},
+				Run: record("upload"),
+			},
+		},
+		DefaultSubcommand: "get",
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters []string
+		want       []string
+		wantOutput string
+	}{
+		{
+			name:       "default subcommand without parameters",
+			parameters: nil,
+			want:       []string{"get"},
+		}, {
+			name:       "default subcommand with argument",
+			parameters: []string{"Marcel"},
+			want:       []string{"get Marcel"},
+		}, {
+			name:       "explicit subcommand with flags in order",
+			parameters: []string{"get", "-t", "file", "-v", "Marcel"},
+			want:       []string{"get -t -v target=file Marcel"},
+		}, {
+			name:       "optional flag value left out",
+			parameters: []string{"get", "-a", "-v"},
+			want:       []string{"get -a -v"},
+		}, {
+			name:       "alias and variadic argument",
+			parameters: []string{"send", "Marcel", "a.txt", "b.txt"},
+			want:       []string{"upload Marcel a.txt b.txt"},
+		}, {
+			name:       "flag-like arguments after double dash",
+			parameters: []string{"upload", "--", "-Marcel", "-a.txt"},
+			want:       []string{"upload -Marcel -a.txt"},
+		}, {
+			name:       "undeclared flag is an argument",
+			parameters: []string{"get", "--unknown"},
+			want:       []string{"get --unknown"},
+		}, {
+			name:       "negative number is an argument",
+			parameters: []string{"get", "-v", "-5"},
+			want:       []string{"get -v -5"},
+		}, {
+			name:       "text starting with a dash is an argument",
+			parameters: []string{"send", "-_-", "lol"},
+			want:       []string{"upload -_- lol"},
+		}, {
+			name:       "single dash is an argument",
+			parameters: []string{"send", "-", "list", "item"},
+			want:       []string{"upload - list item"},
+		}, {
+			name:       "flag value starting with a dash",
+			parameters: []string{"get", "-t", "-5"},
+			want:       []string{"get -t target=-5"},
+		}, {
+			name:       "double dash isn't a flag value",
+			parameters: []string{"get", "-t", "--"},
+			wantOutput: "flag '-t' requires a value",
+		}, {
+			name:       "missing flag value",
+			parameters: []string{"get", "--target"},
+			wantOutput: "flag '--target' requires a value",
+		}, {
+			name:       "missing argument",
+			parameters: []string{"upload"},
+			wantOutput: "missing argument <user>",
+		}, {
+			name:       "too many arguments",
+			parameters: []string{"get", "Marcel", "Mario"},
+			wantOutput: "too many arguments, unexpected 'Mario'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var invocations []string
+			output := &bytes.Buffer{}
+			Dispatch(newTestSpec(&invocations), output, tt.parameters)
+
+			if !reflect.DeepEqual(invocations, tt.want) {
+				t.Errorf("Dispatch() invocations = %v, want %v", invocations, tt.want)
+			}
+			if tt.wantOutput != "" && !strings.Contains(output.String(), tt.wantOutput) {
+				t.Errorf("Dispatch() output = %q, want it to contain %q", output.String(), tt.wantOutput)
+			}
+			if tt.wantOutput != "" && !strings.Contains(output.String(), "Usage: [::b]test ") {
+				t.Errorf("Dispatch() output = %q, want it to contain the usage", output.String())
+			}
+		})
+	}
+}
+
+func TestDispatchWithInput(t *testing.T) {
+	var gotInput string
+	var gotPiped bool
+	spec := &Spec{
+		Name: "send",
+		Run: func(writer io.Writer, invocation *Invocation) {
+			gotInput, gotPiped = invocation.Input()
+		},
+	}
+
+	Dispatch(spec, &bytes.Buffer{}, nil)
+	if gotPiped {
+		t.Errorf("Dispatch() piped = true, want false")
+	}
+
+	DispatchWithInput(spec, &bytes.Buffer{}, "", nil)
+	if !gotPiped || gotInput != "" {
+		t.Errorf("DispatchWithInput() input = %q, %v, want %q, true", gotInput, gotPiped, "")
+	}
+
+	DispatchWithInput(spec, &bytes.Buffer{}, "Marcel\n", nil)
+	if !gotPiped || gotInput != "Marcel\n" {
+		t.Errorf("DispatchWithInput() input = %q, %v, want %q, true", gotInput, gotPiped, "Marcel\n")
+	}
+}
+
+func TestPrintSpecHelp(t *testing.T) {
+	spec := newTestSpec(new([]string))
+
+	output := &bytes.Buffer{}
+	if err := PrintSpecHelp(output, spec); err != nil {
+		t.Fatalf("PrintSpecHelp() error = %v", err)
+	}
+	for _, expected := range []string{
+		"\ttest - command for testing\n",
+		"\t[::b]test[::-] [subcommand[]\n",
+		"\t[::b]get (default)[::-]\n\t\tgets a user\n",
+		"\t[::b]upload[::-]\n\t\tuploads files\n",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("PrintSpecHelp() = %q, want it to contain %q", output.String(), expected)
+		}
+	}
+
+	output.Reset()
+	if err := PrintSpecHelp(output, spec, "send"); err != nil {
+		t.Fatalf("PrintSpecHelp() error = %v", err)
+	}
+	for _, expected := range []string{
+		"\ttest upload - uploads files\n",
+		"\t[::b]test upload[::-] <user> <files...>\n",
+		"[::b]ALIASES\n\tsend\n",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("PrintSpecHelp() = %q, want it to contain %q", output.String(), expected)
+		}
+	}
+
+	output.Reset()
+	if err := PrintSpecHelp(output, spec, "get"); err != nil {
+		t.Fatalf("PrintSpecHelp() error = %v", err)
+	}
+	if expected := "\t[::b]-a, --avatar [file[][::-]\n"; !strings.Contains(output.String(), expected) {
+		t.Errorf("PrintSpecHelp() = %q, want it to contain %q", output.String(), expected)
+	}
+
+	if err := PrintSpecHelp(output, spec, "nope"); err != ErrNoSuchSubcommand {
+		t.Errorf("PrintSpecHelp() error = %v, want %v", err, ErrNoSuchSubcommand)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters []string
+		want       []string
+	}{
+		{
+			name:       "nothing typed yet",
+			parameters: nil,
+			want:       nil,
+		}, {
+			name:       "subcommands and arguments of the default subcommand",
+			parameters: []string{"M"},
+			want:       []string{"Marcel", "Mario"},
+		}, {
+			name:       "subcommand aliases",
+			parameters: []string{"s"},
+			want:       []string{"send"},
+		}, {
+			name:       "argument of subcommand",
+			parameters: []string{"get", "L"},
+			want:       []string{"Luigi"},
+		}, {
+			name:       "flag names",
+			parameters: []string{"get", "--"},
+			want:       []string{"--verbose", "--target", "--avatar"},
+		}, {
+			name:       "flag value",
+			parameters: []string{"get", "-t", "f"},
+			want:       []string{"file"},
+		}, {
+			name:       "argument after flag with value",
+			parameters: []string{"get", "-t", "file", "Ma"},
+			want:       []string{"Marcel", "Mario"},
+		}, {
+			name:       "variadic argument",
+			parameters: []string{"upload", "Marcel", "a.txt", ""},
+			want:       []string{"a.txt", "b.txt"},
+		}, {
+			name:       "no more arguments",
+			parameters: []string{"get", "Marcel", ""},
+			want:       nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Complete(newTestSpec(new([]string)), tt.parameters); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Complete() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileCompleter(t *testing.T) {
+	directory, tempDirError := ioutil.TempDir("", "cordless-completion")
+	if tempDirError != nil {
+		t.Fatalf("error creating temporary directory: %s", tempDirError)
+	}
+	defer os.RemoveAll(directory)
+
+	for _, file := range []string{"avatar.png", "avatar.gif", ".hidden"} {
+		if writeError := ioutil.WriteFile(filepath.Join(directory, file), nil, 0600); writeError != nil {
+			t.Fatalf("error creating file: %s", writeError)
+		}
+	}
+	if mkdirError := os.Mkdir(filepath.Join(directory, "avatars"), 0700); mkdirError != nil {
+		t.Fatalf("error creating directory: %s", mkdirError)
+	}
+
+	prefix := directory + string(filepath.Separator)
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{
+			name:   "all visible files",
+			prefix: prefix,
+			want:   []string{prefix + "avatar.gif", prefix + "avatar.png", prefix + "avatars" + string(filepath.Separator)},
+		}, {
+			name:   "partial name",
+			prefix: prefix + "avatar.p",
+			want:   []string{prefix + "avatar.png"},
+		}, {
+			name:   "hidden files",
+			prefix: prefix + ".",
+			want:   []string{prefix + ".hidden"},
+		}, {
+			name:   "non existent directory",
+			prefix: filepath.Join(directory, "nope") + string(filepath.Separator),
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FileCompleter()(tt.prefix); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FileCompleter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -117,6 +117,15 @@ type Config struct {
 	// also sent for the currently selected (loaded) channel.
 	DesktopNotificationsForLoadedChannel bool
 
+	// AutoIdleThreshold defines after how many minutes without any user input
+	// (key stroke) the status is changed to idle. Zero disables this.
+	AutoIdleThreshold int
+
+	// SendTypingNotifications decides whether other users are notified while
+	// you are typing a message. Disabling this doesn't prevent you from seeing
+	// who else is typing.
+	SendTypingNotifications bool
+
 	// ShowPlaceholderForBlockedMessages will cause blocked message to shown
 	// as a placeholder message, replacing user and message with generic text.
 	// The time of the message will still be correct in order to not mess up
@@ -158,6 +167,28 @@ type Config struct {
 	// download files to. If FileOpenSaveFilesPermanently has been set to
 	// true, then all opened files are saved in this folder for example.
 	FileDownloadSaveLocation string
+
+	// CommandHistorySize is the maximum amount of commands that are kept in
+	// the command history. Older commands are dropped. The history is saved
+	// in the config directory, but commands containing secrets, such as
+	// tokens, are never added to it. A size of 0 disables the history.
+	CommandHistorySize int
+
+	// CommandAliases maps alias names to the commands that they are
+	// replaced with. A definition may contain multiple commands separated
+	// by semicolons and the placeholders $1, $2 ... and $@ for the
+	// parameters passed to the alias.
+	CommandAliases map[string]string
+
+	// RecentReactionEmoji contains the emoji that have been used for
+	// reactions most recently, the most recent one first. They are shown
+	// first in the reaction picker. This is managed by cordless itself.
+	RecentReactionEmoji []string
+
+	// IgnoredUsers contains all users whose messages are hidden, without
+	// discord knowing about it. Whether a placeholder is shown instead is
+	// decided by ShowPlaceholderForBlockedMessages.
+	IgnoredUsers []*IgnoredUser
 }
 
 // Account has a name and a token. The name is just for the users recognition.
@@ -179,9 +210,64 @@ func (config *Config) GetAccountToken(account string) string {
 	return ""
 }
 
+// IgnoredUser is a user that has been ignored either everywhere or in a
+// single guild. The name is just for the users recognition.
+type IgnoredUser struct {
+	UserID string
+	Name   string
+	// GuildID is empty if the user is ignored in all guilds and private
+	// chats.
+	GuildID string
+}
+
+// IsUserIgnored checks whether the user has been ignored globally or in the
+// given guild.
+func (config *Config) IsUserIgnored(userID, guildID string) bool {
+	for _, ignoredUser := range config.IgnoredUsers {
+		if ignoredUser.UserID == userID && (ignoredUser.GuildID == "" || ignoredUser.GuildID == guildID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IgnoreUser adds the user to the ignored users for the given guild or
+// globally if the guildID is empty. If the user had already been ignored
+// in exactly this scope, false is returned.
+func (config *Config) IgnoreUser(userID, name, guildID string) bool {
+	for _, ignoredUser := range config.IgnoredUsers {
+		if ignoredUser.UserID == userID && ignoredUser.GuildID == guildID {
+			return false
+		}
+	}
+
+	config.IgnoredUsers = append(config.IgnoredUsers, &IgnoredUser{
+		UserID:  userID,
+		Name:    name,
+		GuildID: guildID,
+	})
+	return true
+}
+
+// UnignoreUser removes the user from the ignored users for the given guild
+// or the global ones if the guildID is empty. If the user hadn't been
+// ignored in exactly this scope, false is returned.
+func (config *Config) UnignoreUser(userID, guildID string) bool {
+	for index, ignoredUser := range config.IgnoredUsers {
+		if ignoredUser.UserID == userID && ignoredUser.GuildID == guildID {
+			config.IgnoredUsers = append(config.IgnoredUsers[:index], config.IgnoredUsers[index+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
 var cachedConfigDir string
 var cachedConfigFile string
 var cachedScriptDir string
+var cachedRCFile string
 
 func createDefaultConfig() *Config {
 	// The values here are the defaults which can / will be overwritten
@@ -205,6 +291,7 @@ func createDefaultConfig() *Config {
 		DesktopNotifications:                   true,
 		DesktopNotificationsUserInactivityThreshold: 10,
 		DesktopNotificationsForLoadedChannel:        true,
+		SendTypingNotifications:                     true,
 		ShowPlaceholderForBlockedMessages:           true,
 		DontShowUpdateNotificationFor:               "",
 		ShowUpdateNotifications:                     true,
@@ -215,6 +302,10 @@ func createDefaultConfig() *Config {
 		FileOpenHandlers:                            make(map[string]string),
 		FileOpenSaveFilesPermanently:                false,
 		FileDownloadSaveLocation:                    "~/Downloads",
+		CommandHistorySize:                          500,
+		CommandAliases:                              make(map[string]string),
+		RecentReactionEmoji:                         make([]string, 0),
+		IgnoredUsers:                                make([]*IgnoredUser, 0),
 	}
 }
 
@@ -284,6 +375,55 @@ func GetScriptDirectory() string {
 	return cachedScriptDir
 }
 
+// GetScriptStorageFile returns the path of the file that scripts persist
+// their data in. It's located next to the script directory.
+func GetScriptStorageFile() string {
+	return filepath.Join(filepath.Dir(GetScriptDirectory()), "script-storage.json")
+}
+
+// GetCommandHistoryFile returns the path of the file that the command
+// history is persisted in.
+func GetCommandHistoryFile() (string, error) {
+	configDir, configError := GetConfigDirectory()
+	if configError != nil {
+		return "", configError
+	}
+
+	return filepath.Join(configDir, "command-history.json"), nil
+}
+
+// GetNotesFile returns the path of the file that the notes on other users
+// are persisted in.
+func GetNotesFile() (string, error) {
+	configDir, configError := GetConfigDirectory()
+	if configError != nil {
+		return "", configError
+	}
+
+	return filepath.Join(configDir, "notes.json"), nil
+}
+
+// SetRCFile sets the path of the command file that is run on startup.
+func SetRCFile(rcFilePath string) {
+	cachedRCFile = rcFilePath
+}
+
+// GetRCFile returns the path of the command file that is run on startup.
+// Unless a different path has been set, it's located in the config
+// directory.
+func GetRCFile() (string, error) {
+	if cachedRCFile != "" {
+		return cachedRCFile, nil
+	}
+
+	configDir, configError := GetConfigDirectory()
+	if configError != nil {
+		return "", configError
+	}
+
+	return filepath.Join(configDir, "cordlessrc"), nil
+}
+
 // SetConfigDirectory sets the directory cache
 func SetConfigDirectory(directoryPath string) error {
 	err := files.EnsureDirectoryExists(directoryPath)
@@ -0,0 +1,63 @@
+package config
+
+import "testing"
+
+func TestIsUserIgnored(t *testing.T) {
+	config := &Config{
+		IgnoredUsers: []*IgnoredUser{
+			{UserID: "1", Name: "global"},
+			{UserID: "2", Name: "guild", GuildID: "10"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		guildID string
+		want    bool
+	}{
+		{"globally ignored in private chat", "1", "", true},
+		{"globally ignored in guild", "1", "10", true},
+		{"ignored in guild", "2", "10", true},
+		{"ignored in other guild", "2", "11", false},
+		{"ignored in guild, but private chat", "2", "", false},
+		{"not ignored", "3", "10", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.IsUserIgnored(tt.userID, tt.guildID); got != tt.want {
+				t.Errorf("IsUserIgnored() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIgnoreAndUnignoreUser(t *testing.T) {
+	config := &Config{}
+
+	if !config.IgnoreUser("1", "user", "10") {
+		t.Error("IgnoreUser() = false, want true for a new entry")
+	}
+	if config.IgnoreUser("1", "user", "10") {
+		t.Error("IgnoreUser() = true, want false for an existing entry")
+	}
+	if !config.IgnoreUser("1", "user", "") {
+		t.Error("IgnoreUser() = false, want true for a different scope")
+	}
+	if len(config.IgnoredUsers) != 2 {
+		t.Fatalf("len(IgnoredUsers) = %d, want 2", len(config.IgnoredUsers))
+	}
+
+	if !config.UnignoreUser("1", "") {
+		t.Error("UnignoreUser() = false, want true for the global entry")
+	}
+	if config.UnignoreUser("1", "") {
+		t.Error("UnignoreUser() = true, want false for a removed entry")
+	}
+	if config.UnignoreUser("1", "11") {
+		t.Error("UnignoreUser() = true, want false for a different scope")
+	}
+	if !config.IsUserIgnored("1", "10") || config.IsUserIgnored("1", "") {
+		t.Error("only the guild entry should have been left")
+	}
+}
@@ -1,9 +1,11 @@
 package discordutil
 
 import (
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/Bios-Marcel/discordgo"
 
@@ -112,12 +114,98 @@ func SortPrivateChannels(channels []*discordgo.Channel) {
 // HasReadMessagesPermission checks if the user has permission to view a
 // specific channel.
 func HasReadMessagesPermission(channelID string, state *discordgo.State) bool {
+	return HasPermission(channelID, state, discordgo.PermissionViewChannel)
+}
+
+// HasPermission checks if the user has the given permission in a specific
+// guild channel. Since private channels don't have any permissions, false is
+// returned for those.
+func HasPermission(channelID string, state *discordgo.State, permission int) bool {
 	userPermissions, err := state.UserChannelPermissions(state.User.ID, channelID)
 	if err != nil {
 		// Unable to access channel permissions.
 		return false
 	}
-	return (userPermissions & discordgo.PermissionViewChannel) > 0
+	return (userPermissions & permission) == permission
+}
+
+// FindGuildChannels returns all channels of the guild, including categories,
+// that have the given ID or name. Names are compared case insensitively and
+// a leading '#' is ignored.
+func FindGuildChannels(guild *discordgo.Guild, nameOrID string) []*discordgo.Channel {
+	name := strings.TrimPrefix(nameOrID, "#")
+	var matches []*discordgo.Channel
+	for _, channel := range guild.Channels {
+		if channel.ID == nameOrID {
+			return []*discordgo.Channel{channel}
+		}
+
+		if strings.EqualFold(channel.Name, name) {
+			matches = append(matches, channel)
+		}
+	}
+
+	return matches
+}
+
+// EditChannel applies the given changes to a channel. Unlike
+// discordgo.Session.ChannelEditComplex, only the given fields are sent.
+// This allows resetting values, for example removing a channel from its
+// category by setting "parent_id" to nil, while leaving the position of the
+// channel untouched.
+func EditChannel(session *discordgo.Session, channelID string, changes map[string]interface{}) (*discordgo.Channel, error) {
+	endpoint := discordgo.EndpointChannel(channelID)
+	body, requestError := session.RequestWithBucketID("PATCH", endpoint, changes, endpoint)
+	if requestError != nil {
+		return nil, requestError
+	}
+
+	var channel *discordgo.Channel
+	if parseError := json.Unmarshal(body, &channel); parseError != nil {
+		return nil, parseError
+	}
+
+	return channel, nil
+}
+
+// ReorderChannel moves the channel to the given index among its siblings,
+// which are the channels of the same kind in the same category. The
+// siblings are returned with their new positions, so they can be passed to
+// discordgo.Session.GuildChannelsReorder. The passed channels aren't
+// modified.
+func ReorderChannel(channels []*discordgo.Channel, channel *discordgo.Channel, index int) []*discordgo.Channel {
+	isVoice := func(c *discordgo.Channel) bool {
+		return c.Type == discordgo.ChannelTypeGuildVoice
+	}
+
+	siblings := make([]*discordgo.Channel, 0, len(channels))
+	for _, sibling := range channels {
+		if sibling.ID != channel.ID && sibling.ParentID == channel.ParentID &&
+			(sibling.Type == discordgo.ChannelTypeGuildCategory) == (channel.Type == discordgo.ChannelTypeGuildCategory) &&
+			isVoice(sibling) == isVoice(channel) {
+			siblings = append(siblings, sibling)
+		}
+	}
+	sort.SliceStable(siblings, func(a, b int) bool {
+		return siblings[a].Position < siblings[b].Position
+	})
+
+	if index < 0 {
+		index = 0
+	} else if index > len(siblings) {
+		index = len(siblings)
+	}
+	siblings = append(siblings[:index], append([]*discordgo.Channel{channel}, siblings[index:]...)...)
+
+	reordered := make([]*discordgo.Channel, 0, len(siblings))
+	for position, sibling := range siblings {
+		reordered = append(reordered, &discordgo.Channel{
+			ID:       sibling.ID,
+			Position: position,
+		})
+	}
+
+	return reordered
 }
 
 // AcknowledgeChannel acknowledges all messages in the given channel. If the
@@ -1,6 +1,7 @@
 package discordutil
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/Bios-Marcel/discordgo"
@@ -113,3 +114,69 @@ func TestGetPrivateChannelName(t *testing.T) {
 		})
 	}
 }
+
+func TestFindGuildChannels(t *testing.T) {
+	general := &discordgo.Channel{ID: "1", Name: "general"}
+	generalVoice := &discordgo.Channel{ID: "2", Name: "General", Type: discordgo.ChannelTypeGuildVoice}
+	offTopic := &discordgo.Channel{ID: "3", Name: "off-topic"}
+	guild := &discordgo.Guild{Channels: []*discordgo.Channel{general, generalVoice, offTopic}}
+
+	tests := []struct {
+		name     string
+		nameOrID string
+		want     []*discordgo.Channel
+	}{
+		{name: "by ID", nameOrID: "3", want: []*discordgo.Channel{offTopic}},
+		{name: "by name with hash", nameOrID: "#off-topic", want: []*discordgo.Channel{offTopic}},
+		{name: "ambiguous name", nameOrID: "GENERAL", want: []*discordgo.Channel{general, generalVoice}},
+		{name: "no match", nameOrID: "random", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindGuildChannels(guild, tt.nameOrID); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindGuildChannels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReorderChannel(t *testing.T) {
+	channels := []*discordgo.Channel{
+		{ID: "a", Position: 0, ParentID: "category"},
+		{ID: "b", Position: 1, ParentID: "category"},
+		{ID: "c", Position: 2, ParentID: "category"},
+		{ID: "voice", Position: 0, ParentID: "category", Type: discordgo.ChannelTypeGuildVoice},
+		{ID: "other", Position: 0},
+		{ID: "category", Position: 0, Type: discordgo.ChannelTypeGuildCategory},
+	}
+
+	tests := []struct {
+		name    string
+		channel *discordgo.Channel
+		index   int
+		want    []string
+	}{
+		{name: "to the top", channel: channels[2], index: 0, want: []string{"c", "a", "b"}},
+		{name: "to the middle", channel: channels[0], index: 1, want: []string{"b", "a", "c"}},
+		{name: "index out of range", channel: channels[0], index: 10, want: []string{"b", "c", "a"}},
+		{name: "category", channel: channels[5], index: 3, want: []string{"category"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reordered := ReorderChannel(channels, tt.channel, tt.index)
+			got := make([]string, 0, len(reordered))
+			for position, channel := range reordered {
+				if channel.Position != position {
+					t.Errorf("channel %s has position %d, want %d", channel.ID, channel.Position, position)
+				}
+				got = append(got, channel.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReorderChannel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if channels[0].Position != 0 || channels[2].Position != 2 {
+		t.Error("ReorderChannel() modified the passed channels")
+	}
+}
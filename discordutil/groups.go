@@ -0,0 +1,213 @@
+package discordutil
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/util/files"
+)
+
+const (
+	// EventChannelRecipientAdd is the type of the event sent when a user has
+	// been added to a group. The library doesn't know about this event.
+	EventChannelRecipientAdd = "CHANNEL_RECIPIENT_ADD"
+	// EventChannelRecipientRemove is the type of the event sent when a user
+	// has left or has been removed from a group. The library doesn't know
+	// about this event.
+	EventChannelRecipientRemove = "CHANNEL_RECIPIENT_REMOVE"
+)
+
+// RecipientEvent is the payload of the EventChannelRecipientAdd and
+// EventChannelRecipientRemove events.
+type RecipientEvent struct {
+	ChannelID string          `json:"channel_id"`
+	User      *discordgo.User `json:"user"`
+}
+
+// ParseRecipientEvent reads the payload of an EventChannelRecipientAdd or
+// EventChannelRecipientRemove event.
+func ParseRecipientEvent(event *discordgo.Event) (*RecipientEvent, error) {
+	var recipientEvent *RecipientEvent
+	if parseError := json.Unmarshal(event.RawData, &recipientEvent); parseError != nil {
+		return nil, parseError
+	}
+
+	if recipientEvent == nil || recipientEvent.User == nil {
+		return nil, errors.New("the event doesn't contain a user")
+	}
+
+	return recipientEvent, nil
+}
+
+// CreateGroupChannel creates a new group with the current user and the given
+// users, which have to be friends of the current user.
+func CreateGroupChannel(session *discordgo.Session, recipientIDs []string) (*discordgo.Channel, error) {
+	data := struct {
+		Recipients []string `json:"recipients"`
+	}{
+		Recipients: recipientIDs,
+	}
+
+	endpoint := discordgo.EndpointUserChannelsV8("@me")
+	body, requestError := session.RequestWithBucketID("POST", endpoint, data, discordgo.EndpointUserChannelsV8(""))
+	if requestError != nil {
+		return nil, requestError
+	}
+
+	var channel *discordgo.Channel
+	if parseError := json.Unmarshal(body, &channel); parseError != nil {
+		return nil, parseError
+	}
+
+	return channel, nil
+}
+
+// AddGroupRecipient adds the given user to a group. Only friends of the
+// current user can be added.
+func AddGroupRecipient(session *discordgo.Session, channelID, userID string) error {
+	endpoint := discordgo.EndpointChannel(channelID) + "/recipients/" + userID
+	_, requestError := session.RequestWithBucketID("PUT", endpoint, nil, discordgo.EndpointChannel(channelID)+"/recipients/")
+	return requestError
+}
+
+// RemoveGroupRecipient removes the given user from a group. This is only
+// allowed for the owner of the group.
+func RemoveGroupRecipient(session *discordgo.Session, channelID, userID string) error {
+	endpoint := discordgo.EndpointChannel(channelID) + "/recipients/" + userID
+	_, requestError := session.RequestWithBucketID("DELETE", endpoint, nil, discordgo.EndpointChannel(channelID)+"/recipients/")
+	return requestError
+}
+
+// EncodeImage turns the given PNG, JPEG or GIF image into a data URI, which
+// is the format discord expects for avatars and icons.
+func EncodeImage(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if contentType != "image/png" && contentType != "image/jpeg" && contentType != "image/gif" {
+		return "", fmt.Errorf("content type '%s' not supported", contentType)
+	}
+
+	return fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// LoadImage reads the image at the given path and encodes it via
+// EncodeImage. A leading ~ is resolved, but environment variables have to
+// be expanded by the caller, see commands.ExpandPath.
+func LoadImage(path string) (string, error) {
+	resolvedPath, resolveError := files.ToAbsolutePath(path)
+	if resolveError != nil {
+		return "", resolveError
+	}
+
+	data, readError := ioutil.ReadFile(resolvedPath)
+	if readError != nil {
+		return "", readError
+	}
+
+	return EncodeImage(data)
+}
+
+// FindGroupChannels returns all groups that have the given ID or name.
+// Names are compared case insensitively.
+func FindGroupChannels(state *discordgo.State, nameOrID string) []*discordgo.Channel {
+	state.RLock()
+	defer state.RUnlock()
+
+	var matches []*discordgo.Channel
+	for _, channel := range state.PrivateChannels {
+		if channel.Type != discordgo.ChannelTypeGroupDM {
+			continue
+		}
+
+		if channel.ID == nameOrID {
+			return []*discordgo.Channel{channel}
+		}
+
+		if strings.EqualFold(GetPrivateChannelNameUnescaped(channel), nameOrID) {
+			matches = append(matches, channel)
+		}
+	}
+
+	return matches
+}
+
+// FindUsers returns all users that have the given ID, username or username
+// including the discriminator.
+func FindUsers(users []*discordgo.User, nameOrID string) []*discordgo.User {
+	var matches []*discordgo.User
+	for _, user := range users {
+		if user.ID == nameOrID {
+			return []*discordgo.User{user}
+		}
+
+		if user.Username == nameOrID || user.String() == nameOrID {
+			matches = append(matches, user)
+		}
+	}
+
+	return matches
+}
+
+// GetFriends returns all users that are friends with the current user.
+func GetFriends(state *discordgo.State) []*discordgo.User {
+	state.RLock()
+	defer state.RUnlock()
+
+	var friends []*discordgo.User
+	for _, relationship := range state.Relationships {
+		if relationship.Type == discordgo.RelationTypeFriend {
+			friends = append(friends, relationship.User)
+		}
+	}
+
+	return friends
+}
+
+// AddRecipient adds the user to the recipients of the group in the state.
+// The updated channel is returned, or nil if the group is unknown.
+func AddRecipient(state *discordgo.State, channelID string, user *discordgo.User) *discordgo.Channel {
+	channel, stateError := state.Channel(channelID)
+	if stateError != nil {
+		return nil
+	}
+
+	state.Lock()
+	defer state.Unlock()
+
+	for index, recipient := range channel.Recipients {
+		if recipient.ID == user.ID {
+			channel.Recipients[index] = user
+			return channel
+		}
+	}
+
+	channel.Recipients = append(channel.Recipients, user)
+	return channel
+}
+
+// RemoveRecipient removes the user from the recipients of the group in the
+// state. The updated channel is returned, or nil if the group is unknown.
+func RemoveRecipient(state *discordgo.State, channelID, userID string) *discordgo.Channel {
+	channel, stateError := state.Channel(channelID)
+	if stateError != nil {
+		return nil
+	}
+
+	state.Lock()
+	defer state.Unlock()
+
+	for index, recipient := range channel.Recipients {
+		if recipient.ID == userID {
+			channel.Recipients = append(channel.Recipients[:index], channel.Recipients[index+1:]...)
+			break
+		}
+	}
+
+	return channel
+}
@@ -0,0 +1,139 @@
+package discordutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestGroupRecipients(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "self"}
+	alice := &discordgo.User{ID: "alice", Username: "alice"}
+	bob := &discordgo.User{ID: "bob", Username: "bob"}
+	group := &discordgo.Channel{ID: "group", Type: discordgo.ChannelTypeGroupDM, Recipients: []*discordgo.User{alice}}
+	if err := state.ChannelAdd(group); err != nil {
+		t.Fatal(err)
+	}
+
+	if channel := AddRecipient(state, "group", bob); channel != group || len(group.Recipients) != 2 {
+		t.Errorf("bob should have been added, but recipients are %v", group.Recipients)
+	}
+	if AddRecipient(state, "group", bob); len(group.Recipients) != 2 {
+		t.Errorf("adding a recipient twice should replace the user, but recipients are %v", group.Recipients)
+	}
+	if channel := RemoveRecipient(state, "group", "alice"); channel != group || len(group.Recipients) != 1 || group.Recipients[0] != bob {
+		t.Errorf("alice should have been removed, but recipients are %v", group.Recipients)
+	}
+	if RemoveRecipient(state, "group", "unknown"); len(group.Recipients) != 1 {
+		t.Errorf("removing an unknown user should do nothing, but recipients are %v", group.Recipients)
+	}
+	if channel := AddRecipient(state, "unknown", alice); channel != nil {
+		t.Errorf("unknown groups should be ignored, but got %v", channel)
+	}
+}
+
+func TestFindGroupChannels(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "self"}
+	alice := &discordgo.User{ID: "alice", Username: "alice"}
+	bob := &discordgo.User{ID: "bob", Username: "bob"}
+	channels := []*discordgo.Channel{
+		{ID: "dm", Type: discordgo.ChannelTypeDM, Recipients: []*discordgo.User{alice}},
+		{ID: "named", Type: discordgo.ChannelTypeGroupDM, Name: "Gophers", Recipients: []*discordgo.User{alice, bob}},
+		{ID: "unnamed", Type: discordgo.ChannelTypeGroupDM, Recipients: []*discordgo.User{alice, bob}},
+		{ID: "other", Type: discordgo.ChannelTypeGroupDM, Name: "gophers", Recipients: []*discordgo.User{bob}},
+	}
+	for _, channel := range channels {
+		if err := state.ChannelAdd(channel); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		nameOrID string
+		want     []string
+	}{
+		{name: "ID", nameOrID: "named", want: []string{"named"}},
+		{name: "recipient names", nameOrID: "alice, bob", want: []string{"unnamed"}},
+		{name: "ambiguous name", nameOrID: "GOPHERS", want: []string{"named", "other"}},
+		{name: "direct messages are ignored", nameOrID: "alice", want: nil},
+		{name: "direct messages are ignored by ID", nameOrID: "dm", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, channel := range FindGroupChannels(state, tt.nameOrID) {
+				got = append(got, channel.ID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FindGroupChannels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindUsers(t *testing.T) {
+	users := []*discordgo.User{
+		{ID: "1", Username: "alice", Discriminator: "0001"},
+		{ID: "2", Username: "alice", Discriminator: "0002"},
+		{ID: "3", Username: "bob", Discriminator: "0003"},
+	}
+
+	tests := []struct {
+		nameOrID string
+		want     int
+	}{
+		{nameOrID: "3", want: 1},
+		{nameOrID: "alice", want: 2},
+		{nameOrID: "alice#0002", want: 1},
+		{nameOrID: "Bob", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nameOrID, func(t *testing.T) {
+			if got := FindUsers(users, tt.nameOrID); len(got) != tt.want {
+				t.Errorf("FindUsers() returned %d users, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeImage(t *testing.T) {
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+	encoded, err := EncodeImage(png)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "data:image/png;base64,") {
+		t.Errorf("EncodeImage() = %v, want a PNG data URI", encoded)
+	}
+
+	if _, err := EncodeImage([]byte("plain text")); err == nil {
+		t.Error("EncodeImage() should reject files that aren't images")
+	}
+}
+
+func TestParseRecipientEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawData string
+		wantErr bool
+	}{
+		{name: "valid", rawData: `{"channel_id":"group","user":{"id":"alice","username":"alice"}}`},
+		{name: "missing user", rawData: `{"channel_id":"group"}`, wantErr: true},
+		{name: "invalid json", rawData: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRecipientEvent(&discordgo.Event{Type: EventChannelRecipientAdd, RawData: []byte(tt.rawData)})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRecipientEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got.ChannelID != "group" || got.User.ID != "alice") {
+				t.Errorf("ParseRecipientEvent() = %v", got)
+			}
+		})
+	}
+}
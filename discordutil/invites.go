@@ -0,0 +1,43 @@
+package discordutil
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// InviteBaseURL is the prefix of all invite links.
+const InviteBaseURL = "https://discord.gg/"
+
+// ParseInviteCode extracts the invite code from an invite link, such as
+// "https://discord.gg/JDScUK". If the input isn't a link, it's treated as
+// the code itself.
+func ParseInviteCode(input string) string {
+	input = strings.TrimSpace(input)
+	if queryStart := strings.IndexAny(input, "?#"); queryStart != -1 {
+		input = input[:queryStart]
+	}
+	input = strings.TrimSuffix(input, "/")
+
+	if lastSlash := strings.LastIndex(input, "/"); lastSlash != -1 {
+		return input[lastSlash+1:]
+	}
+
+	return input
+}
+
+// GetInviteExpiry returns the point in time at which the invite expires. If
+// the invite never expires, false is returned.
+func GetInviteExpiry(invite *discordgo.Invite) (time.Time, bool) {
+	if invite.MaxAge <= 0 {
+		return time.Time{}, false
+	}
+
+	created, parseError := invite.CreatedAt.Parse()
+	if parseError != nil {
+		return time.Time{}, false
+	}
+
+	return created.Add(time.Duration(invite.MaxAge) * time.Second), true
+}
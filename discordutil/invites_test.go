@@ -0,0 +1,63 @@
+package discordutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestParseInviteCode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "JDScUK", want: "JDScUK"},
+		{input: "discord.gg/JDScUK", want: "JDScUK"},
+		{input: "https://discord.gg/JDScUK", want: "JDScUK"},
+		{input: "https://discord.gg/JDScUK/", want: "JDScUK"},
+		{input: "https://discord.com/invite/JDScUK?event=1", want: "JDScUK"},
+		{input: " JDScUK ", want: "JDScUK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ParseInviteCode(tt.input); got != tt.want {
+				t.Errorf("ParseInviteCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetInviteExpiry(t *testing.T) {
+	created := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		invite      *discordgo.Invite
+		want        time.Time
+		wantExpires bool
+	}{
+		{
+			name:   "never expires",
+			invite: &discordgo.Invite{CreatedAt: discordgo.Timestamp(created.Format(time.RFC3339)), MaxAge: 0},
+		}, {
+			name:        "expires after a day",
+			invite:      &discordgo.Invite{CreatedAt: discordgo.Timestamp(created.Format(time.RFC3339)), MaxAge: 86400},
+			want:        created.Add(24 * time.Hour),
+			wantExpires: true,
+		}, {
+			name:   "unknown creation time",
+			invite: &discordgo.Invite{MaxAge: 86400},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, expires := GetInviteExpiry(tt.invite)
+			if expires != tt.wantExpires {
+				t.Fatalf("GetInviteExpiry() expires = %v, want %v", expires, tt.wantExpires)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("GetInviteExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
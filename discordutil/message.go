@@ -216,6 +216,17 @@ func MessageToPlainText(message *discordgo.Message) string {
 	return builder.String()
 }
 
+// GetMessageLink creates a link to the given message, which can be opened
+// in the browser or in any discord client. Messages in private channels
+// don't have a guild, which is indicated by an empty guildID.
+func GetMessageLink(guildID, channelID, messageID string) string {
+	if guildID == "" {
+		guildID = "@me"
+	}
+
+	return fmt.Sprintf("https://discordapp.com/channels/%s/%s/%s", guildID, channelID, messageID)
+}
+
 // ResolveFilePathAndSendFile will attempt to resolve the message and see if
 // it points to a file on the users harddrive. If so, it's sent to the given
 // channel using it's basename as the discord filename.
@@ -256,6 +267,9 @@ func HandleReactionAdd(state *discordgo.State,
 		if reaction.Emoji.ID == newReaction.Emoji.ID && reaction.Emoji.Name == newReaction.Emoji.Name {
 			//Match found, so we can add one to the count.
 			reaction.Count++
+			if newReaction.UserID == state.User.ID {
+				reaction.Me = true
+			}
 			return
 		}
 	}
@@ -282,6 +296,9 @@ func HandleReactionRemove(state *discordgo.State,
 			} else {
 				//Only a single user removed his reaction, so we keep the array entry.
 				reaction.Count--
+				if newReaction.UserID == state.User.ID {
+					reaction.Me = false
+				}
 			}
 			return
 		}
@@ -372,3 +372,62 @@ func TestGenerateQuote(t *testing.T) {
 		})
 	}
 }
+
+func TestGetMessageLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		guildID   string
+		channelID string
+		messageID string
+		want      string
+	}{
+		{
+			name:      "guild message",
+			guildID:   "1",
+			channelID: "2",
+			messageID: "3",
+			want:      "https://discordapp.com/channels/1/2/3",
+		}, {
+			name:      "private message",
+			channelID: "2",
+			messageID: "3",
+			want:      "https://discordapp.com/channels/@me/2/3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetMessageLink(tt.guildID, tt.channelID, tt.messageID); got != tt.want {
+				t.Errorf("GetMessageLink() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleReactions(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "me"}
+	message := &discordgo.Message{}
+	reaction := func(userID string) *discordgo.MessageReaction {
+		return &discordgo.MessageReaction{UserID: userID, Emoji: discordgo.Emoji{Name: "👍"}}
+	}
+
+	HandleReactionAdd(state, message, &discordgo.MessageReactionAdd{MessageReaction: reaction("other")})
+	if len(message.Reactions) != 1 || message.Reactions[0].Count != 1 || message.Reactions[0].Me {
+		t.Fatalf("unexpected reactions after reaction of other user: %+v", message.Reactions)
+	}
+
+	HandleReactionAdd(state, message, &discordgo.MessageReactionAdd{MessageReaction: reaction("me")})
+	if message.Reactions[0].Count != 2 || !message.Reactions[0].Me {
+		t.Errorf("unexpected reaction after own reaction: %+v", message.Reactions[0])
+	}
+
+	HandleReactionRemove(state, message, &discordgo.MessageReactionRemove{MessageReaction: reaction("me")})
+	if message.Reactions[0].Count != 1 || message.Reactions[0].Me {
+		t.Errorf("unexpected reaction after removing own reaction: %+v", message.Reactions[0])
+	}
+
+	HandleReactionRemove(state, message, &discordgo.MessageReactionRemove{MessageReaction: reaction("other")})
+	if len(message.Reactions) != 0 {
+		t.Errorf("reactions should be empty, but were: %+v", message.Reactions)
+	}
+}
@@ -0,0 +1,77 @@
+package discordutil
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+const (
+	// BulkDeleteMaxAge is the maximum age of messages that can be deleted
+	// in bulk. Older messages have to be deleted one by one.
+	BulkDeleteMaxAge = 14 * 24 * time.Hour
+	// BulkDeleteLimit is the maximum amount of messages that can be deleted
+	// using a single bulk delete request.
+	BulkDeleteLimit = 100
+	// MaxTimeout is the maximum duration a member can be timed out for.
+	MaxTimeout = 28 * 24 * time.Hour
+)
+
+// TimeoutMember prevents a member from interacting with the guild until the
+// given point in time. Passing nil removes an existing timeout. Since the
+// library doesn't know about timeouts, the request is done manually.
+func TimeoutMember(session *discordgo.Session, guildID, userID string, until *time.Time) error {
+	var communicationDisabledUntil interface{}
+	if until != nil {
+		communicationDisabledUntil = until.UTC().Format(time.RFC3339)
+	}
+
+	endpoint := discordgo.EndpointGuildMember(guildID, userID)
+	_, requestError := session.RequestWithBucketID("PATCH", endpoint, map[string]interface{}{
+		"communication_disabled_until": communicationDisabledUntil,
+	}, discordgo.EndpointGuildMember(guildID, ""))
+	return requestError
+}
+
+// SelectMessagesForPurge picks the IDs of the newest messages that are to be
+// deleted. If an authorID is given, only messages by that author are picked.
+// Messages that are too old to be deleted in bulk aren't picked, but
+// counted instead.
+func SelectMessagesForPurge(messages []*discordgo.Message, authorID string, limit int, now time.Time) (messageIDs []string, tooOld int) {
+	candidates := make([]*discordgo.Message, 0, len(messages))
+	creationTimes := make(map[string]time.Time, len(messages))
+	for _, message := range messages {
+		if authorID != "" && (message.Author == nil || message.Author.ID != authorID) {
+			continue
+		}
+
+		created, parseError := discordgo.SnowflakeTimestamp(message.ID)
+		if parseError != nil {
+			continue
+		}
+
+		creationTimes[message.ID] = created
+		candidates = append(candidates, message)
+	}
+
+	//Newest first, since those are the ones to be purged.
+	sort.Slice(candidates, func(a, b int) bool {
+		return creationTimes[candidates[a].ID].After(creationTimes[candidates[b].ID])
+	})
+
+	for _, message := range candidates {
+		if len(messageIDs) >= limit {
+			break
+		}
+
+		if now.Sub(creationTimes[message.ID]) >= BulkDeleteMaxAge {
+			tooOld++
+			continue
+		}
+
+		messageIDs = append(messageIDs, message.ID)
+	}
+
+	return messageIDs, tooOld
+}
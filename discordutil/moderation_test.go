@@ -0,0 +1,69 @@
+package discordutil
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// snowflakeAt creates a message ID that was created at the given time.
+func snowflakeAt(created time.Time) string {
+	milliseconds := created.UnixNano()/int64(time.Millisecond) - 1420070400000
+	return strconv.FormatInt(milliseconds<<22, 10)
+}
+
+func TestSelectMessagesForPurge(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	alice := &discordgo.User{ID: "alice"}
+	bob := &discordgo.User{ID: "bob"}
+
+	newest := &discordgo.Message{ID: snowflakeAt(now.Add(-time.Minute)), Author: alice}
+	newer := &discordgo.Message{ID: snowflakeAt(now.Add(-time.Hour)), Author: bob}
+	older := &discordgo.Message{ID: snowflakeAt(now.Add(-24 * time.Hour)), Author: alice}
+	tooOld := &discordgo.Message{ID: snowflakeAt(now.Add(-15 * 24 * time.Hour)), Author: alice}
+	//Unordered on purpose, the messages have to be sorted by age.
+	messages := []*discordgo.Message{older, tooOld, newest, newer}
+
+	tests := []struct {
+		name       string
+		authorID   string
+		limit      int
+		want       []string
+		wantTooOld int
+	}{
+		{
+			name:  "newest messages",
+			limit: 2,
+			want:  []string{newest.ID, newer.ID},
+		}, {
+			name:       "all messages",
+			limit:      10,
+			want:       []string{newest.ID, newer.ID, older.ID},
+			wantTooOld: 1,
+		}, {
+			name:     "by author",
+			authorID: "alice",
+			limit:    2,
+			want:     []string{newest.ID, older.ID},
+		}, {
+			name:     "no matching author",
+			authorID: "carol",
+			limit:    10,
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotTooOld := SelectMessagesForPurge(messages, tt.authorID, tt.limit, now)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SelectMessagesForPurge() = %v, want %v", got, tt.want)
+			}
+			if gotTooOld != tt.wantTooOld {
+				t.Errorf("SelectMessagesForPurge() tooOld = %v, want %v", gotTooOld, tt.wantTooOld)
+			}
+		})
+	}
+}
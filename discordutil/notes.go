@@ -0,0 +1,139 @@
+package discordutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Bios-Marcel/cordless/util/files"
+)
+
+// MaxNoteLength is the maximum amount of characters a note may consist of.
+const MaxNoteLength = 256
+
+// ErrNoteTooLong means that a note exceeded MaxNoteLength.
+var ErrNoteTooLong = fmt.Errorf("notes can't be longer than %d characters", MaxNoteLength)
+
+// Notes holds the private notes on other users. Discord only supports notes
+// for user accounts, therefore all notes are also kept in a local JSON file,
+// so that they are available for bot accounts and while being offline. Every
+// change is written to disk immediately.
+type Notes struct {
+	lock  sync.Mutex
+	path  string
+	notes map[string]string
+}
+
+// LoadNotes reads the notes from the given file. If the file doesn't exist
+// yet, there are no notes and the file is created on the first change. If
+// the path is empty, the notes are only kept in memory.
+func LoadNotes(path string) (*Notes, error) {
+	notes := &Notes{
+		path:  path,
+		notes: make(map[string]string),
+	}
+	if path == "" {
+		return notes, nil
+	}
+
+	data, readError := ioutil.ReadFile(path)
+	if os.IsNotExist(readError) {
+		return notes, nil
+	}
+	if readError != nil {
+		return notes, readError
+	}
+
+	if len(data) > 0 {
+		if parseError := json.Unmarshal(data, &notes.notes); parseError != nil {
+			return notes, parseError
+		}
+	}
+
+	return notes, nil
+}
+
+func (notes *Notes) persist() error {
+	if notes.path == "" {
+		return nil
+	}
+
+	data, jsonError := json.MarshalIndent(notes.notes, "", "    ")
+	if jsonError != nil {
+		return jsonError
+	}
+
+	return files.WriteFileAtomically(notes.path, data, 0600)
+}
+
+// Get returns the note on the given user or an empty string if there's
+// none.
+func (notes *Notes) Get(userID string) string {
+	notes.lock.Lock()
+	defer notes.lock.Unlock()
+
+	return notes.notes[userID]
+}
+
+// Set changes the note on the given user and writes the notes to disk. An
+// empty note removes the note.
+func (notes *Notes) Set(userID, note string) error {
+	if utf8.RuneCountInString(note) > MaxNoteLength {
+		return ErrNoteTooLong
+	}
+
+	notes.lock.Lock()
+	defer notes.lock.Unlock()
+
+	if notes.notes[userID] == note {
+		return nil
+	}
+
+	if note == "" {
+		delete(notes.notes, userID)
+	} else {
+		notes.notes[userID] = note
+	}
+
+	return notes.persist()
+}
+
+// Merge adds the notes that discord sent on connecting. Since those are
+// the most recent ones, they replace local notes on the same users. Local
+// notes on other users are kept.
+func (notes *Notes) Merge(remoteNotes map[string]string) error {
+	notes.lock.Lock()
+	defer notes.lock.Unlock()
+
+	var changed bool
+	for userID, note := range remoteNotes {
+		if note != "" && notes.notes[userID] != note {
+			notes.notes[userID] = note
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	return notes.persist()
+}
+
+// UserIDs returns the IDs of all users that have a note in ascending order.
+func (notes *Notes) UserIDs() []string {
+	notes.lock.Lock()
+	defer notes.lock.Unlock()
+
+	userIDs := make([]string, 0, len(notes.notes))
+	for userID := range notes.notes {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+
+	return userIDs
+}
@@ -0,0 +1,79 @@
+package discordutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNotes(t *testing.T) {
+	directory, tempDirError := ioutil.TempDir("", "cordless-notes-test")
+	if tempDirError != nil {
+		t.Fatal(tempDirError)
+	}
+	defer os.RemoveAll(directory)
+	path := filepath.Join(directory, "notes.json")
+
+	notes, loadError := LoadNotes(path)
+	if loadError != nil {
+		t.Fatalf("LoadNotes() error = %v", loadError)
+	}
+	if setError := notes.Set("1", "local"); setError != nil {
+		t.Fatalf("Set() error = %v", setError)
+	}
+	if setError := notes.Set("2", "outdated"); setError != nil {
+		t.Fatalf("Set() error = %v", setError)
+	}
+	if mergeError := notes.Merge(map[string]string{"2": "remote", "3": "new", "4": ""}); mergeError != nil {
+		t.Fatalf("Merge() error = %v", mergeError)
+	}
+
+	notes, loadError = LoadNotes(path)
+	if loadError != nil {
+		t.Fatalf("LoadNotes() error = %v", loadError)
+	}
+	tests := []struct {
+		userID string
+		want   string
+	}{
+		{"1", "local"},
+		{"2", "remote"},
+		{"3", "new"},
+		{"4", ""},
+	}
+	for _, tt := range tests {
+		if got := notes.Get(tt.userID); got != tt.want {
+			t.Errorf("Get(%s) after reload = %q, want %q", tt.userID, got, tt.want)
+		}
+	}
+
+	if setError := notes.Set("1", ""); setError != nil {
+		t.Fatalf("Set() error = %v", setError)
+	}
+	if userIDs := notes.UserIDs(); !reflect.DeepEqual(userIDs, []string{"2", "3"}) {
+		t.Errorf("UserIDs() = %v, want [2 3]", userIDs)
+	}
+
+	if setError := notes.Set("2", strings.Repeat("ä", MaxNoteLength+1)); setError != ErrNoteTooLong {
+		t.Errorf("Set() error = %v, want %v", setError, ErrNoteTooLong)
+	}
+	if got := notes.Get("2"); got != "remote" {
+		t.Errorf("Get() after failed Set() = %q, want %q", got, "remote")
+	}
+}
+
+func TestNotesInMemory(t *testing.T) {
+	notes, loadError := LoadNotes("")
+	if loadError != nil {
+		t.Fatalf("LoadNotes() error = %v", loadError)
+	}
+	if setError := notes.Set("1", "note"); setError != nil {
+		t.Fatalf("Set() error = %v", setError)
+	}
+	if got := notes.Get("1"); got != "note" {
+		t.Errorf("Get() = %q, want %q", got, "note")
+	}
+}
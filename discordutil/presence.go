@@ -0,0 +1,172 @@
+package discordutil
+
+import (
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// GetStatusColor returns the color that is used to indicate the given status.
+func GetStatusColor(status discordgo.Status) string {
+	switch status {
+	case discordgo.StatusOnline:
+		return "green"
+	case discordgo.StatusDoNotDisturb:
+		return "red"
+	case discordgo.StatusIdle:
+		return "yellow"
+	case discordgo.StatusInvisible, discordgo.StatusOffline:
+		return "gray"
+	default:
+		return "gray"
+	}
+}
+
+// GetStatusSymbol returns a colored glyph that indicates the given status.
+// The foreground color is reset after the glyph.
+func GetStatusSymbol(status discordgo.Status) string {
+	if IsOffline(status) {
+		return "[" + GetStatusColor(status) + "]○[-]"
+	}
+
+	return "[" + GetStatusColor(status) + "]●[-]"
+}
+
+// IsOffline checks whether a user with the given status is shown as offline
+// to others. Users that are invisible or whose status is unknown count as
+// offline.
+func IsOffline(status discordgo.Status) bool {
+	return status != discordgo.StatusOnline &&
+		status != discordgo.StatusIdle &&
+		status != discordgo.StatusDoNotDisturb
+}
+
+// GetPresence returns the presence of the given user. Guild member presences
+// are looked up in the given guild, while presences of friends are looked up
+// in the private state by passing an empty guildID. If no presence is known,
+// nil is returned.
+func GetPresence(state *discordgo.State, guildID, userID string) *discordgo.Presence {
+	if state.User != nil && state.User.ID == userID && state.Settings != nil {
+		return getOwnPresence(state)
+	}
+
+	if guildID != "" {
+		presence, stateError := state.Presence(guildID, userID)
+		if stateError == nil {
+			return presence
+		}
+		return nil
+	}
+
+	state.RLock()
+	defer state.RUnlock()
+	for _, presence := range state.Presences {
+		if presence.User != nil && presence.User.ID == userID {
+			return presence
+		}
+	}
+
+	return nil
+}
+
+// getOwnPresence creates a presence out of the users settings, since discord
+// doesn't send presence updates for the current user.
+func getOwnPresence(state *discordgo.State) *discordgo.Presence {
+	presence := &discordgo.Presence{
+		User:   state.User,
+		Status: state.Settings.Status,
+	}
+	customStatus := state.Settings.CustomStatus
+	if customStatus.Text != "" || customStatus.EmojiName != "" {
+		presence.Activities = []*discordgo.Game{{
+			Name:  "Custom Status",
+			Type:  discordgo.GameTypeCustom,
+			State: customStatus.Text,
+			Emoji: discordgo.Emoji{Name: customStatus.EmojiName},
+		}}
+	}
+
+	return presence
+}
+
+// GetPresenceStatus returns the status of the given presence. If the
+// presence is unknown, the user is considered offline.
+func GetPresenceStatus(presence *discordgo.Presence) discordgo.Status {
+	if presence == nil || presence.Status == "" {
+		return discordgo.StatusOffline
+	}
+
+	return presence.Status
+}
+
+// GetPresenceActivity returns a short human readable description of what
+// the user is currently doing. Custom statuses take precedence over any
+// other activity. If there's no activity, an empty string is returned.
+func GetPresenceActivity(presence *discordgo.Presence) string {
+	if presence == nil || IsOffline(presence.Status) {
+		return ""
+	}
+
+	activities := presence.Activities
+	if len(activities) == 0 && presence.Game != nil {
+		activities = []*discordgo.Game{presence.Game}
+	}
+
+	for _, activity := range activities {
+		if activity != nil && activity.Type == discordgo.GameTypeCustom {
+			return strings.TrimSpace(activity.Emoji.Name + " " + activity.State)
+		}
+	}
+
+	for _, activity := range activities {
+		if activity == nil || activity.Name == "" {
+			continue
+		}
+
+		switch activity.Type {
+		case discordgo.GameTypeStreaming:
+			return "Streaming " + activity.Name
+		case discordgo.GameTypeListening:
+			return "Listening to " + activity.Name
+		case discordgo.GameTypeWatching:
+			return "Watching " + activity.Name
+		case discordgo.GameTypeGame:
+			return "Playing " + activity.Name
+		}
+	}
+
+	return ""
+}
+
+// UpdatePresence applies the parts of a presence update that the state
+// doesn't keep track of by itself. That is the list of activities and
+// presences of users that aren't part of a guild, such as friends.
+func UpdatePresence(state *discordgo.State, update *discordgo.PresenceUpdate) {
+	if update.User == nil {
+		return
+	}
+
+	if update.GuildID != "" {
+		presence, stateError := state.Presence(update.GuildID, update.User.ID)
+		if stateError == nil {
+			state.Lock()
+			presence.Activities = update.Activities
+			state.Unlock()
+		}
+		return
+	}
+
+	state.Lock()
+	defer state.Unlock()
+	for _, presence := range state.Presences {
+		if presence.User != nil && presence.User.ID == update.User.ID {
+			presence.Status = update.Status
+			presence.Game = update.Game
+			presence.Activities = update.Activities
+			return
+		}
+	}
+
+	newPresence := update.Presence
+	state.Presences = append(state.Presences, &newPresence)
+}
@@ -0,0 +1,144 @@
+package discordutil
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestGetPresenceActivity(t *testing.T) {
+	tests := []struct {
+		name     string
+		presence *discordgo.Presence
+		want     string
+	}{
+		{
+			name:     "unknown presence",
+			presence: nil,
+			want:     "",
+		}, {
+			name: "no activity",
+			presence: &discordgo.Presence{
+				Status: discordgo.StatusOnline,
+			},
+			want: "",
+		}, {
+			name: "game without activities",
+			presence: &discordgo.Presence{
+				Status: discordgo.StatusIdle,
+				Game:   &discordgo.Game{Name: "Minecraft", Type: discordgo.GameTypeGame},
+			},
+			want: "Playing Minecraft",
+		}, {
+			name: "custom status takes precedence",
+			presence: &discordgo.Presence{
+				Status: discordgo.StatusDoNotDisturb,
+				Activities: []*discordgo.Game{
+					{Name: "Spotify", Type: discordgo.GameTypeListening},
+					{Name: "Custom Status", Type: discordgo.GameTypeCustom, State: "busy", Emoji: discordgo.Emoji{Name: "🔥"}},
+				},
+			},
+			want: "🔥 busy",
+		}, {
+			name: "listening",
+			presence: &discordgo.Presence{
+				Status:     discordgo.StatusOnline,
+				Activities: []*discordgo.Game{{Name: "Spotify", Type: discordgo.GameTypeListening}},
+			},
+			want: "Listening to Spotify",
+		}, {
+			name: "offline users have no activity",
+			presence: &discordgo.Presence{
+				Status:     discordgo.StatusOffline,
+				Activities: []*discordgo.Game{{Name: "Minecraft", Type: discordgo.GameTypeGame}},
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetPresenceActivity(tt.presence); got != tt.want {
+				t.Errorf("GetPresenceActivity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStatusSymbol(t *testing.T) {
+	tests := []struct {
+		status discordgo.Status
+		want   string
+	}{
+		{status: discordgo.StatusOnline, want: "[green]●[-]"},
+		{status: discordgo.StatusIdle, want: "[yellow]●[-]"},
+		{status: discordgo.StatusDoNotDisturb, want: "[red]●[-]"},
+		{status: discordgo.StatusInvisible, want: "[gray]○[-]"},
+		{status: discordgo.StatusOffline, want: "[gray]○[-]"},
+		{status: "", want: "[gray]○[-]"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := GetStatusSymbol(tt.status); got != tt.want {
+				t.Errorf("GetStatusSymbol() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdatePresence(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "self"}
+	guild := &discordgo.Guild{ID: "guild"}
+	if err := state.GuildAdd(guild); err != nil {
+		t.Fatal(err)
+	}
+
+	friend := &discordgo.User{ID: "friend"}
+	member := &discordgo.User{ID: "member"}
+	activities := []*discordgo.Game{{Name: "Minecraft", Type: discordgo.GameTypeGame}}
+
+	//The state itself only keeps track of guild presences without activities.
+	guildUpdate := &discordgo.PresenceUpdate{
+		GuildID:  "guild",
+		Presence: discordgo.Presence{User: member, Status: discordgo.StatusIdle, Activities: activities},
+	}
+	if err := state.PresenceAdd(guildUpdate.GuildID, &discordgo.Presence{User: member, Status: discordgo.StatusIdle}); err != nil {
+		t.Fatal(err)
+	}
+	UpdatePresence(state, guildUpdate)
+
+	presence := GetPresence(state, "guild", "member")
+	if presence == nil || len(presence.Activities) != 1 {
+		t.Errorf("activities of guild presence haven't been updated: %v", presence)
+	}
+
+	UpdatePresence(state, &discordgo.PresenceUpdate{
+		Presence: discordgo.Presence{User: friend, Status: discordgo.StatusOnline},
+	})
+	if status := GetPresenceStatus(GetPresence(state, "", "friend")); status != discordgo.StatusOnline {
+		t.Errorf("private presence status = %v, want %v", status, discordgo.StatusOnline)
+	}
+
+	UpdatePresence(state, &discordgo.PresenceUpdate{
+		Presence: discordgo.Presence{User: friend, Status: discordgo.StatusOffline},
+	})
+	if status := GetPresenceStatus(GetPresence(state, "", "friend")); status != discordgo.StatusOffline {
+		t.Errorf("private presence status = %v, want %v", status, discordgo.StatusOffline)
+	}
+	if len(state.Presences) != 1 {
+		t.Errorf("private presences should be updated in place, but got %d", len(state.Presences))
+	}
+
+	if presence := GetPresence(state, "", "unknown"); presence != nil {
+		t.Errorf("presence of unknown user should be nil, but was %v", presence)
+	}
+
+	state.Settings = &discordgo.Settings{
+		Status:       discordgo.StatusDoNotDisturb,
+		CustomStatus: discordgo.CustomStatus{Text: "working"},
+	}
+	ownPresence := GetPresence(state, "guild", "self")
+	if GetPresenceStatus(ownPresence) != discordgo.StatusDoNotDisturb || GetPresenceActivity(ownPresence) != "working" {
+		t.Errorf("own presence should be taken from the settings, but was %v", ownPresence)
+	}
+}
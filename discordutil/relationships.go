@@ -0,0 +1,108 @@
+package discordutil
+
+import (
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// GetRelationship returns the relationship between the current user and the
+// given user. If there's no relationship, nil is returned.
+func GetRelationship(state *discordgo.State, userID string) *discordgo.Relationship {
+	state.RLock()
+	defer state.RUnlock()
+
+	for _, relationship := range state.Relationships {
+		if relationship.ID == userID {
+			return relationship
+		}
+	}
+
+	return nil
+}
+
+// AddOrUpdateRelationship adds the relationship to the state or replaces an
+// existing relationship with the same user. The state doesn't keep track of
+// relationships by itself.
+func AddOrUpdateRelationship(state *discordgo.State, relationship *discordgo.Relationship) {
+	state.Lock()
+	defer state.Unlock()
+
+	for index, existing := range state.Relationships {
+		if existing.ID == relationship.ID {
+			state.Relationships[index] = relationship
+			return
+		}
+	}
+
+	state.Relationships = append(state.Relationships, relationship)
+}
+
+// RemoveRelationship removes the relationship with the given user from the
+// state, if there is one.
+func RemoveRelationship(state *discordgo.State, userID string) {
+	state.Lock()
+	defer state.Unlock()
+
+	for index, existing := range state.Relationships {
+		if existing.ID == userID {
+			state.Relationships = append(state.Relationships[:index], state.Relationships[index+1:]...)
+			return
+		}
+	}
+}
+
+// GetRelationshipName returns a human readable description of the given
+// relationship. If there's no relationship, an empty string is returned.
+func GetRelationshipName(relationship *discordgo.Relationship) string {
+	if relationship == nil {
+		return ""
+	}
+
+	switch relationship.Type {
+	case discordgo.RelationTypeFriend:
+		return "Friend"
+	case discordgo.RelationTypeBlocked:
+		return "Blocked"
+	case discordgo.RelationTypeIncommingRequest:
+		return "Incoming friend request"
+	case discordgo.RelationTypeOutgoingRequest:
+		return "Outgoing friend request"
+	default:
+		return ""
+	}
+}
+
+// GetMutualGuilds returns all guilds that both the current user and the given
+// user are a member of. Since members of large guilds are loaded lazily, the
+// result might be incomplete.
+func GetMutualGuilds(state *discordgo.State, userID string) []*discordgo.Guild {
+	var mutualGuilds []*discordgo.Guild
+	for _, guild := range state.Guilds {
+		if _, stateError := state.Member(guild.ID, userID); stateError == nil {
+			mutualGuilds = append(mutualGuilds, guild)
+		}
+	}
+
+	return mutualGuilds
+}
+
+// GetKnownUsers returns all users known to the state, including the users
+// that the current user has a relationship with, but no guild or private
+// chat in common.
+func GetKnownUsers(state *discordgo.State) []*discordgo.User {
+	users, _ := state.Users()
+
+	state.RLock()
+	defer state.RUnlock()
+
+RELATIONSHIP_LOOP:
+	for _, relationship := range state.Relationships {
+		for _, user := range users {
+			if user.ID == relationship.User.ID {
+				continue RELATIONSHIP_LOOP
+			}
+		}
+		users = append(users, relationship.User)
+	}
+
+	return users
+}
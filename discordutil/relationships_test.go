@@ -0,0 +1,100 @@
+package discordutil
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestRelationships(t *testing.T) {
+	state := discordgo.NewState()
+	user := &discordgo.User{ID: "user"}
+
+	tests := []struct {
+		name   string
+		update func()
+		want   string
+	}{
+		{
+			name:   "no relationship",
+			update: func() {},
+			want:   "",
+		}, {
+			name: "outgoing request",
+			update: func() {
+				AddOrUpdateRelationship(state, &discordgo.Relationship{ID: "user", User: user, Type: discordgo.RelationTypeOutgoingRequest})
+			},
+			want: "Outgoing friend request",
+		}, {
+			name: "request accepted",
+			update: func() {
+				AddOrUpdateRelationship(state, &discordgo.Relationship{ID: "user", User: user, Type: discordgo.RelationTypeFriend})
+			},
+			want: "Friend",
+		}, {
+			name: "removed",
+			update: func() {
+				RemoveRelationship(state, "user")
+			},
+			want: "",
+		}, {
+			name: "removing twice",
+			update: func() {
+				RemoveRelationship(state, "user")
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.update()
+			if got := GetRelationshipName(GetRelationship(state, "user")); got != tt.want {
+				t.Errorf("GetRelationshipName() = %v, want %v", got, tt.want)
+			}
+			if len(state.Relationships) > 1 {
+				t.Errorf("expected at most one relationship, but got %d", len(state.Relationships))
+			}
+		})
+	}
+}
+
+func TestGetMutualGuilds(t *testing.T) {
+	state := discordgo.NewState()
+	for _, guild := range []*discordgo.Guild{
+		{ID: "A", Members: []*discordgo.Member{{GuildID: "A", User: &discordgo.User{ID: "user"}}}},
+		{ID: "B"},
+		{ID: "C", Members: []*discordgo.Member{{GuildID: "C", User: &discordgo.User{ID: "user"}}}},
+	} {
+		if err := state.GuildAdd(guild); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	for _, guild := range GetMutualGuilds(state, "user") {
+		got = append(got, guild.ID)
+	}
+	if len(got) != 2 || got[0] != "A" || got[1] != "C" {
+		t.Errorf("GetMutualGuilds() = %v, want [A C]", got)
+	}
+}
+
+func TestGetKnownUsers(t *testing.T) {
+	state := discordgo.NewState()
+	member := &discordgo.User{ID: "member"}
+	if err := state.GuildAdd(&discordgo.Guild{ID: "A", Members: []*discordgo.Member{{GuildID: "A", User: member}}}); err != nil {
+		t.Fatal(err)
+	}
+	state.Relationships = []*discordgo.Relationship{
+		{ID: "member", User: member, Type: discordgo.RelationTypeFriend},
+		{ID: "blocked", User: &discordgo.User{ID: "blocked"}, Type: discordgo.RelationTypeBlocked},
+	}
+
+	got := make(map[string]int)
+	for _, user := range GetKnownUsers(state) {
+		got[user.ID]++
+	}
+	if len(got) != 2 || got["member"] != 1 || got["blocked"] != 1 {
+		t.Errorf("GetKnownUsers() = %v, want each of member and blocked exactly once", got)
+	}
+}
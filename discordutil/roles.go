@@ -2,6 +2,8 @@ package discordutil
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/Bios-Marcel/discordgo"
 )
@@ -21,3 +23,86 @@ func GetRoleColor(role *discordgo.Role) string {
 	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
 
 }
+
+// HasGuildPermission checks if the current user has the given permission in
+// the guild, ignoring any channel specific overwrites.
+func HasGuildPermission(guildID string, state *discordgo.State, permission int) bool {
+	guild, stateError := state.Guild(guildID)
+	if stateError != nil {
+		return false
+	}
+
+	if guild.OwnerID == state.User.ID {
+		return true
+	}
+
+	member, stateError := state.Member(guildID, state.User.ID)
+	if stateError != nil {
+		return false
+	}
+
+	permissions := GetMemberGuildPermissions(guild, member)
+	return permissions&discordgo.PermissionAdministrator != 0 || permissions&permission == permission
+}
+
+// GetMemberGuildPermissions combines the permissions of the guilds
+// "@everyone" role and all roles of the given member.
+func GetMemberGuildPermissions(guild *discordgo.Guild, member *discordgo.Member) int {
+	var permissions int
+	for _, role := range guild.Roles {
+		//The ID of the "@everyone" role is the same as the guilds ID.
+		if role.ID == guild.ID {
+			permissions |= role.Permissions
+			continue
+		}
+
+		for _, roleID := range member.Roles {
+			if role.ID == roleID {
+				permissions |= role.Permissions
+				break
+			}
+		}
+	}
+
+	return permissions
+}
+
+// FindRoles returns all roles that have the given ID or name. Role mentions,
+// such as "<@&ID>", are accepted as well. Names are compared case
+// insensitively.
+func FindRoles(roles []*discordgo.Role, nameOrID string) []*discordgo.Role {
+	roleID := strings.TrimSuffix(strings.TrimPrefix(nameOrID, "<@&"), ">")
+	name := strings.TrimPrefix(nameOrID, "@")
+	var matches []*discordgo.Role
+	for _, role := range roles {
+		if role.ID == roleID {
+			return []*discordgo.Role{role}
+		}
+
+		if strings.EqualFold(strings.TrimPrefix(role.Name, "@"), name) {
+			matches = append(matches, role)
+		}
+	}
+
+	return matches
+}
+
+// ParseRoleColor parses a color in the format #RRGGBB. The prefix is
+// optional. "none" is accepted in order to remove the color of a role.
+func ParseRoleColor(value string) (int, error) {
+	if strings.EqualFold(value, "none") {
+		return 0, nil
+	}
+
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) != 6 {
+		return 0, fmt.Errorf("'%s' isn't a color in the format #RRGGBB", value)
+	}
+
+	color, parseError := strconv.ParseUint(hex, 16, 32)
+	if parseError != nil {
+		return 0, fmt.Errorf("'%s' isn't a color in the format #RRGGBB", value)
+	}
+
+	return int(color), nil
+}
@@ -0,0 +1,106 @@
+package discordutil
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestGetMemberGuildPermissions(t *testing.T) {
+	guild := &discordgo.Guild{
+		ID: "guild",
+		Roles: []*discordgo.Role{
+			{ID: "guild", Permissions: discordgo.PermissionSendMessages},
+			{ID: "moderator", Permissions: discordgo.PermissionManageChannels | discordgo.PermissionKickMembers},
+			{ID: "admin", Permissions: discordgo.PermissionAdministrator},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		member *discordgo.Member
+		want   int
+	}{
+		{
+			name:   "everyone",
+			member: &discordgo.Member{},
+			want:   discordgo.PermissionSendMessages,
+		}, {
+			name:   "moderator",
+			member: &discordgo.Member{Roles: []string{"moderator"}},
+			want:   discordgo.PermissionSendMessages | discordgo.PermissionManageChannels | discordgo.PermissionKickMembers,
+		}, {
+			name:   "unknown role",
+			member: &discordgo.Member{Roles: []string{"deleted"}},
+			want:   discordgo.PermissionSendMessages,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetMemberGuildPermissions(guild, tt.member); got != tt.want {
+				t.Errorf("GetMemberGuildPermissions() = %b, want %b", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindRoles(t *testing.T) {
+	everyone := &discordgo.Role{ID: "guild", Name: "@everyone"}
+	moderators := &discordgo.Role{ID: "1", Name: "Moderators"}
+	otherModerators := &discordgo.Role{ID: "2", Name: "moderators"}
+	admins := &discordgo.Role{ID: "3", Name: "Admins"}
+	roles := []*discordgo.Role{everyone, moderators, otherModerators, admins}
+
+	tests := []struct {
+		name      string
+		nameOrID  string
+		wantCount int
+		want      *discordgo.Role
+	}{
+		{name: "ID", nameOrID: "3", wantCount: 1, want: admins},
+		{name: "mention", nameOrID: "<@&2>", wantCount: 1, want: otherModerators},
+		{name: "name", nameOrID: "admins", wantCount: 1, want: admins},
+		{name: "name with at sign", nameOrID: "@Admins", wantCount: 1, want: admins},
+		{name: "everyone", nameOrID: "everyone", wantCount: 1, want: everyone},
+		{name: "ambiguous name", nameOrID: "MODERATORS", wantCount: 2},
+		{name: "unknown", nameOrID: "Members", wantCount: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindRoles(roles, tt.nameOrID)
+			if len(got) != tt.wantCount {
+				t.Fatalf("FindRoles() returned %d roles, want %d", len(got), tt.wantCount)
+			}
+			if tt.want != nil && got[0] != tt.want {
+				t.Errorf("FindRoles() = %v, want %v", got[0].Name, tt.want.Name)
+			}
+		})
+	}
+}
+
+func TestParseRoleColor(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "#ff0000", want: 0xff0000},
+		{value: "00FF7f", want: 0x00ff7f},
+		{value: "none", want: 0},
+		{value: "#fff", wantErr: true},
+		{value: "-12345", wantErr: true},
+		{value: "#gggggg", wantErr: true},
+		{value: "red", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseRoleColor(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRoleColor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRoleColor() = %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package discordutil
+
+import (
+	"encoding/json"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+// ApplySettingsUpdate returns a copy of the given settings with the changes
+// of the update applied. Discord only sends the changed settings, so
+// everything else is taken from the given settings. The given settings
+// aren't modified, as they might be read concurrently.
+func ApplySettingsUpdate(settings *discordgo.Settings, update discordgo.UserSettingsUpdate) (*discordgo.Settings, error) {
+	updated := &discordgo.Settings{}
+	if settings != nil {
+		*updated = *settings
+	}
+
+	//The custom status is always sent as a whole and is null if it has
+	//been removed, therefore the old one mustn't be merged into the new one.
+	if _, changed := update["custom_status"]; changed {
+		updated.CustomStatus = discordgo.CustomStatus{}
+	}
+
+	data, jsonError := json.Marshal(update)
+	if jsonError != nil {
+		return nil, jsonError
+	}
+	if parseError := json.Unmarshal(data, updated); parseError != nil {
+		return nil, parseError
+	}
+
+	return updated, nil
+}
+
+// GetSettings returns the settings of the current user while holding the
+// lock of the state. The result is nil if no settings have been received.
+func GetSettings(state *discordgo.State) *discordgo.Settings {
+	state.RLock()
+	defer state.RUnlock()
+
+	return state.Settings
+}
+
+// SetSettings replaces the settings of the current user while holding the
+// lock of the state. The settings are always replaced as a whole, so that
+// readers never see a partial update.
+func SetSettings(state *discordgo.State, settings *discordgo.Settings) {
+	state.Lock()
+	defer state.Unlock()
+
+	state.Settings = settings
+}
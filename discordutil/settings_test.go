@@ -0,0 +1,53 @@
+package discordutil
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestApplySettingsUpdate(t *testing.T) {
+	settings := &discordgo.Settings{
+		Status: discordgo.StatusOnline,
+		Locale: "en-US",
+		CustomStatus: discordgo.CustomStatus{
+			Text:      "old",
+			ExpiresAt: "2020-03-10T17:00:00.000Z",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		update discordgo.UserSettingsUpdate
+		want   discordgo.Settings
+	}{
+		{
+			name:   "status",
+			update: discordgo.UserSettingsUpdate{"status": "idle"},
+			want:   discordgo.Settings{Status: discordgo.StatusIdle, Locale: "en-US", CustomStatus: settings.CustomStatus},
+		}, {
+			name:   "custom status without expiry",
+			update: discordgo.UserSettingsUpdate{"custom_status": map[string]interface{}{"text": "new", "expires_at": nil}},
+			want:   discordgo.Settings{Status: discordgo.StatusOnline, Locale: "en-US", CustomStatus: discordgo.CustomStatus{Text: "new"}},
+		}, {
+			name:   "removed custom status",
+			update: discordgo.UserSettingsUpdate{"custom_status": nil},
+			want:   discordgo.Settings{Status: discordgo.StatusOnline, Locale: "en-US"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplySettingsUpdate(settings, tt.update)
+			if err != nil {
+				t.Fatalf("ApplySettingsUpdate() error = %v", err)
+			}
+			if got.Status != tt.want.Status || got.Locale != tt.want.Locale || got.CustomStatus != tt.want.CustomStatus {
+				t.Errorf("ApplySettingsUpdate() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+
+	if settings.Status != discordgo.StatusOnline || settings.CustomStatus.Text != "old" {
+		t.Errorf("ApplySettingsUpdate() modified the given settings: %+v", *settings)
+	}
+}
@@ -3,6 +3,7 @@ package discordutil
 import (
 	"math/rand"
 	"sort"
+	"strings"
 
 	"github.com/Bios-Marcel/cordless/tview"
 	"github.com/Bios-Marcel/discordgo"
@@ -165,3 +166,26 @@ func IsBlocked(state *discordgo.State, user *discordgo.User) bool {
 
 	return false
 }
+
+// FindMembers returns all members that have the given ID or name. Mentions,
+// such as "<@ID>", are accepted as well. Names are compared case
+// insensitively to the username, the username including the discriminator
+// and the nickname.
+func FindMembers(members []*discordgo.Member, nameOrID string) []*discordgo.Member {
+	userID := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(nameOrID, "<@"), "!"), ">")
+	name := strings.TrimPrefix(nameOrID, "@")
+	var matches []*discordgo.Member
+	for _, member := range members {
+		if member.User.ID == userID {
+			return []*discordgo.Member{member}
+		}
+
+		if strings.EqualFold(member.User.String(), name) ||
+			strings.EqualFold(member.User.Username, name) ||
+			(member.Nick != "" && strings.EqualFold(member.Nick, name)) {
+			matches = append(matches, member)
+		}
+	}
+
+	return matches
+}
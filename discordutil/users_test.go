@@ -538,3 +538,36 @@ func TestIsBlocked1(t *testing.T) {
 		})
 	}
 }
+
+func TestFindMembers(t *testing.T) {
+	alice := &discordgo.Member{User: &discordgo.User{ID: "1", Username: "Alice", Discriminator: "0001"}}
+	otherAlice := &discordgo.Member{User: &discordgo.User{ID: "2", Username: "alice", Discriminator: "0002"}}
+	bob := &discordgo.Member{User: &discordgo.User{ID: "3", Username: "bob", Discriminator: "0003"}, Nick: "Bobby"}
+	members := []*discordgo.Member{alice, otherAlice, bob}
+
+	tests := []struct {
+		name      string
+		nameOrID  string
+		wantCount int
+		want      *discordgo.Member
+	}{
+		{name: "ID", nameOrID: "3", wantCount: 1, want: bob},
+		{name: "mention", nameOrID: "<@2>", wantCount: 1, want: otherAlice},
+		{name: "nickname mention", nameOrID: "<@!1>", wantCount: 1, want: alice},
+		{name: "name with discriminator", nameOrID: "ALICE#0002", wantCount: 1, want: otherAlice},
+		{name: "nickname", nameOrID: "@bobby", wantCount: 1, want: bob},
+		{name: "ambiguous username", nameOrID: "alice", wantCount: 2},
+		{name: "unknown", nameOrID: "carol", wantCount: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindMembers(members, tt.nameOrID)
+			if len(got) != tt.wantCount {
+				t.Fatalf("FindMembers() returned %d members, want %d", len(got), tt.wantCount)
+			}
+			if tt.want != nil && got[0] != tt.want {
+				t.Errorf("FindMembers() = %v, want %v", got[0].User, tt.want.User)
+			}
+		})
+	}
+}
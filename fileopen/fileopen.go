@@ -1,6 +1,7 @@
 package fileopen
 
 import (
+	"errors"
 	"log"
 	"os"
 	"os/exec"
@@ -80,7 +81,15 @@ func OpenFile(targetFolder, fileID, downloadURL string) error {
 }
 
 func openWithHandler(handler, targetFile string) error {
-	commandParts := commands.ParseCommand(strings.ReplaceAll(handler, "{$file}", targetFile))
+	//The path is quoted, as it might contain spaces or characters that
+	//would otherwise be interpreted by the parser.
+	commandParts, parseError := commands.ParseCommand(strings.ReplaceAll(handler, "{$file}", commands.QuoteParameter(targetFile)))
+	if parseError != nil {
+		return parseError
+	}
+	if len(commandParts) == 0 {
+		return errors.New("the file handler is empty")
+	}
 	command := exec.Command(commandParts[0], commandParts[1:]...)
 	return command.Start()
 }
@@ -20,6 +20,7 @@ require (
 	github.com/robertkrimen/otto v0.0.0-20200922221731-ef014fd054ac
 	github.com/sergi/go-diff v1.1.0
 	github.com/skratchdot/open-golang v0.0.0-20200116055534-eef842397966
+	github.com/yuin/gopher-lua v0.0.0-20200816102855-ee81675732da
 	gopkg.in/sourcemap.v1 v1.0.5 // indirect
 	rsc.io/qr v0.2.0
 )
@@ -22,6 +22,7 @@ func main() {
 	setConfigDirectory := flag.String("config-dir", "", "Sets the configuration directory")
 	setScriptDirectory := flag.String("script-dir", "", "Sets the script directory")
 	setConfigFilePath := flag.String("config-file", "", "Sets exact path of the configuration file")
+	setRCFilePath := flag.String("rc", "", "Sets the path of the command file that is run on startup")
 	accountToUse := flag.String("account", "", "Defines which account cordless tries to load")
 	logPath := flag.String("log", "", "Defines what file we log to")
 	flag.Parse()
@@ -44,6 +45,9 @@ func main() {
 	if setConfigFilePath != nil {
 		config.SetConfigFile(*setConfigFilePath)
 	}
+	if setRCFilePath != nil {
+		config.SetRCFile(*setRCFilePath)
+	}
 
 	//Making sure both the main app and the shortcuts dialog have the
 	//correct theme and configuration files.
@@ -9,10 +9,19 @@ import (
 // Engine describes a type that is capable of handling events from the main
 // application and allows mutation of data.
 type Engine interface {
-	// LoadScripts loads scripts from a directory into the VM
+	// LoadScripts loads scripts from a directory into the VM. Scripts that
+	// have been loaded before are unloaded first, cancelling all of their
+	// pending timers.
 	LoadScripts(string) error
 	// SetErrorOutput sets the io.Writer that the errors are piped into.
 	SetErrorOutput(errorOutput io.Writer)
+	// SetStorage sets the persistent storage that scripts can use to keep
+	// data across restarts. This has to be called before LoadScripts.
+	SetStorage(storage *Storage)
+
+	// OnReady gets called as soon as the client has established a connection
+	// and received all initial data. This also happens after reconnecting.
+	OnReady(*discordgo.Ready)
 
 	// OnMessageSend handles the client sending new messages, allowing scripts
 	// to manipulate what's sent. The order of script execution is undefined
@@ -27,6 +36,41 @@ type Engine interface {
 	// OnMessageDelete gets called every time a message gets deleted, no matter
 	// in which channel or guild.
 	OnMessageDelete(*discordgo.Message)
+	// OnMessageRender gets called every time a message is about to be
+	// formatted for display. The result may be nil, in which case the
+	// message is displayed as usual.
+	OnMessageRender(*discordgo.Message) *MessageRender
+
+	// OnReactionAdd gets called every time anyone adds a reaction to a
+	// message, no matter in which channel or guild.
+	OnReactionAdd(*discordgo.MessageReaction)
+	// OnReactionRemove gets called every time anyone removes a reaction from
+	// a message, no matter in which channel or guild.
+	OnReactionRemove(*discordgo.MessageReaction)
+
+	// OnTypingStart gets called every time a user starts typing in a channel
+	// that is visible to the client.
+	OnTypingStart(*discordgo.TypingStart)
+	// OnPresenceUpdate gets called every time a users status or activity
+	// changes.
+	OnPresenceUpdate(*discordgo.PresenceUpdate)
+
+	// OnChannelSwitch gets called every time the user loads a different
+	// channel in the UI.
+	OnChannelSwitch(*discordgo.Channel)
+
+	// OnGuildJoin gets called every time the client joins a new guild.
+	OnGuildJoin(*discordgo.Guild)
+	// OnGuildLeave gets called every time the client leaves a guild or gets
+	// removed from one.
+	OnGuildLeave(*discordgo.Guild)
+
+	// OnMemberJoin gets called every time a user joins a guild that the
+	// client is part of.
+	OnMemberJoin(*discordgo.Member)
+	// OnMemberLeave gets called every time a user leaves a guild that the
+	// client is part of.
+	OnMemberLeave(*discordgo.Member)
 
 	SetTriggerNotificationFunction(func(string, string))
 	SetPrintToConsoleFunction(func(string))
@@ -35,3 +79,24 @@ type Engine interface {
 	SetGetCurrentGuildFunction(func() string)
 	SetGetCurrentChannelFunction(func() string)
 }
+
+// MessageRender describes how scripts want a message to be displayed. The
+// zero value means that the message is displayed unchanged.
+type MessageRender struct {
+	// Hidden causes the message not to be displayed at all.
+	Hidden bool
+	// Text replaces the messages content if it isn't empty. The text is
+	// displayed as is, meaning it mustn't contain any markup.
+	Text string
+	// Tags are displayed in front of the message.
+	Tags []*MessageTag
+}
+
+// MessageTag is a short colored label that is displayed next to a message.
+type MessageTag struct {
+	// Text is the label itself.
+	Text string
+	// Color is either a color name, such as "red", or a hexadecimal color
+	// in the format "#RRGGBB". If empty, the default text color is used.
+	Color string
+}
@@ -6,6 +6,7 @@
 package js
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -14,6 +15,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Bios-Marcel/discordgo"
 
@@ -36,8 +38,13 @@ var (
 
 // JavaScriptEngine stores scripting engine state
 type JavaScriptEngine struct {
+	// instancesLock guards scriptInstances and globalInstance, since
+	// scripts can be reloaded while events are being handled.
+	instancesLock   sync.RWMutex
 	scriptInstances []*ScriptInstance
 	errorOutput     io.Writer
+	storage         *scripting.Storage
+	scriptDirectory string
 	// globalInstance is used for actions that don't require to be run on a
 	// specific VM, but any VM. An example for this is converting a Go-struct
 	// into a valid Otto-Value.
@@ -51,10 +58,33 @@ type ScriptInstance struct {
 	vm   *otto.Otto
 	lock sync.Mutex
 
+	// name is the path of the script relative to the script directory. It
+	// is used as the namespace for the scripts storage.
+	name string
+
+	timers *scripting.Timers
+
+	onReady *otto.Value
+
 	onMessageSend    *otto.Value
 	onMessageReceive *otto.Value
 	onMessageEdit    *otto.Value
 	onMessageDelete  *otto.Value
+	onMessageRender  *otto.Value
+
+	onReactionAdd    *otto.Value
+	onReactionRemove *otto.Value
+
+	onTypingStart    *otto.Value
+	onPresenceUpdate *otto.Value
+
+	onChannelSwitch *otto.Value
+
+	onGuildJoin  *otto.Value
+	onGuildLeave *otto.Value
+
+	onMemberJoin  *otto.Value
+	onMemberLeave *otto.Value
 }
 
 // New instantiates a new scripting engine. The resulting object doesn't hold
@@ -67,8 +97,17 @@ func New() *JavaScriptEngine {
 // order to avoid scripts modifying each others state by accident. All
 // available callbacks get eagerly evaluated in the beginning. Locking of the
 // instances when calling one of the callbacks only happens, if a callback
-// actually exists.
-func (engine *JavaScriptEngine) LoadScripts(dirname string) (err error) {
+// actually exists. Previously loaded scripts are replaced, even if loading
+// fails. In that case, the scripts that have been loaded successfully are
+// kept.
+func (engine *JavaScriptEngine) LoadScripts(dirname string) error {
+	var instances []*ScriptInstance
+	loadError := engine.readScripts(dirname, &instances)
+	engine.replaceScripts(instances)
+	return loadError
+}
+
+func (engine *JavaScriptEngine) readScripts(dirname string, instances *[]*ScriptInstance) error {
 	_, statError := os.Stat(dirname)
 	if os.IsNotExist(statError) {
 		return nil
@@ -76,10 +115,42 @@ func (engine *JavaScriptEngine) LoadScripts(dirname string) (err error) {
 		return errors.Wrapf(statError, "Error loading scripts '%s'", statError.Error())
 	}
 
-	return engine.readScriptsRecursively(dirname)
+	engine.scriptDirectory = dirname
+	return engine.readScriptsRecursively(dirname, instances)
+}
+
+// replaceScripts swaps the loaded scripts for the given ones. The pending
+// timers of the previously loaded scripts are cancelled.
+func (engine *JavaScriptEngine) replaceScripts(instances []*ScriptInstance) {
+	//Avoid unnecessarily creating an unused VM.
+	var globalInstance *otto.Otto
+	if len(instances) > 0 {
+		globalInstance = otto.New()
+	}
+
+	engine.instancesLock.Lock()
+	oldInstances := engine.scriptInstances
+	engine.scriptInstances = instances
+	engine.globalInstance = globalInstance
+	engine.instancesLock.Unlock()
+
+	for _, instance := range oldInstances {
+		instance.lock.Lock()
+		instance.timers.Stop()
+		instance.lock.Unlock()
+	}
+}
+
+// loadedScripts returns the currently loaded scripts and the VM for
+// converting values. The returned slice is never modified.
+func (engine *JavaScriptEngine) loadedScripts() ([]*ScriptInstance, *otto.Otto) {
+	engine.instancesLock.RLock()
+	defer engine.instancesLock.RUnlock()
+
+	return engine.scriptInstances, engine.globalInstance
 }
 
-func (engine *JavaScriptEngine) readScriptsRecursively(dirname string) error {
+func (engine *JavaScriptEngine) readScriptsRecursively(dirname string, instances *[]*ScriptInstance) error {
 	files, err := ioutil.ReadDir(dirname)
 	if err != nil {
 		return err
@@ -91,7 +162,7 @@ func (engine *JavaScriptEngine) readScriptsRecursively(dirname string) error {
 		//Skip dot-folders and read non-dot-folders.
 		if file.IsDir() {
 			if !strings.HasPrefix(file.Name(), ".") {
-				readError := engine.readScriptsRecursively(path)
+				readError := engine.readScriptsRecursively(path, instances)
 				if readError != nil {
 					return readError
 				}
@@ -110,71 +181,238 @@ func (engine *JavaScriptEngine) readScriptsRecursively(dirname string) error {
 			return errors.Wrap(err, path)
 		}
 
-		vm := otto.New()
-		_, err = vm.Run(file)
+		name, err := filepath.Rel(engine.scriptDirectory, path)
 		if err != nil {
-			return errors.Wrapf(err, "failed to run script '%s'", path)
+			return errors.Wrap(err, path)
 		}
+		name = filepath.ToSlash(name)
 
+		vm := otto.New()
 		instance := &ScriptInstance{
 			vm:   vm,
 			lock: sync.Mutex{},
+			name: name,
+		}
+		instance.timers = scripting.NewTimers(&instance.lock)
+		engine.setTimerFunctions(instance)
+		if engine.storage != nil {
+			engine.setStorageFunctions(vm, engine.storage.Namespace(name))
+		}
+
+		// Timers scheduled by the script could fire before initialisation
+		// has finished, therefore the instance has to be locked.
+		instance.lock.Lock()
+		initError := engine.initScript(instance, file)
+		instance.lock.Unlock()
+		file.Close()
+		if initError != nil {
+			instance.lock.Lock()
+			instance.timers.Stop()
+			instance.lock.Unlock()
+			return errors.Wrapf(initError, "failed to run script '%s'", path)
+		}
+
+		callbacks := []struct {
+			name   string
+			target **otto.Value
+		}{
+			{"onReady", &instance.onReady},
+			{"onMessageSend", &instance.onMessageSend},
+			{"onMessageReceive", &instance.onMessageReceive},
+			{"onMessageEdit", &instance.onMessageEdit},
+			{"onMessageDelete", &instance.onMessageDelete},
+			{"onMessageRender", &instance.onMessageRender},
+			{"onReactionAdd", &instance.onReactionAdd},
+			{"onReactionRemove", &instance.onReactionRemove},
+			{"onTypingStart", &instance.onTypingStart},
+			{"onPresenceUpdate", &instance.onPresenceUpdate},
+			{"onChannelSwitch", &instance.onChannelSwitch},
+			{"onGuildJoin", &instance.onGuildJoin},
+			{"onGuildLeave", &instance.onGuildLeave},
+			{"onMemberJoin", &instance.onMemberJoin},
+			{"onMemberLeave", &instance.onMemberLeave},
+		}
+		for _, callback := range callbacks {
+			callbackJS, resolveError := vm.Get(callback.name)
+			if resolveError != nil {
+				return errors.Wrapf(resolveError, "error resolving function %s", callback.name)
+			}
+			if !callbackJS.IsUndefined() {
+				*callback.target = &callbackJS
+			}
+		}
+
+		*instances = append(*instances, instance)
+	}
+
+	return nil
+}
+
+// initScript runs the top-level code of the script and its init function.
+// The caller has to hold the instances lock.
+func (engine *JavaScriptEngine) initScript(instance *ScriptInstance, script io.Reader) error {
+	if _, runError := instance.vm.Run(script); runError != nil {
+		return runError
+	}
+
+	initFunction, resolveError := instance.vm.Get("init")
+	if resolveError != nil {
+		return resolveError
+	}
+	if !initFunction.IsUndefined() {
+		initFunction.Call(nullValue)
+
+		// We attempt clearing the init function, as it's not supposed to
+		// be called again after initialisation and therefore only wastes
+		// precious memory.
+		clearError := instance.vm.Set("init", undefinedValue)
+		if clearError != nil {
+			return errors.Wrap(clearError, "error clearing init function from VM.")
 		}
+	}
+
+	return nil
+}
 
-		initFunction, resolveError := vm.Get("init")
-		if !initFunction.IsUndefined() {
-			initFunction.Call(nullValue)
+// setTimerFunctions defines setTimeout, setInterval, clearTimeout and
+// clearInterval on the VM of the given instance. Just like in browsers,
+// additional arguments are passed on to the callback and the delay is
+// specified in milliseconds.
+func (engine *JavaScriptEngine) setTimerFunctions(instance *ScriptInstance) {
+	schedule := func(repeat bool) func(call otto.FunctionCall) otto.Value {
+		return func(call otto.FunctionCall) otto.Value {
+			callback := call.Argument(0)
+			if !callback.IsFunction() {
+				panic(call.Otto.MakeTypeError("the first argument has to be a function"))
+			}
+			delay, _ := call.Argument(1).ToInteger()
 
-			// We attempt clearing the init function, as it's not supposed to
-			// be called again after initialisation and therefore only wastes
-			// precious memory.
-			clearError := vm.Set("init", undefinedValue)
-			if clearError != nil {
-				return errors.Wrap(resolveError, "error clearing init function from VM.")
+			var arguments []interface{}
+			if len(call.ArgumentList) > 2 {
+				for _, argument := range call.ArgumentList[2:] {
+					arguments = append(arguments, argument)
+				}
 			}
+
+			id := instance.timers.Schedule(time.Duration(delay)*time.Millisecond, repeat, func() {
+				if _, callError := callback.Call(nullValue, arguments...); callError != nil {
+					log.Printf("Error calling timer callback of script '%s': %s\n", instance.name, callError)
+				}
+			})
+
+			idValue, _ := call.Otto.ToValue(id)
+			return idValue
 		}
+	}
 
-		onMessageSendJS, resolveError := vm.Get("onMessageSend")
-		if !onMessageSendJS.IsUndefined() {
-			instance.onMessageSend = &onMessageSendJS
+	clearTimer := func(call otto.FunctionCall) otto.Value {
+		id, argError := call.Argument(0).ToInteger()
+		if argError == nil {
+			instance.timers.Cancel(int(id))
 		}
-		if resolveError != nil {
-			return errors.Wrap(resolveError, "error resolving function onMessageSend")
+		return undefinedValue
+	}
+
+	functions := map[string]func(call otto.FunctionCall) otto.Value{
+		"setTimeout":    schedule(false),
+		"setInterval":   schedule(true),
+		"clearTimeout":  clearTimer,
+		"clearInterval": clearTimer,
+	}
+	for name, function := range functions {
+		if setError := instance.vm.Set(name, function); setError != nil {
+			log.Printf("Error setting function %s: %s", name, setError)
 		}
+	}
+}
 
-		onMessageReceiveJS, resolveError := vm.Get("onMessageReceive")
-		if !onMessageReceiveJS.IsUndefined() {
-			instance.onMessageReceive = &onMessageReceiveJS
+// SetStorage implements Engine. Each script gets the functions storageGet,
+// storageSet, storageDelete and storageList, which operate on a namespace
+// named after the scripts path. Values are passed through JSON.stringify and
+// JSON.parse, so only JSON compatible values survive a restart.
+func (engine *JavaScriptEngine) SetStorage(storage *scripting.Storage) {
+	engine.storage = storage
+}
+
+func (engine *JavaScriptEngine) setStorageFunctions(vm *otto.Otto, namespace *scripting.StorageNamespace) {
+	throwStorageError := func(call otto.FunctionCall, storageError error) {
+		panic(call.Otto.MakeCustomError("StorageError", storageError.Error()))
+	}
+
+	storageGet := func(call otto.FunctionCall) otto.Value {
+		key, argError := call.Argument(0).ToString()
+		if argError != nil {
+			throwStorageError(call, argError)
 		}
-		if resolveError != nil {
-			return errors.Wrap(resolveError, "error resolving function onMessageReceive")
+
+		value, contains := namespace.Get(key)
+		if !contains {
+			return undefinedValue
 		}
 
-		onMessageEditJS, resolveError := vm.Get("onMessageEdit")
-		if !onMessageEditJS.IsUndefined() {
-			instance.onMessageEdit = &onMessageEditJS
+		parsed, parseError := call.Otto.Call("JSON.parse", nil, string(value))
+		if parseError != nil {
+			throwStorageError(call, parseError)
 		}
-		if resolveError != nil {
-			return errors.Wrap(resolveError, "error resolving function onMessageEdit")
+		return parsed
+	}
+
+	storageSet := func(call otto.FunctionCall) otto.Value {
+		key, argError := call.Argument(0).ToString()
+		if argError != nil {
+			throwStorageError(call, argError)
 		}
 
-		onMessageDeleteJS, resolveError := vm.Get("onMessageDelete")
-		if !onMessageDeleteJS.IsUndefined() {
-			instance.onMessageDelete = &onMessageDeleteJS
+		value, stringifyError := call.Otto.Call("JSON.stringify", nil, call.Argument(1))
+		if stringifyError != nil {
+			throwStorageError(call, stringifyError)
 		}
-		if resolveError != nil {
-			return errors.Wrap(resolveError, "error resolving function onMessageDelete")
+		if value.IsUndefined() {
+			throwStorageError(call, errors.New("value can't be represented as JSON"))
 		}
 
-		engine.scriptInstances = append(engine.scriptInstances, instance)
+		if setError := namespace.Set(key, json.RawMessage(value.String())); setError != nil {
+			throwStorageError(call, setError)
+		}
+		return undefinedValue
 	}
 
-	//Avoid unnecessarily creating an unused VM.
-	if len(engine.scriptInstances) > 0 {
-		engine.globalInstance = otto.New()
+	storageDelete := func(call otto.FunctionCall) otto.Value {
+		key, argError := call.Argument(0).ToString()
+		if argError != nil {
+			throwStorageError(call, argError)
+		}
+
+		if deleteError := namespace.Delete(key); deleteError != nil {
+			throwStorageError(call, deleteError)
+		}
+		return undefinedValue
 	}
 
-	return nil
+	storageList := func(call otto.FunctionCall) otto.Value {
+		keys, jsonError := json.Marshal(namespace.Keys())
+		if jsonError != nil {
+			throwStorageError(call, jsonError)
+		}
+
+		parsed, parseError := call.Otto.Call("JSON.parse", nil, string(keys))
+		if parseError != nil {
+			throwStorageError(call, parseError)
+		}
+		return parsed
+	}
+
+	functions := map[string]func(call otto.FunctionCall) otto.Value{
+		"storageGet":    storageGet,
+		"storageSet":    storageSet,
+		"storageDelete": storageDelete,
+		"storageList":   storageList,
+	}
+	for name, function := range functions {
+		if setError := vm.Set(name, function); setError != nil {
+			log.Printf("Error setting function %s: %s", name, setError)
+		}
+	}
 }
 
 // SetErrorOutput sets the writer to which errors can be written from inside
@@ -186,7 +424,8 @@ func (engine *JavaScriptEngine) SetErrorOutput(errorOutput io.Writer) {
 // OnMessageSend implements Engine
 func (engine *JavaScriptEngine) OnMessageSend(oldText string) (newText string) {
 	newText = oldText
-	for _, instance := range engine.scriptInstances {
+	instances, _ := engine.loadedScripts()
+	for _, instance := range instances {
 		func() {
 			if instance.onMessageSend != nil {
 				defer instance.lock.Unlock()
@@ -209,79 +448,195 @@ func (engine *JavaScriptEngine) OnMessageSend(oldText string) (newText string) {
 
 // OnMessageReceive implements Engine
 func (engine *JavaScriptEngine) OnMessageReceive(message *discordgo.Message) {
-	if len(engine.scriptInstances) == 0 {
-		return
+	engine.invokeCallbacks("onMessageReceive", *message, func(instance *ScriptInstance) *otto.Value {
+		return instance.onMessageReceive
+	})
+}
+
+// OnMessageEdit implements Engine
+func (engine *JavaScriptEngine) OnMessageEdit(message *discordgo.Message) {
+	engine.invokeCallbacks("onMessageEdit", *message, func(instance *ScriptInstance) *otto.Value {
+		return instance.onMessageEdit
+	})
+}
+
+// OnMessageDelete implements Engine
+func (engine *JavaScriptEngine) OnMessageDelete(message *discordgo.Message) {
+	engine.invokeCallbacks("onMessageDelete", *message, func(instance *ScriptInstance) *otto.Value {
+		return instance.onMessageDelete
+	})
+}
+
+// OnMessageRender implements Engine. The callback may return an object with
+// the optional properties "hidden", "text", "tag" and "tagColor". Returning
+// nothing keeps the message as it is. If multiple scripts return a text, the
+// last one wins, while the tags of all scripts are kept.
+func (engine *JavaScriptEngine) OnMessageRender(message *discordgo.Message) *scripting.MessageRender {
+	instances, globalInstance := engine.loadedScripts()
+	if len(instances) == 0 {
+		return nil
 	}
 
-	messageToJS, toValueError := engine.globalInstance.ToValue(*message)
+	messageToJS, toValueError := globalInstance.ToValue(*message)
 	if toValueError != nil {
 		log.Printf("Error converting message to Otto value: %s\n", toValueError)
-		return
+		return nil
 	}
 
-	for _, instance := range engine.scriptInstances {
+	var render *scripting.MessageRender
+	for _, instance := range instances {
+		if instance.onMessageRender == nil {
+			continue
+		}
+
 		func() {
-			if instance.onMessageReceive != nil {
-				instance.lock.Lock()
-				defer instance.lock.Unlock()
+			instance.lock.Lock()
+			defer instance.lock.Unlock()
 
-				_, callError := instance.onMessageReceive.Call(nullValue, messageToJS)
-				if callError != nil {
-					log.Printf("Error calling onMessageReceive: %s\n", callError)
-				}
+			jsValue, callError := instance.onMessageRender.Call(nullValue, messageToJS)
+			if callError != nil {
+				log.Printf("Error calling onMessageRender: %s\n", callError)
+				return
 			}
+
+			if !jsValue.IsObject() {
+				return
+			}
+
+			if render == nil {
+				render = &scripting.MessageRender{}
+			}
+			applyMessageRender(render, jsValue.Object())
 		}()
+
+		//No need to bother other scripts, since there's nothing to display.
+		if render != nil && render.Hidden {
+			break
+		}
 	}
+
+	return render
 }
 
-// OnMessageEdit implements Engine
-func (engine *JavaScriptEngine) OnMessageEdit(message *discordgo.Message) {
-	if len(engine.scriptInstances) == 0 {
-		return
+// applyMessageRender merges the properties of the object returned by an
+// onMessageRender callback into the given MessageRender.
+func applyMessageRender(render *scripting.MessageRender, result *otto.Object) {
+	if hidden, _ := result.Get("hidden"); hidden.IsDefined() {
+		render.Hidden, _ = hidden.ToBoolean()
 	}
 
-	messageToJS, toValueError := engine.globalInstance.ToValue(*message)
-	if toValueError != nil {
-		log.Printf("Error converting message to Otto value: %s\n", toValueError)
-		return
+	if text, _ := result.Get("text"); text.IsDefined() && !text.IsNull() {
+		render.Text, _ = text.ToString()
 	}
 
-	for _, instance := range engine.scriptInstances {
-		func() {
-			if instance.onMessageEdit != nil {
-				instance.lock.Lock()
-				defer instance.lock.Unlock()
-
-				_, callError := instance.onMessageEdit.Call(nullValue, messageToJS)
-				if callError != nil {
-					log.Printf("Error calling onMessageEdit: %s\n", callError)
-				}
-			}
-		}()
+	if tag, _ := result.Get("tag"); tag.IsDefined() && !tag.IsNull() {
+		messageTag := &scripting.MessageTag{}
+		messageTag.Text, _ = tag.ToString()
+		if tagColor, _ := result.Get("tagColor"); tagColor.IsDefined() && !tagColor.IsNull() {
+			messageTag.Color, _ = tagColor.ToString()
+		}
+		render.Tags = append(render.Tags, messageTag)
 	}
 }
 
-// OnMessageDelete implements Engine
-func (engine *JavaScriptEngine) OnMessageDelete(message *discordgo.Message) {
-	if len(engine.scriptInstances) == 0 {
+// OnReady implements Engine
+func (engine *JavaScriptEngine) OnReady(ready *discordgo.Ready) {
+	engine.invokeCallbacks("onReady", *ready, func(instance *ScriptInstance) *otto.Value {
+		return instance.onReady
+	})
+}
+
+// OnReactionAdd implements Engine
+func (engine *JavaScriptEngine) OnReactionAdd(reaction *discordgo.MessageReaction) {
+	engine.invokeCallbacks("onReactionAdd", *reaction, func(instance *ScriptInstance) *otto.Value {
+		return instance.onReactionAdd
+	})
+}
+
+// OnReactionRemove implements Engine
+func (engine *JavaScriptEngine) OnReactionRemove(reaction *discordgo.MessageReaction) {
+	engine.invokeCallbacks("onReactionRemove", *reaction, func(instance *ScriptInstance) *otto.Value {
+		return instance.onReactionRemove
+	})
+}
+
+// OnTypingStart implements Engine
+func (engine *JavaScriptEngine) OnTypingStart(typingStart *discordgo.TypingStart) {
+	engine.invokeCallbacks("onTypingStart", *typingStart, func(instance *ScriptInstance) *otto.Value {
+		return instance.onTypingStart
+	})
+}
+
+// OnPresenceUpdate implements Engine
+func (engine *JavaScriptEngine) OnPresenceUpdate(presenceUpdate *discordgo.PresenceUpdate) {
+	engine.invokeCallbacks("onPresenceUpdate", *presenceUpdate, func(instance *ScriptInstance) *otto.Value {
+		return instance.onPresenceUpdate
+	})
+}
+
+// OnChannelSwitch implements Engine
+func (engine *JavaScriptEngine) OnChannelSwitch(channel *discordgo.Channel) {
+	engine.invokeCallbacks("onChannelSwitch", *channel, func(instance *ScriptInstance) *otto.Value {
+		return instance.onChannelSwitch
+	})
+}
+
+// OnGuildJoin implements Engine
+func (engine *JavaScriptEngine) OnGuildJoin(guild *discordgo.Guild) {
+	engine.invokeCallbacks("onGuildJoin", *guild, func(instance *ScriptInstance) *otto.Value {
+		return instance.onGuildJoin
+	})
+}
+
+// OnGuildLeave implements Engine
+func (engine *JavaScriptEngine) OnGuildLeave(guild *discordgo.Guild) {
+	engine.invokeCallbacks("onGuildLeave", *guild, func(instance *ScriptInstance) *otto.Value {
+		return instance.onGuildLeave
+	})
+}
+
+// OnMemberJoin implements Engine
+func (engine *JavaScriptEngine) OnMemberJoin(member *discordgo.Member) {
+	engine.invokeCallbacks("onMemberJoin", *member, func(instance *ScriptInstance) *otto.Value {
+		return instance.onMemberJoin
+	})
+}
+
+// OnMemberLeave implements Engine
+func (engine *JavaScriptEngine) OnMemberLeave(member *discordgo.Member) {
+	engine.invokeCallbacks("onMemberLeave", *member, func(instance *ScriptInstance) *otto.Value {
+		return instance.onMemberLeave
+	})
+}
+
+// invokeCallbacks converts the given value into a JavaScript value once and
+// passes it to the callback chosen by selectCallback. Instances that don't
+// define the callback are skipped without being locked.
+func (engine *JavaScriptEngine) invokeCallbacks(name string, value interface{}, selectCallback func(*ScriptInstance) *otto.Value) {
+	instances, globalInstance := engine.loadedScripts()
+	if len(instances) == 0 {
 		return
 	}
 
-	messageToJS, toValueError := engine.globalInstance.ToValue(*message)
+	valueToJS, toValueError := globalInstance.ToValue(value)
 	if toValueError != nil {
-		log.Printf("Error converting message to Otto value: %s\n", toValueError)
+		log.Printf("Error converting %T to Otto value: %s\n", value, toValueError)
 		return
 	}
 
-	for _, instance := range engine.scriptInstances {
+	for _, instance := range instances {
+		callback := selectCallback(instance)
+		if callback == nil {
+			continue
+		}
+
 		func() {
-			if instance.onMessageDelete != nil {
-				instance.lock.Lock()
-				defer instance.lock.Unlock()
-				_, callError := instance.onMessageDelete.Call(nullValue, messageToJS)
-				if callError != nil {
-					log.Printf("Error calling onMessageDelete: %s\n", callError)
-				}
+			instance.lock.Lock()
+			defer instance.lock.Unlock()
+
+			_, callError := callback.Call(nullValue, valueToJS)
+			if callError != nil {
+				log.Printf("Error calling %s: %s\n", name, callError)
 			}
 		}()
 	}
@@ -363,8 +718,11 @@ func (engine *JavaScriptEngine) SetGetCurrentChannelFunction(function func() str
 }
 
 func (engine *JavaScriptEngine) setFunctionOnVMs(name string, function func(call otto.FunctionCall) otto.Value) {
-	for _, instance := range engine.scriptInstances {
+	instances, _ := engine.loadedScripts()
+	for _, instance := range instances {
+		instance.lock.Lock()
 		setError := instance.vm.Set(name, function)
+		instance.lock.Unlock()
 		if setError != nil {
 			log.Printf("Error setting function %s: %s", name, setError)
 		}
@@ -1,7 +1,16 @@
 package js
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/scripting"
+	"github.com/Bios-Marcel/cordless/scripting/scriptingtest"
 )
 
 func TestJavaScriptEngine(t *testing.T) {
@@ -30,3 +39,152 @@ func TestJavaScriptEngine(t *testing.T) {
 		})
 	}
 }
+
+func TestJavaScriptEngineEvents(t *testing.T) {
+	e := New()
+	if err := e.LoadScripts("test/events"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	tests := []struct {
+		name     string
+		trigger  func()
+		variable string
+		want     string
+	}{
+		{
+			name:     "reaction add",
+			trigger:  func() { e.OnReactionAdd(&discordgo.MessageReaction{Emoji: discordgo.Emoji{Name: "👍"}}) },
+			variable: "lastReaction",
+			want:     "add:👍",
+		}, {
+			name:     "reaction remove",
+			trigger:  func() { e.OnReactionRemove(&discordgo.MessageReaction{Emoji: discordgo.Emoji{Name: "👎"}}) },
+			variable: "lastReaction",
+			want:     "remove:👎",
+		}, {
+			name:     "typing start",
+			trigger:  func() { e.OnTypingStart(&discordgo.TypingStart{UserID: "1234"}) },
+			variable: "typingUser",
+			want:     "1234",
+		}, {
+			name:     "channel switch",
+			trigger:  func() { e.OnChannelSwitch(&discordgo.Channel{Name: "general"}) },
+			variable: "switchedTo",
+			want:     "general",
+		}, {
+			name:     "guild join",
+			trigger:  func() { e.OnGuildJoin(&discordgo.Guild{Name: "cordless"}) },
+			variable: "joinedGuild",
+			want:     "cordless",
+		}, {
+			name:     "member leave",
+			trigger:  func() { e.OnMemberLeave(&discordgo.Member{User: &discordgo.User{Username: "Marcel"}}) },
+			variable: "leftMember",
+			want:     "Marcel",
+		}, {
+			name: "undefined callbacks are ignored",
+			trigger: func() {
+				e.OnReady(&discordgo.Ready{})
+				e.OnGuildLeave(&discordgo.Guild{Name: "other"})
+				e.OnMemberJoin(&discordgo.Member{User: &discordgo.User{Username: "other"}})
+				e.OnPresenceUpdate(&discordgo.PresenceUpdate{})
+			},
+			variable: "joinedGuild",
+			want:     "cordless",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.trigger()
+
+			value, getError := e.scriptInstances[0].vm.Get(tt.variable)
+			if getError != nil {
+				t.Fatalf("Error retrieving variable %s: %s", tt.variable, getError)
+			}
+			if got := value.String(); got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.variable, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJavaScriptEngineOnMessageRender(t *testing.T) {
+	e := New()
+	if err := e.LoadScripts("test/render"); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	tests := []struct {
+		name    string
+		message *discordgo.Message
+		want    *scripting.MessageRender
+	}{
+		{
+			name:    "unchanged",
+			message: &discordgo.Message{Content: "Hello", Author: &discordgo.User{Username: "Marcel"}},
+			want:    nil,
+		}, {
+			name:    "replaced text",
+			message: &discordgo.Message{Content: "Lots of noise", Author: &discordgo.User{Username: "Bot", Bot: true}},
+			want:    &scripting.MessageRender{Text: "[bot output collapsed]"},
+		}, {
+			name:    "hidden",
+			message: &discordgo.Message{Content: "Buy this!", Author: &discordgo.User{Username: "spammer"}},
+			want:    &scripting.MessageRender{Hidden: true},
+		}, {
+			name:    "tagged",
+			message: &discordgo.Message{Content: "See TICKET-123", Author: &discordgo.User{Username: "Marcel"}},
+			want: &scripting.MessageRender{Tags: []*scripting.MessageTag{
+				{Text: "TICKET-123", Color: "#ff0000"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.OnMessageRender(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("JavaScriptEngine.OnMessageRender() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJavaScriptEngineStorage(t *testing.T) {
+	directory, tempDirError := ioutil.TempDir("", "cordless-js-storage-test")
+	if tempDirError != nil {
+		t.Fatal(tempDirError)
+	}
+	defer os.RemoveAll(directory)
+	storagePath := filepath.Join(directory, "script-storage.json")
+
+	for run := 1; run <= 2; run++ {
+		storage, loadError := scripting.LoadStorage(storagePath)
+		if loadError != nil {
+			t.Fatal("LoadStorage failed:", loadError)
+		}
+
+		e := New()
+		e.SetStorage(storage)
+		if err := e.LoadScripts("test/storage"); err != nil {
+			t.Fatal("LoadScripts failed:", err)
+		}
+
+		starts, _ := e.scriptInstances[0].vm.Get("starts")
+		if got, _ := starts.ToInteger(); got != int64(run) {
+			t.Errorf("starts = %d, want %d", got, run)
+		}
+
+		namespace := storage.Namespace("counter.js")
+		if keys := namespace.Keys(); !reflect.DeepEqual(keys, []string{"preferences", "starts"}) {
+			t.Errorf("Keys() = %v, want [preferences starts]", keys)
+		}
+		if preferences, _ := namespace.Get("preferences"); string(preferences) != `{"mentions":["Marcel"],"theme":"dark"}` &&
+			string(preferences) != `{"theme":"dark","mentions":["Marcel"]}` {
+			t.Errorf("preferences = %s", preferences)
+		}
+	}
+}
+
+func TestJavaScriptEngineConformance(t *testing.T) {
+	scriptingtest.TestEngine(t, func() scripting.Engine { return New() }, "test/conformance")
+}
@@ -0,0 +1,106 @@
+function init() {
+  storageSet("starts", (storageGet("starts") || 0) + 1);
+  storageSet("preferences", { theme: "dark", mentions: ["Marcel"] });
+  storageDelete("nonexistent");
+}
+
+function onReady(ready) {
+  printLineToConsole("onReady:" + ready.User.Username + ":" + ready.Guilds.length + ":" + ready.Guilds[0].Name);
+
+  var preferences = storageGet("preferences");
+  printLineToConsole("storage:" + storageGet("starts") + ":" + storageList().join(",") + ":" +
+    preferences.theme + ":" + preferences.mentions[0]);
+}
+
+function onMessageSend(text) {
+  return text.toUpperCase();
+}
+
+function onMessageReceive(message) {
+  if (message.Content === "schedule") {
+    setTimeout(function (argument) {
+      printLineToConsole("timeout:" + argument);
+    }, 1, "value");
+
+    var runs = 0;
+    var interval = setInterval(function () {
+      runs++;
+      printLineToConsole("interval:" + runs);
+      if (runs === 3) {
+        clearTimeout(interval);
+      }
+    }, 1);
+
+    clearTimeout(setTimeout(function () {
+      printLineToConsole("cancelled");
+    }, 1));
+  } else if (message.Content === "schedule late") {
+    setTimeout(function () {
+      printLineToConsole("late");
+    }, 50);
+  } else if (message.Content === "file access") {
+    printLineToConsole("fileAccess:" + (typeof dofile !== "undefined") + ":" + (typeof loadfile !== "undefined"));
+  } else {
+    printLineToConsole("onMessageReceive:" + message.Content + ":" + message.Author.Username);
+  }
+}
+
+function onMessageEdit(message) {
+  printLineToConsole("onMessageEdit:" + message.Content + ":" + message.Author.Username);
+}
+
+function onMessageDelete(message) {
+  throw new Error("failing on purpose");
+}
+
+function onMessageRender(message) {
+  if (message.Author.Bot) {
+    return { text: "[bot output collapsed]" };
+  }
+
+  if (message.Author.Username === "spammer") {
+    return { hidden: true };
+  }
+
+  var ticket = message.Content.match(/TICKET-\d+/);
+  if (ticket) {
+    return { tag: ticket[0], tagColor: "#ff0000" };
+  }
+}
+
+function onReactionAdd(reaction) {
+  printLineToConsole("onReactionAdd:" + reaction.Emoji.Name);
+}
+
+function onReactionRemove(reaction) {
+  printLineToConsole("onReactionRemove:" + reaction.Emoji.Name);
+}
+
+function onTypingStart(typingStart) {
+  printLineToConsole("onTypingStart:" + typingStart.UserID);
+}
+
+function onPresenceUpdate(presenceUpdate) {
+  printLineToConsole("onPresenceUpdate:" + presenceUpdate.Status + ":" + presenceUpdate.User.ID);
+}
+
+function onChannelSwitch(channel) {
+  printToConsole("onChannelSwitch:" + channel.Name + ":" + getCurrentGuild() + ":" + getCurrentChannel());
+  triggerNotification("Switched", channel.Name);
+}
+
+function onGuildJoin(guild) {
+  printLineToConsole("onGuildJoin:" + guild.Name);
+}
+
+function onGuildLeave(guild) {
+  printLineToConsole("onGuildLeave:" + guild.Name);
+}
+
+function onMemberJoin(member) {
+  printLineToConsole("onMemberJoin:" + member.User.Username);
+}
+
+function onMemberLeave(member) {
+  printLineToConsole("onMemberLeave:" + member.User.Username);
+}
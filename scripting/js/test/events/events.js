@@ -0,0 +1,29 @@
+var lastReaction = "";
+var typingUser = "";
+var switchedTo = "";
+var joinedGuild = "";
+var leftMember = "";
+
+function onReactionAdd(reaction) {
+  lastReaction = "add:" + reaction.Emoji.Name;
+}
+
+function onReactionRemove(reaction) {
+  lastReaction = "remove:" + reaction.Emoji.Name;
+}
+
+function onTypingStart(typingStart) {
+  typingUser = typingStart.UserID;
+}
+
+function onChannelSwitch(channel) {
+  switchedTo = channel.Name;
+}
+
+function onGuildJoin(guild) {
+  joinedGuild = guild.Name;
+}
+
+function onMemberLeave(member) {
+  leftMember = member.User.Username;
+}
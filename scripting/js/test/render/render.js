@@ -0,0 +1,14 @@
+function onMessageRender(message) {
+  if (message.Author.Bot) {
+    return { text: "[bot output collapsed]" };
+  }
+
+  if (message.Author.Username === "spammer") {
+    return { hidden: true };
+  }
+
+  var ticket = message.Content.match(/TICKET-\d+/);
+  if (ticket) {
+    return { tag: ticket[0], tagColor: "#ff0000" };
+  }
+}
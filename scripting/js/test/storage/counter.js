@@ -0,0 +1,8 @@
+var starts = 0;
+
+function init() {
+  starts = (storageGet("starts") || 0) + 1;
+  storageSet("starts", starts);
+  storageSet("preferences", { theme: "dark", mentions: ["Marcel"] });
+  storageDelete("nonexistent");
+}
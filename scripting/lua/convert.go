@@ -0,0 +1,197 @@
+package lua
+
+import (
+	"fmt"
+	"reflect"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// valueTypeName is the name of the metatable used for wrapped Go values.
+const valueTypeName = "cordless.value"
+
+// registerValueType creates the metatable that allows scripts to access the
+// exported fields of wrapped Go structs, as well as the elements of wrapped
+// slices and maps.
+func registerValueType(state *lua.LState) {
+	metatable := state.NewTypeMetatable(valueTypeName)
+	state.SetField(metatable, "__index", state.NewFunction(indexValue))
+	state.SetField(metatable, "__len", state.NewFunction(lengthOfValue))
+}
+
+// toLuaValue converts a Go value into a lua value. Primitives are copied,
+// while structs, slices and maps are wrapped and only resolved once a script
+// accesses them. This way, huge structures, such as the Ready event, don't
+// have to be converted completely. Fields are accessed via their Go names,
+// e.g. message.Author.Username, just like in the javascript engine. Slices
+// are indexed starting at 1 and support the length operator, but can't be
+// iterated via ipairs.
+func toLuaValue(state *lua.LState, value interface{}) lua.LValue {
+	return reflectToLuaValue(state, reflect.ValueOf(value))
+}
+
+func reflectToLuaValue(state *lua.LState, value reflect.Value) lua.LValue {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return lua.LNil
+		}
+		return reflectToLuaValue(state, value.Elem())
+	case reflect.Bool:
+		return lua.LBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return lua.LNumber(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return lua.LNumber(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return lua.LNumber(value.Float())
+	case reflect.String:
+		return lua.LString(value.String())
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		userData := state.NewUserData()
+		userData.Value = value
+		state.SetMetatable(userData, state.GetTypeMetatable(valueTypeName))
+		return userData
+	}
+
+	return lua.LNil
+}
+
+func indexValue(state *lua.LState) int {
+	value := state.CheckUserData(1).Value.(reflect.Value)
+	key := state.Get(2)
+
+	switch value.Kind() {
+	case reflect.Struct:
+		name, isString := key.(lua.LString)
+		if !isString {
+			break
+		}
+		field, exists := value.Type().FieldByName(string(name))
+		//Unexported fields mustn't be accessible.
+		if !exists || field.PkgPath != "" {
+			break
+		}
+		state.Push(reflectToLuaValue(state, value.FieldByIndex(field.Index)))
+		return 1
+	case reflect.Slice, reflect.Array:
+		index, isNumber := key.(lua.LNumber)
+		if !isNumber || int(index) < 1 || int(index) > value.Len() {
+			break
+		}
+		state.Push(reflectToLuaValue(state, value.Index(int(index)-1)))
+		return 1
+	case reflect.Map:
+		mapKey := reflect.ValueOf(lua.LVAsString(key))
+		if !mapKey.Type().ConvertibleTo(value.Type().Key()) {
+			break
+		}
+		state.Push(reflectToLuaValue(state, value.MapIndex(mapKey.Convert(value.Type().Key()))))
+		return 1
+	}
+
+	state.Push(lua.LNil)
+	return 1
+}
+
+func lengthOfValue(state *lua.LState) int {
+	value := state.CheckUserData(1).Value.(reflect.Value)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		state.Push(lua.LNumber(value.Len()))
+	default:
+		state.Push(lua.LNumber(0))
+	}
+	return 1
+}
+
+// toJSONValue converts a lua value into a value that can be passed to
+// json.Marshal. Tables that only have consecutive integer keys starting at 1
+// become arrays, all other tables become objects. The visited tables are
+// tracked in order to detect cycles.
+func toJSONValue(value lua.LValue, visited map[*lua.LTable]bool) (interface{}, error) {
+	switch typedValue := value.(type) {
+	case *lua.LNilType:
+		return nil, nil
+	case lua.LBool:
+		return bool(typedValue), nil
+	case lua.LNumber:
+		return float64(typedValue), nil
+	case lua.LString:
+		return string(typedValue), nil
+	case *lua.LTable:
+		if visited[typedValue] {
+			return nil, fmt.Errorf("tables containing themselves can't be represented as JSON")
+		}
+		if visited == nil {
+			visited = make(map[*lua.LTable]bool)
+		}
+		visited[typedValue] = true
+		defer delete(visited, typedValue)
+
+		length := typedValue.Len()
+		var keyCount int
+		typedValue.ForEach(func(_, _ lua.LValue) { keyCount++ })
+
+		if keyCount == length {
+			array := make([]interface{}, 0, length)
+			for index := 1; index <= length; index++ {
+				element, convertError := toJSONValue(typedValue.RawGetInt(index), visited)
+				if convertError != nil {
+					return nil, convertError
+				}
+				array = append(array, element)
+			}
+			return array, nil
+		}
+
+		object := make(map[string]interface{}, keyCount)
+		var convertError error
+		typedValue.ForEach(func(key, element lua.LValue) {
+			if convertError != nil {
+				return
+			}
+
+			switch key.(type) {
+			case lua.LString, lua.LNumber:
+			default:
+				convertError = fmt.Errorf("table keys of type %s can't be represented as JSON", key.Type())
+				return
+			}
+
+			object[key.String()], convertError = toJSONValue(element, visited)
+		})
+		if convertError != nil {
+			return nil, convertError
+		}
+		return object, nil
+	}
+
+	return nil, fmt.Errorf("values of type %s can't be represented as JSON", value.Type())
+}
+
+// fromJSONValue converts a value created by json.Unmarshal into a lua value.
+func fromJSONValue(state *lua.LState, value interface{}) lua.LValue {
+	switch typedValue := value.(type) {
+	case bool:
+		return lua.LBool(typedValue)
+	case float64:
+		return lua.LNumber(typedValue)
+	case string:
+		return lua.LString(typedValue)
+	case []interface{}:
+		table := state.CreateTable(len(typedValue), 0)
+		for index, element := range typedValue {
+			table.RawSetInt(index+1, fromJSONValue(state, element))
+		}
+		return table
+	case map[string]interface{}:
+		table := state.CreateTable(0, len(typedValue))
+		for key, element := range typedValue {
+			table.RawSetString(key, fromJSONValue(state, element))
+		}
+		return table
+	}
+
+	return lua.LNil
+}
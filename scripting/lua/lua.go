@@ -0,0 +1,652 @@
+// This package is the Engine implementation for a lua scripting interface.
+// It offers the same host functions and callbacks as the javascript engine.
+// All callbacks are optional and are resolved once on script
+// initialisation. All invocations of callbacks perform locking on the
+// instance that they are being called on. Each instance has their own lock.
+package lua
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/pkg/errors"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/Bios-Marcel/cordless/scripting"
+)
+
+// This declaration makes sure that LuaEngine complies with the Engine
+// interface.
+var _ scripting.Engine = &LuaEngine{}
+
+// LuaEngine stores scripting engine state
+type LuaEngine struct {
+	// instancesLock guards scriptInstances, since scripts can be reloaded
+	// while events are being handled.
+	instancesLock   sync.RWMutex
+	scriptInstances []*ScriptInstance
+	errorOutput     io.Writer
+	storage         *scripting.Storage
+	scriptDirectory string
+}
+
+// ScriptInstance represents a usable and already loaded lua script. The
+// callbacks are pre-evaluated and the instance can be locked as soon as any
+// of the requested callbacks are available.
+type ScriptInstance struct {
+	state *lua.LState
+	lock  sync.Mutex
+
+	// name is the path of the script relative to the script directory. It
+	// is used as the namespace for the scripts storage.
+	name string
+
+	timers *scripting.Timers
+
+	onReady *lua.LFunction
+
+	onMessageSend    *lua.LFunction
+	onMessageReceive *lua.LFunction
+	onMessageEdit    *lua.LFunction
+	onMessageDelete  *lua.LFunction
+	onMessageRender  *lua.LFunction
+
+	onReactionAdd    *lua.LFunction
+	onReactionRemove *lua.LFunction
+
+	onTypingStart    *lua.LFunction
+	onPresenceUpdate *lua.LFunction
+
+	onChannelSwitch *lua.LFunction
+
+	onGuildJoin  *lua.LFunction
+	onGuildLeave *lua.LFunction
+
+	onMemberJoin  *lua.LFunction
+	onMemberLeave *lua.LFunction
+}
+
+// New instantiates a new scripting engine. The resulting object doesn't hold
+// any data or states initially. Only upon loading scripts, states are
+// created.
+func New() *LuaEngine {
+	return &LuaEngine{}
+}
+
+// LoadScripts implements Engine. Each script gets a designated lua state in
+// order to avoid scripts modifying each others state by accident. All
+// available callbacks get eagerly evaluated in the beginning. Locking of the
+// instances when calling one of the callbacks only happens, if a callback
+// actually exists. Previously loaded scripts are replaced, even if loading
+// fails. In that case, the scripts that have been loaded successfully are
+// kept.
+func (engine *LuaEngine) LoadScripts(dirname string) error {
+	var instances []*ScriptInstance
+	loadError := engine.readScripts(dirname, &instances)
+	engine.replaceScripts(instances)
+	return loadError
+}
+
+func (engine *LuaEngine) readScripts(dirname string, instances *[]*ScriptInstance) error {
+	_, statError := os.Stat(dirname)
+	if os.IsNotExist(statError) {
+		return nil
+	} else if statError != nil {
+		return errors.Wrapf(statError, "Error loading scripts '%s'", statError.Error())
+	}
+
+	engine.scriptDirectory = dirname
+	return engine.readScriptsRecursively(dirname, instances)
+}
+
+// replaceScripts swaps the loaded scripts for the given ones. The pending
+// timers of the previously loaded scripts are cancelled.
+func (engine *LuaEngine) replaceScripts(instances []*ScriptInstance) {
+	engine.instancesLock.Lock()
+	oldInstances := engine.scriptInstances
+	engine.scriptInstances = instances
+	engine.instancesLock.Unlock()
+
+	for _, instance := range oldInstances {
+		instance.lock.Lock()
+		instance.timers.Stop()
+		instance.lock.Unlock()
+	}
+}
+
+// loadedScripts returns the currently loaded scripts. The returned slice is
+// never modified.
+func (engine *LuaEngine) loadedScripts() []*ScriptInstance {
+	engine.instancesLock.RLock()
+	defer engine.instancesLock.RUnlock()
+
+	return engine.scriptInstances
+}
+
+func (engine *LuaEngine) readScriptsRecursively(dirname string, instances *[]*ScriptInstance) error {
+	files, err := ioutil.ReadDir(dirname)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		path := filepath.Join(dirname, file.Name())
+
+		//Skip dot-folders and read non-dot-folders.
+		if file.IsDir() {
+			if !strings.HasPrefix(file.Name(), ".") {
+				readError := engine.readScriptsRecursively(path, instances)
+				if readError != nil {
+					return readError
+				}
+			}
+
+			continue
+		}
+
+		//Only lua files
+		if !strings.HasSuffix(file.Name(), ".lua") {
+			continue
+		}
+
+		name, err := filepath.Rel(engine.scriptDirectory, path)
+		if err != nil {
+			return errors.Wrap(err, path)
+		}
+		name = filepath.ToSlash(name)
+
+		state := newState()
+		instance := &ScriptInstance{
+			state: state,
+			lock:  sync.Mutex{},
+			name:  name,
+		}
+		instance.timers = scripting.NewTimers(&instance.lock)
+		setTimerFunctions(instance)
+		if engine.storage != nil {
+			setStorageFunctions(state, engine.storage.Namespace(name))
+		}
+
+		// Timers scheduled by the script could fire before initialisation
+		// has finished, therefore the instance has to be locked.
+		instance.lock.Lock()
+		initError := initScript(instance, path)
+		if initError != nil {
+			instance.timers.Stop()
+		}
+		instance.lock.Unlock()
+		if initError != nil {
+			return errors.Wrapf(initError, "failed to run script '%s'", path)
+		}
+
+		callbacks := []struct {
+			name   string
+			target **lua.LFunction
+		}{
+			{"onReady", &instance.onReady},
+			{"onMessageSend", &instance.onMessageSend},
+			{"onMessageReceive", &instance.onMessageReceive},
+			{"onMessageEdit", &instance.onMessageEdit},
+			{"onMessageDelete", &instance.onMessageDelete},
+			{"onMessageRender", &instance.onMessageRender},
+			{"onReactionAdd", &instance.onReactionAdd},
+			{"onReactionRemove", &instance.onReactionRemove},
+			{"onTypingStart", &instance.onTypingStart},
+			{"onPresenceUpdate", &instance.onPresenceUpdate},
+			{"onChannelSwitch", &instance.onChannelSwitch},
+			{"onGuildJoin", &instance.onGuildJoin},
+			{"onGuildLeave", &instance.onGuildLeave},
+			{"onMemberJoin", &instance.onMemberJoin},
+			{"onMemberLeave", &instance.onMemberLeave},
+		}
+		for _, callback := range callbacks {
+			if function, ok := state.GetGlobal(callback.name).(*lua.LFunction); ok {
+				*callback.target = function
+			}
+		}
+
+		*instances = append(*instances, instance)
+	}
+
+	return nil
+}
+
+// initScript runs the top-level code of the script and its init function.
+// The caller has to hold the instances lock.
+func initScript(instance *ScriptInstance, path string) error {
+	if runError := instance.state.DoFile(path); runError != nil {
+		return runError
+	}
+
+	if initFunction, ok := instance.state.GetGlobal("init").(*lua.LFunction); ok {
+		if _, initError := instance.call(initFunction); initError != nil {
+			log.Printf("Error calling init of script '%s': %s\n", path, initError)
+		}
+
+		// The init function isn't supposed to be called again after
+		// initialisation and therefore only wastes precious memory.
+		instance.state.SetGlobal("init", lua.LNil)
+	}
+
+	return nil
+}
+
+// setTimerFunctions defines setTimeout, setInterval, clearTimeout and
+// clearInterval in the state of the given instance. They behave like their
+// javascript counterparts, meaning that the delay is specified in
+// milliseconds and additional arguments are passed on to the callback.
+func setTimerFunctions(instance *ScriptInstance) {
+	schedule := func(repeat bool) lua.LGFunction {
+		return func(state *lua.LState) int {
+			callback := state.CheckFunction(1)
+			delay := state.OptNumber(2, 0)
+
+			var arguments []lua.LValue
+			for index := 3; index <= state.GetTop(); index++ {
+				arguments = append(arguments, state.Get(index))
+			}
+
+			id := instance.timers.Schedule(time.Duration(delay)*time.Millisecond, repeat, func() {
+				if _, callError := instance.call(callback, arguments...); callError != nil {
+					log.Printf("Error calling timer callback of script '%s': %s\n", instance.name, callError)
+				}
+			})
+
+			state.Push(lua.LNumber(id))
+			return 1
+		}
+	}
+
+	clearTimer := func(state *lua.LState) int {
+		instance.timers.Cancel(int(state.CheckNumber(1)))
+		return 0
+	}
+
+	instance.state.SetGlobal("setTimeout", instance.state.NewFunction(schedule(false)))
+	instance.state.SetGlobal("setInterval", instance.state.NewFunction(schedule(true)))
+	instance.state.SetGlobal("clearTimeout", instance.state.NewFunction(clearTimer))
+	instance.state.SetGlobal("clearInterval", instance.state.NewFunction(clearTimer))
+}
+
+// newState creates a lua state that only has access to the libraries that
+// don't allow touching the system. This way scripts have the same
+// capabilities as in the javascript engine.
+func newState() *lua.LState {
+	state := lua.NewState(lua.Options{SkipOpenLibs: true})
+	libraries := []struct {
+		name     string
+		function lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+		{lua.CoroutineLibName, lua.OpenCoroutine},
+	}
+	for _, library := range libraries {
+		state.Push(state.NewFunction(library.function))
+		state.Push(lua.LString(library.name))
+		state.Call(1, 0)
+	}
+	//The base library contains functions for running files, which would
+	//allow scripts to read arbitrary files.
+	state.SetGlobal("dofile", lua.LNil)
+	state.SetGlobal("loadfile", lua.LNil)
+
+	registerValueType(state)
+
+	return state
+}
+
+// SetStorage implements Engine. Each script gets the functions storageGet,
+// storageSet, storageDelete and storageList, which operate on a namespace
+// named after the scripts path. Values are converted to JSON, so only
+// booleans, numbers, strings and tables consisting of those survive a
+// restart.
+func (engine *LuaEngine) SetStorage(storage *scripting.Storage) {
+	engine.storage = storage
+}
+
+func setStorageFunctions(state *lua.LState, namespace *scripting.StorageNamespace) {
+	storageGet := func(state *lua.LState) int {
+		value, contains := namespace.Get(state.CheckString(1))
+		if !contains {
+			state.Push(lua.LNil)
+			return 1
+		}
+
+		var parsed interface{}
+		if parseError := json.Unmarshal(value, &parsed); parseError != nil {
+			state.RaiseError("StorageError: %s", parseError)
+		}
+		state.Push(fromJSONValue(state, parsed))
+		return 1
+	}
+
+	storageSet := func(state *lua.LState) int {
+		key := state.CheckString(1)
+		value, convertError := toJSONValue(state.Get(2), nil)
+		if convertError != nil {
+			state.RaiseError("StorageError: %s", convertError)
+		}
+
+		data, jsonError := json.Marshal(value)
+		if jsonError != nil {
+			state.RaiseError("StorageError: %s", jsonError)
+		}
+
+		if setError := namespace.Set(key, json.RawMessage(data)); setError != nil {
+			state.RaiseError("StorageError: %s", setError)
+		}
+		return 0
+	}
+
+	storageDelete := func(state *lua.LState) int {
+		if deleteError := namespace.Delete(state.CheckString(1)); deleteError != nil {
+			state.RaiseError("StorageError: %s", deleteError)
+		}
+		return 0
+	}
+
+	storageList := func(state *lua.LState) int {
+		keys := state.NewTable()
+		for _, key := range namespace.Keys() {
+			keys.Append(lua.LString(key))
+		}
+		state.Push(keys)
+		return 1
+	}
+
+	state.SetGlobal("storageGet", state.NewFunction(storageGet))
+	state.SetGlobal("storageSet", state.NewFunction(storageSet))
+	state.SetGlobal("storageDelete", state.NewFunction(storageDelete))
+	state.SetGlobal("storageList", state.NewFunction(storageList))
+}
+
+// SetErrorOutput sets the writer to which errors can be written from inside
+// the lua engines.
+func (engine *LuaEngine) SetErrorOutput(errorOutput io.Writer) {
+	engine.errorOutput = errorOutput
+}
+
+// OnMessageSend implements Engine
+func (engine *LuaEngine) OnMessageSend(oldText string) (newText string) {
+	newText = oldText
+	for _, instance := range engine.loadedScripts() {
+		if instance.onMessageSend == nil {
+			continue
+		}
+
+		func() {
+			instance.lock.Lock()
+			defer instance.lock.Unlock()
+
+			result, callError := instance.call(instance.onMessageSend, lua.LString(newText))
+			if callError != nil {
+				if engine.errorOutput != nil {
+					fmt.Fprintf(engine.errorOutput, "Error occurred during execution of lua: %s\n", callError.Error())
+				}
+				//This script failed, go to next one
+				return
+			}
+			newText = result.String()
+		}()
+	}
+
+	return
+}
+
+// OnMessageReceive implements Engine
+func (engine *LuaEngine) OnMessageReceive(message *discordgo.Message) {
+	engine.invokeCallbacks("onMessageReceive", message, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onMessageReceive
+	})
+}
+
+// OnMessageEdit implements Engine
+func (engine *LuaEngine) OnMessageEdit(message *discordgo.Message) {
+	engine.invokeCallbacks("onMessageEdit", message, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onMessageEdit
+	})
+}
+
+// OnMessageDelete implements Engine
+func (engine *LuaEngine) OnMessageDelete(message *discordgo.Message) {
+	engine.invokeCallbacks("onMessageDelete", message, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onMessageDelete
+	})
+}
+
+// OnMessageRender implements Engine. The callback may return a table with
+// the optional fields "hidden", "text", "tag" and "tagColor". Returning
+// nothing keeps the message as it is. If multiple scripts return a text, the
+// last one wins, while the tags of all scripts are kept.
+func (engine *LuaEngine) OnMessageRender(message *discordgo.Message) *scripting.MessageRender {
+	var render *scripting.MessageRender
+	for _, instance := range engine.loadedScripts() {
+		if instance.onMessageRender == nil {
+			continue
+		}
+
+		func() {
+			instance.lock.Lock()
+			defer instance.lock.Unlock()
+
+			result, callError := instance.call(instance.onMessageRender, toLuaValue(instance.state, message))
+			if callError != nil {
+				log.Printf("Error calling onMessageRender: %s\n", callError)
+				return
+			}
+
+			table, isTable := result.(*lua.LTable)
+			if !isTable {
+				return
+			}
+
+			if render == nil {
+				render = &scripting.MessageRender{}
+			}
+			applyMessageRender(render, table)
+		}()
+
+		//No need to bother other scripts, since there's nothing to display.
+		if render != nil && render.Hidden {
+			break
+		}
+	}
+
+	return render
+}
+
+// applyMessageRender merges the fields of the table returned by an
+// onMessageRender callback into the given MessageRender.
+func applyMessageRender(render *scripting.MessageRender, result *lua.LTable) {
+	if hidden := result.RawGetString("hidden"); hidden != lua.LNil {
+		render.Hidden = lua.LVAsBool(hidden)
+	}
+
+	if text := result.RawGetString("text"); text != lua.LNil {
+		render.Text = text.String()
+	}
+
+	if tag := result.RawGetString("tag"); tag != lua.LNil {
+		messageTag := &scripting.MessageTag{Text: tag.String()}
+		if tagColor := result.RawGetString("tagColor"); tagColor != lua.LNil {
+			messageTag.Color = tagColor.String()
+		}
+		render.Tags = append(render.Tags, messageTag)
+	}
+}
+
+// OnReady implements Engine
+func (engine *LuaEngine) OnReady(ready *discordgo.Ready) {
+	engine.invokeCallbacks("onReady", ready, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onReady
+	})
+}
+
+// OnReactionAdd implements Engine
+func (engine *LuaEngine) OnReactionAdd(reaction *discordgo.MessageReaction) {
+	engine.invokeCallbacks("onReactionAdd", reaction, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onReactionAdd
+	})
+}
+
+// OnReactionRemove implements Engine
+func (engine *LuaEngine) OnReactionRemove(reaction *discordgo.MessageReaction) {
+	engine.invokeCallbacks("onReactionRemove", reaction, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onReactionRemove
+	})
+}
+
+// OnTypingStart implements Engine
+func (engine *LuaEngine) OnTypingStart(typingStart *discordgo.TypingStart) {
+	engine.invokeCallbacks("onTypingStart", typingStart, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onTypingStart
+	})
+}
+
+// OnPresenceUpdate implements Engine
+func (engine *LuaEngine) OnPresenceUpdate(presenceUpdate *discordgo.PresenceUpdate) {
+	engine.invokeCallbacks("onPresenceUpdate", presenceUpdate, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onPresenceUpdate
+	})
+}
+
+// OnChannelSwitch implements Engine
+func (engine *LuaEngine) OnChannelSwitch(channel *discordgo.Channel) {
+	engine.invokeCallbacks("onChannelSwitch", channel, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onChannelSwitch
+	})
+}
+
+// OnGuildJoin implements Engine
+func (engine *LuaEngine) OnGuildJoin(guild *discordgo.Guild) {
+	engine.invokeCallbacks("onGuildJoin", guild, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onGuildJoin
+	})
+}
+
+// OnGuildLeave implements Engine
+func (engine *LuaEngine) OnGuildLeave(guild *discordgo.Guild) {
+	engine.invokeCallbacks("onGuildLeave", guild, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onGuildLeave
+	})
+}
+
+// OnMemberJoin implements Engine
+func (engine *LuaEngine) OnMemberJoin(member *discordgo.Member) {
+	engine.invokeCallbacks("onMemberJoin", member, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onMemberJoin
+	})
+}
+
+// OnMemberLeave implements Engine
+func (engine *LuaEngine) OnMemberLeave(member *discordgo.Member) {
+	engine.invokeCallbacks("onMemberLeave", member, func(instance *ScriptInstance) *lua.LFunction {
+		return instance.onMemberLeave
+	})
+}
+
+// invokeCallbacks passes the given value to the callback chosen by
+// selectCallback. Instances that don't define the callback are skipped
+// without being locked. Since lua values are bound to a single state, the
+// value has to be wrapped for each instance separately.
+func (engine *LuaEngine) invokeCallbacks(name string, value interface{}, selectCallback func(*ScriptInstance) *lua.LFunction) {
+	for _, instance := range engine.loadedScripts() {
+		callback := selectCallback(instance)
+		if callback == nil {
+			continue
+		}
+
+		func() {
+			instance.lock.Lock()
+			defer instance.lock.Unlock()
+
+			if _, callError := instance.call(callback, toLuaValue(instance.state, value)); callError != nil {
+				log.Printf("Error calling %s: %s\n", name, callError)
+			}
+		}()
+	}
+}
+
+// call invokes the given function in protected mode and returns its first
+// return value. The caller has to hold the instances lock.
+func (instance *ScriptInstance) call(function *lua.LFunction, arguments ...lua.LValue) (lua.LValue, error) {
+	callError := instance.state.CallByParam(lua.P{
+		Fn:      function,
+		NRet:    1,
+		Protect: true,
+	}, arguments...)
+	if callError != nil {
+		return lua.LNil, callError
+	}
+
+	result := instance.state.Get(-1)
+	instance.state.Pop(1)
+	return result, nil
+}
+
+// SetTriggerNotificationFunction implements Engine
+func (engine *LuaEngine) SetTriggerNotificationFunction(function func(title, text string)) {
+	engine.setFunctionOnStates("triggerNotification", func(state *lua.LState) int {
+		function(argumentToString(state, 1), argumentToString(state, 2))
+		return 0
+	})
+}
+
+// SetPrintToConsoleFunction implements Engine
+func (engine *LuaEngine) SetPrintToConsoleFunction(function func(text string)) {
+	engine.setFunctionOnStates("printToConsole", func(state *lua.LState) int {
+		function(argumentToString(state, 1))
+		return 0
+	})
+}
+
+// SetPrintLineToConsoleFunction implements Engine
+func (engine *LuaEngine) SetPrintLineToConsoleFunction(function func(text string)) {
+	engine.setFunctionOnStates("printLineToConsole", func(state *lua.LState) int {
+		function(argumentToString(state, 1))
+		return 0
+	})
+}
+
+// SetGetCurrentGuildFunction implements Engine
+func (engine *LuaEngine) SetGetCurrentGuildFunction(function func() string) {
+	engine.setFunctionOnStates("getCurrentGuild", func(state *lua.LState) int {
+		state.Push(lua.LString(function()))
+		return 1
+	})
+}
+
+// SetGetCurrentChannelFunction implements Engine
+func (engine *LuaEngine) SetGetCurrentChannelFunction(function func() string) {
+	engine.setFunctionOnStates("getCurrentChannel", func(state *lua.LState) int {
+		state.Push(lua.LString(function()))
+		return 1
+	})
+}
+
+func (engine *LuaEngine) setFunctionOnStates(name string, function lua.LGFunction) {
+	for _, instance := range engine.loadedScripts() {
+		instance.lock.Lock()
+		instance.state.SetGlobal(name, instance.state.NewFunction(function))
+		instance.lock.Unlock()
+	}
+}
+
+// argumentToString converts the argument at the given position the same way
+// lua's tostring does.
+func argumentToString(state *lua.LState, position int) string {
+	return state.ToStringMeta(state.Get(position)).String()
+}
@@ -0,0 +1,12 @@
+package lua
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/cordless/scripting"
+	"github.com/Bios-Marcel/cordless/scripting/scriptingtest"
+)
+
+func TestLuaEngineConformance(t *testing.T) {
+	scriptingtest.TestEngine(t, func() scripting.Engine { return New() }, "test/conformance")
+}
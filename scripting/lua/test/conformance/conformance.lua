@@ -0,0 +1,107 @@
+function init()
+  storageSet("starts", (storageGet("starts") or 0) + 1)
+  storageSet("preferences", { theme = "dark", mentions = { "Marcel" } })
+  storageDelete("nonexistent")
+end
+
+function onReady(ready)
+  printLineToConsole("onReady:" .. ready.User.Username .. ":" .. #ready.Guilds .. ":" .. ready.Guilds[1].Name)
+
+  local preferences = storageGet("preferences")
+  printLineToConsole("storage:" .. storageGet("starts") .. ":" .. table.concat(storageList(), ",") .. ":" ..
+    preferences.theme .. ":" .. preferences.mentions[1])
+end
+
+function onMessageSend(text)
+  return string.upper(text)
+end
+
+function onMessageReceive(message)
+  if message.Content == "schedule" then
+    setTimeout(function(argument)
+      printLineToConsole("timeout:" .. argument)
+    end, 1, "value")
+
+    local runs = 0
+    local interval
+    interval = setInterval(function()
+      runs = runs + 1
+      printLineToConsole("interval:" .. runs)
+      if runs == 3 then
+        clearTimeout(interval)
+      end
+    end, 1)
+
+    clearTimeout(setTimeout(function()
+      printLineToConsole("cancelled")
+    end, 1))
+  elseif message.Content == "schedule late" then
+    setTimeout(function()
+      printLineToConsole("late")
+    end, 50)
+  elseif message.Content == "file access" then
+    printLineToConsole("fileAccess:" .. tostring(dofile ~= nil) .. ":" .. tostring(loadfile ~= nil))
+  else
+    printLineToConsole("onMessageReceive:" .. message.Content .. ":" .. message.Author.Username)
+  end
+end
+
+function onMessageEdit(message)
+  printLineToConsole("onMessageEdit:" .. message.Content .. ":" .. message.Author.Username)
+end
+
+function onMessageDelete(message)
+  error("failing on purpose")
+end
+
+function onMessageRender(message)
+  if message.Author.Bot then
+    return { text = "[bot output collapsed]" }
+  end
+
+  if message.Author.Username == "spammer" then
+    return { hidden = true }
+  end
+
+  local ticket = string.match(message.Content, "TICKET%-%d+")
+  if ticket then
+    return { tag = ticket, tagColor = "#ff0000" }
+  end
+end
+
+function onReactionAdd(reaction)
+  printLineToConsole("onReactionAdd:" .. reaction.Emoji.Name)
+end
+
+function onReactionRemove(reaction)
+  printLineToConsole("onReactionRemove:" .. reaction.Emoji.Name)
+end
+
+function onTypingStart(typingStart)
+  printLineToConsole("onTypingStart:" .. typingStart.UserID)
+end
+
+function onPresenceUpdate(presenceUpdate)
+  printLineToConsole("onPresenceUpdate:" .. presenceUpdate.Status .. ":" .. presenceUpdate.User.ID)
+end
+
+function onChannelSwitch(channel)
+  printToConsole("onChannelSwitch:" .. channel.Name .. ":" .. getCurrentGuild() .. ":" .. getCurrentChannel())
+  triggerNotification("Switched", channel.Name)
+end
+
+function onGuildJoin(guild)
+  printLineToConsole("onGuildJoin:" .. guild.Name)
+end
+
+function onGuildLeave(guild)
+  printLineToConsole("onGuildLeave:" .. guild.Name)
+end
+
+function onMemberJoin(member)
+  printLineToConsole("onMemberJoin:" .. member.User.Username)
+end
+
+function onMemberLeave(member)
+  printLineToConsole("onMemberLeave:" .. member.User.Username)
+end
@@ -0,0 +1,357 @@
+// Package scriptingtest offers a conformance test suite that every
+// scripting.Engine implementation has to pass. This makes sure that scripts
+// behave the same, no matter which language they have been written in.
+//
+// Each engine has to provide a directory containing a single script, which
+// implements the following contract by using the host API:
+//
+// init: Increments the stored number "starts" (defaulting to 0), stores
+// "preferences" as {theme: "dark", mentions: ["Marcel"]} and deletes the
+// key "nonexistent".
+//
+// onReady: Prints the lines "onReady:<User.Username>:<len(Guilds)>:
+// <Guilds[0].Name>" and "storage:<starts>:<storageList joined by ','>:
+// <preferences.theme>:<preferences.mentions[0]>".
+//
+// onMessageSend: Returns the text in upper case.
+//
+// onMessageReceive and onMessageEdit: Print the line
+// "<callback>:<Content>:<Author.Username>". However, if the content of a
+// received message is "schedule", onMessageReceive instead schedules a
+// timeout with a delay of 1ms that prints "timeout:<argument>", where the
+// argument "value" is passed via setTimeout, an interval with a delay of 1ms
+// that prints "interval:<run>" and clears itself after the third run, and a
+// timeout printing "cancelled", which is cleared right away. If the content
+// is "schedule late", a timeout with a delay of 50ms printing "late" is
+// scheduled instead. If the content is "file access", the line
+// "fileAccess:<dofile is defined>:<loadfile is defined>" is printed instead.
+//
+// onMessageDelete: Throws an error.
+//
+// onMessageRender: Returns {text: "[bot output collapsed]"} for messages by
+// bots, {hidden: true} for messages by the user "spammer" and
+// {tag: "<ticket>", tagColor: "#ff0000"} for messages containing a ticket
+// number such as "TICKET-123". Returns nothing otherwise.
+//
+// onReactionAdd and onReactionRemove: Print the line
+// "<callback>:<Emoji.Name>".
+//
+// onTypingStart: Prints the line "onTypingStart:<UserID>".
+//
+// onPresenceUpdate: Prints the line "onPresenceUpdate:<Status>:<User.ID>".
+//
+// onChannelSwitch: Calls printToConsole with "onChannelSwitch:<Name>:
+// <getCurrentGuild()>:<getCurrentChannel()>" and triggers a notification
+// with the title "Switched" and the channels name as the text.
+//
+// onGuildJoin and onGuildLeave: Print the line "<callback>:<Name>".
+//
+// onMemberJoin and onMemberLeave: Print the line
+// "<callback>:<User.Username>".
+package scriptingtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/scripting"
+)
+
+// TestEngine runs the conformance test suite against engines created by
+// newEngine. The scriptDirectory has to contain a script that implements
+// the contract described in the package documentation.
+func TestEngine(t *testing.T, newEngine func() scripting.Engine, scriptDirectory string) {
+	t.Run("no scripts", func(t *testing.T) {
+		engine := newEngine()
+		if err := engine.LoadScripts(filepath.Join(scriptDirectory, "does-not-exist")); err != nil {
+			t.Fatal("LoadScripts failed:", err)
+		}
+
+		if got := engine.OnMessageSend("unchanged"); got != "unchanged" {
+			t.Errorf("OnMessageSend() = %v, want unchanged", got)
+		}
+		if got := engine.OnMessageRender(&discordgo.Message{}); got != nil {
+			t.Errorf("OnMessageRender() = %v, want nil", got)
+		}
+		engine.OnReady(&discordgo.Ready{})
+		engine.OnMessageReceive(&discordgo.Message{})
+	})
+
+	storageDirectory, tempDirError := ioutil.TempDir("", "cordless-conformance-test")
+	if tempDirError != nil {
+		t.Fatal(tempDirError)
+	}
+	defer os.RemoveAll(storageDirectory)
+	storagePath := filepath.Join(storageDirectory, "script-storage.json")
+
+	for run := 1; run <= 2; run++ {
+		engine, output := loadEngine(t, newEngine, scriptDirectory, storagePath)
+
+		storageLine := fmt.Sprintf("storage:%d:preferences,starts:dark:Marcel", run)
+
+		tests := []struct {
+			name    string
+			trigger func()
+			want    []string
+		}{
+			{
+				name: "ready",
+				trigger: func() {
+					engine.OnReady(&discordgo.Ready{
+						User:   &discordgo.User{Username: "Marcel"},
+						Guilds: []*discordgo.Guild{{Name: "cordless"}, {Name: "other"}},
+					})
+				},
+				want: []string{"onReady:Marcel:2:cordless", storageLine},
+			}, {
+				name: "message receive",
+				trigger: func() {
+					engine.OnMessageReceive(&discordgo.Message{Content: "Hello", Author: &discordgo.User{Username: "Marcel"}})
+				},
+				want: []string{"onMessageReceive:Hello:Marcel"},
+			}, {
+				name: "no file access",
+				trigger: func() {
+					engine.OnMessageReceive(&discordgo.Message{Content: "file access", Author: &discordgo.User{Username: "Marcel"}})
+				},
+				want: []string{"fileAccess:false:false"},
+			}, {
+				name: "message edit",
+				trigger: func() {
+					engine.OnMessageEdit(&discordgo.Message{Content: "Hello!", Author: &discordgo.User{Username: "Marcel"}})
+				},
+				want: []string{"onMessageEdit:Hello!:Marcel"},
+			}, {
+				name: "erroring callback",
+				trigger: func() {
+					engine.OnMessageDelete(&discordgo.Message{Content: "Hello!"})
+				},
+				want: nil,
+			}, {
+				name:    "reaction add",
+				trigger: func() { engine.OnReactionAdd(&discordgo.MessageReaction{Emoji: discordgo.Emoji{Name: "👍"}}) },
+				want:    []string{"onReactionAdd:👍"},
+			}, {
+				name:    "reaction remove",
+				trigger: func() { engine.OnReactionRemove(&discordgo.MessageReaction{Emoji: discordgo.Emoji{Name: "👎"}}) },
+				want:    []string{"onReactionRemove:👎"},
+			}, {
+				name:    "typing start",
+				trigger: func() { engine.OnTypingStart(&discordgo.TypingStart{UserID: "1234"}) },
+				want:    []string{"onTypingStart:1234"},
+			}, {
+				name: "presence update",
+				trigger: func() {
+					engine.OnPresenceUpdate(&discordgo.PresenceUpdate{Presence: discordgo.Presence{
+						User:   &discordgo.User{ID: "1234"},
+						Status: discordgo.StatusIdle,
+					}})
+				},
+				want: []string{"onPresenceUpdate:idle:1234"},
+			}, {
+				name:    "channel switch",
+				trigger: func() { engine.OnChannelSwitch(&discordgo.Channel{Name: "general"}) },
+				want:    []string{"onChannelSwitch:general:guild-id:channel-id", "notification:Switched:general"},
+			}, {
+				name:    "guild join",
+				trigger: func() { engine.OnGuildJoin(&discordgo.Guild{Name: "cordless"}) },
+				want:    []string{"onGuildJoin:cordless"},
+			}, {
+				name:    "guild leave",
+				trigger: func() { engine.OnGuildLeave(&discordgo.Guild{Name: "cordless"}) },
+				want:    []string{"onGuildLeave:cordless"},
+			}, {
+				name:    "member join",
+				trigger: func() { engine.OnMemberJoin(&discordgo.Member{User: &discordgo.User{Username: "Marcel"}}) },
+				want:    []string{"onMemberJoin:Marcel"},
+			}, {
+				name:    "member leave",
+				trigger: func() { engine.OnMemberLeave(&discordgo.Member{User: &discordgo.User{Username: "Marcel"}}) },
+				want:    []string{"onMemberLeave:Marcel"},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				output.reset()
+				tt.trigger()
+				if got := output.get(); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("output = %q, want %q", got, tt.want)
+				}
+			})
+		}
+
+		t.Run("message send", func(t *testing.T) {
+			if got := engine.OnMessageSend("Hello"); got != "HELLO" {
+				t.Errorf("OnMessageSend() = %v, want HELLO", got)
+			}
+		})
+
+		renderTests := []struct {
+			name    string
+			message *discordgo.Message
+			want    *scripting.MessageRender
+		}{
+			{
+				name:    "render unchanged",
+				message: &discordgo.Message{Content: "Hello", Author: &discordgo.User{Username: "Marcel"}},
+				want:    nil,
+			}, {
+				name:    "render replaced text",
+				message: &discordgo.Message{Content: "Lots of noise", Author: &discordgo.User{Username: "Bot", Bot: true}},
+				want:    &scripting.MessageRender{Text: "[bot output collapsed]"},
+			}, {
+				name:    "render hidden",
+				message: &discordgo.Message{Content: "Buy this!", Author: &discordgo.User{Username: "spammer"}},
+				want:    &scripting.MessageRender{Hidden: true},
+			}, {
+				name:    "render tagged",
+				message: &discordgo.Message{Content: "See TICKET-123", Author: &discordgo.User{Username: "Marcel"}},
+				want: &scripting.MessageRender{Tags: []*scripting.MessageTag{
+					{Text: "TICKET-123", Color: "#ff0000"},
+				}},
+			},
+		}
+		for _, tt := range renderTests {
+			t.Run(tt.name, func(t *testing.T) {
+				if got := engine.OnMessageRender(tt.message); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("OnMessageRender() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+
+		t.Run("timers", func(t *testing.T) {
+			output.reset()
+			engine.OnMessageReceive(&discordgo.Message{Content: "schedule", Author: &discordgo.User{Username: "Marcel"}})
+
+			want := []string{"interval:1", "interval:2", "interval:3", "timeout:value"}
+			deadline := time.Now().Add(2 * time.Second)
+			for len(output.get()) < len(want) && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			// Give cleared timers the chance to misbehave.
+			time.Sleep(50 * time.Millisecond)
+
+			got := output.get()
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("output = %q, want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("reload", func(t *testing.T) {
+		reloadStoragePath := filepath.Join(storageDirectory, "reload-storage.json")
+		engine, output := loadEngine(t, newEngine, scriptDirectory, reloadStoragePath)
+		engine.OnMessageReceive(&discordgo.Message{Content: "schedule late", Author: &discordgo.User{Username: "Marcel"}})
+
+		_, reloadedOutput := reloadEngine(t, engine, scriptDirectory)
+		time.Sleep(100 * time.Millisecond)
+		if got := output.get(); len(got) != 0 {
+			t.Errorf("timers of unloaded scripts fired: %q", got)
+		}
+
+		engine.OnMessageReceive(&discordgo.Message{Content: "Hello", Author: &discordgo.User{Username: "Marcel"}})
+		want := []string{"onMessageReceive:Hello:Marcel"}
+		if got := reloadedOutput.get(); !reflect.DeepEqual(got, want) {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+	})
+
+	//Scripts are reloaded while events are handled on other goroutines.
+	//Running this with the race detector verifies the synchronization.
+	t.Run("reload while handling events", func(t *testing.T) {
+		engine, _ := loadEngine(t, newEngine, scriptDirectory, filepath.Join(storageDirectory, "concurrent-storage.json"))
+
+		done := make(chan struct{})
+		var waitGroup sync.WaitGroup
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					engine.OnMessageSend("Hello")
+					engine.OnMessageRender(&discordgo.Message{Content: "Buy this!", Author: &discordgo.User{Username: "spammer"}})
+				}
+			}
+		}()
+
+		for i := 0; i < 3; i++ {
+			reloadEngine(t, engine, scriptDirectory)
+		}
+		close(done)
+		waitGroup.Wait()
+
+		if got := engine.OnMessageSend("Hello"); got != "HELLO" {
+			t.Errorf("OnMessageSend() after reloading = %v, want HELLO", got)
+		}
+	})
+}
+
+// outputCollector records everything that scripts print. Since timers run on
+// different goroutines, access is synchronized.
+type outputCollector struct {
+	lock  sync.Mutex
+	lines []string
+}
+
+func (collector *outputCollector) add(line string) {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+	collector.lines = append(collector.lines, line)
+}
+
+func (collector *outputCollector) reset() {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+	collector.lines = nil
+}
+
+func (collector *outputCollector) get() []string {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+	return append([]string(nil), collector.lines...)
+}
+
+// loadEngine creates a new engine that uses the storage at the given path
+// and loads the scripts from the given directory.
+func loadEngine(t *testing.T, newEngine func() scripting.Engine, scriptDirectory, storagePath string) (scripting.Engine, *outputCollector) {
+	storage, loadError := scripting.LoadStorage(storagePath)
+	if loadError != nil {
+		t.Fatal("LoadStorage failed:", loadError)
+	}
+
+	engine := newEngine()
+	engine.SetStorage(storage)
+	return reloadEngine(t, engine, scriptDirectory)
+}
+
+// reloadEngine (re)loads the scripts from the given directory and sets up
+// the host functions, just like the application does.
+func reloadEngine(t *testing.T, engine scripting.Engine, scriptDirectory string) (scripting.Engine, *outputCollector) {
+	if err := engine.LoadScripts(scriptDirectory); err != nil {
+		t.Fatal("LoadScripts failed:", err)
+	}
+
+	output := &outputCollector{}
+	engine.SetPrintToConsoleFunction(output.add)
+	engine.SetPrintLineToConsoleFunction(output.add)
+	engine.SetTriggerNotificationFunction(func(title, text string) {
+		output.add("notification:" + title + ":" + text)
+	})
+	engine.SetGetCurrentGuildFunction(func() string { return "guild-id" })
+	engine.SetGetCurrentChannelFunction(func() string { return "channel-id" })
+
+	return engine, output
+}
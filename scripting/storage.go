@@ -0,0 +1,229 @@
+package scripting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Bios-Marcel/cordless/util/files"
+)
+
+const (
+	// MaxStorageKeyLength is the maximum amount of bytes a single key may
+	// consist of.
+	MaxStorageKeyLength = 256
+	// MaxStorageValueSize is the maximum amount of bytes a single JSON
+	// encoded value may consist of.
+	MaxStorageValueSize = 64 * 1024
+	// MaxStorageNamespaceSize is the maximum amount of bytes all keys and
+	// values of a single namespace may consist of in total.
+	MaxStorageNamespaceSize = 1024 * 1024
+)
+
+var (
+	// ErrStorageKeyInvalid means that the key was either empty or longer
+	// than MaxStorageKeyLength.
+	ErrStorageKeyInvalid = errors.New("storage keys must be between 1 and 256 bytes long")
+	// ErrStorageValueTooBig means that the value exceeded MaxStorageValueSize.
+	ErrStorageValueTooBig = errors.New("storage values mustn't be bigger than 64 KiB")
+	// ErrStorageNamespaceFull means that the value would have caused the
+	// namespace to exceed MaxStorageNamespaceSize.
+	ErrStorageNamespaceFull = errors.New("storage namespace can't hold more than 1 MiB")
+)
+
+// Storage is a persistent key-value store for scripts. All data is saved in
+// a single JSON file, but each script only gets access to its own namespace.
+// Every change is written to disk immediately.
+type Storage struct {
+	lock sync.Mutex
+	path string
+	data map[string]map[string]json.RawMessage
+	//loadError is set if the file couldn't be read. Since writing would
+	//overwrite the file, all changes are refused in that case.
+	loadError error
+}
+
+// StorageNamespace grants access to a single namespace of a Storage.
+type StorageNamespace struct {
+	storage *Storage
+	name    string
+}
+
+// LoadStorage reads the storage from the given file. If the file doesn't
+// exist yet, the storage starts out empty and the file will be created on
+// the first change. If the file can't be read, an empty storage is
+// returned alongside the error. A corrupt file is moved aside first, so
+// that it isn't overwritten by the next change. If that isn't possible or
+// the file can't be read at all, the returned storage refuses all changes.
+func LoadStorage(path string) (*Storage, error) {
+	storage := &Storage{
+		path: path,
+		data: make(map[string]map[string]json.RawMessage),
+	}
+
+	data, readError := ioutil.ReadFile(path)
+	if os.IsNotExist(readError) {
+		return storage, nil
+	}
+	if readError != nil {
+		storage.loadError = readError
+		return storage, readError
+	}
+
+	if len(data) > 0 {
+		if parseError := json.Unmarshal(data, &storage.data); parseError != nil {
+			storage.data = make(map[string]map[string]json.RawMessage)
+			corruptPath := path + ".corrupt-" + time.Now().Format("20060102-150405")
+			if renameError := os.Rename(path, corruptPath); renameError != nil {
+				storage.loadError = fmt.Errorf("%s, additionally the file couldn't be moved aside: %s", parseError, renameError)
+				return storage, storage.loadError
+			}
+			return storage, fmt.Errorf("%s, the file has been moved to %s", parseError, corruptPath)
+		}
+	}
+
+	return storage, nil
+}
+
+// Namespace returns a handle for the namespace with the given name. The
+// namespace is created on demand.
+func (storage *Storage) Namespace(name string) *StorageNamespace {
+	return &StorageNamespace{
+		storage: storage,
+		name:    name,
+	}
+}
+
+func (storage *Storage) persist() error {
+	if storage.loadError != nil {
+		return fmt.Errorf("the storage can't be changed, as it couldn't be loaded: %s", storage.loadError)
+	}
+
+	data, jsonError := json.Marshal(storage.data)
+	if jsonError != nil {
+		return jsonError
+	}
+
+	return files.WriteFileAtomically(storage.path, data, 0600)
+}
+
+// Get returns the JSON encoded value for the given key and whether the key
+// exists.
+func (namespace *StorageNamespace) Get(key string) (json.RawMessage, bool) {
+	namespace.storage.lock.Lock()
+	defer namespace.storage.lock.Unlock()
+
+	value, contains := namespace.storage.data[namespace.name][key]
+	return value, contains
+}
+
+// Set saves the JSON encoded value for the given key and writes the storage
+// to disk. If the key or value exceed the size limits or the storage can't
+// be written, nothing is changed.
+func (namespace *StorageNamespace) Set(key string, value json.RawMessage) error {
+	if len(key) == 0 || len(key) > MaxStorageKeyLength {
+		return ErrStorageKeyInvalid
+	}
+	if len(value) > MaxStorageValueSize {
+		return ErrStorageValueTooBig
+	}
+	if !json.Valid(value) {
+		return errors.New("storage values have to be valid JSON")
+	}
+
+	namespace.storage.lock.Lock()
+	defer namespace.storage.lock.Unlock()
+
+	values := namespace.storage.data[namespace.name]
+	newSize := namespaceSize(values) + len(key) + len(value)
+	if oldValue, contains := values[key]; contains {
+		newSize -= len(key) + len(oldValue)
+	}
+	if newSize > MaxStorageNamespaceSize {
+		return ErrStorageNamespaceFull
+	}
+
+	if values == nil {
+		values = make(map[string]json.RawMessage)
+		namespace.storage.data[namespace.name] = values
+	}
+	oldValue, contained := values[key]
+	values[key] = value
+
+	//The data has to be changed in order to be persisted, so it is reverted
+	//on failure, keeping memory and disk in sync.
+	if persistError := namespace.storage.persist(); persistError != nil {
+		if contained {
+			values[key] = oldValue
+		} else {
+			namespace.deleteInMemory(key)
+		}
+		return persistError
+	}
+
+	return nil
+}
+
+// Delete removes the given key and writes the storage to disk. Deleting a
+// key that doesn't exist is a no-op. If the storage can't be written,
+// nothing is changed.
+func (namespace *StorageNamespace) Delete(key string) error {
+	namespace.storage.lock.Lock()
+	defer namespace.storage.lock.Unlock()
+
+	oldValue, contains := namespace.storage.data[namespace.name][key]
+	if !contains {
+		return nil
+	}
+
+	namespace.deleteInMemory(key)
+	if persistError := namespace.storage.persist(); persistError != nil {
+		values := namespace.storage.data[namespace.name]
+		if values == nil {
+			values = make(map[string]json.RawMessage)
+			namespace.storage.data[namespace.name] = values
+		}
+		values[key] = oldValue
+		return persistError
+	}
+
+	return nil
+}
+
+// deleteInMemory removes the key without writing the storage to disk. Empty
+// namespaces are removed as well. The lock has to be held by the caller.
+func (namespace *StorageNamespace) deleteInMemory(key string) {
+	values := namespace.storage.data[namespace.name]
+	delete(values, key)
+	if len(values) == 0 {
+		delete(namespace.storage.data, namespace.name)
+	}
+}
+
+// Keys returns all keys of this namespace in alphabetical order.
+func (namespace *StorageNamespace) Keys() []string {
+	namespace.storage.lock.Lock()
+	defer namespace.storage.lock.Unlock()
+
+	values := namespace.storage.data[namespace.name]
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func namespaceSize(values map[string]json.RawMessage) int {
+	var size int
+	for key, value := range values {
+		size += len(key) + len(value)
+	}
+	return size
+}
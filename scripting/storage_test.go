@@ -0,0 +1,190 @@
+package scripting
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	directory, tempDirError := ioutil.TempDir("", "cordless-storage-test")
+	if tempDirError != nil {
+		t.Fatal(tempDirError)
+	}
+	defer os.RemoveAll(directory)
+	path := filepath.Join(directory, "script-storage.json")
+
+	storage, loadError := LoadStorage(path)
+	if loadError != nil {
+		t.Fatalf("LoadStorage() error = %v", loadError)
+	}
+
+	first := storage.Namespace("first.js")
+	second := storage.Namespace("second.js")
+	if setError := first.Set("counter", json.RawMessage("1")); setError != nil {
+		t.Fatalf("Set() error = %v", setError)
+	}
+	if setError := first.Set("seen", json.RawMessage(`["a","b"]`)); setError != nil {
+		t.Fatalf("Set() error = %v", setError)
+	}
+	if _, contains := second.Get("counter"); contains {
+		t.Error("namespaces aren't separated")
+	}
+
+	storage, loadError = LoadStorage(path)
+	if loadError != nil {
+		t.Fatalf("LoadStorage() error = %v", loadError)
+	}
+	first = storage.Namespace("first.js")
+	if value, _ := first.Get("counter"); string(value) != "1" {
+		t.Errorf("Get() after reload = %s, want 1", value)
+	}
+	if keys := first.Keys(); !reflect.DeepEqual(keys, []string{"counter", "seen"}) {
+		t.Errorf("Keys() = %v, want [counter seen]", keys)
+	}
+
+	if deleteError := first.Delete("counter"); deleteError != nil {
+		t.Fatalf("Delete() error = %v", deleteError)
+	}
+	if _, contains := first.Get("counter"); contains {
+		t.Error("Delete() didn't remove the key")
+	}
+}
+
+func TestStorageLimits(t *testing.T) {
+	directory, tempDirError := ioutil.TempDir("", "cordless-storage-test")
+	if tempDirError != nil {
+		t.Fatal(tempDirError)
+	}
+	defer os.RemoveAll(directory)
+
+	storage, loadError := LoadStorage(filepath.Join(directory, "script-storage.json"))
+	if loadError != nil {
+		t.Fatalf("LoadStorage() error = %v", loadError)
+	}
+	namespace := storage.Namespace("limits.js")
+
+	bigValue := json.RawMessage(`"` + strings.Repeat("a", MaxStorageValueSize-2) + `"`)
+	tests := []struct {
+		name  string
+		key   string
+		value json.RawMessage
+		want  error
+	}{
+		{
+			name:  "empty key",
+			key:   "",
+			value: json.RawMessage("1"),
+			want:  ErrStorageKeyInvalid,
+		}, {
+			name:  "key too long",
+			key:   strings.Repeat("k", MaxStorageKeyLength+1),
+			value: json.RawMessage("1"),
+			want:  ErrStorageKeyInvalid,
+		}, {
+			name:  "value too big",
+			key:   "big",
+			value: json.RawMessage(`"` + strings.Repeat("a", MaxStorageValueSize) + `"`),
+			want:  ErrStorageValueTooBig,
+		}, {
+			name:  "value at the limit",
+			key:   "big",
+			value: bigValue,
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := namespace.Set(tt.key, tt.value); got != tt.want {
+				t.Errorf("Set() error = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var setError error
+	for i := 0; setError == nil && i < MaxStorageNamespaceSize/MaxStorageValueSize+1; i++ {
+		setError = namespace.Set("big"+strings.Repeat("g", i), bigValue)
+	}
+	if setError != ErrStorageNamespaceFull {
+		t.Errorf("Set() error = %v, want %v", setError, ErrStorageNamespaceFull)
+	}
+}
+
+func TestStorageFailures(t *testing.T) {
+	directory, tempDirError := ioutil.TempDir("", "cordless-storage-test")
+	if tempDirError != nil {
+		t.Fatal(tempDirError)
+	}
+	defer os.RemoveAll(directory)
+
+	corruptPath := filepath.Join(directory, "corrupt.json")
+	if writeError := ioutil.WriteFile(corruptPath, []byte("{"), 0600); writeError != nil {
+		t.Fatal(writeError)
+	}
+	storage, loadError := LoadStorage(corruptPath)
+	if loadError == nil {
+		t.Error("LoadStorage() error = nil for a corrupt file")
+	}
+	if storage == nil || len(storage.Namespace("a.js").Keys()) != 0 {
+		t.Fatal("LoadStorage() should return an empty storage for a corrupt file")
+	}
+
+	//The corrupt file mustn't be lost due to the next change.
+	if setError := storage.Namespace("a.js").Set("key", json.RawMessage("1")); setError != nil {
+		t.Fatalf("Set() error = %v", setError)
+	}
+	corruptFiles, _ := filepath.Glob(corruptPath + ".corrupt-*")
+	if len(corruptFiles) != 1 {
+		t.Fatalf("found %d moved corrupt files, want 1", len(corruptFiles))
+	}
+	if data, _ := ioutil.ReadFile(corruptFiles[0]); string(data) != "{" {
+		t.Errorf("corrupt file contains %q, want %q", data, "{")
+	}
+
+	//A file that can't be read mustn't be overwritten.
+	unreadablePath := filepath.Join(directory, "unreadable.json")
+	if mkdirError := os.Mkdir(unreadablePath, 0700); mkdirError != nil {
+		t.Fatal(mkdirError)
+	}
+	storage, loadError = LoadStorage(unreadablePath)
+	if loadError == nil {
+		t.Error("LoadStorage() error = nil for an unreadable file")
+	}
+	if setError := storage.Namespace("a.js").Set("key", json.RawMessage("1")); setError == nil {
+		t.Error("Set() error = nil, but the storage couldn't be loaded")
+	}
+	if value, contains := storage.Namespace("a.js").Get("key"); contains {
+		t.Errorf("Get() = %s after refused Set(), want nothing", value)
+	}
+	if stat, statError := os.Stat(unreadablePath); statError != nil || !stat.IsDir() {
+		t.Errorf("the unreadable file has been changed")
+	}
+
+	//Writing into a directory that doesn't exist always fails.
+	storage, loadError = LoadStorage(filepath.Join(directory, "missing", "storage.json"))
+	if loadError != nil {
+		t.Fatalf("LoadStorage() error = %v", loadError)
+	}
+	namespace := storage.Namespace("a.js")
+	if setError := namespace.Set("key", json.RawMessage("1")); setError == nil {
+		t.Fatal("Set() error = nil, but the storage can't be written")
+	}
+	if value, contains := namespace.Get("key"); contains {
+		t.Errorf("Get() = %s after failed Set(), want nothing", value)
+	}
+
+	storage.data["a.js"] = map[string]json.RawMessage{"key": json.RawMessage("1")}
+	if setError := namespace.Set("key", json.RawMessage("2")); setError == nil {
+		t.Fatal("Set() error = nil, but the storage can't be written")
+	}
+	if deleteError := namespace.Delete("key"); deleteError == nil {
+		t.Fatal("Delete() error = nil, but the storage can't be written")
+	}
+	if value, _ := namespace.Get("key"); string(value) != "1" {
+		t.Errorf("Get() = %s after failed changes, want 1", value)
+	}
+}
@@ -0,0 +1,102 @@
+package scripting
+
+import (
+	"sync"
+	"time"
+)
+
+// MinTimerInterval is the smallest delay allowed between two runs of a
+// repeating timer. This prevents scripts from accidentally hogging the
+// instance lock and therefore blocking all other callbacks.
+const MinTimerInterval = 10 * time.Millisecond
+
+// Timers manages the timers of a single script instance. It is the backing
+// implementation for the setTimeout, setInterval and clearTimeout functions
+// of all engines.
+//
+// All callbacks are run while holding the lock of the script instance. In
+// turn, Schedule and Cancel expect the caller to already hold that lock,
+// which is always the case when they are called from within a script.
+type Timers struct {
+	lock    sync.Locker
+	nextID  int
+	timers  map[int]*time.Timer
+	stopped bool
+}
+
+// NewTimers creates a Timers instance that synchronizes all callbacks via the
+// given lock.
+func NewTimers(lock sync.Locker) *Timers {
+	return &Timers{
+		lock:   lock,
+		nextID: 1,
+		timers: make(map[int]*time.Timer),
+	}
+}
+
+// Schedule runs the callback once after the given delay or, if repeat is
+// true, every time the delay has passed, until the timer gets cancelled. The
+// returned ID can be passed to Cancel and is never 0.
+func (timers *Timers) Schedule(delay time.Duration, repeat bool, callback func()) int {
+	if delay < 0 {
+		delay = 0
+	}
+	if repeat && delay < MinTimerInterval {
+		delay = MinTimerInterval
+	}
+
+	id := timers.nextID
+	timers.nextID++
+
+	if timers.stopped {
+		return id
+	}
+
+	timers.timers[id] = time.AfterFunc(delay, func() {
+		timers.lock.Lock()
+		defer timers.lock.Unlock()
+
+		// The timer might have been cancelled after it had already fired,
+		// but before we were able to acquire the lock.
+		timer, exists := timers.timers[id]
+		if !exists || timers.stopped {
+			return
+		}
+
+		if !repeat {
+			delete(timers.timers, id)
+		}
+
+		callback()
+
+		// The callback itself might have cancelled the timer.
+		if repeat && !timers.stopped {
+			if _, exists := timers.timers[id]; exists {
+				timer.Reset(delay)
+			}
+		}
+	})
+
+	return id
+}
+
+// Cancel stops the timer with the given ID. Cancelling a timer that has
+// already run or doesn't exist is a no-op.
+func (timers *Timers) Cancel(id int) {
+	timer, exists := timers.timers[id]
+	if exists {
+		timer.Stop()
+		delete(timers.timers, id)
+	}
+}
+
+// Stop cancels all pending timers and prevents any further timers from
+// being scheduled. This has to be called before a script gets unloaded. Just
+// like Cancel, this expects the caller to hold the lock.
+func (timers *Timers) Stop() {
+	timers.stopped = true
+	for id, timer := range timers.timers {
+		timer.Stop()
+		delete(timers.timers, id)
+	}
+}
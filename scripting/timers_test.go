@@ -0,0 +1,67 @@
+package scripting
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTimers(t *testing.T) {
+	var lock sync.Mutex
+	timers := NewTimers(&lock)
+	runs := make(chan string, 10)
+
+	lock.Lock()
+	timers.Schedule(0, false, func() { runs <- "once" })
+	var intervalID int
+	var intervalRuns int
+	intervalID = timers.Schedule(0, true, func() {
+		intervalRuns++
+		if intervalRuns == 2 {
+			timers.Cancel(intervalID)
+		}
+		runs <- "interval"
+	})
+	cancelledID := timers.Schedule(0, false, func() { runs <- "cancelled" })
+	timers.Cancel(cancelledID)
+	lock.Unlock()
+
+	counts := make(map[string]int)
+	timeout := time.After(2 * time.Second)
+	for counts["once"] < 1 || counts["interval"] < 2 {
+		select {
+		case run := <-runs:
+			counts[run]++
+		case <-timeout:
+			t.Fatalf("timers didn't run in time: %v", counts)
+		}
+	}
+
+	time.Sleep(3 * MinTimerInterval)
+	close(runs)
+	for run := range runs {
+		counts[run]++
+	}
+
+	if counts["once"] != 1 || counts["interval"] != 2 || counts["cancelled"] != 0 {
+		t.Errorf("unexpected runs: %v", counts)
+	}
+}
+
+func TestTimersStop(t *testing.T) {
+	var lock sync.Mutex
+	timers := NewTimers(&lock)
+	runs := make(chan struct{}, 10)
+
+	lock.Lock()
+	timers.Schedule(MinTimerInterval, false, func() { runs <- struct{}{} })
+	timers.Schedule(MinTimerInterval, true, func() { runs <- struct{}{} })
+	timers.Stop()
+	timers.Schedule(0, false, func() { runs <- struct{}{} })
+	lock.Unlock()
+
+	time.Sleep(3 * MinTimerInterval)
+	if len(runs) != 0 {
+		t.Errorf("%d timers ran after being stopped", len(runs))
+	}
+}
@@ -21,6 +21,9 @@ var (
 	chatview           = addScope("chatview", "Chatview", globalScope)
 	guildlist          = addScope("guildlist", "Guildlist", globalScope)
 	channeltree        = addScope("channeltree", "Channeltree", globalScope)
+	privatechats       = addScope("privatechats", "Private chats", globalScope)
+	commandview        = addScope("commandview", "Command view", globalScope)
+	reactionpicker     = addScope("reactionpicker", "Reaction picker", globalScope)
 
 	QuoteSelectedMessage = addShortcut("quote_selected_message", "Quote selected message",
 		chatview, tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModNone))
@@ -42,6 +45,22 @@ var (
 		chatview, tcell.NewEventKey(tcell.KeyDelete, 0, tcell.ModNone))
 	ViewSelectedMessageImages = addShortcut("view_selected_message_images", "View selected message's attached files",
 		chatview, tcell.NewEventKey(tcell.KeyRune, 'o', tcell.ModNone))
+	TogglePinSelectedMessage = addShortcut("toggle_pin_selected_message", "Pin or unpin the selected message",
+		chatview, tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone))
+	ShowPinnedMessages = addShortcut("show_pinned_messages", "Show the pinned messages of the current channel",
+		chatview, tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone))
+	ReactToSelectedMessage = addShortcut("react_to_selected_message", "Add or remove a reaction on the selected message",
+		chatview, tcell.NewEventKey(tcell.KeyRune, '+', tcell.ModNone))
+	ShowAuthorProfile = addShortcut("show_author_profile", "Show the profile of the selected message's author",
+		chatview, tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone))
+	ShowMentionedUserProfile = addShortcut("show_mentioned_user_profile", "Show the profile of a user mentioned in the selected message",
+		chatview, tcell.NewEventKey(tcell.KeyRune, 'U', tcell.ModNone))
+	ToggleBlockAuthor = addShortcut("toggle_block_author", "Block or unblock the selected message's author",
+		chatview, tcell.NewEventKey(tcell.KeyRune, 'b', tcell.ModNone))
+	ToggleIgnoreAuthor = addShortcut("toggle_ignore_author", "Ignore or unignore the selected message's author",
+		chatview, tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone))
+	JumpToMessage = addShortcut("jump_to_message", "Jump to the selected pin or the message it refers to",
+		chatview, tcell.NewEventKey(tcell.KeyEnter, rune(tcell.KeyEnter), tcell.ModNone))
 	ChatViewSelectionUp = addShortcut("selection_up", "Move selection up by one",
 		chatview, tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone))
 	ChatViewSelectionDown = addShortcut("selection_down", "Move selection down by one",
@@ -151,6 +170,17 @@ var (
 	ChannelTreeMarkRead = addShortcut("channel_mark_read", "Mark channel as read",
 		channeltree, tcell.NewEventKey(tcell.KeyCtrlR, rune(tcell.KeyCtrlR), tcell.ModCtrl))
 
+	PrivateChatsCreateGroup = addShortcut("create_group", "Create a group with friends",
+		privatechats, tcell.NewEventKey(tcell.KeyCtrlG, rune(tcell.KeyCtrlG), tcell.ModCtrl))
+	PrivateChatsEditGroup = addShortcut("edit_group", "Edit the selected group",
+		privatechats, tcell.NewEventKey(tcell.KeyCtrlE, rune(tcell.KeyCtrlE), tcell.ModCtrl))
+
+	SearchCommandHistory = addShortcut("search_command_history", "Search the command history backwards",
+		commandview, tcell.NewEventKey(tcell.KeyCtrlR, rune(tcell.KeyCtrlR), tcell.ModCtrl))
+
+	ShowReactionUsers = addShortcut("show_reaction_users", "Show who reacted with the selected emoji",
+		reactionpicker, tcell.NewEventKey(tcell.KeyCtrlL, rune(tcell.KeyCtrlL), tcell.ModCtrl))
+
 	scopes    []*Scope
 	Shortcuts []*Shortcut
 )
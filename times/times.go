@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Bios-Marcel/cordless/config"
@@ -35,6 +37,49 @@ func AreDatesTheSameDay(t1, t2 time.Time) bool {
 	return t1.Year() == t2.Year() && t1.YearDay() == t2.YearDay()
 }
 
+// ParseDuration works like time.ParseDuration, but additionally accepts
+// whole days, for example "7d". Days can't be combined with other units.
+func ParseDuration(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, parseError := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if parseError != nil {
+			return 0, fmt.Errorf("invalid duration %s", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// ParseExpiry returns the point in time described by the given value,
+// relative to now. Accepted are durations like "30m" or "2d", "today" for
+// the end of the current day and clock times like "17:00" or "until 17:00".
+// Clock times that have already passed today refer to tomorrow.
+func ParseExpiry(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if value == "today" {
+		year, month, day := now.Date()
+		return time.Date(year, month, day+1, 0, 0, 0, 0, now.Location()), nil
+	}
+
+	clockTime := strings.TrimSpace(strings.TrimPrefix(value, "until "))
+	if parsed, parseError := time.Parse("15:04", clockTime); parseError == nil {
+		year, month, day := now.Date()
+		expiry := time.Date(year, month, day, parsed.Hour(), parsed.Minute(), 0, 0, now.Location())
+		if !expiry.After(now) {
+			expiry = expiry.AddDate(0, 0, 1)
+		}
+		return expiry, nil
+	}
+
+	duration, parseError := ParseDuration(value)
+	if parseError != nil || duration <= 0 {
+		return time.Time{}, fmt.Errorf("invalid expiry %s, expected a duration like 30m, today or a time like 17:00", value)
+	}
+
+	return now.Add(duration), nil
+}
+
 type Duration time.Duration
 
 func (d Duration) MarshalJSON() ([]byte, error) {
@@ -106,3 +106,65 @@ func TestUnmarshalDuration(t *testing.T) {
 		t.Errorf("duration should've been '%v', but was '%v'", expected, duration)
 	}
 }
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "10m", want: 10 * time.Minute},
+		{value: "1h30m", want: 90 * time.Minute},
+		{value: "7d", want: 7 * 24 * time.Hour},
+		{value: "d", wantErr: true},
+		{value: "1d2h", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseDuration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2020, time.March, 10, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "30m", want: now.Add(30 * time.Minute)},
+		{value: "1d", want: now.Add(24 * time.Hour)},
+		{value: "today", want: time.Date(2020, time.March, 11, 0, 0, 0, 0, time.UTC)},
+		{value: "Today", want: time.Date(2020, time.March, 11, 0, 0, 0, 0, time.UTC)},
+		{value: "17:00", want: time.Date(2020, time.March, 10, 17, 0, 0, 0, time.UTC)},
+		{value: "until 17:00", want: time.Date(2020, time.March, 10, 17, 0, 0, 0, time.UTC)},
+		{value: "until 09:15", want: time.Date(2020, time.March, 11, 9, 15, 0, 0, time.UTC)},
+		{value: "14:30", want: time.Date(2020, time.March, 11, 14, 30, 0, 0, time.UTC)},
+		{value: "0s", wantErr: true},
+		{value: "-5m", wantErr: true},
+		{value: "until", wantErr: true},
+		{value: "25:00", wantErr: true},
+		{value: "tomorrow", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseExpiry(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseExpiry() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -561,6 +561,14 @@ func Escape(text string) string {
 	return nonEscapePattern.ReplaceAllString(text, "$1[]")
 }
 
+// StripTags removes all color and region tags from the given text and
+// unescapes escaped tags. This is the inverse of what the print functions of
+// this package display, for example when writing the text into a file.
+func StripTags(text string) string {
+	_, _, _, _, _, stripped, _ := decomposeString(text, true, true)
+	return stripped
+}
+
 // iterateString iterates through the given string one printed character at a
 // time. For each such character, the callback function is called with the
 // Unicode code points of the character (the first rune and any combining runes
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// RefreshMessages reloads the messages of the selected channel, so that
+// changes to the blocked or ignored users take effect. This can be called
+// from any goroutine.
+func (window *Window) RefreshMessages() {
+	go func() {
+		window.chatView.Lock()
+		defer window.chatView.Unlock()
+
+		if window.selectedChannel == nil {
+			return
+		}
+
+		window.QueueUpdateDrawSynchronized(func() {
+			//The messages have already been loaded when selecting the
+			//channel, therefore they are taken from the cache.
+			messages, loadError := window.messageLoader.LoadMessages(window.selectedChannel)
+			if loadError != nil {
+				window.ShowErrorDialog(loadError.Error())
+				return
+			}
+			discordutil.SortMessagesByTimestamp(messages)
+			window.chatView.ReloadMessages(messages)
+		})
+	}()
+}
+
+// SetUserBlocked blocks or unblocks the given user and updates the state
+// without waiting for discord to send the relationship events. The messages
+// are refreshed once the relationship event arrives.
+func (window *Window) SetUserBlocked(user *discordgo.User, blocked bool) error {
+	state := window.session.State
+	if blocked {
+		if blockError := window.session.RelationshipUserBlock(user.ID); blockError != nil {
+			return blockError
+		}
+		discordutil.AddOrUpdateRelationship(state, &discordgo.Relationship{
+			ID:   user.ID,
+			Type: discordgo.RelationTypeBlocked,
+			User: user,
+		})
+	} else {
+		if unblockError := window.session.RelationshipDelete(user.ID); unblockError != nil {
+			return unblockError
+		}
+		discordutil.RemoveRelationship(state, user.ID)
+	}
+
+	return nil
+}
+
+// toggleBlocked asks whether the given user should be blocked or unblocked,
+// depending on whether they are currently blocked.
+func (window *Window) toggleBlocked(user *discordgo.User) {
+	if user.ID == window.session.State.User.ID {
+		return
+	}
+
+	if window.session.State.User.Bot {
+		window.ShowErrorDialog("Bots can't block users due to Discord API restrictions.")
+		return
+	}
+
+	blocked := discordutil.IsBlocked(window.session.State, user)
+	question := fmt.Sprintf("Do you really want to block %s?", tviewutil.Escape(user.String()))
+	if blocked {
+		question = fmt.Sprintf("Do you really want to unblock %s?", tviewutil.Escape(user.String()))
+	}
+
+	window.ShowDialog(config.GetTheme().PrimitiveBackgroundColor, question, func(button string) {
+		if button != "Yes" {
+			return
+		}
+
+		//The request mustn't block the UI thread.
+		go func() {
+			if blockError := window.SetUserBlocked(user, !blocked); blockError != nil {
+				window.app.QueueUpdateDraw(func() {
+					window.ShowErrorDialog(blockError.Error())
+				})
+			}
+		}()
+	}, "Yes", "No")
+}
+
+// toggleIgnored ignores the given user everywhere. If the user is already
+// ignored everywhere or in the given guild, they aren't ignored in either
+// anymore.
+func (window *Window) toggleIgnored(user *discordgo.User, guildID string) {
+	if user.ID == window.session.State.User.ID {
+		return
+	}
+
+	if config.Current.IsUserIgnored(user.ID, guildID) {
+		config.Current.UnignoreUser(user.ID, "")
+		if guildID != "" {
+			config.Current.UnignoreUser(user.ID, guildID)
+		}
+	} else {
+		config.Current.IgnoreUser(user.ID, user.String(), "")
+	}
+
+	window.persistIgnoredUsers()
+}
+
+// persistIgnoredUsers saves the changed ignored users and reloads the
+// messages of the selected channel.
+func (window *Window) persistIgnoredUsers() {
+	if persistError := config.PersistConfig(); persistError != nil {
+		window.ShowErrorDialog(fmt.Sprintf("Error saving ignored users: %s", persistError.Error()))
+	}
+
+	window.RefreshMessages()
+}
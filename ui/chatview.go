@@ -15,6 +15,7 @@ import (
 
 	"github.com/Bios-Marcel/cordless/config"
 	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/scripting"
 	"github.com/Bios-Marcel/cordless/shortcuts"
 	"github.com/Bios-Marcel/cordless/times"
 	"github.com/Bios-Marcel/cordless/ui/tviewutil"
@@ -72,12 +73,37 @@ type ChatView struct {
 
 	showSpoilerContent map[string]bool
 	formattedMessages  map[string]string
+	// hiddenMessages contains the messages that have been hidden by the
+	// render handler. They are kept, so that they can still be shown if
+	// they become visible due to an update.
+	hiddenMessages map[string]*discordgo.Message
 
 	onMessageAction func(message *discordgo.Message, event *tcell.EventKey) *tcell.EventKey
+	onMessageRender func(message *discordgo.Message) *scripting.MessageRender
 }
 
 // NewChatView constructs a new ready to use ChatView.
 func NewChatView(state *discordgo.State, ownUserID string) *ChatView {
+	chatView := newChatView(state, ownUserID)
+
+	if chatView.shortenLinks {
+		chatView.shortener = linkshortener.NewShortener(config.Current.ShortenerPort)
+		go func() {
+			shortenerError := chatView.shortener.Start()
+			if shortenerError != nil {
+				//Disable shortening in case of start failure.
+				chatView.shortenLinks = false
+			}
+		}()
+	}
+
+	return chatView
+}
+
+// newChatView constructs a ChatView without starting the link shortener.
+// This allows additional views, such as the pinned messages, to share the
+// shortener of the main view instead of trying to start a second one.
+func newChatView(state *discordgo.State, ownUserID string) *ChatView {
 	chatView := ChatView{
 		data:             make([]*discordgo.Message, 0, 100),
 		internalTextView: tview.NewTextView(),
@@ -94,20 +120,10 @@ func NewChatView(state *discordgo.State, ownUserID string) *ChatView {
 		shortenLinks:         config.Current.ShortenLinks,
 		shortenWithExtension: config.Current.ShortenWithExtension,
 		formattedMessages:    make(map[string]string),
+		hiddenMessages:       make(map[string]*discordgo.Message),
 		Mutex:                &sync.Mutex{},
 	}
 
-	if chatView.shortenLinks {
-		chatView.shortener = linkshortener.NewShortener(config.Current.ShortenerPort)
-		go func() {
-			shortenerError := chatView.shortener.Start()
-			if shortenerError != nil {
-				//Disable shortening in case of start failure.
-				chatView.shortenLinks = false
-			}
-		}()
-	}
-
 	chatView.internalTextView.SetOnBlur(func() {
 		chatView.selectionMode = false
 		chatView.ClearSelection()
@@ -170,6 +186,10 @@ func NewChatView(state *discordgo.State, ownUserID string) *ChatView {
 			if chatView.selection > 0 && chatView.selection < len(chatView.data) &&
 				shortcuts.ToggleSelectedMessageSpoilers.Equals(event) {
 				message := chatView.data[chatView.selection]
+				//Toggling spoilers mustn't reveal the content of placeholders.
+				if discordutil.IsBlocked(chatView.state, message.Author) || chatView.isIgnored(message) {
+					return nil
+				}
 				messageID := message.ID
 				currentValue, contains := chatView.showSpoilerContent[messageID]
 				if contains {
@@ -177,7 +197,7 @@ func NewChatView(state *discordgo.State, ownUserID string) *ChatView {
 				} else {
 					chatView.showSpoilerContent[messageID] = true
 				}
-				chatView.formattedMessages[messageID] = chatView.formatMessage(message)
+				chatView.formattedMessages[messageID], _ = chatView.formatMessage(message)
 				chatView.Reprint()
 				return nil
 			}
@@ -212,6 +232,13 @@ func (chatView *ChatView) SetOnMessageAction(onMessageAction func(message *disco
 	chatView.onMessageAction = onMessageAction
 }
 
+// SetOnMessageRender sets the handler that decides whether a message is
+// displayed differently than usual. The handler is called once per message
+// format, as the result is cached.
+func (chatView *ChatView) SetOnMessageRender(onMessageRender func(message *discordgo.Message) *scripting.MessageRender) {
+	chatView.onMessageRender = onMessageRender
+}
+
 func intToString(value int) string {
 	return strconv.FormatInt(int64(value), 10)
 }
@@ -233,15 +260,100 @@ func (chatView *ChatView) GetPrimitive() tview.Primitive {
 }
 
 // UpdateMessage reformats the passed message, updates the cache and triggers
-// a reprint.
+// a reprint. If the message is supposed to be hidden now, it is removed. If
+// a hidden message is supposed to be visible now, it is inserted.
 func (chatView *ChatView) UpdateMessage(updatedMessage *discordgo.Message) {
 	for _, message := range chatView.data {
 		if message.ID == updatedMessage.ID {
-			chatView.formattedMessages[updatedMessage.ID] = chatView.formatMessage(updatedMessage)
-			chatView.Reprint()
+			//The placeholder doesn't depend on the content.
+			if discordutil.IsBlocked(chatView.state, updatedMessage.Author) || chatView.isIgnored(updatedMessage) {
+				return
+			}
+
+			formattedMessage, hidden := chatView.formatMessage(updatedMessage)
+			if hidden {
+				chatView.DeleteMessage(updatedMessage)
+				chatView.hideMessage(updatedMessage)
+			} else {
+				chatView.formattedMessages[updatedMessage.ID] = formattedMessage
+				chatView.Reprint()
+			}
+			return
+		}
+	}
+
+	if _, wasHidden := chatView.hiddenMessages[updatedMessage.ID]; !wasHidden {
+		return
+	}
+
+	formattedMessage, hidden := chatView.formatMessage(updatedMessage)
+	if hidden {
+		chatView.hiddenMessages[updatedMessage.ID] = updatedMessage
+		return
+	}
+
+	delete(chatView.hiddenMessages, updatedMessage.ID)
+	if chatView.insertMessage(updatedMessage) {
+		chatView.formattedMessages[updatedMessage.ID] = formattedMessage
+		chatView.Reprint()
+		chatView.refreshSelectionAndScrollToSelection()
+	}
+}
+
+// hideMessage remembers a message that has been hidden by the render
+// handler, so that UpdateMessage can show it again.
+func (chatView *ChatView) hideMessage(message *discordgo.Message) {
+	if chatView.hiddenMessages == nil {
+		chatView.hiddenMessages = make(map[string]*discordgo.Message)
+	}
+	chatView.hiddenMessages[message.ID] = message
+}
+
+// insertMessage inserts the message into the loaded messages, keeping them
+// ordered by their IDs. If the buffer is full, the oldest message is
+// dropped. If the message is older than all loaded messages in a full
+// buffer, it isn't inserted and false is returned. This doesn't reprint.
+func (chatView *ChatView) insertMessage(message *discordgo.Message) bool {
+	index := len(chatView.data)
+	for existingIndex, existing := range chatView.data {
+		if isSnowflakeBefore(message.ID, existing.ID) {
+			index = existingIndex
 			break
 		}
 	}
+
+	chatFull := len(chatView.data) >= chatView.bufferSize
+	if chatFull && index == 0 {
+		return false
+	}
+
+	chatView.data = append(chatView.data, nil)
+	copy(chatView.data[index+1:], chatView.data[index:])
+	chatView.data[index] = message
+	if chatView.selection >= index {
+		chatView.selection++
+	}
+
+	if chatFull {
+		idToDrop := chatView.data[0].ID
+		delete(chatView.showSpoilerContent, idToDrop)
+		delete(chatView.formattedMessages, idToDrop)
+		chatView.data = chatView.data[1:]
+		if chatView.selection > -1 {
+			chatView.selection--
+		}
+	}
+
+	return true
+}
+
+// isSnowflakeBefore checks whether the first ID has been created before the
+// second one. Snowflakes grow over time, so shorter ones are always older.
+func isSnowflakeBefore(first, second string) bool {
+	if len(first) != len(second) {
+		return len(first) < len(second)
+	}
+	return first < second
 }
 
 // DeleteMessage drops the message from the cache and triggers a reprint
@@ -291,20 +403,41 @@ func (chatView *ChatView) ClearViewAndCache() {
 	chatView.data = make([]*discordgo.Message, 0, 100)
 	chatView.showSpoilerContent = make(map[string]bool)
 	chatView.formattedMessages = make(map[string]string)
+	chatView.hiddenMessages = make(map[string]*discordgo.Message)
 	chatView.selection = -1
 	chatView.internalTextView.Clear()
 	chatView.SetTitle("")
 }
 
 // addMessageInternal prints a new message to the textview or triggers a
-// rerender. It also takes the blocked relation into consideration.
+// rerender. It also takes the blocked relation, ignored users and the
+// render handler into consideration. A date delimiter is only printed if
+// the message is actually shown.
 func (chatView *ChatView) addMessageInternal(message *discordgo.Message) {
 	isBlocked := discordutil.IsBlocked(chatView.state, message.Author)
+	isIgnored := !isBlocked && chatView.isIgnored(message)
 
-	if !config.Current.ShowPlaceholderForBlockedMessages && isBlocked {
+	if !config.Current.ShowPlaceholderForBlockedMessages && (isBlocked || isIgnored) {
 		return
 	}
 
+	formattedMessage, messageAlreadyFormatted := chatView.formattedMessages[message.ID]
+	if !messageAlreadyFormatted {
+		if isBlocked {
+			formattedMessage = chatView.messagePartsToColouredString(message.Timestamp, "Blocked user", "Blocked message")
+		} else if isIgnored {
+			formattedMessage = chatView.messagePartsToColouredString(message.Timestamp, "Ignored user", "Ignored message")
+		} else {
+			var hidden bool
+			formattedMessage, hidden = chatView.formatMessage(message)
+			if hidden {
+				chatView.hideMessage(message)
+				return
+			}
+		}
+		chatView.formattedMessages[message.ID] = formattedMessage
+	}
+
 	chatFull := len(chatView.data) >= chatView.bufferSize
 	if chatFull {
 		idToDrop := chatView.data[0].ID
@@ -324,20 +457,13 @@ func (chatView *ChatView) addMessageInternal(message *discordgo.Message) {
 		chatView.data = append(chatView.data, message)
 	}
 
-	formattedMessage, messageAlreadyFormatted := chatView.formattedMessages[message.ID]
-	if !messageAlreadyFormatted {
-		if isBlocked {
-			formattedMessage = chatView.messagePartsToColouredString(message.Timestamp, "Blocked user", "Blocked message")
-		} else {
-			formattedMessage = chatView.formatMessage(message)
-		}
-		chatView.formattedMessages[message.ID] = formattedMessage
-	}
-
 	if chatFull {
 		chatView.Reprint()
 	} else {
-		fmt.Fprint(chatView.internalTextView, "\n[\""+intToString(len(chatView.data)-1)+"\"]"+formattedMessage)
+		newIndex := len(chatView.data) - 1
+		fmt.Fprint(chatView.internalTextView,
+			chatView.createDateDelimiterIfNecessary(chatView.data, newIndex)+
+				"\n[\""+intToString(newIndex)+"\"]"+formattedMessage)
 	}
 }
 
@@ -345,17 +471,6 @@ func (chatView *ChatView) addMessageInternal(message *discordgo.Message) {
 func (chatView *ChatView) AddMessage(message *discordgo.Message) {
 	wasScrolledToTheEnd := chatView.internalTextView.IsScrolledToEnd()
 
-	newMessageTime, _ := message.Timestamp.Parse()
-	newMessageTimeLocal := newMessageTime.Local()
-	if len(chatView.data) > 0 {
-		previousMessageTime, _ := chatView.data[len(chatView.data)-1].Timestamp.Parse()
-		if !times.AreDatesTheSameDay(previousMessageTime.Local(), newMessageTimeLocal) {
-			fmt.Fprint(chatView.internalTextView, chatView.createDateDelimiter(newMessageTimeLocal.Format(chatView.format)))
-		}
-	} else {
-		fmt.Fprint(chatView.internalTextView, chatView.createDateDelimiter(newMessageTimeLocal.Format(chatView.format)))
-	}
-
 	chatView.addMessageInternal(message)
 	chatView.refreshSelectionAndScrollToSelection()
 	if wasScrolledToTheEnd {
@@ -390,15 +505,6 @@ func (chatView *ChatView) createDateDelimiterIfNecessary(messages []*discordgo.M
 	return ""
 }
 
-// printDateDelimiterIfNecessary prints a date delimiter if the message is the
-// first message or if the dates are different between the current a
-func (chatView *ChatView) printDateDelimiterIfNecessary(messages []*discordgo.Message, index int) {
-	delimiter := chatView.createDateDelimiterIfNecessary(messages, index)
-	if delimiter != "" {
-		fmt.Fprint(chatView.internalTextView, delimiter)
-	}
-}
-
 // Reprint clears the internal TextView and prints all currently cached
 // messages into the internal TextView again. This will not actually cause a
 // redraw in the user interface. This would still only be done by
@@ -424,11 +530,57 @@ func (chatView *ChatView) Reprint() {
 	chatView.internalTextView.SetText(newContent.String())
 }
 
-func (chatView *ChatView) formatMessage(message *discordgo.Message) string {
+// formatMessage formats the message for display. If a render handler has
+// been set, it might replace the text, add tags or hide the message. The
+// second return value indicates whether the message should be hidden.
+func (chatView *ChatView) formatMessage(message *discordgo.Message) (string, bool) {
+	var render *scripting.MessageRender
+	if chatView.onMessageRender != nil {
+		render = chatView.onMessageRender(message)
+	}
+
+	if render == nil {
+		return chatView.messagePartsToColouredString(
+			message.Timestamp,
+			chatView.formatMessageAuthor(message),
+			chatView.formatMessageText(message)), false
+	}
+
+	if render.Hidden {
+		return "", true
+	}
+
+	var messageText string
+	if render.Text != "" {
+		messageText = tviewutil.Escape(render.Text)
+	} else {
+		messageText = chatView.formatMessageText(message)
+	}
+
 	return chatView.messagePartsToColouredString(
 		message.Timestamp,
-		chatView.formatMessageAuthor(message),
-		chatView.formatMessageText(message))
+		formatMessageTags(render.Tags)+chatView.formatMessageAuthor(message),
+		messageText), false
+}
+
+// formatMessageTags renders each tag as a bracketed label in its respective
+// color. Unknown or missing colors fall back to the primary text color.
+func formatMessageTags(tags []*scripting.MessageTag) string {
+	var tagBuilder strings.Builder
+	for _, tag := range tags {
+		color := tcell.GetColor(tag.Color)
+		if tag.Color == "" || color == tcell.ColorDefault {
+			color = config.GetTheme().PrimaryTextColor
+		}
+
+		tagBuilder.WriteRune('[')
+		tagBuilder.WriteString(tviewutil.ColorToHex(color))
+		tagBuilder.WriteRune(']')
+		tagBuilder.WriteString(tviewutil.Escape("[" + tag.Text + "]"))
+		tagBuilder.WriteRune(' ')
+	}
+
+	return tagBuilder.String()
 }
 
 func (chatView *ChatView) formatMessageAuthor(message *discordgo.Message) string {
@@ -463,6 +615,15 @@ func (chatView *ChatView) formatMessageText(message *discordgo.Message) string {
 	} else if message.Type == discordgo.MessageTypeChannelNameChange {
 		return "[" + tviewutil.ColorToHex(config.GetTheme().InfoMessageColor) + "]changed the channel name to " + message.Content + "."
 	} else if message.Type == discordgo.MessageTypeChannelPinnedMessage {
+		if message.MessageReference != nil && message.MessageReference.MessageID != "" {
+			reference := message.MessageReference
+			channelID := reference.ChannelID
+			if channelID == "" {
+				channelID = message.ChannelID
+			}
+			return "[" + tviewutil.ColorToHex(config.GetTheme().InfoMessageColor) + "]pinned a message: " +
+				discordutil.GetMessageLink(reference.GuildID, channelID, reference.MessageID)
+		}
 		return "[" + tviewutil.ColorToHex(config.GetTheme().InfoMessageColor) + "]pinned a message."
 	} else if message.Type == discordgo.MessageTypeRecipientAdd {
 		return "[" + tviewutil.ColorToHex(config.GetTheme().InfoMessageColor) + "]added " + message.Mentions[0].Username + " to the group."
@@ -1051,6 +1212,20 @@ func (chatView *ChatView) ClearSelection() {
 	chatView.refreshSelectionAndScrollToSelection()
 }
 
+// SelectMessage selects the message with the given ID and scrolls to it. If
+// the message isn't loaded into the view, false is returned.
+func (chatView *ChatView) SelectMessage(messageID string) bool {
+	for index, message := range chatView.data {
+		if message.ID == messageID {
+			chatView.selection = index
+			chatView.refreshSelectionAndScrollToSelection()
+			return true
+		}
+	}
+
+	return false
+}
+
 // SignalSelectionDeleted notifies the ChatView that its currently selected
 // message doesn't exist anymore, moving the selection up by a row if possible.
 func (chatView *ChatView) SignalSelectionDeleted() {
@@ -1059,16 +1234,30 @@ func (chatView *ChatView) SignalSelectionDeleted() {
 	}
 }
 
+// isIgnored checks whether the author of the message has been ignored
+// globally or in the guild the message has been sent in.
+func (chatView *ChatView) isIgnored(message *discordgo.Message) bool {
+	return config.Current.IsUserIgnored(message.Author.ID, getMessageGuildID(chatView.state, message))
+}
+
+// ReloadMessages discards all previously formatted messages before setting
+// the given messages. This is necessary if the criteria for hiding messages,
+// such as blocked or ignored users, have changed.
+func (chatView *ChatView) ReloadMessages(messages []*discordgo.Message) {
+	chatView.formattedMessages = make(map[string]string)
+	chatView.SetMessages(messages)
+}
+
 // SetMessages defines all currently displayed messages. Parsing and
 // manipulation of single message elements happens in this function.
 func (chatView *ChatView) SetMessages(messages []*discordgo.Message) {
 	chatView.data = make([]*discordgo.Message, 0, len(messages))
+	chatView.hiddenMessages = make(map[string]*discordgo.Message)
 	chatView.internalTextView.Clear()
 
 	wasScrolledToTheEnd := chatView.internalTextView.IsScrolledToEnd()
 
-	for index, message := range messages {
-		chatView.printDateDelimiterIfNecessary(messages, index)
+	for _, message := range messages {
 		chatView.addMessageInternal(message)
 	}
 
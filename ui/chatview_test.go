@@ -1,12 +1,15 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/Bios-Marcel/discordgo"
 
 	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/scripting"
 	_ "github.com/Bios-Marcel/cordless/syntax"
+	"github.com/Bios-Marcel/cordless/tview"
 	"github.com/Bios-Marcel/cordless/ui/tviewutil"
 )
 
@@ -457,6 +460,25 @@ func TestChatView_formatMessageText(t *testing.T) {
 			},
 			want:     "Hello \n[a:owo[]( https://cdn.discordapp.com/emojis/123 )",
 			chatView: defaultChatView,
+		}, {
+			name: "pinned message notice",
+			input: &discordgo.Message{
+				Type: discordgo.MessageTypeChannelPinnedMessage,
+			},
+			want:     "[" + tviewutil.ColorToHex(config.GetTheme().InfoMessageColor) + "]pinned a message.",
+			chatView: defaultChatView,
+		}, {
+			name: "pinned message notice with reference",
+			input: &discordgo.Message{
+				Type:      discordgo.MessageTypeChannelPinnedMessage,
+				ChannelID: "2",
+				MessageReference: &discordgo.MessageReference{
+					GuildID:   "1",
+					MessageID: "3",
+				},
+			},
+			want:     "[" + tviewutil.ColorToHex(config.GetTheme().InfoMessageColor) + "]pinned a message: https://discordapp.com/channels/1/2/3",
+			chatView: defaultChatView,
 		},
 	}
 	for _, tt := range tests {
@@ -540,3 +562,216 @@ func Test_removeLeadingWhitespaceInCode(t *testing.T) {
 		})
 	}
 }
+
+func TestChatView_formatMessageWithRender(t *testing.T) {
+	message := &discordgo.Message{
+		ID:      "1",
+		Content: "original",
+		Author:  &discordgo.User{Username: "Marcel"},
+	}
+	tests := []struct {
+		name       string
+		render     *scripting.MessageRender
+		wantHidden bool
+		contains   []string
+		excludes   []string
+	}{
+		{
+			name:     "no render result",
+			render:   nil,
+			contains: []string{"original"},
+		}, {
+			name:       "hidden",
+			render:     &scripting.MessageRender{Hidden: true},
+			wantHidden: true,
+		}, {
+			name:     "replaced text is escaped",
+			render:   &scripting.MessageRender{Text: "[collapsed]"},
+			contains: []string{"[collapsed[]"},
+			excludes: []string{"original"},
+		}, {
+			name: "colored tag",
+			render: &scripting.MessageRender{Tags: []*scripting.MessageTag{
+				{Text: "TICKET-1", Color: "#ff0000"},
+			}},
+			contains: []string{"[#ff0000][TICKET-1[] ", "original"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatView := &ChatView{
+				showSpoilerContent: make(map[string]bool),
+				state:              &discordgo.State{},
+				onMessageRender: func(*discordgo.Message) *scripting.MessageRender {
+					return tt.render
+				},
+			}
+			got, hidden := chatView.formatMessage(message)
+			if hidden != tt.wantHidden {
+				t.Errorf("ChatView.formatMessage() hidden = %v, want %v", hidden, tt.wantHidden)
+			}
+			for _, expected := range tt.contains {
+				if !strings.Contains(got, expected) {
+					t.Errorf("ChatView.formatMessage() = '%s', should contain '%s'", got, expected)
+				}
+			}
+			for _, unexpected := range tt.excludes {
+				if strings.Contains(got, unexpected) {
+					t.Errorf("ChatView.formatMessage() = '%s', shouldn't contain '%s'", got, unexpected)
+				}
+			}
+		})
+	}
+}
+
+func TestChatView_SelectMessage(t *testing.T) {
+	chatView := &ChatView{
+		internalTextView: tview.NewTextView(),
+		selection:        -1,
+		data: []*discordgo.Message{
+			{ID: "1"},
+			{ID: "2"},
+		},
+	}
+
+	if !chatView.SelectMessage("2") {
+		t.Error("ChatView.SelectMessage() = false for a loaded message")
+	}
+	if chatView.selection != 1 {
+		t.Errorf("ChatView.selection = %d, want 1", chatView.selection)
+	}
+
+	if chatView.SelectMessage("3") {
+		t.Error("ChatView.SelectMessage() = true for a message that isn't loaded")
+	}
+	if chatView.selection != 1 {
+		t.Errorf("ChatView.selection = %d after failed selection, want 1", chatView.selection)
+	}
+}
+
+func TestChatView_ReloadMessagesWithIgnoredUsers(t *testing.T) {
+	previousConfig := config.Current
+	defer func() {
+		config.Current = previousConfig
+	}()
+
+	messages := []*discordgo.Message{
+		{ID: "1", GuildID: "10", Content: "visible", Author: &discordgo.User{ID: "100", Username: "Marcel"}},
+		{ID: "2", GuildID: "10", Content: "annoying", Author: &discordgo.User{ID: "200", Username: "Troll"}},
+	}
+	tests := []struct {
+		name            string
+		showPlaceholder bool
+		ignoredUsers    []*config.IgnoredUser
+		wantCount       int
+		contains        []string
+		excludes        []string
+	}{
+		{
+			name:      "nobody ignored",
+			wantCount: 2,
+			contains:  []string{"visible", "annoying"},
+		}, {
+			name:         "ignored in other guild",
+			ignoredUsers: []*config.IgnoredUser{{UserID: "200", GuildID: "11"}},
+			wantCount:    2,
+			contains:     []string{"visible", "annoying"},
+		}, {
+			name:         "ignored without placeholder",
+			ignoredUsers: []*config.IgnoredUser{{UserID: "200", GuildID: "10"}},
+			wantCount:    1,
+			contains:     []string{"visible"},
+			excludes:     []string{"annoying", "Ignored message"},
+		}, {
+			name:            "ignored globally with placeholder",
+			showPlaceholder: true,
+			ignoredUsers:    []*config.IgnoredUser{{UserID: "200"}},
+			wantCount:       2,
+			contains:        []string{"visible", "Ignored message"},
+			excludes:        []string{"annoying", "Troll"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Current = &config.Config{
+				ShowPlaceholderForBlockedMessages: tt.showPlaceholder,
+			}
+
+			chatView := NewChatView(discordgo.NewState(), "0")
+			//Formatting the messages once before, makes sure that the
+			//previous formatting isn't reused.
+			chatView.SetMessages(messages)
+			config.Current.IgnoredUsers = tt.ignoredUsers
+			chatView.ReloadMessages(messages)
+
+			if len(chatView.data) != tt.wantCount {
+				t.Errorf("len(ChatView.data) = %d, want %d", len(chatView.data), tt.wantCount)
+			}
+			text := chatView.internalTextView.GetText(true)
+			for _, expected := range tt.contains {
+				if !strings.Contains(text, expected) {
+					t.Errorf("ChatView text = '%s', should contain '%s'", text, expected)
+				}
+			}
+			for _, unexpected := range tt.excludes {
+				if strings.Contains(text, unexpected) {
+					t.Errorf("ChatView text = '%s', shouldn't contain '%s'", text, unexpected)
+				}
+			}
+		})
+	}
+}
+
+func TestChatView_HiddenMessages(t *testing.T) {
+	previousConfig := config.Current
+	defer func() {
+		config.Current = previousConfig
+	}()
+	config.Current = &config.Config{}
+
+	author := &discordgo.User{ID: "100", Username: "Marcel"}
+	messages := []*discordgo.Message{
+		{ID: "1", Content: "first", Timestamp: "2020-03-10T12:00:00+00:00", Author: author},
+		{ID: "2", Content: "hide second", Timestamp: "2020-03-11T12:00:00+00:00", Author: author},
+		{ID: "3", Content: "third", Timestamp: "2020-03-12T12:00:00+00:00", Author: author},
+	}
+
+	chatView := NewChatView(discordgo.NewState(), "0")
+	chatView.SetOnMessageRender(func(message *discordgo.Message) *scripting.MessageRender {
+		return &scripting.MessageRender{Hidden: strings.HasPrefix(message.Content, "hide")}
+	})
+
+	//A hidden message mustn't leave behind the delimiter of its date.
+	chatView.SetMessages(messages[:2])
+	text := chatView.internalTextView.GetText(true)
+	if strings.Contains(text, "2020-03-11") || strings.Contains(text, "second") {
+		t.Errorf("ChatView text = '%s', shouldn't contain the hidden message", text)
+	}
+	chatView.AddMessage(&discordgo.Message{ID: "4", Content: "hide fourth", Timestamp: "2020-03-13T12:00:00+00:00", Author: author})
+	chatView.AddMessage(messages[2])
+	text = chatView.internalTextView.GetText(true)
+	if strings.Contains(text, "2020-03-13") || strings.Contains(text, "fourth") {
+		t.Errorf("ChatView text = '%s', shouldn't contain the hidden message", text)
+	}
+
+	//The message becomes visible and has to be shown between the others.
+	chatView.UpdateMessage(&discordgo.Message{ID: "2", Content: "second", Timestamp: messages[1].Timestamp, Author: author})
+	gotIDs := make([]string, 0, len(chatView.data))
+	for _, message := range chatView.data {
+		gotIDs = append(gotIDs, message.ID)
+	}
+	if strings.Join(gotIDs, ",") != "1,2,3" {
+		t.Errorf("ChatView.data IDs = %v, want [1 2 3]", gotIDs)
+	}
+	text = chatView.internalTextView.GetText(true)
+	second, third := strings.Index(text, "second"), strings.Index(text, "third")
+	if second == -1 || third < second || !strings.Contains(text, "2020-03-11") {
+		t.Errorf("ChatView text = '%s', should contain the second message before the third", text)
+	}
+
+	//Hiding it again removes it once more.
+	chatView.UpdateMessage(messages[1])
+	if len(chatView.data) != 2 {
+		t.Errorf("len(ChatView.data) = %d after hiding, want 2", len(chatView.data))
+	}
+}
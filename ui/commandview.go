@@ -1,33 +1,54 @@
 package ui
 
 import (
+	"path/filepath"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/shortcuts"
 	"github.com/Bios-Marcel/cordless/tview"
+	"github.com/Bios-Marcel/cordless/ui/components"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
 	tcell "github.com/gdamore/tcell/v2"
 )
 
 const noHistoryIndexSelected = -1
 
 // CommandView contains a simple textview for output and an input field for
-// input. All commands are added to the history when confirmed via enter.
+// input. All commands are added to the history when confirmed via enter,
+// unless they contain secrets.
 type CommandView struct {
 	commandOutput *tview.TextView
 	commandInput  *Editor
+	// autocompleteView displays the candidates for tab completion.
+	autocompleteView *components.AutocompleteView
 
 	// commandHistoryIndex is the current index cycling through the history.
 	// -1 means that no index is selected.
 	commandHistoryIndex int
-	// commandHistory is just a flat array of text that the user has sent.
-	// The more recent ones are on the right side of the array.
-	commandHistory []string
+	// commandHistory contains the text that the user has sent.
+	commandHistory *commands.History
+
+	// historySearchActive is true while the history is being searched
+	// backwards for historySearchQuery.
+	historySearchActive bool
+	historySearchQuery  string
+	// historySearchIndex is the index of the current match or the length
+	// of the history if nothing has been found yet.
+	historySearchIndex int
+	// historySearchOriginalInput is restored if the search is cancelled.
+	historySearchOriginalInput string
 
 	onExecuteCommand func(command string)
+	// registeredCommands supplies the commands used for tab completion.
+	registeredCommands func() []commands.Command
 }
 
 // NewCommandView creates a new struct containing the components necessary
 // for a command view. It also contains the state for those components.
-func NewCommandView(app *tview.Application, onExecuteCommand func(command string)) *CommandView {
+func NewCommandView(app *tview.Application, history *commands.History, registeredCommands func() []commands.Command, onExecuteCommand func(command string)) *CommandView {
 	commandOutput := tview.NewTextView()
 	commandOutput.SetDynamicColors(true).
 		SetWordWrap(true).
@@ -41,14 +62,23 @@ func NewCommandView(app *tview.Application, onExecuteCommand func(command string
 		SetWrap(false).
 		SetWordWrap(false)
 
+	autocompleteView := components.NewAutocompleteView()
+	autocompleteView.
+		SetRoot(tview.NewTreeNode("")).
+		SetBorder(true).
+		SetBorderSides(false, true, false, true)
+	autocompleteView.SetVisible(false)
+
 	cmdView := &CommandView{
-		commandOutput: commandOutput,
-		commandInput:  commandInput,
+		commandOutput:    commandOutput,
+		commandInput:     commandInput,
+		autocompleteView: autocompleteView,
 
 		commandHistoryIndex: noHistoryIndexSelected,
-		commandHistory:      make([]string, 0),
+		commandHistory:      history,
 
-		onExecuteCommand: onExecuteCommand,
+		onExecuteCommand:   onExecuteCommand,
+		registeredCommands: registeredCommands,
 	}
 
 	commandInput.SetInputCapture(cmdView.handleInput)
@@ -79,7 +109,22 @@ func (cmdView *CommandView) SetInputCaptureForOutput(handler func(event *tcell.E
 }
 
 func (cmdView *CommandView) handleInput(event *tcell.EventKey) *tcell.EventKey {
+	if cmdView.historySearchActive {
+		if event = cmdView.handleHistorySearchInput(event); event == nil {
+			return nil
+		}
+	} else if shortcuts.SearchCommandHistory.Equals(event) {
+		cmdView.startHistorySearch()
+		return nil
+	}
+
 	if event.Modifiers() == tcell.ModNone {
+		if event.Key() == tcell.KeyTab {
+			replaceLength, values := completeCommandInput(cmdView.registeredCommands(), cmdView.commandInput.GetTextLeftOfCursor())
+			cmdView.commandInput.TriggerAutocompletion(replaceLength, values)
+			return nil
+		}
+
 		if event.Key() == tcell.KeyPgUp {
 			handler := cmdView.commandOutput.InputHandler()
 			handler(tcell.NewEventKey(tcell.KeyPgUp, 0, tcell.ModNone), nil)
@@ -104,30 +149,35 @@ func (cmdView *CommandView) handleInput(event *tcell.EventKey) *tcell.EventKey {
 				return nil
 			}
 
-			cmdView.onExecuteCommand(strings.TrimSpace(command))
+			command = strings.TrimSpace(command)
+			cmdView.onExecuteCommand(command)
 			cmdView.commandInput.SetText("")
-			cmdView.commandHistory = append(cmdView.commandHistory, command)
+			if !commands.ContainsSecret(cmdView.registeredCommands(), command) {
+				if historyError := cmdView.commandHistory.Add(command); historyError != nil {
+					commands.PrintError(cmdView, "Error saving command history", historyError.Error())
+				}
+			}
 
 			return nil
 		}
 
 		if event.Key() == tcell.KeyDown {
-			if cmdView.commandHistoryIndex > len(cmdView.commandHistory)-1 {
+			if cmdView.commandHistoryIndex > cmdView.commandHistory.Len()-1 {
 				cmdView.commandHistoryIndex = 0
 			} else {
 				cmdView.commandHistoryIndex++
 			}
 
-			if cmdView.commandHistoryIndex > len(cmdView.commandHistory)-1 {
+			if cmdView.commandHistoryIndex > cmdView.commandHistory.Len()-1 {
 				return nil
 			}
 
-			cmdView.commandInput.SetText(cmdView.commandHistory[cmdView.commandHistoryIndex])
+			cmdView.commandInput.SetText(cmdView.commandHistory.Get(cmdView.commandHistoryIndex))
 		}
 
 		if event.Key() == tcell.KeyUp {
 			if cmdView.commandHistoryIndex < 0 {
-				cmdView.commandHistoryIndex = len(cmdView.commandHistory) - 1
+				cmdView.commandHistoryIndex = cmdView.commandHistory.Len() - 1
 			} else {
 				cmdView.commandHistoryIndex--
 			}
@@ -136,7 +186,7 @@ func (cmdView *CommandView) handleInput(event *tcell.EventKey) *tcell.EventKey {
 				return nil
 			}
 
-			cmdView.commandInput.SetText(cmdView.commandHistory[cmdView.commandHistoryIndex])
+			cmdView.commandInput.SetText(cmdView.commandHistory.Get(cmdView.commandHistoryIndex))
 		}
 	}
 
@@ -157,6 +207,65 @@ func (cmdView *CommandView) handleInput(event *tcell.EventKey) *tcell.EventKey {
 	return event
 }
 
+func (cmdView *CommandView) startHistorySearch() {
+	cmdView.historySearchActive = true
+	cmdView.historySearchQuery = ""
+	cmdView.historySearchIndex = cmdView.commandHistory.Len()
+	cmdView.historySearchOriginalInput = cmdView.commandInput.GetText()
+	cmdView.commandInput.internalTextView.SetTitle("reverse-search: ")
+}
+
+// handleHistorySearchInput extends the search query while typing and
+// searches for older matches when the search shortcut is pressed again.
+// The search can be cancelled via escape. Any other key accepts the
+// current match and is handled as usual.
+func (cmdView *CommandView) handleHistorySearchInput(event *tcell.EventKey) *tcell.EventKey {
+	if shortcuts.SearchCommandHistory.Equals(event) {
+		cmdView.searchHistory(cmdView.historySearchIndex)
+		return nil
+	}
+
+	if shortcuts.DeleteLeft.Equals(event) {
+		if queryRunes := []rune(cmdView.historySearchQuery); len(queryRunes) > 0 {
+			cmdView.historySearchQuery = string(queryRunes[:len(queryRunes)-1])
+		}
+		cmdView.searchHistory(cmdView.commandHistory.Len())
+		return nil
+	}
+
+	if event.Key() == tcell.KeyRune && event.Modifiers()&(tcell.ModCtrl|tcell.ModAlt) == 0 {
+		cmdView.historySearchQuery += string(event.Rune())
+		//The current match is searched again, as it might still match.
+		cmdView.searchHistory(cmdView.historySearchIndex + 1)
+		return nil
+	}
+
+	cmdView.stopHistorySearch()
+	if event.Key() == tcell.KeyEscape {
+		cmdView.commandInput.SetText(cmdView.historySearchOriginalInput)
+		return nil
+	}
+
+	return event
+}
+
+func (cmdView *CommandView) searchHistory(before int) {
+	title := "reverse-search: " + tviewutil.Escape(cmdView.historySearchQuery)
+	if index := cmdView.commandHistory.Search(cmdView.historySearchQuery, before); index != -1 {
+		cmdView.historySearchIndex = index
+		cmdView.commandInput.SetText(cmdView.commandHistory.Get(index))
+	} else {
+		title = "failed " + title
+	}
+	cmdView.commandInput.internalTextView.SetTitle(title)
+}
+
+func (cmdView *CommandView) stopHistorySearch() {
+	cmdView.historySearchActive = false
+	cmdView.commandHistoryIndex = noHistoryIndexSelected
+	cmdView.commandInput.internalTextView.SetTitle("")
+}
+
 // GetCommandInputWidget returns the component that can be added to the layout
 // for the users command input.
 func (cmdView *CommandView) GetCommandInputWidget() *tview.TextView {
@@ -169,11 +278,20 @@ func (cmdView *CommandView) GetCommandOutputWidget() *tview.TextView {
 	return cmdView.commandOutput
 }
 
+// GetCommandAutocompleteWidget returns the component that displays the
+// candidates for tab completion. It can be added to the layout in between
+// the output and the input.
+func (cmdView *CommandView) GetCommandAutocompleteWidget() *components.AutocompleteView {
+	return cmdView.autocompleteView
+}
+
 // SetVisible sets the given visible state to both the input component and
-// the output component
+// the output component. The autocompletion is always hidden, as it is only
+// shown on demand.
 func (cmdView *CommandView) SetVisible(visible bool) {
 	cmdView.commandInput.internalTextView.SetVisible(visible)
 	cmdView.commandOutput.SetVisible(visible)
+	cmdView.autocompleteView.SetVisible(false)
 }
 
 // Write lets us implement the io.Writer interface. Tab characters will be
@@ -187,3 +305,86 @@ func (cmdView *CommandView) Write(p []byte) (n int, err error) {
 
 	return
 }
+
+// completeCommandInput finds the candidates for the word in front of the
+// cursor. The first word is completed using the names and aliases of all
+// commands, while the following words are completed using the Spec of the
+// command, if it has one. The returned length is the amount of characters
+// that the chosen candidate replaces.
+func completeCommandInput(registeredCommands []commands.Command, input string) (int, []*AutocompleteValue) {
+	//The current word starts after the last space that is neither quoted
+	//nor escaped.
+	runes := []rune(input)
+	var wordStart int
+	var quote rune
+	for index := 0; index < len(runes); index++ {
+		char := runes[index]
+		if quote == 0 && char == '\\' {
+			index++
+		} else if quote == 0 && (char == '"' || char == '\'' && (index == wordStart || runes[index-1] == ' ')) {
+			quote = char
+		} else if char == quote {
+			quote = 0
+		} else if quote == 0 && char == ' ' {
+			wordStart = index + 1
+		}
+	}
+	currentWord := string(runes[wordStart:])
+
+	//A quote that has been started is closed, so that the word can be
+	//parsed in order to get rid of quotes and escape sequences.
+	var prefix string
+	if quote != 0 {
+		currentWord += string(quote)
+	}
+	if parsedWord, parseError := commands.ParseCommand(currentWord); parseError == nil && len(parsedWord) > 0 {
+		prefix = parsedWord[0]
+	}
+	if quote != 0 {
+		currentWord = currentWord[:len(currentWord)-1]
+	}
+
+	var candidates []string
+	parameters, parseError := commands.ParseCommand(string(runes[:wordStart]))
+	//Input that can't be parsed can't be completed either.
+	if parseError != nil {
+		return 0, nil
+	}
+	if len(parameters) == 0 {
+		lowerPrefix := strings.ToLower(prefix)
+		for _, command := range registeredCommands {
+			for _, name := range append([]string{command.Name()}, command.Aliases()...) {
+				if strings.HasPrefix(strings.ToLower(name), lowerPrefix) {
+					candidates = append(candidates, name)
+				}
+			}
+		}
+		sort.Strings(candidates)
+	} else {
+		for _, command := range registeredCommands {
+			if specCommand, ok := command.(commands.SpecCommand); ok && commands.CommandEquals(command, parameters[0]) {
+				candidates = commands.Complete(specCommand.Spec(), append(parameters[1:], prefix))
+				break
+			}
+		}
+	}
+
+	values := make([]*AutocompleteValue, 0, len(candidates))
+	for _, candidate := range candidates {
+		insertValue := commands.QuoteParameter(candidate)
+		incomplete := strings.HasSuffix(candidate, string(filepath.Separator))
+		//Incomplete values stay unterminated, so that the completion can
+		//be continued within the quotes.
+		if incomplete && insertValue != candidate {
+			insertValue = strings.TrimSuffix(insertValue, "\"")
+		}
+
+		values = append(values, &AutocompleteValue{
+			RenderValue: tviewutil.Escape(candidate),
+			InsertValue: insertValue,
+			Incomplete:  incomplete,
+		})
+	}
+
+	return utf8.RuneCountInString(currentWord), values
+}
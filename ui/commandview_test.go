@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/Bios-Marcel/cordless/commands"
+)
+
+type testCommand struct {
+	spec *commands.Spec
+}
+
+func (cmd *testCommand) Execute(writer io.Writer, parameters []string) {}
+func (cmd *testCommand) PrintHelp(writer io.Writer)                    {}
+func (cmd *testCommand) Name() string                                  { return cmd.spec.Name }
+func (cmd *testCommand) Aliases() []string                             { return cmd.spec.Aliases }
+func (cmd *testCommand) Spec() *commands.Spec                          { return cmd.spec }
+
+func TestCompleteCommandInput(t *testing.T) {
+	registeredCommands := []commands.Command{
+		&testCommand{&commands.Spec{
+			Name:    "friends",
+			Aliases: []string{"friend"},
+			Subcommands: []*commands.Spec{
+				{
+					Name: "accept",
+					Arguments: []*commands.Argument{{
+						Name:      "user",
+						Completer: commands.ValuesCompleter("Marcel#7299", "Marcel Davis#0001"),
+					}},
+				},
+			},
+		}},
+		&testCommand{&commands.Spec{
+			Name: "file-send",
+			Arguments: []*commands.Argument{{
+				Name:      "path",
+				Variadic:  true,
+				Completer: commands.ValuesCompleter("/tmp/my files/", "/tmp/file.txt"),
+			}},
+		}},
+	}
+
+	tests := []struct {
+		name              string
+		input             string
+		wantReplaceLength int
+		wantValues        []*AutocompleteValue
+	}{
+		{
+			name:              "command names and aliases",
+			input:             "fri",
+			wantReplaceLength: 3,
+			wantValues: []*AutocompleteValue{
+				{RenderValue: "friend", InsertValue: "friend"},
+				{RenderValue: "friends", InsertValue: "friends"},
+			},
+		}, {
+			name:              "subcommand",
+			input:             "friend a",
+			wantReplaceLength: 1,
+			wantValues: []*AutocompleteValue{
+				{RenderValue: "accept", InsertValue: "accept"},
+			},
+		}, {
+			name:              "argument with spaces is quoted",
+			input:             "friends accept Marcel",
+			wantReplaceLength: 6,
+			wantValues: []*AutocompleteValue{
+				{RenderValue: "Marcel#7299", InsertValue: "Marcel#7299"},
+				{RenderValue: "Marcel Davis#0001", InsertValue: `"Marcel Davis#0001"`},
+			},
+		}, {
+			name:              "started quote",
+			input:             `friends accept "Marcel D`,
+			wantReplaceLength: 9,
+			wantValues: []*AutocompleteValue{
+				{RenderValue: "Marcel Davis#0001", InsertValue: `"Marcel Davis#0001"`},
+			},
+		}, {
+			name:              "started single quote",
+			input:             `friends accept 'Marcel D`,
+			wantReplaceLength: 9,
+			wantValues: []*AutocompleteValue{
+				{RenderValue: "Marcel Davis#0001", InsertValue: `"Marcel Davis#0001"`},
+			},
+		}, {
+			name:              "escaped space",
+			input:             `friends accept Marcel\ D`,
+			wantReplaceLength: 9,
+			wantValues: []*AutocompleteValue{
+				{RenderValue: "Marcel Davis#0001", InsertValue: `"Marcel Davis#0001"`},
+			},
+		}, {
+			name:              "incomplete value stays unterminated",
+			input:             "file-send /tmp/file.txt /tmp/m",
+			wantReplaceLength: 6,
+			wantValues: []*AutocompleteValue{
+				{RenderValue: "/tmp/my files/", InsertValue: `"/tmp/my files/`, Incomplete: true},
+			},
+		}, {
+			name:              "unknown command",
+			input:             "unknown ",
+			wantReplaceLength: 0,
+			wantValues:        []*AutocompleteValue{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replaceLength, values := completeCommandInput(registeredCommands, tt.input)
+			if replaceLength != tt.wantReplaceLength {
+				t.Errorf("completeCommandInput() replaceLength = %d, want %d", replaceLength, tt.wantReplaceLength)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("completeCommandInput() values = %v, want %v", values, tt.wantValues)
+			}
+		})
+	}
+}
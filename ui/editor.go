@@ -28,6 +28,9 @@ type Editor struct {
 	requestedHeight      int
 	autocompleteFrom     *femto.Loc
 
+	textChangeHandler func(text string)
+	lastText          string
+
 	// App is the tview Application this editor is used in. The reference is
 	// required to query the current bracketed paste state.
 	App *tview.Application
@@ -35,7 +38,9 @@ type Editor struct {
 
 func (editor *Editor) applyBuffer() {
 	editor.applyBufferWithoutAutocompletionCheck()
-	if config.Current.Autocomplete {
+	//An autocompletion that has been triggered manually is always closed
+	//again when typing, even if automatic autocompletion is disabled.
+	if config.Current.Autocomplete || editor.autocompleteFrom != nil {
 		editor.checkForAutocompletion()
 	}
 }
@@ -43,9 +48,10 @@ func (editor *Editor) applyBuffer() {
 func (editor *Editor) applyBufferWithoutAutocompletionCheck() {
 	selectionStart := editor.buffer.Cursor.CurSelection[0]
 	selectionEnd := editor.buffer.Cursor.CurSelection[1]
+	text := editor.buffer.String()
 
 	//Copy relevant buffer-state over to temporary buffer
-	editor.tempBuffer.Replace(editor.tempBuffer.Start(), editor.tempBuffer.End(), tviewutil.Escape(editor.buffer.String()))
+	editor.tempBuffer.Replace(editor.tempBuffer.Start(), editor.tempBuffer.End(), tviewutil.Escape(text))
 	editor.tempBuffer.Cursor.GotoLoc(editor.buffer.Cursor.Loc)
 	editor.tempBuffer.Cursor.SetSelectionStart(selectionStart)
 	editor.tempBuffer.Cursor.SetSelectionEnd(selectionEnd)
@@ -66,6 +72,15 @@ func (editor *Editor) applyBufferWithoutAutocompletionCheck() {
 	}
 
 	editor.internalTextView.SetText(editor.tempBuffer.String())
+
+	//Cursor movement and selection changes also reapply the buffer, but
+	//aren't of interest for anyone listening for text changes.
+	if text != editor.lastText {
+		editor.lastText = text
+		if editor.textChangeHandler != nil {
+			editor.textChangeHandler(text)
+		}
+	}
 }
 
 func (editor *Editor) checkForAutocompletion() {
@@ -596,6 +611,9 @@ func (editor *Editor) TriggerHeightRequestIfNecessary() {
 type AutocompleteValue struct {
 	RenderValue string
 	InsertValue string
+	// Incomplete values, such as directories, aren't followed by a space
+	// when inserted, so that the completion can be continued.
+	Incomplete bool
 }
 
 type Autocomplete struct {
@@ -613,8 +631,23 @@ func (editor *Editor) RegisterAutocomplete(firstRune rune, allowSpaces bool, val
 }
 
 func (editor *Editor) Autocomplete(value string) {
+	editor.replaceAutocompletion(value + " ")
+}
+
+// ApplyAutocompleteValue inserts the given value the same way as
+// Autocomplete does, but leaves out the trailing space for Incomplete
+// values.
+func (editor *Editor) ApplyAutocompleteValue(value *AutocompleteValue) {
+	if value.Incomplete {
+		editor.replaceAutocompletion(value.InsertValue)
+	} else {
+		editor.Autocomplete(value.InsertValue)
+	}
+}
+
+func (editor *Editor) replaceAutocompletion(text string) {
 	if editor.autocompleteFrom != nil {
-		editor.buffer.Replace(*editor.autocompleteFrom, editor.buffer.Cursor.Loc, value+" ")
+		editor.buffer.Replace(*editor.autocompleteFrom, editor.buffer.Cursor.Loc, text)
 		editor.autocompleteFrom = nil
 		//Not necessary, since you probably don't want to autocomplete any
 		//further after you've chosen a value.
@@ -622,6 +655,33 @@ func (editor *Editor) Autocomplete(value string) {
 	}
 }
 
+// TriggerAutocompletion offers the given values as replacement for the
+// replaceLength characters in front of the cursor. Unlike the autocompleters
+// registered via RegisterAutocomplete, this isn't bound to a certain
+// character and has to be triggered manually, for example by pressing tab.
+// A single value is inserted right away.
+func (editor *Editor) TriggerAutocompletion(replaceLength int, values []*AutocompleteValue) {
+	autocompleteFrom := editor.buffer.Cursor.Loc.Move(-replaceLength, editor.buffer)
+	editor.autocompleteFrom = &autocompleteFrom
+
+	if len(values) == 1 {
+		editor.ApplyAutocompleteValue(values[0])
+		values = nil
+	} else if len(values) == 0 {
+		editor.autocompleteFrom = nil
+	}
+
+	if editor.autocompleteValuesUpdateHandler != nil {
+		editor.autocompleteValuesUpdateHandler(values)
+	}
+}
+
+// GetTextLeftOfCursor returns the text between the start of the buffer and
+// the cursor.
+func (editor *Editor) GetTextLeftOfCursor() string {
+	return editor.buffer.Substr(editor.buffer.Start(), editor.buffer.Cursor.Loc)
+}
+
 // GetRequestedHeight returns the currently requested size.
 func (editor *Editor) GetRequestedHeight() int {
 	return editor.requestedHeight
@@ -633,6 +693,12 @@ func (editor *Editor) SetOnHeightChangeRequest(handler func(requestHeight int))
 	editor.heightRequestHandler = handler
 }
 
+// SetOnTextChange sets a handler that is called whenever the text inside of
+// the editor has changed, be it by typing or by calling SetText.
+func (editor *Editor) SetOnTextChange(handler func(text string)) {
+	editor.textChangeHandler = handler
+}
+
 // SetBackgroundColor sets the background color of the internal TextView
 func (editor *Editor) SetBackgroundColor(color tcell.Color) {
 	editor.internalTextView.SetBackgroundColor(color)
@@ -0,0 +1,271 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/Bios-Marcel/discordgo"
+	tcell "github.com/gdamore/tcell/v2"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/tview"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+// ShowCreateGroup shows a fullscreen popup that allows picking the friends
+// to create a new group with. The group shows up in the private chat list
+// once discord has sent the channel create event.
+func (window *Window) ShowCreateGroup() {
+	window.showUserPicker("Create group", "Create group", discordutil.GetFriends(window.session.State),
+		func(users []*discordgo.User) error {
+			recipientIDs := make([]string, 0, len(users))
+			for _, user := range users {
+				recipientIDs = append(recipientIDs, user.ID)
+			}
+			_, createError := discordutil.CreateGroupChannel(window.session, recipientIDs)
+			return createError
+		})
+}
+
+// ShowGroupSettings shows a fullscreen popup containing the members of the
+// given group and actions for managing it, such as renaming or leaving it.
+func (window *Window) ShowGroupSettings(channel *discordgo.Channel) {
+	groupView := tview.NewTextView()
+	groupView.SetDynamicColors(true)
+	groupView.SetWrap(true)
+	groupView.SetWordWrap(true)
+	groupView.SetBorder(true)
+	groupView.SetTitle(discordutil.GetPrivateChannelName(channel))
+	groupView.SetText(createGroupText(window.session.State, channel))
+
+	actionList := tview.NewList()
+	actionList.ShowSecondaryText(false)
+	actionList.SetBorder(true)
+	actionList.SetTitle("Actions")
+	actionList.SetTitleAlign(tview.AlignLeft)
+
+	container := tview.NewFlex().SetDirection(tview.FlexRow)
+	container.AddItem(groupView, 0, 1, false)
+	container.AddItem(actionList, 0, 0, true)
+
+	previousFocus := window.app.GetFocus()
+	closeSettings := func() {
+		window.app.SetRoot(window.rootContainer, true)
+		window.app.SetFocus(previousFocus)
+	}
+	//All changes show up via the channel and recipient events, therefore
+	//the popup is closed before doing anything.
+	addAction := func(label string, action func()) {
+		actionList.AddItem(label, "", 0, func() {
+			closeSettings()
+			action()
+		})
+	}
+
+	addAction("Rename", func() {
+		//Prompting blocks until the user is done, so it can't happen on the
+		//UI thread.
+		go func() {
+			name := PromptSingleLineInput(window.app, nil, "Renaming group", "Please enter the new name of the group.")
+			if name != "" {
+				window.editGroup(channel.ID, map[string]interface{}{"name": name})
+			}
+		}()
+	})
+	addAction("Change icon", func() {
+		go func() {
+			path := PromptSingleLineInput(window.app, nil, "Changing group icon", "Please enter the path of a PNG, JPEG or GIF image.")
+			if path == "" {
+				return
+			}
+
+			icon, loadError := discordutil.LoadImage(commands.ExpandPath(path))
+			if loadError != nil {
+				window.app.QueueUpdateDraw(func() {
+					window.ShowErrorDialog(loadError.Error())
+				})
+				return
+			}
+			window.editGroup(channel.ID, map[string]interface{}{"icon": icon})
+		}()
+	})
+	if channel.Icon != "" {
+		addAction("Remove icon", func() {
+			go window.editGroup(channel.ID, map[string]interface{}{"icon": nil})
+		})
+	}
+
+	addAction("Add friends", func() {
+		var friends []*discordgo.User
+	FRIEND_LOOP:
+		for _, friend := range discordutil.GetFriends(window.session.State) {
+			for _, recipient := range channel.Recipients {
+				if recipient.ID == friend.ID {
+					continue FRIEND_LOOP
+				}
+			}
+			friends = append(friends, friend)
+		}
+
+		window.showUserPicker("Add friends", "Add to group", friends, func(users []*discordgo.User) error {
+			for _, user := range users {
+				if addError := discordutil.AddGroupRecipient(window.session, channel.ID, user.ID); addError != nil {
+					return addError
+				}
+			}
+			return nil
+		})
+	})
+
+	//Only the owner may remove other members. Since the library doesn't
+	//know who owns the group, discord is left to check this.
+	addAction("Remove members", func() {
+		window.showUserPicker("Remove members", "Remove from group", channel.Recipients, func(users []*discordgo.User) error {
+			for _, user := range users {
+				if removeError := discordutil.RemoveGroupRecipient(window.session, channel.ID, user.ID); removeError != nil {
+					return removeError
+				}
+			}
+			return nil
+		})
+	})
+
+	addAction("Leave group", func() {
+		window.ShowDialog(config.GetTheme().PrimitiveBackgroundColor,
+			fmt.Sprintf("Do you really want to leave '%s'?", discordutil.GetPrivateChannelName(channel)), func(button string) {
+				if button != "Yes" {
+					return
+				}
+
+				go func() {
+					if _, leaveError := window.session.ChannelDelete(channel.ID); leaveError != nil {
+						window.app.QueueUpdateDraw(func() {
+							window.ShowErrorDialog(leaveError.Error())
+						})
+					}
+				}()
+			}, "Yes", "No")
+	})
+
+	//Two additional rows for the border.
+	container.ResizeItem(actionList, actionList.GetItemCount()+2, 0)
+	container.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			closeSettings()
+			return nil
+		}
+
+		return event
+	})
+
+	window.app.SetRoot(container, true)
+	window.app.SetFocus(actionList)
+}
+
+// editGroup applies the changes to the given group and shows a dialog in
+// case of an error. This has to be called outside of the UI thread.
+func (window *Window) editGroup(channelID string, changes map[string]interface{}) {
+	if _, editError := discordutil.EditChannel(window.session, channelID, changes); editError != nil {
+		window.app.QueueUpdateDraw(func() {
+			window.ShowErrorDialog(editError.Error())
+		})
+	}
+}
+
+// showUserPicker shows a fullscreen list of the given users. Users are
+// toggled on selection and onDone is called with all picked users after
+// selecting the last item, which is labeled confirmLabel. onDone is called
+// outside of the UI thread, as it usually sends requests to discord.
+func (window *Window) showUserPicker(title, confirmLabel string, users []*discordgo.User, onDone func(users []*discordgo.User) error) {
+	users = append([]*discordgo.User(nil), users...)
+	sort.Slice(users, func(a, b int) bool {
+		return users[a].String() < users[b].String()
+	})
+
+	userList := tview.NewList()
+	userList.ShowSecondaryText(false)
+	userList.SetBorder(true)
+	userList.SetTitle(title)
+	userList.SetTitleAlign(tview.AlignLeft)
+
+	previousFocus := window.app.GetFocus()
+	closePicker := func() {
+		window.app.SetRoot(window.rootContainer, true)
+		window.app.SetFocus(previousFocus)
+	}
+
+	if len(users) == 0 {
+		userList.AddItem("There are no users to pick from.", "", 0, closePicker)
+	}
+
+	picked := make([]bool, len(users))
+	for index, user := range users {
+		userIndex := index
+		userName := tviewutil.Escape(user.String())
+		userList.AddItem("[ ] "+userName, "", 0, func() {
+			picked[userIndex] = !picked[userIndex]
+			if picked[userIndex] {
+				userList.SetItemText(userIndex, "[x[] "+userName, "")
+			} else {
+				userList.SetItemText(userIndex, "[ ] "+userName, "")
+			}
+		})
+	}
+
+	if len(users) > 0 {
+		userList.AddItem(confirmLabel, "", 0, func() {
+			closePicker()
+
+			var pickedUsers []*discordgo.User
+			for index, user := range users {
+				if picked[index] {
+					pickedUsers = append(pickedUsers, user)
+				}
+			}
+			if len(pickedUsers) == 0 {
+				return
+			}
+
+			go func() {
+				if doneError := onDone(pickedUsers); doneError != nil {
+					window.app.QueueUpdateDraw(func() {
+						window.ShowErrorDialog(doneError.Error())
+					})
+				}
+			}()
+		})
+	}
+
+	userList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			closePicker()
+			return nil
+		}
+
+		return event
+	})
+
+	window.app.SetRoot(userList, true)
+	window.app.SetFocus(userList)
+}
+
+// createGroupText creates the formatted overview of a group, containing all
+// members with their presence.
+func createGroupText(state *discordgo.State, channel *discordgo.Channel) string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "[::b]%s[::-]\n\n", discordutil.GetPrivateChannelName(channel))
+
+	members := append([]*discordgo.User{state.User}, channel.Recipients...)
+	fmt.Fprintf(&buffer, "Members (%d):\n", len(members))
+	for _, member := range members {
+		status := discordutil.GetPresenceStatus(discordutil.GetPresence(state, "", member.ID))
+		fmt.Fprintf(&buffer, "  %s %s\n", discordutil.GetStatusSymbol(status), tviewutil.Escape(member.String()))
+	}
+
+	fmt.Fprintf(&buffer, "\nID: %s", channel.ID)
+
+	return buffer.String()
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestCreateGroupText(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "self", Username: "me", Discriminator: "0001"}
+	state.Settings = &discordgo.Settings{Status: discordgo.StatusOnline}
+
+	alice := &discordgo.User{ID: "alice", Username: "alice", Discriminator: "0002"}
+	bob := &discordgo.User{ID: "bob", Username: "[bob]", Discriminator: "0003"}
+	state.Presences = []*discordgo.Presence{{User: alice, Status: discordgo.StatusIdle}}
+
+	tests := []struct {
+		name    string
+		channel *discordgo.Channel
+		want    []string
+	}{
+		{
+			name:    "named group",
+			channel: &discordgo.Channel{ID: "group", Type: discordgo.ChannelTypeGroupDM, Name: "Gophers", Recipients: []*discordgo.User{alice, bob}},
+			want: []string{
+				"[::b]Gophers[::-]",
+				"Members (3):",
+				"[green]●[-] me#0001",
+				"[yellow]●[-] alice#0002",
+				"[gray]○[-] [bob[]#0003",
+				"ID: group",
+			},
+		}, {
+			name:    "unnamed group",
+			channel: &discordgo.Channel{ID: "group", Type: discordgo.ChannelTypeGroupDM, Recipients: []*discordgo.User{alice}},
+			want: []string{
+				"[::b]alice[::-]",
+				"Members (2):",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := createGroupText(state, tt.channel)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("createGroupText() = %q, should contain %q", got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/discordutil"
+)
+
+// GetNotes returns the notes on other users. In order to change a note,
+// SetUserNote has to be used.
+func (window *Window) GetNotes() *discordutil.Notes {
+	return window.notes
+}
+
+// SetUserNote changes the note on the given user. The note is always saved
+// locally, but bot accounts can't save it on discord. An empty note removes
+// the note. This can be called from any goroutine.
+func (window *Window) SetUserNote(userID, note string) error {
+	if setError := window.notes.Set(userID, note); setError != nil {
+		return setError
+	}
+	window.app.QueueUpdateDraw(window.userList.RefreshNote)
+
+	if window.session.State.User.Bot {
+		return nil
+	}
+
+	if syncError := window.session.UserNoteSet(userID, note); syncError != nil {
+		return fmt.Errorf("the note has only been saved locally: %s", syncError)
+	}
+
+	return nil
+}
+
+// editNote asks for a new note on the given user. Since there's no way to
+// edit the existing note in the prompt, leaving it empty keeps the note.
+func (window *Window) editNote(user *discordgo.User) {
+	//Prompting blocks until the user is done, so it can't happen on the UI
+	//thread.
+	go func() {
+		note := PromptSingleLineInput(window.app, nil, "Editing note on "+user.String(),
+			"Please enter the new note. Use the \"note\" command to remove a note.")
+		if note == "" {
+			return
+		}
+
+		if noteError := window.SetUserNote(user.ID, note); noteError != nil {
+			window.app.QueueUpdateDraw(func() {
+				window.ShowErrorDialog(noteError.Error())
+			})
+		}
+	}()
+}
+
+// mergeNotes adds the notes that discord sent with the ready event. This
+// can be called from any goroutine.
+func (window *Window) mergeNotes(notes map[string]string) {
+	if mergeError := window.notes.Merge(notes); mergeError != nil {
+		window.app.QueueUpdateDraw(func() {
+			commands.PrintError(window.commandView, "Error saving notes", mergeError.Error())
+		})
+	}
+}
+
+func (window *Window) registerNoteHandler() {
+	//Notes changed by other clients or by ourselves.
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.UserNoteUpdate) {
+		setError := window.notes.Set(event.ID, event.Note)
+		window.app.QueueUpdateDraw(func() {
+			if setError != nil {
+				commands.PrintError(window.commandView, "Error saving notes", setError.Error())
+			}
+			window.userList.RefreshNote()
+		})
+	})
+}
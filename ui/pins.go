@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Bios-Marcel/discordgo"
+	tcell "github.com/gdamore/tcell/v2"
+
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/shortcuts"
+	"github.com/Bios-Marcel/cordless/tview"
+)
+
+// CanManagePins checks whether the current user is allowed to pin and unpin
+// messages in the given channel. In private channels everyone is allowed to
+// do so, while guild channels require the permission to manage messages.
+func (window *Window) CanManagePins(channel *discordgo.Channel) bool {
+	if channel.GuildID == "" {
+		return true
+	}
+
+	return discordutil.HasPermission(channel.ID, window.session.State, discordgo.PermissionManageMessages)
+}
+
+// SetMessagePinned pins or unpins the message with the given ID inside of
+// the given channel.
+func (window *Window) SetMessagePinned(channel *discordgo.Channel, messageID string, pinned bool) error {
+	if !window.CanManagePins(channel) {
+		return errors.New("you don't have the permission to manage pins in this channel")
+	}
+
+	if pinned {
+		return window.session.ChannelMessagePin(channel.ID, messageID)
+	}
+
+	return window.session.ChannelMessageUnpin(channel.ID, messageID)
+}
+
+// togglePinnedState pins the message if it isn't pinned yet and unpins it
+// otherwise. Errors are shown in a dialog.
+func (window *Window) togglePinnedState(message *discordgo.Message) {
+	channel, stateError := window.session.State.Channel(message.ChannelID)
+	if stateError != nil {
+		window.ShowErrorDialog(fmt.Sprintf("Error changing pin: %s", stateError))
+		return
+	}
+
+	pinError := window.SetMessagePinned(channel, message.ID, !message.Pinned)
+	if pinError != nil {
+		window.ShowErrorDialog(fmt.Sprintf("Error changing pin: %s", pinError))
+		return
+	}
+
+	message.Pinned = !message.Pinned
+}
+
+// JumpToMessage focuses the chatview and selects the message with the given
+// ID. If the message isn't part of the currently loaded messages, an error is
+// shown instead.
+func (window *Window) JumpToMessage(messageID string) {
+	window.app.SetFocus(window.chatView.internalTextView)
+	if !window.chatView.SelectMessage(messageID) {
+		window.ShowErrorDialog("The message is too old to be shown in the chatview.")
+	}
+}
+
+// ShowPinnedMessages loads the pinned messages of the given channel and shows
+// them in a fullscreen view. Selecting a message jumps to it in the chatview
+// and closes the view, as does pressing escape.
+func (window *Window) ShowPinnedMessages(channel *discordgo.Channel) error {
+	pinnedMessages, loadError := window.session.ChannelMessagesPinned(channel.ID)
+	if loadError != nil {
+		return loadError
+	}
+	discordutil.SortMessagesByTimestamp(pinnedMessages)
+
+	pinsView := newChatView(window.session.State, window.session.State.User.ID)
+	pinsView.shortener = window.chatView.shortener
+	pinsView.shortenLinks = window.chatView.shortenLinks && window.chatView.shortener != nil
+	pinsView.SetOnMessageRender(window.renderMessageWithExtensionEngines)
+	if channel.GuildID != "" {
+		pinsView.SetTitle("Pinned messages in #" + channel.Name)
+	} else {
+		pinsView.SetTitle("Pinned messages in " + discordutil.GetPrivateChannelName(channel))
+	}
+
+	previousFocus := window.app.GetFocus()
+	closePins := func() {
+		window.app.SetRoot(window.rootContainer, true)
+		window.app.SetFocus(previousFocus)
+	}
+
+	pinsView.SetOnMessageAction(func(message *discordgo.Message, event *tcell.EventKey) *tcell.EventKey {
+		if shortcuts.JumpToMessage.Equals(event) {
+			window.app.SetRoot(window.rootContainer, true)
+			window.JumpToMessage(message.ID)
+			return nil
+		}
+
+		if shortcuts.TogglePinSelectedMessage.Equals(event) {
+			unpinError := window.SetMessagePinned(channel, message.ID, false)
+			if unpinError != nil {
+				pinsView.SetTitle(fmt.Sprintf("Error unpinning message: %s", unpinError))
+			} else {
+				for _, loadedMessage := range window.chatView.data {
+					if loadedMessage.ID == message.ID {
+						loadedMessage.Pinned = false
+						break
+					}
+				}
+				pinsView.DeleteMessage(message)
+				pinsView.SignalSelectionDeleted()
+				pinsView.refreshSelectionAndScrollToSelection()
+			}
+			return nil
+		}
+
+		return event
+	})
+
+	container := tview.NewFlex().SetDirection(tview.FlexRow)
+	container.AddItem(pinsView.GetPrimitive(), 0, 1, true)
+	container.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			closePins()
+			return nil
+		}
+
+		return event
+	})
+
+	pinsView.SetMessages(pinnedMessages)
+	if len(pinnedMessages) == 0 {
+		fmt.Fprint(pinsView.internalTextView, "There are no pinned messages in this channel.")
+	}
+
+	window.app.SetRoot(container, true)
+	window.app.SetFocus(pinsView.internalTextView)
+
+	return nil
+}
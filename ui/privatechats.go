@@ -103,7 +103,7 @@ func (privateList *PrivateChatList) AddOrUpdateChannel(channel *discordgo.Channe
 	for _, node := range privateList.chatsNode.GetChildren() {
 		referenceChannelID, ok := node.GetReference().(string)
 		if ok && referenceChannelID == channel.ID {
-			node.SetText(discordutil.GetPrivateChannelName(channel))
+			node.SetText(privateList.getChannelText(channel))
 			return
 		}
 	}
@@ -123,12 +123,12 @@ func (privateList *PrivateChatList) AddOrUpdateChannel(channel *discordgo.Channe
 }
 
 func (privateList *PrivateChatList) prependChannel(channel *discordgo.Channel) {
-	newChildren := append([]*tview.TreeNode{createPrivateChannelNode(channel)}, privateList.chatsNode.GetChildren()...)
+	newChildren := append([]*tview.TreeNode{privateList.createPrivateChannelNode(channel)}, privateList.chatsNode.GetChildren()...)
 	privateList.chatsNode.SetChildren(newChildren)
 }
 
 func (privateList *PrivateChatList) addChannel(channel *discordgo.Channel) {
-	newNode := createPrivateChannelNode(channel)
+	newNode := privateList.createPrivateChannelNode(channel)
 	if !readstate.HasBeenRead(channel, channel.LastMessageID) {
 		privateList.privateChannelStates[newNode] = unread
 		if tview.IsVtxxx {
@@ -140,12 +140,27 @@ func (privateList *PrivateChatList) addChannel(channel *discordgo.Channel) {
 	privateList.chatsNode.AddChild(newNode)
 }
 
-func createPrivateChannelNode(channel *discordgo.Channel) *tview.TreeNode {
-	channelNode := tview.NewTreeNode(discordutil.GetPrivateChannelName(channel))
+func (privateList *PrivateChatList) createPrivateChannelNode(channel *discordgo.Channel) *tview.TreeNode {
+	channelNode := tview.NewTreeNode(privateList.getChannelText(channel))
 	channelNode.SetReference(channel.ID)
 	return channelNode
 }
 
+// getChannelText returns the text for a channel node. Direct messages are
+// decorated with the presence of the recipient, while groups aren't.
+func (privateList *PrivateChatList) getChannelText(channel *discordgo.Channel) string {
+	channelName := discordutil.GetPrivateChannelName(channel)
+	if channel.Type != discordgo.ChannelTypeDM || len(channel.Recipients) == 0 {
+		return channelName
+	}
+
+	return withPresence(channelName, discordutil.GetPresence(privateList.state, "", channel.Recipients[0].ID))
+}
+
+func (privateList *PrivateChatList) getFriendText(user *discordgo.User) string {
+	return withPresence(discordutil.GetUserName(user), discordutil.GetPresence(privateList.state, "", user.ID))
+}
+
 // AddOrUpdateFriend either adds a friend or updates the node if it is
 // already present.
 func (privateList *PrivateChatList) AddOrUpdateFriend(user *discordgo.User) {
@@ -155,7 +170,7 @@ func (privateList *PrivateChatList) AddOrUpdateFriend(user *discordgo.User) {
 			channel, stateError := privateList.state.Channel(referenceChannelID)
 			if stateError == nil && channel.Type == discordgo.ChannelTypeDM {
 				if channel.Recipients[0].ID == user.ID {
-					node.SetText(discordutil.GetUserName(user))
+					node.SetText(privateList.getChannelText(channel))
 					return
 				}
 			}
@@ -165,7 +180,7 @@ func (privateList *PrivateChatList) AddOrUpdateFriend(user *discordgo.User) {
 	for _, node := range privateList.friendsNode.GetChildren() {
 		referenceUserID, ok := node.GetReference().(string)
 		if ok && referenceUserID == user.ID {
-			node.SetText(discordutil.GetUserName(user))
+			node.SetText(privateList.getFriendText(user))
 			return
 		}
 	}
@@ -174,7 +189,7 @@ func (privateList *PrivateChatList) AddOrUpdateFriend(user *discordgo.User) {
 }
 
 func (privateList *PrivateChatList) addFriend(user *discordgo.User) {
-	friendNode := tview.NewTreeNode(user.Username)
+	friendNode := tview.NewTreeNode(privateList.getFriendText(user))
 	friendNode.SetReference(user.ID)
 	privateList.friendsNode.AddChild(friendNode)
 }
@@ -208,6 +223,14 @@ func (privateList *PrivateChatList) RemoveChannel(channel *discordgo.Channel) {
 		}
 	}
 
+	privateList.chatsNode.SetChildren(newChildren)
+
+	//Friends are listed separately as long as there's no DM with them.
+	//Groups don't affect the friends list.
+	if channel.Type != discordgo.ChannelTypeDM || len(channel.Recipients) == 0 {
+		return
+	}
+
 	userID := channel.Recipients[0].ID
 
 	for _, relationship := range privateList.state.Relationships {
@@ -217,8 +240,6 @@ func (privateList *PrivateChatList) RemoveChannel(channel *discordgo.Channel) {
 			break
 		}
 	}
-
-	privateList.chatsNode.SetChildren(newChildren)
 }
 
 // MarkAsUnread marks the channel as unread, coloring it red.
@@ -323,6 +344,37 @@ func (privateList *PrivateChatList) MarkAsLoaded(channelID string) {
 	privateList.setNotificationCount(privateList.amountOfUnreadChannels())
 }
 
+// UpdatePresence refreshes the status of the given user in the list of chats
+// and the list of friends.
+func (privateList *PrivateChatList) UpdatePresence(userID string) {
+	for _, node := range privateList.chatsNode.GetChildren() {
+		referenceChannelID, ok := node.GetReference().(string)
+		if !ok {
+			continue
+		}
+
+		channel, stateError := privateList.state.Channel(referenceChannelID)
+		if stateError == nil && channel.Type == discordgo.ChannelTypeDM &&
+			len(channel.Recipients) > 0 && channel.Recipients[0].ID == userID {
+			node.SetText(privateList.getChannelText(channel))
+		}
+	}
+
+	for _, node := range privateList.friendsNode.GetChildren() {
+		referenceUserID, ok := node.GetReference().(string)
+		if !ok || referenceUserID != userID {
+			continue
+		}
+
+		for _, relationship := range privateList.state.Relationships {
+			if relationship.User != nil && relationship.User.ID == userID {
+				node.SetText(privateList.getFriendText(relationship.User))
+				break
+			}
+		}
+	}
+}
+
 // SetOnFriendSelect sets the handler that decides what happens when a friend
 // node gets selected.
 func (privateList *PrivateChatList) SetOnFriendSelect(handler func(userID string)) {
@@ -369,6 +421,18 @@ FRIEND_LOOP:
 	privateList.setNotificationCount(privateList.amountOfUnreadChannels())
 }
 
+// GetSelectedChannelID returns the ID of the currently selected private
+// channel. If a friend or nothing is selected, an empty string is returned.
+func (privateList *PrivateChatList) GetSelectedChannelID() string {
+	selectedNode := privateList.internalTreeView.GetCurrentNode()
+	if selectedNode == nil || selectedNode.GetParent() != privateList.chatsNode {
+		return ""
+	}
+
+	channelID, _ := selectedNode.GetReference().(string)
+	return channelID
+}
+
 // GetComponent returns the TreeView component that is used.
 // This component is the top-level container of this struct.
 func (privateList *PrivateChatList) GetComponent() *tview.TreeView {
@@ -0,0 +1,406 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/Bios-Marcel/discordgo"
+	"github.com/atotto/clipboard"
+	tcell "github.com/gdamore/tcell/v2"
+
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/tview"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+)
+
+const profileDateFormat = "2006-01-02"
+
+// profileAction is an entry in the list of actions of the profile popup.
+type profileAction struct {
+	label  string
+	action func() error
+	// closes decides whether the popup is closed before running the action.
+	// Errors of such actions are shown in a dialog instead of the popup.
+	closes bool
+}
+
+// ShowUserProfile shows a fullscreen popup containing information about the
+// given user and actions, such as sending a direct message. The guildID
+// decides which server specific information is shown and may be empty.
+func (window *Window) ShowUserProfile(user *discordgo.User, guildID string) {
+	profileView := tview.NewTextView()
+	profileView.SetDynamicColors(true)
+	profileView.SetWrap(true)
+	profileView.SetWordWrap(true)
+	profileView.SetBorder(true)
+
+	actionList := tview.NewList()
+	actionList.ShowSecondaryText(false)
+	actionList.SetBorder(true)
+	actionList.SetTitle("Actions")
+	actionList.SetTitleAlign(tview.AlignLeft)
+
+	container := tview.NewFlex().SetDirection(tview.FlexRow)
+	container.AddItem(profileView, 0, 1, false)
+	container.AddItem(actionList, 0, 0, true)
+
+	previousFocus := window.app.GetFocus()
+	closeProfile := func() {
+		window.app.SetRoot(window.rootContainer, true)
+		window.app.SetFocus(previousFocus)
+	}
+
+	var refresh func()
+	refresh = func() {
+		profileView.SetTitle(discordutil.GetUserName(user))
+		profileView.SetText(createProfileText(window.session.State, user, guildID, window.notes.Get(user.ID)))
+
+		actions := window.getProfileActions(user, guildID)
+		actionList.Clear()
+		for _, entry := range actions {
+			profileAction := entry
+			actionList.AddItem(profileAction.label, "", 0, func() {
+				if profileAction.closes {
+					closeProfile()
+					if actionError := profileAction.action(); actionError != nil {
+						window.ShowErrorDialog(actionError.Error())
+					}
+					return
+				}
+
+				if actionError := profileAction.action(); actionError != nil {
+					profileView.SetTitle(fmt.Sprintf("[%s]Error: %s",
+						tviewutil.ColorToHex(config.GetTheme().ErrorColor), tviewutil.Escape(actionError.Error())))
+					return
+				}
+				refresh()
+			})
+		}
+		//Two additional rows for the border.
+		container.ResizeItem(actionList, len(actions)+2, 0)
+	}
+
+	container.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyESC {
+			closeProfile()
+			return nil
+		}
+
+		return event
+	})
+
+	refresh()
+
+	window.app.SetRoot(container, true)
+	window.app.SetFocus(actionList)
+}
+
+// getProfileActions decides which actions are available for the given user,
+// depending on whether it's ourselves and our relationship with the user.
+// Ignoring the user in a specific guild is only offered if a guildID is
+// passed.
+func (window *Window) getProfileActions(user *discordgo.User, guildID string) []*profileAction {
+	var actions []*profileAction
+	isSelf := user.ID == window.session.State.User.ID
+
+	if !isSelf {
+		actions = append(actions, &profileAction{
+			label: "Send direct message",
+			action: func() error {
+				return window.OpenDirectMessage(user.ID)
+			},
+			closes: true,
+		})
+	}
+
+	actions = append(actions, &profileAction{
+		label: "Mention in message input",
+		action: func() error {
+			window.insertMention(user)
+			return nil
+		},
+		closes: true,
+	}, &profileAction{
+		label: "Copy ID",
+		action: func() error {
+			return clipboard.WriteAll(user.ID)
+		},
+		closes: true,
+	})
+
+	if !isSelf {
+		actions = append(actions, &profileAction{
+			label: "Edit note",
+			action: func() error {
+				window.editNote(user)
+				return nil
+			},
+			closes: true,
+		})
+		actions = append(actions, window.getIgnoreAction(user, "", "everywhere"))
+		//Users ignored everywhere can't be unignored in a single server.
+		if guildID != "" && !config.Current.IsUserIgnored(user.ID, "") {
+			actions = append(actions, window.getIgnoreAction(user, guildID, "in this server"))
+		}
+	}
+
+	//Relationships can't be managed with bot accounts and aren't possible
+	//with bots or ourselves.
+	if isSelf || user.Bot || window.session.State.User.Bot {
+		return actions
+	}
+
+	state := window.session.State
+	relationship := discordutil.GetRelationship(state, user.ID)
+	removeRelationship := func() error {
+		deleteError := window.session.RelationshipDelete(user.ID)
+		if deleteError == nil {
+			discordutil.RemoveRelationship(state, user.ID)
+		}
+		return deleteError
+	}
+	setRelationship := func(relationshipType int) {
+		discordutil.AddOrUpdateRelationship(state, &discordgo.Relationship{
+			ID:   user.ID,
+			Type: relationshipType,
+			User: user,
+		})
+	}
+
+	relationshipType := 0
+	if relationship != nil {
+		relationshipType = relationship.Type
+	}
+
+	switch relationshipType {
+	case discordgo.RelationTypeFriend:
+		actions = append(actions, &profileAction{label: "Remove friend", action: removeRelationship})
+	case discordgo.RelationTypeIncommingRequest:
+		actions = append(actions, &profileAction{
+			label: "Accept friend request",
+			action: func() error {
+				acceptError := window.session.RelationshipFriendRequestAccept(user.ID)
+				if acceptError == nil {
+					setRelationship(discordgo.RelationTypeFriend)
+				}
+				return acceptError
+			},
+		}, &profileAction{label: "Decline friend request", action: removeRelationship})
+	case discordgo.RelationTypeOutgoingRequest:
+		actions = append(actions, &profileAction{label: "Cancel friend request", action: removeRelationship})
+	case discordgo.RelationTypeBlocked:
+		//Blocked users have to be unblocked before befriending them.
+	default:
+		actions = append(actions, &profileAction{
+			label: "Send friend request",
+			action: func() error {
+				requestError := window.session.RelationshipFriendRequestSend(user.ID)
+				if requestError == nil {
+					setRelationship(discordgo.RelationTypeOutgoingRequest)
+				}
+				return requestError
+			},
+		})
+	}
+
+	if relationshipType == discordgo.RelationTypeBlocked {
+		actions = append(actions, &profileAction{
+			label: "Unblock",
+			action: func() error {
+				return window.SetUserBlocked(user, false)
+			},
+		})
+	} else {
+		actions = append(actions, &profileAction{
+			label: "Block",
+			action: func() error {
+				return window.SetUserBlocked(user, true)
+			},
+		})
+	}
+
+	return actions
+}
+
+// getIgnoreAction creates an action that ignores or unignores the user in
+// the given guild or everywhere if the guildID is empty.
+func (window *Window) getIgnoreAction(user *discordgo.User, guildID, scope string) *profileAction {
+	if config.Current.IsUserIgnored(user.ID, guildID) {
+		return &profileAction{
+			label: "Unignore " + scope,
+			action: func() error {
+				config.Current.UnignoreUser(user.ID, guildID)
+				window.persistIgnoredUsers()
+				return nil
+			},
+		}
+	}
+
+	return &profileAction{
+		label: "Ignore " + scope,
+		action: func() error {
+			config.Current.IgnoreUser(user.ID, user.String(), guildID)
+			window.persistIgnoredUsers()
+			return nil
+		},
+	}
+}
+
+// insertMention appends a mention of the given user to the message input
+// and focuses it.
+func (window *Window) insertMention(user *discordgo.User) {
+	text := window.messageInput.GetText()
+	if text != "" && !strings.HasSuffix(text, " ") && !strings.HasSuffix(text, "\n") {
+		text += " "
+	}
+	window.messageInput.SetText(text + "@" + user.Username + "#" + user.Discriminator + " ")
+	window.app.SetFocus(window.messageInput.GetPrimitive())
+}
+
+// createProfileText creates the formatted profile of the given user. If a
+// guildID is passed, server specific information, such as the nickname and
+// roles, is included. The note is left out if it's empty.
+func createProfileText(state *discordgo.State, user *discordgo.User, guildID, note string) string {
+	var member *discordgo.Member
+	var guild *discordgo.Guild
+	if guildID != "" {
+		guild, _ = state.Guild(guildID)
+		member, _ = state.Member(guildID, user.ID)
+	}
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "[::b]%s#%s[::-]\n", tviewutil.Escape(user.Username), user.Discriminator)
+	if member != nil && member.Nick != "" {
+		fmt.Fprintf(&buffer, "Nickname: %s\n", tviewutil.Escape(member.Nick))
+	}
+	if user.Bot {
+		buffer.WriteString("Bot account\n")
+	}
+
+	presence := discordutil.GetPresence(state, guildID, user.ID)
+	status := discordutil.GetPresenceStatus(presence)
+	fmt.Fprintf(&buffer, "Status: %s %s", discordutil.GetStatusSymbol(status), getStatusName(status))
+	if activity := discordutil.GetPresenceActivity(presence); activity != "" {
+		fmt.Fprintf(&buffer, " - %s", tviewutil.Escape(activity))
+	}
+	buffer.WriteRune('\n')
+
+	if relationship := discordutil.GetRelationshipName(discordutil.GetRelationship(state, user.ID)); relationship != "" {
+		fmt.Fprintf(&buffer, "Relationship: %s\n", relationship)
+	}
+	if note != "" {
+		fmt.Fprintf(&buffer, "Note: %s\n", tviewutil.Escape(note))
+	}
+
+	buffer.WriteRune('\n')
+	if created, parseError := discordgo.SnowflakeTimestamp(user.ID); parseError == nil {
+		fmt.Fprintf(&buffer, "Account created: %s\n", created.Local().Format(profileDateFormat))
+	}
+	if member != nil && member.JoinedAt != "" {
+		if joined, parseError := member.JoinedAt.Parse(); parseError == nil {
+			fmt.Fprintf(&buffer, "Joined server: %s\n", joined.Local().Format(profileDateFormat))
+		}
+	}
+
+	if member != nil && guild != nil && len(member.Roles) > 0 {
+		roles := make([]string, len(member.Roles))
+		copy(roles, member.Roles)
+		discordutil.SortUserRoles(roles, guild.Roles)
+
+		roleNames := make([]string, 0, len(roles))
+		for _, roleID := range roles {
+			for _, role := range guild.Roles {
+				if role.ID != roleID {
+					continue
+				}
+
+				if roleColor := discordutil.GetRoleColor(role); roleColor != "" {
+					roleNames = append(roleNames, "["+roleColor+"]"+tviewutil.Escape(role.Name)+"[-]")
+				} else {
+					roleNames = append(roleNames, tviewutil.Escape(role.Name))
+				}
+				break
+			}
+		}
+		if len(roleNames) > 0 {
+			fmt.Fprintf(&buffer, "Roles: %s\n", strings.Join(roleNames, ", "))
+		}
+	}
+
+	if user.ID != state.User.ID {
+		mutualGuilds := discordutil.GetMutualGuilds(state, user.ID)
+		if len(mutualGuilds) > 0 {
+			guildNames := make([]string, 0, len(mutualGuilds))
+			for _, mutualGuild := range mutualGuilds {
+				guildNames = append(guildNames, tviewutil.Escape(mutualGuild.Name))
+			}
+			fmt.Fprintf(&buffer, "Mutual servers: %s\n", strings.Join(guildNames, ", "))
+		}
+	}
+
+	fmt.Fprintf(&buffer, "ID: %s", user.ID)
+
+	return buffer.String()
+}
+
+func getStatusName(status discordgo.Status) string {
+	switch status {
+	case discordgo.StatusOnline:
+		return "Online"
+	case discordgo.StatusIdle:
+		return "Idle"
+	case discordgo.StatusDoNotDisturb:
+		return "Do not disturb"
+	case discordgo.StatusInvisible:
+		return "Invisible"
+	default:
+		return "Offline"
+	}
+}
+
+// getMessageGuildID returns the ID of the guild the message has been sent
+// in. Messages loaded via the API don't always contain the guild ID,
+// therefore the channel is looked up instead.
+func getMessageGuildID(state *discordgo.State, message *discordgo.Message) string {
+	if channel, stateError := state.Channel(message.ChannelID); stateError == nil {
+		return channel.GuildID
+	}
+
+	return message.GuildID
+}
+
+// showMentionedUserProfile shows the profile of the user mentioned in the
+// given message. If multiple users have been mentioned, the user is asked
+// which profile to show.
+func (window *Window) showMentionedUserProfile(message *discordgo.Message, guildID string) {
+	if len(message.Mentions) == 0 {
+		return
+	}
+
+	if len(message.Mentions) == 1 {
+		window.ShowUserProfile(message.Mentions[0], guildID)
+		return
+	}
+
+	buttons := make([]string, 0, len(message.Mentions)+1)
+	for _, user := range message.Mentions {
+		buttons = append(buttons, user.Username+"#"+user.Discriminator)
+	}
+	buttons = append(buttons, "Cancel")
+
+	window.ShowDialog(config.GetTheme().PrimitiveBackgroundColor,
+		"Whose profile do you want to see?", func(button string) {
+			for index, user := range message.Mentions {
+				if buttons[index] == button {
+					//The dialog restores the focus after this handler has
+					//been called, so the popup has to be opened afterwards.
+					window.app.QueueUpdateDraw(func() {
+						window.ShowUserProfile(user, guildID)
+					})
+					return
+				}
+			}
+		}, buttons...)
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestCreateProfileText(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "self"}
+
+	//The snowflake was created on 2016-04-30.
+	user := &discordgo.User{ID: "175928847299117063", Username: "alice", Discriminator: "1234"}
+	guild := &discordgo.Guild{
+		ID:   "G1",
+		Name: "Gophers",
+		Roles: []*discordgo.Role{
+			{ID: "G1", Name: "@everyone"},
+			{ID: "admin", Name: "Admins", Color: 0xff0000, Position: 2},
+			{ID: "mod", Name: "Mods", Position: 1},
+		},
+		Members: []*discordgo.Member{{
+			GuildID:  "G1",
+			User:     user,
+			Nick:     "ally",
+			Roles:    []string{"mod", "admin"},
+			JoinedAt: "2019-03-01T12:00:00.000000+00:00",
+		}},
+		Presences: []*discordgo.Presence{{
+			User:   user,
+			Status: discordgo.StatusIdle,
+			Game:   &discordgo.Game{Name: "Go", Type: discordgo.GameTypeGame},
+		}},
+	}
+	if err := state.GuildAdd(guild); err != nil {
+		t.Fatal(err)
+	}
+	state.Relationships = []*discordgo.Relationship{{ID: user.ID, User: user, Type: discordgo.RelationTypeFriend}}
+
+	tests := []struct {
+		name    string
+		guildID string
+		note    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "guild profile",
+			guildID: "G1",
+			note:    "Met at [GopherCon]",
+			want: []string{
+				"[::b]alice#1234[::-]",
+				"Nickname: ally",
+				"Status: [yellow]●[-] Idle - Playing Go",
+				"Relationship: Friend",
+				"Note: Met at [GopherCon[]",
+				"Account created: 2016-04-30",
+				"Joined server: 2019-03-01",
+				"Roles: [#ff0000]Admins[-], Mods",
+				"Mutual servers: Gophers",
+				"ID: 175928847299117063",
+			},
+		}, {
+			name: "private profile",
+			want: []string{
+				"Status: [gray]○[-] Offline",
+				"Relationship: Friend",
+				"Mutual servers: Gophers",
+			},
+			notWant: []string{"Nickname", "Joined server", "Roles", "Note"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := createProfileText(state, user, tt.guildID, tt.note)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("createProfileText() = %q, should contain %q", got, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("createProfileText() = %q, shouldn't contain %q", got, notWant)
+				}
+			}
+		})
+	}
+}
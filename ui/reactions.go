@@ -0,0 +1,359 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Bios-Marcel/discordemojimap"
+	"github.com/Bios-Marcel/discordgo"
+	tcell "github.com/gdamore/tcell/v2"
+
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+	"github.com/Bios-Marcel/cordless/shortcuts"
+	"github.com/Bios-Marcel/cordless/tview"
+	"github.com/Bios-Marcel/cordless/ui/tviewutil"
+	"github.com/Bios-Marcel/cordless/util/fuzzy"
+)
+
+// maxRecentReactionEmoji is the amount of emoji that are remembered for the
+// reaction picker.
+const maxRecentReactionEmoji = 20
+
+// maxReactionUsers is the maximum amount of users that discord returns for
+// a single reaction request.
+const maxReactionUsers = 100
+
+// ShowReactionPicker shows a fullscreen view for reacting to the given
+// message. The emoji can be searched for by their shortcode or name, while
+// recently used emoji are shown first. Choosing an emoji that has already
+// been used for reacting by the current user removes the reaction instead.
+func (window *Window) ShowReactionPicker(message *discordgo.Message) {
+	var customEmoji []*discordgo.Emoji
+	channel, stateError := window.session.State.Channel(message.ChannelID)
+	if stateError == nil {
+		customEmoji = window.getReactableCustomEmoji(channel)
+	}
+
+	unicodeEmoji := make([]string, 0, len(discordemojimap.EmojiMap))
+	for emoji := range discordemojimap.EmojiMap {
+		unicodeEmoji = append(unicodeEmoji, emoji)
+	}
+
+	searchInput := tview.NewInputField()
+	searchInput.SetBorder(true)
+	searchInput.SetTitle("React to the message by " + discordutil.GetUserName(message.Author))
+	choiceList := tview.NewList().ShowSecondaryText(false).SetSelectedFocusOnly(false)
+	choiceList.SetBorder(true)
+	detailView := tview.NewTextView().SetDynamicColors(true).SetWordWrap(true)
+	detailView.SetBorder(true)
+
+	var choices []*discordgo.Emoji
+	updateChoices := func(searchTerm string) {
+		choices = findReactionChoices(searchTerm, message.Reactions,
+			config.Current.RecentReactionEmoji, unicodeEmoji, customEmoji)
+		choiceList.Clear()
+		for _, emoji := range choices {
+			choiceList.AddItem(formatReactionChoice(emoji, message.Reactions), "", 0, nil)
+		}
+	}
+	updateChoices("")
+	searchInput.SetChangedFunc(updateChoices)
+
+	//Prevents sending the same request multiple times while waiting for
+	//discord to respond.
+	var changingReaction bool
+	previousFocus := window.app.GetFocus()
+	closePicker := func() {
+		window.app.SetRoot(window.rootContainer, true)
+		window.app.SetFocus(previousFocus)
+	}
+
+	searchInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn:
+			choiceList.InputHandler()(event, nil)
+			return nil
+		case tcell.KeyESC:
+			closePicker()
+			return nil
+		case tcell.KeyEnter:
+			if len(choices) == 0 || changingReaction {
+				return nil
+			}
+
+			changingReaction = true
+			emoji := choices[choiceList.GetCurrentItem()]
+			detailView.SetText("Changing reaction ...")
+			go func() {
+				reactionError := window.toggleReaction(message, emoji)
+				window.app.QueueUpdateDraw(func() {
+					changingReaction = false
+					if reactionError != nil {
+						detailView.SetText(fmt.Sprintf("[%s]Error changing reaction: %s",
+							tviewutil.ColorToHex(config.GetTheme().ErrorColor), tviewutil.Escape(reactionError.Error())))
+						return
+					}
+
+					closePicker()
+					window.rememberReactionEmoji(emoji)
+				})
+			}()
+			return nil
+		}
+
+		if shortcuts.ShowReactionUsers.Equals(event) {
+			if len(choices) != 0 {
+				window.showReactionUsers(detailView, message, choices[choiceList.GetCurrentItem()])
+			}
+			return nil
+		}
+
+		return event
+	})
+
+	container := tview.NewFlex().SetDirection(tview.FlexRow)
+	container.AddItem(searchInput, 3, 0, true)
+	container.AddItem(choiceList, 0, 1, false)
+	container.AddItem(detailView, 5, 0, false)
+
+	window.app.SetRoot(container, true)
+	window.app.SetFocus(searchInput)
+}
+
+// toggleReaction removes the current users reaction with the given emoji or
+// adds it, if there is none yet. This sends a request to discord and
+// therefore shouldn't be called on the UI thread.
+func (window *Window) toggleReaction(message *discordgo.Message, emoji *discordgo.Emoji) error {
+	if reaction := findReaction(message.Reactions, emoji); reaction != nil && reaction.Me {
+		return window.session.MessageReactionRemove(message.ChannelID, message.ID, emoji.APIName(), "@me")
+	}
+	return window.session.MessageReactionAdd(message.ChannelID, message.ID, emoji.APIName())
+}
+
+// rememberReactionEmoji saves the emoji as recently used, so that it's shown
+// first in the reaction picker.
+func (window *Window) rememberReactionEmoji(emoji *discordgo.Emoji) {
+	config.Current.RecentReactionEmoji = addRecentEmoji(config.Current.RecentReactionEmoji, emoji.APIName(), maxRecentReactionEmoji)
+	if persistError := config.PersistConfig(); persistError != nil {
+		window.ShowErrorDialog(fmt.Sprintf("Error saving recent emoji: %s", persistError.Error()))
+	}
+}
+
+// showReactionUsers loads the users that reacted with the given emoji in the
+// background and shows them in the passed view.
+func (window *Window) showReactionUsers(view *tview.TextView, message *discordgo.Message, emoji *discordgo.Emoji) {
+	reaction := findReaction(message.Reactions, emoji)
+	if reaction == nil {
+		view.SetText("Nobody has reacted with " + reactionEmojiSymbol(emoji) + " yet.")
+		return
+	}
+
+	guildID := getMessageGuildID(window.session.State, message)
+
+	view.SetText("Loading reactions ...")
+	go func() {
+		users, loadError := window.session.MessageReactions(message.ChannelID, message.ID, emoji.APIName(), maxReactionUsers, "", "")
+		window.app.QueueUpdateDraw(func() {
+			if loadError != nil {
+				view.SetText(fmt.Sprintf("[%s]Error loading reactions: %s",
+					tviewutil.ColorToHex(config.GetTheme().ErrorColor), tviewutil.Escape(loadError.Error())))
+				return
+			}
+
+			names := make([]string, 0, len(users))
+			for _, user := range users {
+				var name string
+				if guildID != "" {
+					member, stateError := window.session.State.Member(guildID, user.ID)
+					if stateError == nil {
+						name = discordutil.GetMemberName(member)
+					}
+				}
+				if name == "" {
+					name = discordutil.GetUserName(user)
+				}
+				names = append(names, tviewutil.Escape(name))
+			}
+
+			text := "Reacted with " + reactionEmojiSymbol(emoji) + ": " + strings.Join(names, ", ")
+			if reaction.Count > len(users) {
+				text += " and " + strconv.Itoa(reaction.Count-len(users)) + " more"
+			}
+			view.SetText(text)
+		})
+	}()
+}
+
+// getReactableCustomEmoji returns all custom emoji that the current user can
+// use for reacting in the given channel. Users without nitro can only use
+// the non-animated emoji of the guild that the channel belongs to.
+func (window *Window) getReactableCustomEmoji(channel *discordgo.Channel) []*discordgo.Emoji {
+	var reactableEmoji []*discordgo.Emoji
+	if window.session.State.User.PremiumType != discordgo.UserPremiumTypeNone {
+		for _, guild := range window.session.State.Guilds {
+			reactableEmoji = append(reactableEmoji, guild.Emojis...)
+		}
+		return reactableEmoji
+	}
+
+	if channel.GuildID == "" {
+		return nil
+	}
+
+	guild, stateError := window.session.State.Guild(channel.GuildID)
+	if stateError != nil {
+		return nil
+	}
+
+	for _, emoji := range guild.Emojis {
+		if !emoji.Animated {
+			reactableEmoji = append(reactableEmoji, emoji)
+		}
+	}
+
+	return reactableEmoji
+}
+
+// findReactionChoices decides which emoji are shown in the reaction picker.
+// Without a search term, the existing reactions are shown, followed by the
+// recently used emoji. Otherwise all emoji matching the search term are
+// shown, the recently used ones first.
+func findReactionChoices(searchTerm string, reactions []*discordgo.MessageReactions,
+	recentEmoji []string, unicodeEmoji []string, customEmoji []*discordgo.Emoji) []*discordgo.Emoji {
+	customEmojiByName := make(map[string]*discordgo.Emoji, len(customEmoji))
+	customEmojiByAPIName := make(map[string]*discordgo.Emoji, len(customEmoji))
+	for _, emoji := range customEmoji {
+		if _, contains := customEmojiByName[emoji.Name]; !contains {
+			customEmojiByName[emoji.Name] = emoji
+		}
+		customEmojiByAPIName[emoji.APIName()] = emoji
+	}
+
+	resolveRecent := func(apiName string) *discordgo.Emoji {
+		if emoji, contains := customEmojiByAPIName[apiName]; contains {
+			return emoji
+		}
+		if discordemojimap.ContainsEmoji(apiName) {
+			return &discordgo.Emoji{Name: apiName}
+		}
+		//Custom emoji that can't be used anymore.
+		return nil
+	}
+
+	var choices []*discordgo.Emoji
+	alreadyChosen := make(map[string]bool)
+	addChoice := func(emoji *discordgo.Emoji) {
+		if emoji != nil && !alreadyChosen[emoji.APIName()] {
+			alreadyChosen[emoji.APIName()] = true
+			choices = append(choices, emoji)
+		}
+	}
+
+	if searchTerm == "" {
+		for _, reaction := range reactions {
+			addChoice(reaction.Emoji)
+		}
+		for _, apiName := range recentEmoji {
+			addChoice(resolveRecent(apiName))
+		}
+		return choices
+	}
+
+	var matches []*discordgo.Emoji
+	matchesByAPIName := make(map[string]bool)
+	for _, result := range fuzzy.ScoreAndSortEmoji(searchTerm, unicodeEmoji, customEmoji) {
+		var emoji *discordgo.Emoji
+		//Custom emoji are prefixed with a space.
+		if strings.HasPrefix(result, " ") {
+			emoji = customEmojiByName[result[1:]]
+		} else if symbol := discordemojimap.GetEmoji(result); symbol != "" {
+			emoji = &discordgo.Emoji{Name: symbol}
+		}
+
+		if emoji != nil {
+			matches = append(matches, emoji)
+			matchesByAPIName[emoji.APIName()] = true
+		}
+	}
+
+	for _, apiName := range recentEmoji {
+		if matchesByAPIName[apiName] {
+			addChoice(resolveRecent(apiName))
+		}
+	}
+	for _, emoji := range matches {
+		addChoice(emoji)
+	}
+
+	return choices
+}
+
+// addRecentEmoji puts the emoji in front of the recently used emoji,
+// removing any previous occurrence and dropping the oldest entries if the
+// limit has been exceeded.
+func addRecentEmoji(recentEmoji []string, emoji string, limit int) []string {
+	updated := make([]string, 0, len(recentEmoji)+1)
+	updated = append(updated, emoji)
+	for _, recent := range recentEmoji {
+		if recent != emoji {
+			updated = append(updated, recent)
+		}
+	}
+
+	if len(updated) > limit {
+		return updated[:limit]
+	}
+	return updated
+}
+
+// findReaction returns the reaction of the message that uses the given
+// emoji or nil if there's none.
+func findReaction(reactions []*discordgo.MessageReactions, emoji *discordgo.Emoji) *discordgo.MessageReactions {
+	for _, reaction := range reactions {
+		//Only custom emojis have IDs and non custom ones have unique names.
+		if reaction.Emoji.ID == emoji.ID && reaction.Emoji.Name == emoji.Name {
+			return reaction
+		}
+	}
+
+	return nil
+}
+
+// reactionEmojiSymbol returns the unicode symbol of the emoji or its name,
+// if it's a custom emoji.
+func reactionEmojiSymbol(emoji *discordgo.Emoji) string {
+	if emoji.ID != "" {
+		return ":" + tviewutil.Escape(emoji.Name) + ":"
+	}
+
+	return emoji.Name
+}
+
+// formatReactionChoice creates the text for an emoji in the reaction picker,
+// including the amount of reactions and whether the current user has
+// reacted with it.
+func formatReactionChoice(emoji *discordgo.Emoji, reactions []*discordgo.MessageReactions) string {
+	var text string
+	if emoji.ID != "" {
+		text = "? | " + tviewutil.Escape(emoji.Name) + " (custom emoji)"
+	} else if codes := discordemojimap.GetEmojiCodes(emoji.Name); len(codes) > 0 {
+		//Emoji can have multiple codes, which are returned in random order.
+		sort.Strings(codes)
+		text = emoji.Name + " | " + codes[0]
+	} else {
+		text = emoji.Name
+	}
+
+	if reaction := findReaction(reactions, emoji); reaction != nil {
+		if reaction.Me {
+			text += fmt.Sprintf(" (%d, including you)", reaction.Count)
+		} else {
+			text += fmt.Sprintf(" (%d)", reaction.Count)
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+)
+
+func TestFindReactionChoices(t *testing.T) {
+	party := &discordgo.Emoji{ID: "1", Name: "party"}
+	partyParrot := &discordgo.Emoji{ID: "2", Name: "partyparrot"}
+	customEmoji := []*discordgo.Emoji{party, partyParrot}
+	unicodeEmoji := []string{"thumbsup", "tada", "smile"}
+	reactions := []*discordgo.MessageReactions{
+		{Count: 2, Me: true, Emoji: &discordgo.Emoji{Name: "👍"}},
+		{Count: 1, Emoji: party},
+	}
+
+	tests := []struct {
+		name        string
+		searchTerm  string
+		recentEmoji []string
+		want        []string
+	}{
+		{
+			name:        "existing reactions followed by recent emoji",
+			recentEmoji: []string{"🎉", "👍", "unknown:3"},
+			want:        []string{"👍", "party:1", "🎉"},
+		}, {
+			name:        "recent matches first",
+			searchTerm:  "party",
+			recentEmoji: []string{"partyparrot:2"},
+			want:        []string{"partyparrot:2", "party:1"},
+		}, {
+			name:       "unicode match",
+			searchTerm: "tada",
+			want:       []string{"🎉"},
+		}, {
+			name:       "no match",
+			searchTerm: "xyz",
+			want:       nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, emoji := range findReactionChoices(tt.searchTerm, reactions, tt.recentEmoji, unicodeEmoji, customEmoji) {
+				got = append(got, emoji.APIName())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findReactionChoices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRecentEmoji(t *testing.T) {
+	tests := []struct {
+		name   string
+		recent []string
+		emoji  string
+		limit  int
+		want   []string
+	}{
+		{
+			name:  "first emoji",
+			emoji: "👍",
+			limit: 3,
+			want:  []string{"👍"},
+		}, {
+			name:   "moved to the front",
+			recent: []string{"🎉", "👍", "party:1"},
+			emoji:  "👍",
+			limit:  3,
+			want:   []string{"👍", "🎉", "party:1"},
+		}, {
+			name:   "oldest is dropped",
+			recent: []string{"🎉", "👍", "party:1"},
+			emoji:  "😄",
+			limit:  3,
+			want:   []string{"😄", "🎉", "👍"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addRecentEmoji(tt.recent, tt.emoji, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addRecentEmoji() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package ui
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/commands"
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+)
+
+// inactivityTimer decides whether the user is active, meaning that there
+// has been input within the timeout. It is safe for concurrent use.
+type inactivityTimer struct {
+	mutex        sync.Mutex
+	timeout      time.Duration
+	timer        *time.Timer
+	lastActivity time.Time
+	active       bool
+	stopped      bool
+	onInactive   func()
+	onActive     func()
+}
+
+// newInactivityTimer creates a timer that treats the user as active until
+// the timeout has passed without any activity. If active is false, the user
+// is treated as inactive until the first activity is reported. onInactive
+// and onActive are called whenever the state changes. They may be nil and
+// mustn't block, as they are called in order while holding the timers lock.
+func newInactivityTimer(timeout time.Duration, active bool, onInactive, onActive func()) *inactivityTimer {
+	inactivity := &inactivityTimer{
+		timeout:      timeout,
+		lastActivity: time.Now(),
+		active:       active,
+		onInactive:   onInactive,
+		onActive:     onActive,
+	}
+	inactivity.timer = time.AfterFunc(timeout, inactivity.check)
+	if !active {
+		inactivity.timer.Stop()
+	}
+	return inactivity
+}
+
+// check marks the user as inactive if there hasn't been any activity since
+// the timer was started. Otherwise the timer is started again for the rest
+// of the timeout. This way reporting activity is cheap.
+func (inactivity *inactivityTimer) check() {
+	inactivity.mutex.Lock()
+	defer inactivity.mutex.Unlock()
+
+	if inactivity.stopped || !inactivity.active {
+		return
+	}
+
+	if remaining := inactivity.timeout - time.Since(inactivity.lastActivity); remaining > 0 {
+		inactivity.timer.Reset(remaining)
+		return
+	}
+
+	inactivity.active = false
+	if inactivity.onInactive != nil {
+		inactivity.onInactive()
+	}
+}
+
+// ReportActivity marks the user as active and restarts the timeout.
+func (inactivity *inactivityTimer) ReportActivity() {
+	inactivity.mutex.Lock()
+	defer inactivity.mutex.Unlock()
+
+	if inactivity.stopped {
+		return
+	}
+
+	inactivity.lastActivity = time.Now()
+	if inactivity.active {
+		return
+	}
+
+	inactivity.active = true
+	//The timer has either already fired or has never been started, so
+	//resetting it is safe.
+	inactivity.timer.Reset(inactivity.timeout)
+	if inactivity.onActive != nil {
+		inactivity.onActive()
+	}
+}
+
+// IsActive indicates whether there has been activity within the timeout.
+func (inactivity *inactivityTimer) IsActive() bool {
+	inactivity.mutex.Lock()
+	defer inactivity.mutex.Unlock()
+
+	return inactivity.active
+}
+
+// Stop stops the timer. Afterwards the state doesn't change anymore.
+func (inactivity *inactivityTimer) Stop() {
+	inactivity.mutex.Lock()
+	defer inactivity.mutex.Unlock()
+
+	inactivity.stopped = true
+	inactivity.timer.Stop()
+}
+
+// autoIdle switches the status to idle while the user is inactive and
+// restores the previous status afterwards. Only the online status is
+// changed, as dnd and invisible have been chosen deliberately.
+type autoIdle struct {
+	//stateMutex guards wantIdle, which is changed by the inactivityTimer.
+	stateMutex sync.Mutex
+	wantIdle   bool
+
+	//applyMutex serializes the requests to discord, so that they happen in
+	//the same order as the changes of wantIdle.
+	applyMutex     sync.Mutex
+	idle           bool
+	previousStatus discordgo.Status
+
+	getStatus func() discordgo.Status
+	setStatus func(discordgo.Status) error
+}
+
+func newAutoIdle(getStatus func() discordgo.Status, setStatus func(discordgo.Status) error) *autoIdle {
+	return &autoIdle{
+		getStatus: getStatus,
+		setStatus: setStatus,
+	}
+}
+
+// onInactive can be passed to an inactivityTimer.
+func (idle *autoIdle) onInactive() {
+	idle.request(true)
+	go idle.apply()
+}
+
+// onActive can be passed to an inactivityTimer.
+func (idle *autoIdle) onActive() {
+	idle.request(false)
+	go idle.apply()
+}
+
+func (idle *autoIdle) request(wantIdle bool) {
+	idle.stateMutex.Lock()
+	defer idle.stateMutex.Unlock()
+
+	idle.wantIdle = wantIdle
+}
+
+// restore sets the previous status again, in case the status is still idle
+// due to inactivity. This is meant to be called before shutting down, as
+// the status would otherwise stay idle on all clients. The inactivityTimer
+// has to be stopped beforehand.
+func (idle *autoIdle) restore() {
+	idle.request(false)
+	idle.apply()
+}
+
+// apply changes the status according to the last request. Since it always
+// looks at the latest request, it doesn't matter in which order concurrent
+// calls happen.
+func (idle *autoIdle) apply() {
+	idle.applyMutex.Lock()
+	defer idle.applyMutex.Unlock()
+
+	idle.stateMutex.Lock()
+	wantIdle := idle.wantIdle
+	idle.stateMutex.Unlock()
+
+	if wantIdle && !idle.idle {
+		status := idle.getStatus()
+		if status != discordgo.StatusOnline {
+			return
+		}
+
+		if idle.setStatus(discordgo.StatusIdle) == nil {
+			idle.idle = true
+			idle.previousStatus = status
+		}
+	} else if !wantIdle && idle.idle {
+		idle.idle = false
+		//If the status has been changed manually in the meantime, we keep it.
+		if idle.getStatus() == discordgo.StatusIdle {
+			idle.setStatus(idle.previousStatus)
+		}
+	}
+}
+
+// statusExpiry calls a function once the custom status expires. It is safe
+// for concurrent use.
+type statusExpiry struct {
+	mutex    sync.Mutex
+	timer    *time.Timer
+	stopped  bool
+	onExpire func()
+}
+
+func newStatusExpiry(onExpire func()) *statusExpiry {
+	return &statusExpiry{onExpire: onExpire}
+}
+
+// Schedule replaces the previously scheduled expiry. expiresAt is in the
+// format that discord uses. If it is empty, nothing is scheduled. If it has
+// already passed, the function is called right away.
+func (expiry *statusExpiry) Schedule(expiresAt string) {
+	expiry.mutex.Lock()
+	defer expiry.mutex.Unlock()
+
+	if expiry.timer != nil {
+		expiry.timer.Stop()
+		expiry.timer = nil
+	}
+
+	if expiresAt == "" || expiry.stopped {
+		return
+	}
+
+	expiryTime, parseError := time.Parse(time.RFC3339Nano, expiresAt)
+	if parseError != nil {
+		return
+	}
+
+	expiry.timer = time.AfterFunc(time.Until(expiryTime), expiry.onExpire)
+}
+
+// Stop cancels the scheduled expiry. Afterwards nothing can be scheduled
+// anymore.
+func (expiry *statusExpiry) Stop() {
+	expiry.mutex.Lock()
+	defer expiry.mutex.Unlock()
+
+	expiry.stopped = true
+	if expiry.timer != nil {
+		expiry.timer.Stop()
+		expiry.timer = nil
+	}
+}
+
+// isCustomStatusExpired checks whether the given custom status has an
+// expiry that has already passed.
+func isCustomStatusExpired(customStatus discordgo.CustomStatus, now time.Time) bool {
+	if customStatus.ExpiresAt == "" {
+		return false
+	}
+
+	expiryTime, parseError := time.Parse(time.RFC3339Nano, customStatus.ExpiresAt)
+	return parseError == nil && !expiryTime.After(now)
+}
+
+// startStatusTracking starts clearing expired custom statuses and, if
+// enabled, switching to idle after a period of inactivity. Discord doesn't
+// clear expired custom statuses on its own, so this also takes care of
+// expiries that passed while cordless wasn't running.
+func (window *Window) startStatusTracking() {
+	if window.session.State.User.Bot {
+		return
+	}
+
+	window.statusExpiry = newStatusExpiry(window.clearExpiredCustomStatus)
+	window.ScheduleCustomStatusExpiry()
+
+	if config.Current.AutoIdleThreshold > 0 {
+		window.autoIdle = newAutoIdle(window.getStatus, window.setStatus)
+		window.autoIdleTimer = newInactivityTimer(time.Duration(config.Current.AutoIdleThreshold)*time.Minute,
+			true, window.autoIdle.onInactive, window.autoIdle.onActive)
+	}
+
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.UserSettingsUpdate) {
+		//Reading and replacing the settings has to happen at once, as
+		//updates could get lost otherwise.
+		s.State.Lock()
+		settings, updateError := discordutil.ApplySettingsUpdate(s.State.Settings, *event)
+		if updateError == nil {
+			s.State.Settings = settings
+		}
+		s.State.Unlock()
+
+		if updateError != nil {
+			window.app.QueueUpdateDraw(func() {
+				commands.PrintError(window.commandView, "Error updating settings", updateError.Error())
+			})
+			return
+		}
+
+		if _, changed := (*event)["custom_status"]; changed {
+			window.ScheduleCustomStatusExpiry()
+		}
+	})
+}
+
+// ScheduleCustomStatusExpiry makes sure that the current custom status is
+// cleared once it expires. This has to be called after changing the custom
+// status.
+func (window *Window) ScheduleCustomStatusExpiry() {
+	settings := discordutil.GetSettings(window.session.State)
+	if window.statusExpiry == nil || settings == nil {
+		return
+	}
+
+	window.statusExpiry.Schedule(settings.CustomStatus.ExpiresAt)
+}
+
+func (window *Window) clearExpiredCustomStatus() {
+	settings := discordutil.GetSettings(window.session.State)
+	//The status might have been changed since the expiry was scheduled.
+	if settings == nil || !isCustomStatusExpired(settings.CustomStatus, time.Now()) {
+		return
+	}
+
+	updatedSettings, updateError := window.session.UserUpdateStatusCustom(discordgo.CustomStatus{})
+	if updateError != nil {
+		window.app.QueueUpdateDraw(func() {
+			commands.PrintError(window.commandView, "Error clearing expired custom status", updateError.Error())
+		})
+		return
+	}
+
+	if updatedSettings != nil {
+		discordutil.SetSettings(window.session.State, updatedSettings)
+	}
+}
+
+func (window *Window) getStatus() discordgo.Status {
+	if settings := discordutil.GetSettings(window.session.State); settings != nil {
+		return settings.Status
+	}
+
+	return ""
+}
+
+func (window *Window) setStatus(status discordgo.Status) error {
+	updatedSettings, updateError := window.session.UserUpdateStatus(status)
+	if updateError != nil {
+		window.app.QueueUpdateDraw(func() {
+			commands.PrintError(window.commandView, "Error changing status", updateError.Error())
+		})
+		return updateError
+	}
+
+	if updatedSettings != nil {
+		discordutil.SetSettings(window.session.State, updatedSettings)
+	}
+
+	return nil
+}
+
+// reportUserActivity has to be called on every user input.
+func (window *Window) reportUserActivity() {
+	if window.userActivityTimer != nil {
+		window.userActivityTimer.ReportActivity()
+	}
+	if window.autoIdleTimer != nil {
+		window.autoIdleTimer.ReportActivity()
+	}
+}
+
+// stopStatusTracking stops all timers and restores the status, in case it
+// has been changed to idle due to inactivity.
+func (window *Window) stopStatusTracking() {
+	if window.userActivityTimer != nil {
+		window.userActivityTimer.Stop()
+	}
+	if window.statusExpiry != nil {
+		window.statusExpiry.Stop()
+	}
+	if window.autoIdleTimer != nil {
+		window.autoIdleTimer.Stop()
+		window.autoIdle.restore()
+	}
+}
+
+// isUserActive indicates whether there has been input within the
+// DesktopNotificationsUserInactivityThreshold.
+func (window *Window) isUserActive() bool {
+	return window.userActivityTimer != nil && window.userActivityTimer.IsActive()
+}
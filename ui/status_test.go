@@ -0,0 +1,325 @@
+package ui
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/shortcuts"
+	"github.com/Bios-Marcel/cordless/tview"
+)
+
+// waitFor polls the condition, as the timers fire on their own goroutines.
+func waitFor(t *testing.T, description string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", description)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestInactivityTimer(t *testing.T) {
+	var mutex sync.Mutex
+	var events []string
+	record := func(event string) func() {
+		return func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+			events = append(events, event)
+		}
+	}
+	getEvents := func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), events...)
+	}
+
+	timeout := 50 * time.Millisecond
+	inactivity := newInactivityTimer(timeout, true, record("inactive"), record("active"))
+	defer inactivity.Stop()
+
+	if !inactivity.IsActive() {
+		t.Error("IsActive() = false right after creation, want true")
+	}
+
+	//Activity within the timeout postpones the inactivity.
+	for i := 0; i < 3; i++ {
+		time.Sleep(timeout / 2)
+		inactivity.ReportActivity()
+	}
+	if !inactivity.IsActive() || len(getEvents()) != 0 {
+		t.Errorf("user should still be active, but events were %v", getEvents())
+	}
+
+	waitFor(t, "inactivity", func() bool { return !inactivity.IsActive() })
+	inactivity.ReportActivity()
+	inactivity.ReportActivity()
+	if !inactivity.IsActive() {
+		t.Error("IsActive() = false after activity, want true")
+	}
+
+	waitFor(t, "second inactivity", func() bool { return !inactivity.IsActive() })
+	if want := []string{"inactive", "active", "inactive"}; !reflect.DeepEqual(getEvents(), want) {
+		t.Errorf("events = %v, want %v", getEvents(), want)
+	}
+
+	inactivity.Stop()
+	inactivity.ReportActivity()
+	if inactivity.IsActive() {
+		t.Error("IsActive() = true after Stop(), want false")
+	}
+}
+
+func TestInactivityTimerStartingInactive(t *testing.T) {
+	activated := make(chan struct{}, 10)
+	inactivity := newInactivityTimer(20*time.Millisecond, false, nil, func() { activated <- struct{}{} })
+	defer inactivity.Stop()
+
+	time.Sleep(40 * time.Millisecond)
+	if inactivity.IsActive() {
+		t.Error("IsActive() = true before any activity, want false")
+	}
+
+	inactivity.ReportActivity()
+	if !inactivity.IsActive() {
+		t.Error("IsActive() = false after activity, want true")
+	}
+	if len(activated) != 1 {
+		t.Errorf("onActive has been called %d times, want 1", len(activated))
+	}
+
+	waitFor(t, "inactivity", func() bool { return !inactivity.IsActive() })
+}
+
+func TestAutoIdle(t *testing.T) {
+	tests := []struct {
+		name string
+		//status is the status at the start of the test.
+		status discordgo.Status
+		//manualStatus is set while idle, as if the user changed it.
+		manualStatus discordgo.Status
+		failIdle     bool
+		wantIdle     discordgo.Status
+		wantRestored discordgo.Status
+	}{
+		{
+			name:         "online",
+			status:       discordgo.StatusOnline,
+			wantIdle:     discordgo.StatusIdle,
+			wantRestored: discordgo.StatusOnline,
+		}, {
+			name:         "dnd is kept",
+			status:       discordgo.StatusDoNotDisturb,
+			wantIdle:     discordgo.StatusDoNotDisturb,
+			wantRestored: discordgo.StatusDoNotDisturb,
+		}, {
+			name:         "invisible is kept",
+			status:       discordgo.StatusInvisible,
+			wantIdle:     discordgo.StatusInvisible,
+			wantRestored: discordgo.StatusInvisible,
+		}, {
+			name:         "manual change while idle is kept",
+			status:       discordgo.StatusOnline,
+			manualStatus: discordgo.StatusDoNotDisturb,
+			wantIdle:     discordgo.StatusDoNotDisturb,
+			wantRestored: discordgo.StatusDoNotDisturb,
+		}, {
+			name:         "failed update",
+			status:       discordgo.StatusOnline,
+			failIdle:     true,
+			wantIdle:     discordgo.StatusOnline,
+			wantRestored: discordgo.StatusOnline,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			var updates int
+			idle := newAutoIdle(
+				func() discordgo.Status { return status },
+				func(newStatus discordgo.Status) error {
+					if tt.failIdle && newStatus == discordgo.StatusIdle {
+						return errors.New("failed")
+					}
+					updates++
+					status = newStatus
+					return nil
+				})
+
+			idle.request(true)
+			idle.apply()
+			if tt.manualStatus != "" {
+				status = tt.manualStatus
+			}
+			if status != tt.wantIdle {
+				t.Errorf("status while inactive = %s, want %s", status, tt.wantIdle)
+			}
+
+			idle.request(false)
+			idle.apply()
+			if status != tt.wantRestored {
+				t.Errorf("status after activity = %s, want %s", status, tt.wantRestored)
+			}
+
+			//Applying the same request again mustn't cause another update.
+			updatesBefore := updates
+			idle.apply()
+			if updates != updatesBefore {
+				t.Errorf("repeated apply() caused %d updates", updates-updatesBefore)
+			}
+		})
+	}
+}
+
+func TestAutoIdleWithInactivityTimer(t *testing.T) {
+	var mutex sync.Mutex
+	status := discordgo.StatusOnline
+	getStatus := func() discordgo.Status {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return status
+	}
+	idle := newAutoIdle(getStatus, func(newStatus discordgo.Status) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		status = newStatus
+		return nil
+	})
+
+	inactivity := newInactivityTimer(20*time.Millisecond, true, idle.onInactive, idle.onActive)
+	defer inactivity.Stop()
+
+	waitFor(t, "idle status", func() bool { return getStatus() == discordgo.StatusIdle })
+	inactivity.ReportActivity()
+	waitFor(t, "restored status", func() bool { return getStatus() == discordgo.StatusOnline })
+}
+
+func TestAutoIdleRestore(t *testing.T) {
+	status := discordgo.StatusOnline
+	idle := newAutoIdle(
+		func() discordgo.Status { return status },
+		func(newStatus discordgo.Status) error {
+			status = newStatus
+			return nil
+		})
+
+	//Restoring without being idle mustn't change anything.
+	status = discordgo.StatusDoNotDisturb
+	idle.restore()
+	if status != discordgo.StatusDoNotDisturb {
+		t.Errorf("status after restoring without being idle = %s, want %s", status, discordgo.StatusDoNotDisturb)
+	}
+
+	status = discordgo.StatusOnline
+	idle.request(true)
+	idle.apply()
+	if status != discordgo.StatusIdle {
+		t.Fatalf("status while inactive = %s, want %s", status, discordgo.StatusIdle)
+	}
+
+	idle.restore()
+	if status != discordgo.StatusOnline {
+		t.Errorf("status after restoring = %s, want %s", status, discordgo.StatusOnline)
+	}
+}
+
+func TestExitRestoresStatus(t *testing.T) {
+	status := discordgo.StatusOnline
+	idle := newAutoIdle(
+		func() discordgo.Status { return status },
+		func(newStatus discordgo.Status) error {
+			status = newStatus
+			return nil
+		})
+	window := &Window{
+		app:           tview.NewApplication(),
+		autoIdle:      idle,
+		autoIdleTimer: newInactivityTimer(time.Hour, true, idle.onInactive, idle.onActive),
+	}
+
+	idle.request(true)
+	idle.apply()
+	if status != discordgo.StatusIdle {
+		t.Fatalf("status while inactive = %s, want %s", status, discordgo.StatusIdle)
+	}
+
+	window.handleGlobalShortcuts(shortcuts.ExitApplication.Event)
+	if status != discordgo.StatusOnline {
+		t.Errorf("status after exiting = %s, want %s", status, discordgo.StatusOnline)
+	}
+}
+
+func TestStatusExpiry(t *testing.T) {
+	expired := make(chan struct{}, 10)
+	expiry := newStatusExpiry(func() { expired <- struct{}{} })
+
+	expectExpiry := func(want bool, wait time.Duration) {
+		t.Helper()
+		select {
+		case <-expired:
+			if !want {
+				t.Error("status expired unexpectedly")
+			}
+		case <-time.After(wait):
+			if want {
+				t.Error("status didn't expire")
+			}
+		}
+	}
+
+	//Expiries that passed while not running are handled right away.
+	expiry.Schedule(time.Now().Add(-time.Hour).Format(time.RFC3339Nano))
+	expectExpiry(true, time.Second)
+
+	expiry.Schedule(time.Now().Add(30 * time.Millisecond).UTC().Format(time.RFC3339Nano))
+	expectExpiry(false, 10*time.Millisecond)
+	expectExpiry(true, time.Second)
+
+	//Changing the status replaces the previous expiry.
+	expiry.Schedule(time.Now().Add(30 * time.Millisecond).Format(time.RFC3339Nano))
+	expiry.Schedule(time.Now().Add(time.Hour).Format(time.RFC3339Nano))
+	expectExpiry(false, 100*time.Millisecond)
+
+	expiry.Schedule(time.Now().Add(30 * time.Millisecond).Format(time.RFC3339Nano))
+	expiry.Schedule("")
+	expectExpiry(false, 100*time.Millisecond)
+
+	expiry.Schedule("invalid")
+	expectExpiry(false, 50*time.Millisecond)
+
+	//Nothing expires anymore once stopped.
+	expiry.Schedule(time.Now().Add(30 * time.Millisecond).Format(time.RFC3339Nano))
+	expiry.Stop()
+	expectExpiry(false, 100*time.Millisecond)
+	expiry.Schedule(time.Now().Add(-time.Hour).Format(time.RFC3339Nano))
+	expectExpiry(false, 50*time.Millisecond)
+}
+
+func TestIsCustomStatusExpired(t *testing.T) {
+	now := time.Date(2020, time.March, 10, 17, 0, 0, 0, time.UTC)
+	tests := []struct {
+		expiresAt string
+		want      bool
+	}{
+		{expiresAt: "", want: false},
+		{expiresAt: "invalid", want: false},
+		{expiresAt: "2020-03-10T16:59:59.000Z", want: true},
+		{expiresAt: "2020-03-10T17:00:00Z", want: true},
+		{expiresAt: "2020-03-10T18:00:00+02:00", want: true},
+		{expiresAt: "2020-03-10T17:00:01.000Z", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expiresAt, func(t *testing.T) {
+			if got := isCustomStatusExpired(discordgo.CustomStatus{ExpiresAt: tt.expiresAt}, now); got != tt.want {
+				t.Errorf("isCustomStatusExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
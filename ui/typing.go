@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/config"
+	"github.com/Bios-Marcel/cordless/discordutil"
+)
+
+const (
+	// typingTimeout is the duration after which discord stops showing a user
+	// as typing, unless a new typing event has been received in the meantime.
+	typingTimeout = 10 * time.Second
+	// typingInterval is the minimum duration between two typing notifications
+	// for the same channel. It is a bit shorter than typingTimeout, so that
+	// the indicator doesn't flicker for others while we keep typing.
+	typingInterval = 8 * time.Second
+	// maxNamedTypingUsers is the amount of users that are listed by name,
+	// before falling back to a generic text.
+	maxNamedTypingUsers = 3
+)
+
+// typingUsers keeps track of which users are currently typing in which
+// channel. It is safe for concurrent use.
+type typingUsers struct {
+	mutex    sync.Mutex
+	channels map[string]map[string]time.Time
+}
+
+func newTypingUsers() *typingUsers {
+	return &typingUsers{
+		channels: make(map[string]map[string]time.Time),
+	}
+}
+
+// add marks the user as typing in the given channel, starting at the given
+// point in time. Adding a user that is already typing refreshes the start.
+func (typing *typingUsers) add(channelID, userID string, start time.Time) {
+	typing.mutex.Lock()
+	defer typing.mutex.Unlock()
+
+	users, ok := typing.channels[channelID]
+	if !ok {
+		users = make(map[string]time.Time)
+		typing.channels[channelID] = users
+	}
+	users[userID] = start
+}
+
+// remove marks the user as not typing anymore, for example because a
+// message by that user has been received.
+func (typing *typingUsers) remove(channelID, userID string) {
+	typing.mutex.Lock()
+	defer typing.mutex.Unlock()
+
+	users, ok := typing.channels[channelID]
+	if !ok {
+		return
+	}
+	delete(users, userID)
+	if len(users) == 0 {
+		delete(typing.channels, channelID)
+	}
+}
+
+// get returns the IDs of all users that are still typing in the given channel
+// at the given point in time, ordered by when they started typing. Expired
+// entries are dropped.
+func (typing *typingUsers) get(channelID string, now time.Time) []string {
+	typing.mutex.Lock()
+	defer typing.mutex.Unlock()
+
+	users, ok := typing.channels[channelID]
+	if !ok {
+		return nil
+	}
+
+	userIDs := make([]string, 0, len(users))
+	for userID, start := range users {
+		if now.Sub(start) >= typingTimeout {
+			delete(users, userID)
+		} else {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	if len(users) == 0 {
+		delete(typing.channels, channelID)
+		return nil
+	}
+
+	sort.Slice(userIDs, func(a, b int) bool {
+		startA, startB := users[userIDs[a]], users[userIDs[b]]
+		if startA.Equal(startB) {
+			return userIDs[a] < userIDs[b]
+		}
+		return startA.Before(startB)
+	})
+	return userIDs
+}
+
+// typingThrottle decides whether a typing notification has to be sent. Only
+// one notification per typingInterval is sent for the same channel.
+type typingThrottle struct {
+	channelID string
+	lastSent  time.Time
+}
+
+// shouldSend returns true if a notification for the given channel should be
+// sent at the given point in time and remembers the decision.
+func (throttle *typingThrottle) shouldSend(channelID string, now time.Time) bool {
+	if throttle.channelID == channelID && now.Sub(throttle.lastSent) < typingInterval {
+		return false
+	}
+
+	throttle.channelID = channelID
+	throttle.lastSent = now
+	return true
+}
+
+// reset causes the next call to shouldSend to return true. This is required
+// after sending a message, as discord stops showing the author as typing.
+func (throttle *typingThrottle) reset() {
+	throttle.channelID = ""
+	throttle.lastSent = time.Time{}
+}
+
+// formatTypingUsers creates a human readable text telling which users are
+// typing. If nobody is typing, an empty string is returned.
+func formatTypingUsers(names []string) string {
+	switch {
+	case len(names) == 0:
+		return ""
+	case len(names) == 1:
+		return names[0] + " is typing…"
+	case len(names) > maxNamedTypingUsers:
+		return "Several people are typing…"
+	default:
+		return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1] + " are typing…"
+	}
+}
+
+// getTypingUserName resolves the name that is shown for a typing user in the
+// given channel. Nicknames are preferred in guild channels.
+func (window *Window) getTypingUserName(channel *discordgo.Channel, userID string) string {
+	if channel.GuildID != "" {
+		member, stateError := window.session.State.Member(channel.GuildID, userID)
+		if stateError == nil {
+			return discordutil.GetMemberName(member)
+		}
+	}
+
+	for _, recipient := range channel.Recipients {
+		if recipient.ID == userID {
+			return discordutil.GetUserName(recipient)
+		}
+	}
+
+	for _, relationship := range window.session.State.Relationships {
+		if relationship.User != nil && relationship.User.ID == userID {
+			return discordutil.GetUserName(relationship.User)
+		}
+	}
+
+	return "Someone"
+}
+
+// updateTypingIndicator shows the users that are currently typing in the
+// loaded channel. The indicator is hidden if nobody is typing. This has to
+// be called on the UI thread.
+func (window *Window) updateTypingIndicator() {
+	var text string
+	if window.selectedChannel != nil {
+		userIDs := window.typingUsers.get(window.selectedChannel.ID, time.Now())
+		names := make([]string, 0, len(userIDs))
+		for _, userID := range userIDs {
+			names = append(names, window.getTypingUserName(window.selectedChannel, userID))
+		}
+		text = formatTypingUsers(names)
+	}
+
+	window.typingIndicator.SetText(text)
+	window.typingIndicator.SetVisible(text != "")
+}
+
+// queueTypingIndicatorUpdate updates the typing indicator from outside of the
+// UI thread, once right away and once the typing event has expired.
+func (window *Window) queueTypingIndicatorUpdate() {
+	window.app.QueueUpdateDraw(window.updateTypingIndicator)
+	time.AfterFunc(typingTimeout, func() {
+		window.app.QueueUpdateDraw(window.updateTypingIndicator)
+	})
+}
+
+// announceTyping lets others in the loaded channel know that we are typing,
+// unless disabled by the user. Announcements are throttled, since discord
+// shows a user as typing for typingTimeout anyway.
+func (window *Window) announceTyping() {
+	if !config.Current.SendTypingNotifications || window.selectedChannel == nil ||
+		window.editingMessageID != nil {
+		return
+	}
+
+	channelID := window.selectedChannel.ID
+	if !window.typingThrottle.shouldSend(channelID, time.Now()) {
+		return
+	}
+
+	go func() {
+		typingError := window.session.ChannelTyping(channelID)
+		if typingError != nil {
+			log.Printf("Error sending typing notification: %s\n", typingError)
+		}
+	}()
+}
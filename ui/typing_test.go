@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTypingUsers(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	typing := newTypingUsers()
+	typing.add("1", "bob", start.Add(2*time.Second))
+	typing.add("1", "alice", start)
+	typing.add("1", "carol", start.Add(2*time.Second))
+	typing.add("2", "dave", start)
+
+	tests := []struct {
+		name      string
+		prepare   func()
+		channelID string
+		now       time.Time
+		want      []string
+	}{
+		{
+			name:      "ordered by start",
+			channelID: "1",
+			now:       start.Add(5 * time.Second),
+			want:      []string{"alice", "bob", "carol"},
+		}, {
+			name:      "unknown channel",
+			channelID: "3",
+			now:       start,
+			want:      nil,
+		}, {
+			name:      "removed after message",
+			prepare:   func() { typing.remove("1", "bob") },
+			channelID: "1",
+			now:       start.Add(5 * time.Second),
+			want:      []string{"alice", "carol"},
+		}, {
+			name:      "restarted typing",
+			prepare:   func() { typing.add("1", "alice", start.Add(9*time.Second)) },
+			channelID: "1",
+			now:       start.Add(12 * time.Second),
+			want:      []string{"alice"},
+		}, {
+			name:      "expired",
+			channelID: "2",
+			now:       start.Add(typingTimeout),
+			want:      nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.prepare != nil {
+				tt.prepare()
+			}
+			if got := typing.get(tt.channelID, tt.now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("typingUsers.get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypingThrottle(t *testing.T) {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	throttle := &typingThrottle{}
+
+	tests := []struct {
+		name      string
+		reset     bool
+		channelID string
+		now       time.Time
+		want      bool
+	}{
+		{
+			name:      "first notification",
+			channelID: "1",
+			now:       start,
+			want:      true,
+		}, {
+			name:      "within interval",
+			channelID: "1",
+			now:       start.Add(time.Second),
+			want:      false,
+		}, {
+			name:      "other channel",
+			channelID: "2",
+			now:       start.Add(2 * time.Second),
+			want:      true,
+		}, {
+			name:      "interval passed",
+			channelID: "2",
+			now:       start.Add(2*time.Second + typingInterval),
+			want:      true,
+		}, {
+			name:      "after reset",
+			reset:     true,
+			channelID: "2",
+			now:       start.Add(3*time.Second + typingInterval),
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.reset {
+				throttle.reset()
+			}
+			if got := throttle.shouldSend(tt.channelID, tt.now); got != tt.want {
+				t.Errorf("typingThrottle.shouldSend() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTypingUsers(t *testing.T) {
+	tests := []struct {
+		name  string
+		names []string
+		want  string
+	}{
+		{
+			name: "nobody",
+			want: "",
+		}, {
+			name:  "one",
+			names: []string{"alice"},
+			want:  "alice is typing…",
+		}, {
+			name:  "two",
+			names: []string{"alice", "bob"},
+			want:  "alice and bob are typing…",
+		}, {
+			name:  "three",
+			names: []string{"alice", "bob", "carol"},
+			want:  "alice, bob and carol are typing…",
+		}, {
+			name:  "several",
+			names: []string{"alice", "bob", "carol", "dave"},
+			want:  "Several people are typing…",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatTypingUsers(tt.names); got != tt.want {
+				t.Errorf("formatTypingUsers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,11 +21,17 @@ type UserTree struct {
 
 	state *discordgo.State
 
-	userNodes map[string]*tview.TreeNode
-	roleNodes map[string]*tview.TreeNode
-	roles     []*discordgo.Role
-
+	userNodes   map[string]*tview.TreeNode
+	userParents map[string]*tview.TreeNode
+	roleNodes   map[string]*tview.TreeNode
+	onlineNode  *tview.TreeNode
+	offlineNode *tview.TreeNode
+	roles       []*discordgo.Role
+
+	guildID   string
 	loadedFor interface{}
+
+	getNote func(userID string) string
 }
 
 // NewUserTree creates a new pre-configured UserTree that is empty.
@@ -42,10 +48,47 @@ func NewUserTree(state *discordgo.State) *UserTree {
 		SetTopLevel(1).
 		SetCycleSelection(true).
 		SetBorder(true)
+	userTree.internalTreeView.SetChangedFunc(userTree.showNote)
 
 	return userTree
 }
 
+// SetNoteSupplier sets the function that looks up the notes on users. The
+// note on the selected user is shown in the title of the tree.
+func (userTree *UserTree) SetNoteSupplier(getNote func(userID string) string) {
+	userTree.getNote = getNote
+}
+
+// RefreshNote shows the current note on the selected user. This has to be
+// called after changing the note.
+func (userTree *UserTree) RefreshNote() {
+	userTree.showNote(userTree.internalTreeView.GetCurrentNode())
+}
+
+// showNote shows the note on the user represented by the given node in the
+// title of the tree. If there's no note, the title is cleared.
+func (userTree *UserTree) showNote(node *tview.TreeNode) {
+	if note := userTree.getNodeNote(node); note == "" {
+		userTree.internalTreeView.SetTitle("")
+	} else {
+		userTree.internalTreeView.SetTitle("Note: " + tviewutil.Escape(note))
+	}
+}
+
+// getNodeNote returns the note on the user represented by the given node.
+// If the node doesn't represent a user, the note is empty.
+func (userTree *UserTree) getNodeNote(node *tview.TreeNode) string {
+	if node == nil || userTree.getNote == nil {
+		return ""
+	}
+
+	if userID, isUser := node.GetReference().(string); isUser {
+		return userTree.getNote(userID)
+	}
+
+	return ""
+}
+
 // Clear removes all nodes and data out of the view.
 func (userTree *UserTree) Clear() {
 	userTree.Lock()
@@ -67,8 +110,13 @@ func (userTree *UserTree) clear() {
 	// After clearing, we don't reallocate anything, since we don't know
 	// whether we actually want to repopulate the tree.
 	userTree.userNodes = nil
+	userTree.userParents = nil
 	userTree.roleNodes = nil
+	userTree.onlineNode = nil
+	userTree.offlineNode = nil
 	userTree.roles = nil
+	userTree.guildID = ""
+	userTree.internalTreeView.SetTitle("")
 }
 
 // LoadGroup loads all users for a group-channel.
@@ -88,6 +136,7 @@ func (userTree *UserTree) LoadGroup(channelID string) error {
 
 	userTree.clear()
 	userTree.userNodes = make(map[string]*tview.TreeNode)
+	userTree.userParents = make(map[string]*tview.TreeNode)
 	userTree.roleNodes = make(map[string]*tview.TreeNode)
 
 	userTree.addOrUpdateUsers(channel.Recipients)
@@ -114,9 +163,47 @@ func (userTree *UserTree) LoadGuild(guildID string) error {
 		return nil
 	}
 
+	return userTree.loadGuild(guild)
+}
+
+// UpdateRoles rebuilds the role sections of the tree, after the roles of
+// the given guild have been created, edited or deleted. The currently
+// selected user stays selected.
+func (userTree *UserTree) UpdateRoles(guildID string) error {
+	userTree.Lock()
+	defer userTree.Unlock()
+	if !userTree.isLoaded() || userTree.guildID != guildID {
+		return nil
+	}
+
+	guild, stateError := userTree.state.Guild(guildID)
+	if stateError != nil {
+		return stateError
+	}
+
+	var selectedUserID string
+	if selectedNode := userTree.internalTreeView.GetCurrentNode(); selectedNode != nil {
+		selectedUserID, _ = selectedNode.GetReference().(string)
+	}
+
+	loadError := userTree.loadGuild(guild)
+	if loadError != nil {
+		return loadError
+	}
+
+	if userNode, contains := userTree.userNodes[selectedUserID]; contains {
+		userTree.internalTreeView.SetCurrentNode(userNode)
+	}
+
+	return nil
+}
+
+func (userTree *UserTree) loadGuild(guild *discordgo.Guild) error {
 	userTree.clear()
 	userTree.userNodes = make(map[string]*tview.TreeNode)
+	userTree.userParents = make(map[string]*tview.TreeNode)
 	userTree.roleNodes = make(map[string]*tview.TreeNode)
+	userTree.guildID = guild.ID
 
 	guildRoles, roleLoadError := userTree.loadGuildRoles(guild)
 	if roleLoadError != nil {
@@ -124,6 +211,13 @@ func (userTree *UserTree) LoadGuild(guildID string) error {
 	}
 	userTree.roles = guildRoles
 
+	//Just like the official client, members without a hoisted role and
+	//members that are offline are listed separately.
+	userTree.onlineNode = tview.NewTreeNode("Online").SetSelectable(false)
+	userTree.offlineNode = tview.NewTreeNode("Offline").SetSelectable(false)
+	userTree.rootNode().AddChild(userTree.onlineNode)
+	userTree.rootNode().AddChild(userTree.offlineNode)
+
 	userLoadError := userTree.loadGuildMembers(guild)
 	if userLoadError != nil {
 		return userLoadError
@@ -142,6 +236,7 @@ func (userTree *UserTree) selectFirstNode() {
 			userTree.internalTreeView.SetCurrentNode(userTree.rootNode().GetChildren()[0])
 		}
 	}
+	userTree.RefreshNote()
 }
 
 func (userTree *UserTree) loadGuildMembers(guild *discordgo.Guild) error {
@@ -193,29 +288,81 @@ func (userTree *UserTree) AddOrUpdateMember(member *discordgo.Member) {
 }
 
 func (userTree *UserTree) addOrUpdateMember(member *discordgo.Member) {
-	nameToUse := "[" + discordutil.GetMemberColor(userTree.state, member) +
-		"]" + discordutil.GetMemberName(member)
+	presence := discordutil.GetPresence(userTree.state, userTree.guildID, member.User.ID)
+	nameToUse := withPresence("["+discordutil.GetMemberColor(userTree.state, member)+
+		"]"+discordutil.GetMemberName(member), presence)
 
-	userNode, contains := userTree.userNodes[member.User.ID]
-	if contains && userNode != nil {
-		userNode.SetText(nameToUse)
-		return
-	}
+	userNode := userTree.createOrUpdateUserNode(member.User.ID, nameToUse)
+	userTree.moveUserNode(member.User.ID, userNode, userTree.getMemberParentNode(member, presence))
+}
 
-	userNode = tview.NewTreeNode(nameToUse)
-	userTree.userNodes[member.User.ID] = userNode
+// getMemberParentNode decides which section a member is shown in. Offline
+// members are always shown in the offline section, while online members are
+// shown under their highest hoisted role.
+func (userTree *UserTree) getMemberParentNode(member *discordgo.Member, presence *discordgo.Presence) *tview.TreeNode {
+	if discordutil.IsOffline(discordutil.GetPresenceStatus(presence)) {
+		return userTree.offlineNode
+	}
 
 	discordutil.SortUserRoles(member.Roles, userTree.roles)
 
 	for _, userRole := range member.Roles {
 		roleNode, exists := userTree.roleNodes[userRole]
 		if exists && roleNode != nil {
-			roleNode.AddChild(userNode)
+			return roleNode
+		}
+	}
+
+	return userTree.onlineNode
+}
+
+func (userTree *UserTree) createOrUpdateUserNode(userID, text string) *tview.TreeNode {
+	userNode, contains := userTree.userNodes[userID]
+	if contains && userNode != nil {
+		userNode.SetText(text)
+		return userNode
+	}
+
+	userNode = tview.NewTreeNode(text)
+	userNode.SetReference(userID)
+	userTree.userNodes[userID] = userNode
+	return userNode
+}
+
+// moveUserNode adds the node to the given parent, removing it from its
+// previous parent if necessary.
+func (userTree *UserTree) moveUserNode(userID string, userNode, parent *tview.TreeNode) {
+	previousParent, contains := userTree.userParents[userID]
+	if contains {
+		if previousParent == parent {
 			return
 		}
+		removeChildNode(previousParent, userNode)
+	}
+
+	parent.AddChild(userNode)
+	userTree.userParents[userID] = parent
+}
+
+func removeChildNode(parent, child *tview.TreeNode) {
+	newChildren := make([]*tview.TreeNode, 0, len(parent.GetChildren()))
+	for _, node := range parent.GetChildren() {
+		if node != child {
+			newChildren = append(newChildren, node)
+		}
+	}
+	parent.SetChildren(newChildren)
+}
+
+// withPresence prepends a status symbol to the given node text and appends
+// the users current activity or custom status, if there's any.
+func withPresence(text string, presence *discordgo.Presence) string {
+	text = discordutil.GetStatusSymbol(discordutil.GetPresenceStatus(presence)) + " " + text
+	if activity := discordutil.GetPresenceActivity(presence); activity != "" {
+		text += " [" + tviewutil.ColorToHex(config.GetTheme().InfoMessageColor) + "]" + tviewutil.Escape(activity)
 	}
 
-	userTree.rootNode().AddChild(userNode)
+	return text
 }
 
 // AddOrUpdateUser adds a user to the tree, unless the user already exists,
@@ -230,18 +377,11 @@ func (userTree *UserTree) AddOrUpdateUser(user *discordgo.User) {
 }
 
 func (userTree *UserTree) addOrUpdateUser(user *discordgo.User) {
-	nameToUse := "[" + discordutil.GetUserColor(user) +
-		"]" + discordutil.GetUserName(user)
-
-	userNode, contains := userTree.userNodes[user.ID]
-	if contains && userNode != nil {
-		userNode.SetText(nameToUse)
-		return
-	}
+	nameToUse := withPresence("["+discordutil.GetUserColor(user)+
+		"]"+discordutil.GetUserName(user), discordutil.GetPresence(userTree.state, "", user.ID))
 
-	userNode = tview.NewTreeNode(nameToUse)
-	userTree.userNodes[user.ID] = userNode
-	userTree.rootNode().AddChild(userNode)
+	userNode := userTree.createOrUpdateUserNode(user.ID, nameToUse)
+	userTree.moveUserNode(user.ID, userNode, userTree.rootNode())
 }
 
 // AddOrUpdateUsers adds users to the tree, unless they already exists, in that
@@ -289,36 +429,26 @@ func (userTree *UserTree) RemoveMember(member *discordgo.Member) {
 }
 
 func (userTree *UserTree) removeMember(member *discordgo.Member) {
-	userNode, contains := userTree.userNodes[member.User.ID]
-	if contains {
-		userTree.rootNode().Walk(func(node, parent *tview.TreeNode) bool {
-			if node == userNode {
-				if len(parent.GetChildren()) == 1 {
-					parent.SetChildren(make([]*tview.TreeNode, 0))
-				} else {
-					indexToDelete := -1
-					for index, child := range parent.GetChildren() {
-						if child == node {
-							indexToDelete = index
-							break
-						}
-					}
-
-					if indexToDelete == 0 {
-						parent.SetChildren(parent.GetChildren()[1:])
-					} else if indexToDelete == len(parent.GetChildren())-1 {
-						parent.SetChildren(parent.GetChildren()[:len(parent.GetChildren())-1])
-					} else {
-						parent.SetChildren(append(parent.GetChildren()[0:indexToDelete],
-							parent.GetChildren()[indexToDelete+1:]...))
-					}
-				}
-
-				return false
-			}
+	userTree.removeUser(member.User.ID)
+}
 
-			return true
-		})
+// RemoveUser removes the node of the given user, for example after the user
+// has left a group.
+func (userTree *UserTree) RemoveUser(userID string) {
+	userTree.Lock()
+	defer userTree.Unlock()
+	if !userTree.isLoaded() {
+		return
+	}
+	userTree.removeUser(userID)
+}
+
+func (userTree *UserTree) removeUser(userID string) {
+	userNode, contains := userTree.userNodes[userID]
+	if contains {
+		removeChildNode(userTree.userParents[userID], userNode)
+		delete(userTree.userNodes, userID)
+		delete(userTree.userParents, userID)
 	}
 }
 
@@ -334,6 +464,80 @@ func (userTree *UserTree) RemoveMembers(members []*discordgo.Member) {
 	}
 }
 
+// UpdatePresence refreshes the node of the given user after their presence
+// has changed. In guilds, the node is moved into the correct section.
+func (userTree *UserTree) UpdatePresence(guildID string, user *discordgo.User) {
+	userTree.Lock()
+	defer userTree.Unlock()
+	if !userTree.isLoaded() {
+		return
+	}
+
+	if guildID != "" {
+		if guildID != userTree.guildID {
+			return
+		}
+
+		member, stateError := userTree.state.Member(guildID, user.ID)
+		if stateError == nil {
+			userTree.addOrUpdateMember(member)
+		}
+		return
+	}
+
+	channel, isChannel := userTree.loadedFor.(*discordgo.Channel)
+	if !isChannel {
+		return
+	}
+
+	for _, recipient := range channel.Recipients {
+		if recipient.ID == user.ID {
+			userTree.addOrUpdateUser(recipient)
+			return
+		}
+	}
+}
+
+// SetOnUserSelect sets the handler that is called when a user node is
+// selected.
+func (userTree *UserTree) SetOnUserSelect(handler func(user *discordgo.User)) {
+	userTree.internalTreeView.SetSelectedFunc(func(node *tview.TreeNode) {
+		userID, ok := node.GetReference().(string)
+		if !ok {
+			return
+		}
+
+		userTree.Lock()
+		user := userTree.getUser(userID)
+		userTree.Unlock()
+
+		if user != nil {
+			handler(user)
+		}
+	})
+}
+
+// getUser finds a user that is part of the currently loaded guild or group.
+func (userTree *UserTree) getUser(userID string) *discordgo.User {
+	if userTree.guildID != "" {
+		member, stateError := userTree.state.Member(userTree.guildID, userID)
+		if stateError == nil {
+			return member.User
+		}
+		return nil
+	}
+
+	if channel, isChannel := userTree.loadedFor.(*discordgo.Channel); isChannel {
+		for _, recipient := range channel.Recipients {
+			if recipient.ID == userID {
+				return recipient
+			}
+		}
+	}
+
+	return nil
+}
+
 //SetInputCapture delegates to tviews SetInputCapture
 func (userTree *UserTree) SetInputCapture(capture func(event *tcell.EventKey) *tcell.EventKey) {
 	userTree.internalTreeView.SetInputCapture(capture)
@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/discordgo"
+
+	"github.com/Bios-Marcel/cordless/tview"
+)
+
+func TestUserTreePresenceSections(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "self"}
+
+	alice := &discordgo.User{ID: "alice", Username: "alice"}
+	bob := &discordgo.User{ID: "bob", Username: "bob"}
+	carol := &discordgo.User{ID: "carol", Username: "carol"}
+	guild := &discordgo.Guild{
+		ID: "G1",
+		Roles: []*discordgo.Role{
+			{ID: "G1", Name: "@everyone"},
+			{ID: "mod", Name: "Moderators", Hoist: true, Position: 1},
+		},
+		Members: []*discordgo.Member{
+			{GuildID: "G1", User: alice, Roles: []string{"mod"}},
+			{GuildID: "G1", User: bob},
+			{GuildID: "G1", User: carol, Roles: []string{"mod"}},
+		},
+		Presences: []*discordgo.Presence{
+			{User: alice, Status: discordgo.StatusOnline},
+			{User: bob, Status: discordgo.StatusIdle},
+		},
+	}
+	if err := state.GuildAdd(guild); err != nil {
+		t.Fatal(err)
+	}
+
+	userTree := NewUserTree(state)
+	if err := userTree.LoadGuild("G1"); err != nil {
+		t.Fatal(err)
+	}
+
+	sectionOf := func(userID string) *tview.TreeNode {
+		for _, section := range userTree.rootNode().GetChildren() {
+			for _, node := range section.GetChildren() {
+				if node == userTree.userNodes[userID] {
+					return section
+				}
+			}
+		}
+		return nil
+	}
+
+	modNode := userTree.roleNodes["mod"]
+	tests := []struct {
+		name     string
+		userID   string
+		presence *discordgo.Presence
+		want     *tview.TreeNode
+	}{
+		{
+			name:   "online with hoisted role",
+			userID: "alice",
+			want:   modNode,
+		}, {
+			name:   "online without hoisted role",
+			userID: "bob",
+			want:   userTree.onlineNode,
+		}, {
+			name:   "offline with hoisted role",
+			userID: "carol",
+			want:   userTree.offlineNode,
+		}, {
+			name:     "coming online",
+			userID:   "carol",
+			presence: &discordgo.Presence{User: carol, Status: discordgo.StatusDoNotDisturb},
+			want:     modNode,
+		}, {
+			name:     "going offline",
+			userID:   "bob",
+			presence: &discordgo.Presence{User: bob, Status: discordgo.StatusOffline},
+			want:     userTree.offlineNode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.presence != nil {
+				if err := state.PresenceAdd("G1", tt.presence); err != nil {
+					t.Fatal(err)
+				}
+				userTree.UpdatePresence("G1", tt.presence.User)
+			}
+
+			if got := sectionOf(tt.userID); got != tt.want {
+				t.Errorf("user %s is in section %v, want %v", tt.userID, got, tt.want)
+			}
+		})
+	}
+
+	if len(userTree.offlineNode.GetChildren()) != 1 {
+		t.Errorf("offline section should only contain bob, but has %d nodes", len(userTree.offlineNode.GetChildren()))
+	}
+}
+
+func TestUserTreeUpdateRoles(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "self"}
+
+	alice := &discordgo.User{ID: "alice", Username: "alice"}
+	bob := &discordgo.User{ID: "bob", Username: "bob"}
+	guild := &discordgo.Guild{
+		ID: "G1",
+		Roles: []*discordgo.Role{
+			{ID: "G1", Name: "@everyone"},
+			{ID: "mod", Name: "Moderators", Position: 1},
+		},
+		Members: []*discordgo.Member{
+			{GuildID: "G1", User: alice, Roles: []string{"mod"}},
+			{GuildID: "G1", User: bob},
+		},
+		Presences: []*discordgo.Presence{
+			{User: alice, Status: discordgo.StatusOnline},
+			{User: bob, Status: discordgo.StatusOnline},
+		},
+	}
+	if err := state.GuildAdd(guild); err != nil {
+		t.Fatal(err)
+	}
+
+	userTree := NewUserTree(state)
+	if err := userTree.LoadGuild("G1"); err != nil {
+		t.Fatal(err)
+	}
+	userTree.internalTreeView.SetCurrentNode(userTree.userNodes["alice"])
+
+	if len(userTree.roleNodes) != 0 {
+		t.Fatalf("roles that aren't hoisted mustn't have a section, but got %d", len(userTree.roleNodes))
+	}
+
+	tests := []struct {
+		name     string
+		role     *discordgo.Role
+		remove   bool
+		wantRole bool
+	}{
+		{
+			name:     "hoisting role",
+			role:     &discordgo.Role{ID: "mod", Name: "Moderators", Position: 1, Hoist: true},
+			wantRole: true,
+		}, {
+			name:     "unhoisting role",
+			role:     &discordgo.Role{ID: "mod", Name: "Moderators", Position: 1},
+			wantRole: false,
+		}, {
+			name:     "hoisting role again",
+			role:     &discordgo.Role{ID: "mod", Name: "Mods", Position: 1, Hoist: true},
+			wantRole: true,
+		}, {
+			name:     "deleting role",
+			role:     &discordgo.Role{ID: "mod"},
+			remove:   true,
+			wantRole: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.remove {
+				err = state.RoleRemove("G1", tt.role.ID)
+			} else {
+				err = state.RoleAdd("G1", tt.role)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := userTree.UpdateRoles("G1"); err != nil {
+				t.Fatal(err)
+			}
+
+			roleNode, hasRole := userTree.roleNodes["mod"]
+			if hasRole != tt.wantRole {
+				t.Fatalf("role section exists = %v, want %v", hasRole, tt.wantRole)
+			}
+
+			wantParent := userTree.onlineNode
+			if tt.wantRole {
+				wantParent = roleNode
+			}
+			if got := userTree.userParents["alice"]; got != wantParent {
+				t.Errorf("alice is in section %v, want %v", got, wantParent)
+			}
+			if got := userTree.userParents["bob"]; got != userTree.onlineNode {
+				t.Errorf("bob is in section %v, want online section", got)
+			}
+			if userTree.internalTreeView.GetCurrentNode() != userTree.userNodes["alice"] {
+				t.Error("selection hasn't been kept")
+			}
+		})
+	}
+
+	if err := userTree.UpdateRoles("G2"); err != nil {
+		t.Errorf("updating roles of another guild should be ignored, but got %v", err)
+	}
+}
+
+func TestUserTreeNotes(t *testing.T) {
+	state := discordgo.NewState()
+	state.User = &discordgo.User{ID: "self"}
+
+	alice := &discordgo.User{ID: "alice", Username: "alice"}
+	bob := &discordgo.User{ID: "bob", Username: "bob"}
+	if err := state.ChannelAdd(&discordgo.Channel{
+		ID:         "group",
+		Type:       discordgo.ChannelTypeGroupDM,
+		Recipients: []*discordgo.User{alice, bob},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	notes := map[string]string{"alice": "Likes [Go]"}
+	userTree := NewUserTree(state)
+	userTree.SetNoteSupplier(func(userID string) string {
+		return notes[userID]
+	})
+	if err := userTree.LoadGroup("group"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		userID string
+		update func()
+		want   string
+	}{
+		{
+			name:   "user with note",
+			userID: "alice",
+			want:   "Likes [Go]",
+		}, {
+			name:   "user without note",
+			userID: "bob",
+			want:   "",
+		}, {
+			name:   "note added",
+			userID: "bob",
+			update: func() {
+				notes["bob"] = "Plays chess"
+			},
+			want: "Plays chess",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.update != nil {
+				tt.update()
+			}
+			userTree.internalTreeView.SetCurrentNode(userTree.userNodes[tt.userID])
+			if got := userTree.getNodeNote(userTree.internalTreeView.GetCurrentNode()); got != tt.want {
+				t.Errorf("note of the selected user = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,10 @@ package ui
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
@@ -37,6 +40,7 @@ import (
 	"github.com/Bios-Marcel/cordless/readstate"
 	"github.com/Bios-Marcel/cordless/scripting"
 	"github.com/Bios-Marcel/cordless/scripting/js"
+	"github.com/Bios-Marcel/cordless/scripting/lua"
 	"github.com/Bios-Marcel/cordless/shortcuts"
 	"github.com/Bios-Marcel/cordless/ui/components"
 	"github.com/Bios-Marcel/cordless/ui/shortcutdialog"
@@ -69,6 +73,10 @@ type Window struct {
 	messageContainer tview.Primitive
 	messageInput     *Editor
 
+	typingIndicator *tview.TextView
+	typingUsers     *typingUsers
+	typingThrottle  typingThrottle
+
 	editingMessageID *string
 	messageLoader    *discordutil.MessageLoader
 
@@ -81,13 +89,18 @@ type Window struct {
 	previousChannel *discordgo.Channel
 
 	extensionEngines []scripting.Engine
+	scriptStorage    *scripting.Storage
+
+	notes *discordutil.Notes
 
 	commandMode bool
 	commandView *CommandView
 	commands    []commands.Command
 
-	userActive      bool
-	userActiveTimer *time.Timer
+	userActivityTimer *inactivityTimer
+	autoIdleTimer     *inactivityTimer
+	autoIdle          *autoIdle
+	statusExpiry      *statusExpiry
 
 	bareChat   bool
 	activeView ActiveView
@@ -106,25 +119,47 @@ func NewWindow(app *tview.Application, session *discordgo.Session, readyEvent *d
 		session:          session,
 		app:              app,
 		activeView:       Guilds,
-		extensionEngines: []scripting.Engine{js.New()},
+		extensionEngines: []scripting.Engine{js.New(), lua.New()},
 		messageLoader:    discordutil.CreateMessageLoader(session),
+		typingUsers:      newTypingUsers(),
 	}
 
 	if config.Current.DesktopNotificationsUserInactivityThreshold > 0 {
-		window.userActiveTimer = time.NewTimer(time.Duration(config.Current.DesktopNotificationsUserInactivityThreshold) * time.Second)
-		go func() {
-			for {
-				<-window.userActiveTimer.C
-				window.userActive = false
-			}
-		}()
+		window.userActivityTimer = newInactivityTimer(
+			time.Duration(config.Current.DesktopNotificationsUserInactivityThreshold)*time.Second, false, nil, nil)
 	}
 
-	window.commandView = NewCommandView(window.app, window.ExecuteCommand)
+	//Without a config directory, the history only lives in memory.
+	historyFile, _ := config.GetCommandHistoryFile()
+	commandHistory := commands.NewHistory(historyFile, config.Current.CommandHistorySize)
+	historyError := commandHistory.Load()
+
+	window.commandView = NewCommandView(window.app, commandHistory, window.GetRegisteredCommands, window.ExecuteCommand)
 	logging.SetAdditionalOutput(window.commandView)
+	if historyError != nil {
+		commands.PrintError(window.commandView, "Error loading command history", historyError.Error())
+	}
+
+	//Without a config directory, the notes only live in memory.
+	notesFile, _ := config.GetNotesFile()
+	notes, notesError := discordutil.LoadNotes(notesFile)
+	if notesError != nil {
+		commands.PrintError(window.commandView, "Error loading notes", notesError.Error())
+	}
+	window.notes = notes
+	window.mergeNotes(readyEvent.Notes)
+
+	//A broken storage shouldn't prevent cordless from starting, therefore
+	//the scripts start out with an empty storage instead. The broken file
+	//is kept, so that the user can fix it.
+	scriptStorage, storageError := scripting.LoadStorage(config.GetScriptStorageFile())
+	if storageError != nil {
+		commands.PrintError(window.commandView, "Error loading script storage", storageError.Error())
+	}
+	window.scriptStorage = scriptStorage
 
 	for _, engine := range window.extensionEngines {
-		initError := window.initExtensionEngine(engine)
+		initError := window.initExtensionEngine(engine, scriptStorage)
 		if initError != nil {
 			return nil, initError
 		}
@@ -196,6 +231,9 @@ func NewWindow(app *tview.Application, session *discordgo.Session, readyEvent *d
 
 	window.registerGuildHandlers()
 	window.registerGuildMemberHandlers()
+	window.registerGuildRoleHandlers()
+	window.registerNoteHandler()
+	window.startStatusTracking()
 
 	window.guildPage.AddItem(guildList, 0, 1, true)
 	window.guildPage.AddItem(channelTree, 0, 2, false)
@@ -274,6 +312,42 @@ func NewWindow(app *tview.Application, session *discordgo.Session, readyEvent *d
 			return nil
 		}
 
+		if shortcuts.TogglePinSelectedMessage.Equals(event) {
+			window.togglePinnedState(message)
+			return nil
+		}
+
+		if shortcuts.ReactToSelectedMessage.Equals(event) {
+			window.ShowReactionPicker(message)
+			return nil
+		}
+
+		if shortcuts.ShowAuthorProfile.Equals(event) {
+			window.ShowUserProfile(message.Author, getMessageGuildID(window.session.State, message))
+			return nil
+		}
+
+		if shortcuts.ShowMentionedUserProfile.Equals(event) {
+			window.showMentionedUserProfile(message, getMessageGuildID(window.session.State, message))
+			return nil
+		}
+
+		if shortcuts.ToggleBlockAuthor.Equals(event) {
+			window.toggleBlocked(message.Author)
+			return nil
+		}
+
+		if shortcuts.ToggleIgnoreAuthor.Equals(event) {
+			window.toggleIgnored(message.Author, getMessageGuildID(window.session.State, message))
+			return nil
+		}
+
+		if shortcuts.JumpToMessage.Equals(event) && message.Type == discordgo.MessageTypeChannelPinnedMessage &&
+			message.MessageReference != nil && message.MessageReference.MessageID != "" {
+			window.JumpToMessage(message.MessageReference.MessageID)
+			return nil
+		}
+
 		if shortcuts.CopySelectedMessage.Equals(event) {
 			copyError := clipboard.WriteAll(discordutil.MessageToPlainText(message))
 			if copyError != nil {
@@ -395,6 +469,7 @@ func NewWindow(app *tview.Application, session *discordgo.Session, readyEvent *d
 
 		return event
 	})
+	window.chatView.SetOnMessageRender(window.renderMessageWithExtensionEngines)
 	window.messageContainer = window.chatView.GetPrimitive()
 
 	window.messageInput = NewEditor(window.app)
@@ -405,37 +480,23 @@ func NewWindow(app *tview.Application, session *discordgo.Session, readyEvent *d
 
 		window.chatArea.ResizeItem(window.messageInput.GetPrimitive(), newHeight, 0)
 	})
-
-	window.messageInput.SetAutocompleteValuesUpdateHandler(func(values []*AutocompleteValue) {
-		autocompleteView.GetRoot().ClearChildren()
-		if len(values) == 0 {
-			autocompleteView.SetVisible(false)
-			window.app.SetFocus(window.messageInput.GetPrimitive())
+	window.messageInput.SetOnTextChange(func(text string) {
+		//Clearing the input usually means that a message has been sent,
+		//after which discord doesn't show us as typing anymore.
+		if strings.TrimSpace(text) == "" {
+			window.typingThrottle.reset()
 		} else {
-			rootNode := autocompleteView.GetRoot()
-			for _, value := range values {
-				newNode := tview.NewTreeNode(value.RenderValue)
-				newNode.SetReference(value)
-				rootNode.AddChild(newNode)
-			}
-			autocompleteView.SetCurrentNode(rootNode)
-			autocompleteView.SetVisible(true)
-			window.app.SetFocus(autocompleteView)
-			_, _, _, height := window.app.GetRoot().GetRect()
-			//The preferred height is limited to a certain to avoid the
-			//autocomplete taking too much space in small windows, or
-			//furthermore terminals with a big font size.
-			prefHeight := maths.Min(maths.Min(10, height/4), len(values))
-			window.chatArea.ResizeItem(autocompleteView, prefHeight, 0)
+			window.announceTyping()
 		}
 	})
 
-	autocompleteView.SetSelectedFunc(func(node *tview.TreeNode) {
-		value := node.GetReference().(*AutocompleteValue)
-		window.messageInput.Autocomplete(value.InsertValue)
-		window.app.SetFocus(window.messageInput.GetPrimitive())
-		autocompleteView.SetVisible(false)
-	})
+	window.typingIndicator = tview.NewTextView()
+	window.typingIndicator.SetDynamicColors(true)
+	window.typingIndicator.SetBorder(false)
+	window.typingIndicator.SetVisible(false)
+
+	window.setupAutocompleteView(window.messageInput, autocompleteView)
+	window.setupAutocompleteView(window.commandView.commandInput, window.commandView.GetCommandAutocompleteWidget())
 
 	window.messageInput.RegisterAutocomplete('#', false, func(value string) []*AutocompleteValue {
 		if window.selectedChannel != nil && window.selectedChannel.GuildID != "" {
@@ -743,8 +804,18 @@ func NewWindow(app *tview.Application, session *discordgo.Session, readyEvent *d
 	window.registerMessageEventHandler(messageInputChan, messageEditChan, messageDeleteChan, messageBulkDeleteChan)
 	window.startMessageHandlerRoutines(messageInputChan, messageEditChan, messageDeleteChan, messageBulkDeleteChan)
 	window.registerReactionEventHandlers()
+	window.registerTypingEventHandler()
+	window.registerPresenceEventHandler()
 
 	window.userList = NewUserTree(window.session.State)
+	window.userList.SetNoteSupplier(window.notes.Get)
+	window.userList.SetOnUserSelect(func(user *discordgo.User) {
+		var guildID string
+		if window.selectedGuild != nil {
+			guildID = window.selectedGuild.ID
+		}
+		window.ShowUserProfile(user, guildID)
+	})
 
 	if config.Current.OnTypeInListBehaviour == config.SearchOnTypeInList {
 		guildList.SetSearchOnTypeEnabled(true)
@@ -819,6 +890,42 @@ func NewWindow(app *tview.Application, session *discordgo.Session, readyEvent *d
 		})
 	}
 
+	newPrivateListHandler := func(event *tcell.EventKey) *tcell.EventKey {
+		//Bots can't be part of groups.
+		if window.session.State.User.Bot {
+			return event
+		}
+
+		if shortcuts.PrivateChatsCreateGroup.Equals(event) {
+			window.ShowCreateGroup()
+			return nil
+		}
+
+		if shortcuts.PrivateChatsEditGroup.Equals(event) {
+			channel, stateError := window.session.State.Channel(window.privateList.GetSelectedChannelID())
+			if stateError == nil && channel.Type == discordgo.ChannelTypeGroupDM {
+				window.ShowGroupSettings(channel)
+			}
+			return nil
+		}
+
+		return event
+	}
+
+	oldPrivateListHandler := window.privateList.GetComponent().GetInputCapture()
+	if oldPrivateListHandler == nil {
+		window.privateList.SetInputCapture(newPrivateListHandler)
+	} else {
+		window.privateList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			handledEvent := newPrivateListHandler(event)
+			if handledEvent != nil {
+				return oldPrivateListHandler(event)
+			}
+
+			return event
+		})
+	}
+
 	//If another client acknowledges a message, we locally mark the channel as read.
 	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.MessageAck) {
 		window.app.QueueUpdateDraw(func() {
@@ -893,14 +1000,8 @@ func NewWindow(app *tview.Application, session *discordgo.Session, readyEvent *d
 
 	autocompleteView.SetVisible(false)
 
-	//All uncaptured events, e.g. events not relevant for TreeViews, will be
-	//forwarded to the message input, as both full typing capability, but also
-	//autocomplete capability should work at the same time.
-	autocompleteView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		return window.messageInput.internalTextView.GetInputCapture()(event)
-	})
-
 	window.chatArea.AddItem(window.messageContainer, 0, 1, false)
+	window.chatArea.AddItem(window.typingIndicator, 1, 0, false)
 	window.chatArea.AddItem(autocompleteView, 2, 2, true)
 	window.chatArea.AddItem(window.messageInput.GetPrimitive(), window.messageInput.GetRequestedHeight(), 0, false)
 
@@ -908,6 +1009,7 @@ func NewWindow(app *tview.Application, session *discordgo.Session, readyEvent *d
 	window.commandView.commandInput.internalTextView.SetVisible(false)
 
 	window.chatArea.AddItem(window.commandView.commandOutput, 0, 1, false)
+	window.chatArea.AddItem(window.commandView.GetCommandAutocompleteWidget(), 2, 2, false)
 	window.chatArea.AddItem(window.commandView.commandInput.internalTextView, 3, 0, false)
 
 	window.SwitchToGuildsPage()
@@ -953,6 +1055,23 @@ func NewWindow(app *tview.Application, session *discordgo.Session, readyEvent *d
 
 	window.chatView.internalTextView.SetText(getWelcomeText())
 
+	//The initial ready event has already been received before the window
+	//existed, therefore we have to pass it on manually. Ready events sent
+	//due to reconnects are handled by the event handler.
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.Ready) {
+		window.mergeNotes(event.Notes)
+		for _, engine := range window.extensionEngines {
+			engine.OnReady(event)
+		}
+	})
+	if len(window.extensionEngines) > 0 {
+		go func() {
+			for _, engine := range window.extensionEngines {
+				engine.OnReady(readyEvent)
+			}
+		}()
+	}
+
 	return window, nil
 }
 
@@ -1075,11 +1194,12 @@ important changes of the last two versions officially released.
 
 // initExtensionEngine injections necessary functions into the engine.
 // those functions can be called by each script inside of an engine.
-func (window *Window) initExtensionEngine(engine scripting.Engine) error {
+func (window *Window) initExtensionEngine(engine scripting.Engine, storage *scripting.Storage) error {
 	engine.SetErrorOutput(window.commandView.commandOutput)
-	if err := engine.LoadScripts(config.GetScriptDirectory()); err != nil {
-		return err
-	}
+	engine.SetStorage(storage)
+	//The scripts that have been loaded successfully still need the functions,
+	//therefore the error is only returned in the end.
+	loadError := engine.LoadScripts(config.GetScriptDirectory())
 
 	engine.SetTriggerNotificationFunction(func(title, text string) {
 		notifyError := beeep.Notify("Cordless - "+title, text, "assets/information.png")
@@ -1114,9 +1234,67 @@ func (window *Window) initExtensionEngine(engine scripting.Engine) error {
 		fmt.Fprintln(window.commandView, text)
 	})
 
+	return loadError
+}
+
+// ReloadScripts loads all scripts from the script directory again. The
+// previously loaded scripts are unloaded, cancelling all of their pending
+// timers. Since the connection has already been established, the onReady
+// callback of the freshly loaded scripts is called right away. All engines
+// are reloaded, even if one of them fails, and all errors are returned.
+func (window *Window) ReloadScripts() error {
+	var loadErrors []string
+	for _, engine := range window.extensionEngines {
+		if initError := window.initExtensionEngine(engine, window.scriptStorage); initError != nil {
+			loadErrors = append(loadErrors, initError.Error())
+		}
+	}
+
+	go func() {
+		window.session.State.RLock()
+		ready := window.session.State.Ready
+		window.session.State.RUnlock()
+
+		for _, engine := range window.extensionEngines {
+			engine.OnReady(&ready)
+		}
+	}()
+
+	if len(loadErrors) > 0 {
+		return errors.New(strings.Join(loadErrors, "\n"))
+	}
+
 	return nil
 }
 
+// renderMessageWithExtensionEngines asks all extension engines how the given
+// message should be displayed and merges their results. If no engine wants
+// to change anything, nil is returned.
+func (window *Window) renderMessageWithExtensionEngines(message *discordgo.Message) *scripting.MessageRender {
+	var render *scripting.MessageRender
+	for _, engine := range window.extensionEngines {
+		engineRender := engine.OnMessageRender(message)
+		if engineRender == nil {
+			continue
+		}
+
+		if engineRender.Hidden {
+			return engineRender
+		}
+
+		if render == nil {
+			render = engineRender
+		} else {
+			if engineRender.Text != "" {
+				render.Text = engineRender.Text
+			}
+			render.Tags = append(render.Tags, engineRender.Tags...)
+		}
+	}
+
+	return render
+}
+
 // OpenDirectMessage creates a new chat with the given user or loads an
 // already existing one. On success, the channel is loaded.
 func (window *Window) OpenDirectMessage(userID string) error {
@@ -1529,6 +1707,27 @@ func (window *Window) ShowDialog(color tcell.Color, text string, buttonHandler f
 	window.rootContainer.ResizeItem(window.dialogReplacement, height+2, 0)
 }
 
+// ShowConfirmationDialog asks the user whether the action should really be
+// executed. If so, the action is run in the background and its output is
+// shown in the command view afterwards. Commands have to use this instead of
+// writing into their writer from within a dialog, as the writer isn't valid
+// anymore once the command has returned.
+func (window *Window) ShowConfirmationDialog(question string, action func(writer io.Writer)) {
+	window.ShowDialog(config.GetTheme().PrimitiveBackgroundColor, question, func(button string) {
+		if button != "Yes" {
+			return
+		}
+
+		go func() {
+			var output bytes.Buffer
+			action(&output)
+			window.app.QueueUpdateDraw(func() {
+				window.commandView.Write(output.Bytes())
+			})
+		}()
+	}, "Yes", "No")
+}
+
 func (window *Window) registerMouseFocusListeners() {
 	window.chatView.internalTextView.SetMouseHandler(func(event *tcell.EventMouse) bool {
 		if event.Buttons() == tcell.Button1 {
@@ -1658,6 +1857,10 @@ func (window *Window) registerMessageEventHandler(input, edit, delete chan *disc
 // reactions are added or removed and updating the chatview if needed.
 func (window *Window) registerReactionEventHandlers() {
 	window.session.AddHandler(func(s *discordgo.Session, m *discordgo.MessageReactionAdd) {
+		for _, engine := range window.extensionEngines {
+			engine.OnReactionAdd(m.MessageReaction)
+		}
+
 		message, stateError := s.State.Message(m.ChannelID, m.MessageID)
 		if message != nil && stateError == nil {
 			s.State.Lock()
@@ -1679,6 +1882,10 @@ func (window *Window) registerReactionEventHandlers() {
 	})
 
 	window.session.AddHandler(func(s *discordgo.Session, m *discordgo.MessageReactionRemove) {
+		for _, engine := range window.extensionEngines {
+			engine.OnReactionRemove(m.MessageReaction)
+		}
+
 		message, stateError := s.State.Message(m.ChannelID, m.MessageID)
 		if message != nil && stateError == nil {
 			s.State.Lock()
@@ -1721,6 +1928,54 @@ func (window *Window) registerReactionEventHandlers() {
 	})
 }
 
+// registerTypingEventHandler makes sure that typing events are passed on to
+// all extension engines and shown in the typing indicator.
+func (window *Window) registerTypingEventHandler() {
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.TypingStart) {
+		for _, engine := range window.extensionEngines {
+			engine.OnTypingStart(event)
+		}
+
+		if event.UserID == s.State.User.ID {
+			return
+		}
+
+		//The local time is used instead of the event timestamp, as the
+		//latter has a precision of seconds and might be off due to clock
+		//differences.
+		window.typingUsers.add(event.ChannelID, event.UserID, time.Now())
+		window.queueTypingIndicatorUpdate()
+	})
+}
+
+// registerPresenceEventHandler makes sure that presence updates are passed
+// on to all extension engines and shown in the user and private chat lists.
+func (window *Window) registerPresenceEventHandler() {
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.PresenceUpdate) {
+		for _, engine := range window.extensionEngines {
+			engine.OnPresenceUpdate(event)
+		}
+
+		if event.User == nil {
+			return
+		}
+
+		discordutil.UpdatePresence(s.State, event)
+
+		//Updates for guilds other than the loaded one aren't visible anyway.
+		if event.GuildID != "" && (window.selectedGuild == nil || window.selectedGuild.ID != event.GuildID) {
+			return
+		}
+
+		window.app.QueueUpdateDraw(func() {
+			window.userList.UpdatePresence(event.GuildID, event.User)
+			if event.GuildID == "" {
+				window.privateList.UpdatePresence(event.User.ID)
+			}
+		})
+	})
+}
+
 // QueueUpdateDrawSynchronized is meant to be used by goroutines that aren't
 // the main goroutine in order to wait for the UI-Thread to execute the given
 // If this method is ever called from the main thread, the application will
@@ -1754,6 +2009,8 @@ func (window *Window) startMessageHandlerRoutines(input, edit, delete chan *disc
 				continue
 			}
 
+			window.typingUsers.remove(message.ChannelID, message.Author.ID)
+
 			window.chatView.Lock()
 			if window.selectedChannel != nil && message.ChannelID == window.selectedChannel.ID {
 				if message.Author.ID != window.session.State.User.ID {
@@ -1762,6 +2019,7 @@ func (window *Window) startMessageHandlerRoutines(input, edit, delete chan *disc
 
 				window.QueueUpdateDrawSynchronized(func() {
 					window.chatView.AddMessage(message)
+					window.updateTypingIndicator()
 				})
 			}
 			window.chatView.Unlock()
@@ -1923,16 +2181,25 @@ func (window *Window) registerGuildHandlers() {
 	go func() {
 		for guildCreate := range guildCreateChannel {
 			guild := guildCreate
-			if window.guildList.GetCurrentNode() == nil {
-				window.app.QueueUpdateDraw(func() {
-					window.guildList.AddGuild(guild.ID, guild.Name)
+			window.app.QueueUpdateDraw(func() {
+				//GUILD_CREATE is also sent for guilds that are loaded lazily
+				//or become available again after an outage. Those are already
+				//part of the guild list, since they were part of the ready event.
+				isNewGuild := tviewutil.GetNodeByReference(guild.ID, window.guildList.TreeView) == nil
+
+				window.guildList.AddGuild(guild.ID, guild.Name)
+				if window.guildList.GetCurrentNode() == nil {
 					window.guildList.SetCurrentNode(window.guildList.GetRoot())
-				})
-			} else {
-				window.app.QueueUpdateDraw(func() {
-					window.guildList.AddGuild(guild.ID, guild.Name)
-				})
-			}
+				}
+
+				if isNewGuild {
+					go func() {
+						for _, engine := range window.extensionEngines {
+							engine.OnGuildJoin(guild.Guild)
+						}
+					}()
+				}
+			})
 		}
 	}()
 
@@ -1947,6 +2214,13 @@ func (window *Window) registerGuildHandlers() {
 
 	go func() {
 		for guildRemove := range guildRemoveChannel {
+			//Unavailable guilds are only temporarily gone due to outages.
+			if !guildRemove.Unavailable {
+				for _, engine := range window.extensionEngines {
+					engine.OnGuildLeave(guildRemove.Guild)
+				}
+			}
+
 			if window.previousChannel != nil && window.previousChannel.GuildID == guildRemove.ID {
 				window.previousChannel = nil
 			}
@@ -1983,6 +2257,10 @@ func (window *Window) registerGuildMemberHandlers() {
 	})
 
 	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.GuildMemberRemove) {
+		for _, engine := range window.extensionEngines {
+			engine.OnMemberLeave(event.Member)
+		}
+
 		if window.selectedGuild != nil && window.selectedGuild.ID == event.GuildID {
 			window.app.QueueUpdateDraw(func() {
 				window.userList.RemoveMember(event.Member)
@@ -1991,6 +2269,10 @@ func (window *Window) registerGuildMemberHandlers() {
 	})
 
 	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.GuildMemberAdd) {
+		for _, engine := range window.extensionEngines {
+			engine.OnMemberJoin(event.Member)
+		}
+
 		if window.selectedGuild != nil && window.selectedGuild.ID == event.GuildID {
 			window.app.QueueUpdateDraw(func() {
 				window.userList.AddOrUpdateMember(event.Member)
@@ -2007,6 +2289,30 @@ func (window *Window) registerGuildMemberHandlers() {
 	})
 }
 
+// registerGuildRoleHandlers keeps the role sections of the user tree up to
+// date. Changes to the roles of a member are handled via member updates.
+func (window *Window) registerGuildRoleHandlers() {
+	updateRoles := func(guildID string) {
+		if window.selectedGuild != nil && window.selectedGuild.ID == guildID {
+			window.app.QueueUpdateDraw(func() {
+				window.userList.UpdateRoles(guildID)
+			})
+		}
+	}
+
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.GuildRoleCreate) {
+		updateRoles(event.GuildID)
+	})
+
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.GuildRoleUpdate) {
+		updateRoles(event.GuildID)
+	})
+
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.GuildRoleDelete) {
+		updateRoles(event.GuildID)
+	})
+}
+
 func (window *Window) registerPrivateChatsHandler() {
 	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.ChannelCreate) {
 		if event.Type == discordgo.ChannelTypeDM || event.Type == discordgo.ChannelTypeGroupDM {
@@ -2033,24 +2339,66 @@ func (window *Window) registerPrivateChatsHandler() {
 		}
 	})
 
+	//Changes to the recipients of groups aren't known by the library, so
+	//the raw events have to be handled.
+	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.Event) {
+		if event.Type != discordutil.EventChannelRecipientAdd && event.Type != discordutil.EventChannelRecipientRemove {
+			return
+		}
+
+		recipientEvent, parseError := discordutil.ParseRecipientEvent(event)
+		if parseError != nil {
+			log.Printf("Error parsing %s event: %s\n", event.Type, parseError)
+			return
+		}
+
+		var channel *discordgo.Channel
+		if event.Type == discordutil.EventChannelRecipientAdd {
+			channel = discordutil.AddRecipient(s.State, recipientEvent.ChannelID, recipientEvent.User)
+		} else {
+			channel = discordutil.RemoveRecipient(s.State, recipientEvent.ChannelID, recipientEvent.User.ID)
+		}
+		if channel == nil {
+			return
+		}
+
+		window.app.QueueUpdateDraw(func() {
+			window.privateList.AddOrUpdateChannel(channel)
+			if window.selectedChannel == nil || window.selectedChannel.ID != channel.ID {
+				return
+			}
+
+			if event.Type == discordutil.EventChannelRecipientAdd {
+				window.userList.AddOrUpdateUser(recipientEvent.User)
+			} else {
+				window.userList.RemoveUser(recipientEvent.User.ID)
+			}
+		})
+	})
+
 	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.RelationshipAdd) {
+		discordutil.AddOrUpdateRelationship(s.State, event.Relationship)
 		if event.Relationship.Type == discordgo.RelationTypeFriend {
 			window.app.QueueUpdateDraw(func() {
-				window.privateList.addFriend(event.User)
+				window.privateList.AddOrUpdateFriend(event.User)
+			})
+		} else if event.Relationship.Type == discordgo.RelationTypeBlocked {
+			//Blocking a friend replaces the friendship.
+			window.app.QueueUpdateDraw(func() {
+				window.privateList.RemoveFriend(event.ID)
 			})
+			window.RefreshMessages()
 		}
 	})
 
 	window.session.AddHandler(func(s *discordgo.Session, event *discordgo.RelationshipRemove) {
+		discordutil.RemoveRelationship(s.State, event.ID)
 		if event.Relationship.Type == discordgo.RelationTypeFriend {
-			for _, relationship := range window.session.State.Relationships {
-				if relationship.ID == event.ID {
-					window.app.QueueUpdateDraw(func() {
-						window.privateList.RemoveFriend(relationship.User.ID)
-					})
-					break
-				}
-			}
+			window.app.QueueUpdateDraw(func() {
+				window.privateList.RemoveFriend(event.ID)
+			})
+		} else if event.Relationship.Type == discordgo.RelationTypeBlocked {
+			window.RefreshMessages()
 		}
 	})
 }
@@ -2117,13 +2465,14 @@ func (window *Window) registerGuildChannelHandler() {
 }
 
 func (window *Window) isElligibleForNotification(message *discordgo.Message, channel *discordgo.Channel) bool {
-	if discordutil.IsBlocked(window.session.State, message.Author) {
+	if discordutil.IsBlocked(window.session.State, message.Author) ||
+		config.Current.IsUserIgnored(message.Author.ID, channel.GuildID) {
 		return false
 	}
 
 	isCurrentChannel := window.selectedChannel == nil || message.ChannelID != window.selectedChannel.ID
 	//Client is not in a state elligible for notifications.
-	if isCurrentChannel && (window.userActive || !config.Current.DesktopNotificationsForLoadedChannel) {
+	if isCurrentChannel && (window.isUserActive() || !config.Current.DesktopNotificationsForLoadedChannel) {
 		return false
 	}
 
@@ -2188,15 +2537,14 @@ func (window *Window) SetCommandModeEnabled(enabled bool) {
 func (window *Window) handleGlobalShortcuts(event *tcell.EventKey) *tcell.EventKey {
 	if shortcuts.ExitApplication.Equals(event) {
 		//window#Shutdown unnecessary, as we shut the whole process down.
+		//The status however has to be restored, as it'd otherwise stay
+		//idle on all clients.
+		window.stopStatusTracking()
 		window.app.Stop()
 		return nil
 	}
 
-	// Maybe compare directly to table?
-	if config.Current.DesktopNotificationsUserInactivityThreshold > 0 {
-		window.userActive = true
-		window.userActiveTimer.Reset(time.Duration(config.Current.DesktopNotificationsUserInactivityThreshold) * time.Second)
-	}
+	window.reportUserActivity()
 
 	return event
 }
@@ -2243,6 +2591,12 @@ func (window *Window) handleChatWindowShortcuts(event *tcell.EventKey) *tcell.Ev
 		}
 
 		window.app.SetFocus(window.commandView.commandInput.internalTextView)
+	} else if shortcuts.ShowPinnedMessages.Equals(event) && window.selectedChannel != nil &&
+		window.app.GetFocus() == window.chatView.internalTextView {
+		pinsError := window.ShowPinnedMessages(window.selectedChannel)
+		if pinsError != nil {
+			window.ShowErrorDialog(fmt.Sprintf("Error loading pinned messages: %s", pinsError))
+		}
 	} else if shortcuts.ToggleUserContainer.Equals(event) {
 		window.toggleUserContainer()
 	} else if shortcuts.FocusChannelContainer.Equals(event) {
@@ -2307,6 +2661,54 @@ func (window *Window) toggleBareChat() {
 	})
 }
 
+// setupAutocompleteView makes the given autocompleteView display the values
+// offered by the editor and inserts the value chosen by the user. The
+// autocompleteView has to be part of the chatArea.
+func (window *Window) setupAutocompleteView(editor *Editor, autocompleteView *components.AutocompleteView) {
+	editor.SetAutocompleteValuesUpdateHandler(func(values []*AutocompleteValue) {
+		autocompleteView.GetRoot().ClearChildren()
+		if len(values) == 0 {
+			autocompleteView.SetVisible(false)
+			window.app.SetFocus(editor.GetPrimitive())
+		} else {
+			rootNode := autocompleteView.GetRoot()
+			for _, value := range values {
+				newNode := tview.NewTreeNode(value.RenderValue)
+				newNode.SetReference(value)
+				rootNode.AddChild(newNode)
+			}
+			autocompleteView.SetCurrentNode(rootNode)
+			autocompleteView.SetVisible(true)
+			window.app.SetFocus(autocompleteView)
+			_, _, _, height := window.app.GetRoot().GetRect()
+			//The preferred height is limited to a certain to avoid the
+			//autocomplete taking too much space in small windows, or
+			//furthermore terminals with a big font size.
+			prefHeight := maths.Min(maths.Min(10, height/4), len(values))
+			window.chatArea.ResizeItem(autocompleteView, prefHeight, 0)
+		}
+	})
+
+	autocompleteView.SetSelectedFunc(func(node *tview.TreeNode) {
+		value := node.GetReference().(*AutocompleteValue)
+		editor.ApplyAutocompleteValue(value)
+		window.app.SetFocus(editor.GetPrimitive())
+		autocompleteView.SetVisible(false)
+	})
+
+	//All uncaptured events, e.g. events not relevant for TreeViews, will be
+	//forwarded to the editor, as both full typing capability, but also
+	//autocomplete capability should work at the same time.
+	autocompleteView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		return editor.internalTextView.GetInputCapture()(event)
+	})
+}
+
+// GetCommandHistory returns the history of the command view.
+func (window *Window) GetCommandHistory() *commands.History {
+	return window.commandView.commandHistory
+}
+
 // FindCommand searches through the registered command, whether any of them
 // equals the passed name.
 func (window *Window) FindCommand(name string) commands.Command {
@@ -2322,20 +2724,145 @@ func (window *Window) FindCommand(name string) commands.Command {
 //ExecuteCommand tries to execute the given input as a command. The first word
 //will be passed as the commands name and the rest will be parameters. If a
 //command can't be found, that info will be printed onto the command output.
+//Multiple commands can be separated by semicolons and configured aliases are
+//expanded before executing them.
 func (window *Window) ExecuteCommand(input string) {
-	parts := commands.ParseCommand(input)
-	fmt.Fprintf(window.commandView, "[gray]$ %s\n", input)
+	window.executeCommand("", input)
+}
+
+//ExecuteCommandFile runs each line of the given file as if it had been typed
+//into the command view. Empty lines and lines starting with # are skipped.
+//Output and errors are prefixed with the file name and line number. A file
+//that doesn't exist is ignored.
+func (window *Window) ExecuteCommandFile(file string) {
+	content, readError := ioutil.ReadFile(file)
+	if readError != nil {
+		if !os.IsNotExist(readError) {
+			commands.PrintError(window.commandView, "Error reading "+file, readError.Error())
+		}
+		return
+	}
+
+	fileName := filepath.Base(file)
+	for index, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		window.executeCommand(fmt.Sprintf("%s:%d: ", fileName, index+1), line)
+	}
+}
+
+//executeCommand runs the given input and prefixes the echoed input and the
+//errors of unknown commands with the location of the input.
+func (window *Window) executeCommand(location, input string) {
+	fmt.Fprintf(window.commandView, "[gray]%s$ %s\n", location, input)
+
+	expanded, expandError := commands.ExpandAliases(config.Current.CommandAliases, input)
+	if expandError != nil {
+		commands.PrintError(window.commandView, location+"Invalid input", expandError.Error())
+		return
+	}
+	for _, commandInput := range expanded {
+		//Shows the actual commands if the input differs from them.
+		if len(expanded) > 1 || commandInput != strings.TrimSpace(input) {
+			fmt.Fprintf(window.commandView, "[gray]> %s\n", commandInput)
+		}
+
+		window.executePipeline(location, commandInput)
+	}
+}
+
+//executePipeline runs all commands of the pipeline, passing the output of
+//each command to the next one. The output of the last command is either
+//shown or written into a file. Nothing is run if any of the commands can't
+//be found.
+func (window *Window) executePipeline(location, input string) {
+	pipeline, parseError := commands.ParsePipeline(input)
+	if parseError != nil {
+		commands.PrintError(window.commandView, location+"Invalid input", parseError.Error())
+		return
+	}
+
+	pipelineCommands := make([]commands.Command, 0, len(pipeline.Commands))
+	pipelineParameters := make([][]string, 0, len(pipeline.Commands))
+	for index, commandInput := range pipeline.Commands {
+		var parts []string
+		if commands.IsMessageCommand(commandInput) {
+			parts = commands.ParseMessageCommand(commandInput)
+		} else {
+			var parseError error
+			parts, parseError = commands.ParseCommand(commandInput)
+			if parseError != nil {
+				commands.PrintError(window.commandView, location+"Invalid input", parseError.Error())
+				return
+			}
+		}
+		if len(parts) == 0 {
+			return
+		}
 
-	if len(parts) > 0 {
 		command := window.FindCommand(parts[0])
-		if command != nil {
-			command.Execute(window.commandView, parts[1:])
+		if command == nil {
+			fmt.Fprintf(window.commandView, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]%sThe command '%s' doesn't exist[white]\n", location, parts[0])
+			return
+		}
+		if _, acceptsInput := command.(commands.InputCommand); index > 0 && !acceptsInput {
+			commands.PrintError(window.commandView, location+"Invalid input", fmt.Sprintf("The command '%s' can't receive piped input", parts[0]))
+			return
+		}
+
+		pipelineCommands = append(pipelineCommands, command)
+		pipelineParameters = append(pipelineParameters, parts[1:])
+	}
+
+	var output bytes.Buffer
+	for index, command := range pipelineCommands {
+		var writer io.Writer = &output
+		if index == len(pipelineCommands)-1 && pipeline.File == "" {
+			writer = window.commandView
+		}
+
+		if index == 0 {
+			command.Execute(writer, pipelineParameters[index])
 		} else {
-			fmt.Fprintf(window.commandView, "["+tviewutil.ColorToHex(config.GetTheme().ErrorColor)+"]The command '%s' doesn't exist[white]\n", parts[0])
+			input := tview.StripTags(output.String())
+			output.Reset()
+			command.(commands.InputCommand).ExecuteWithInput(writer, input, pipelineParameters[index])
+		}
+	}
+
+	if pipeline.File != "" {
+		if writeError := writeCommandOutput(pipeline.File, pipeline.Append, tview.StripTags(output.String())); writeError != nil {
+			commands.PrintError(window.commandView, location+"Error writing output to "+pipeline.File, writeError.Error())
 		}
 	}
 }
 
+func writeCommandOutput(file string, appendOutput bool, output string) error {
+	path, resolveError := files.ToAbsolutePath(commands.ExpandPath(file))
+	if resolveError != nil {
+		return resolveError
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if appendOutput {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	outputFile, openError := os.OpenFile(path, flags, 0600)
+	if openError != nil {
+		return openError
+	}
+
+	_, writeError := outputFile.WriteString(output)
+	closeError := outputFile.Close()
+	if writeError != nil {
+		return writeError
+	}
+	return closeError
+}
+
 // ShowTFASetup generates a new TFA-Secret and shows a QR-Code. The QR-Code can
 // be scanned and the resulting TFA-Token can be entered into cordless and used
 // to enable TFA on this account.
@@ -2631,6 +3158,7 @@ func (window *Window) UnloadChannel() {
 	window.chatView.ClearViewAndCache()
 	window.UpdateChatHeader(nil)
 	window.exitMessageEditModeAndKeepText()
+	window.updateTypingIndicator()
 
 	hasBeenRead := readstate.HasBeenRead(currentChannel, currentChannel.LastMessageID)
 	if currentChannel.Type == discordgo.ChannelTypeDM || currentChannel.Type == discordgo.ChannelTypeGroupDM {
@@ -2668,6 +3196,14 @@ func (window *Window) LoadChannel(channel *discordgo.Channel) error {
 	discordutil.SortMessagesByTimestamp(messages)
 
 	window.selectedChannel = channel
+	if len(window.extensionEngines) > 0 {
+		go func() {
+			for _, engine := range window.extensionEngines {
+				engine.OnChannelSwitch(channel)
+			}
+		}()
+	}
+
 	//This happens before setting the messages into the view, to avoid
 	//incorrectly drawing the date separators initially.
 	window.updateUserList()
@@ -2677,6 +3213,7 @@ func (window *Window) LoadChannel(channel *discordgo.Channel) error {
 	window.chatView.SetMessages(messages)
 	window.chatView.internalTextView.ScrollToEnd()
 	window.UpdateChatHeader(channel)
+	window.updateTypingIndicator()
 
 	if channel.Type == discordgo.ChannelTypeDM || channel.Type == discordgo.ChannelTypeGroupDM {
 		window.privateList.MarkAsLoaded(channel.ID)
@@ -2768,6 +3305,7 @@ func (window *Window) Run() error {
 
 // Shutdown disconnects from the discord API and stops the tview application.
 func (window *Window) Shutdown() {
+	window.stopStatusTracking()
 	if config.Current.ShortenLinks {
 		window.chatView.shortener.Close()
 	}
@@ -2,6 +2,7 @@ package files
 
 import (
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
@@ -87,3 +88,34 @@ func EnsureDirectoryExists(directoryPath string) error {
 	}
 	return statError
 }
+
+// WriteFileAtomically writes the data into a temporary file in the target
+// directory first and then moves it to the given path. This way the file at
+// the given path is either completely written or not touched at all.
+func WriteFileAtomically(path string, data []byte, perm os.FileMode) error {
+	tempFile, createError := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if createError != nil {
+		return createError
+	}
+	tempPath := tempFile.Name()
+
+	_, writeError := tempFile.Write(data)
+	if writeError == nil {
+		writeError = tempFile.Sync()
+	}
+	closeError := tempFile.Close()
+	if writeError == nil {
+		writeError = closeError
+	}
+	if writeError == nil {
+		writeError = os.Chmod(tempPath, perm)
+	}
+	if writeError == nil {
+		writeError = os.Rename(tempPath, path)
+	}
+
+	if writeError != nil {
+		os.Remove(tempPath)
+	}
+	return writeError
+}
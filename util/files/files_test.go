@@ -3,6 +3,9 @@
 package files
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -43,3 +46,31 @@ func TestToAbsolutePath(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteFileAtomically(t *testing.T) {
+	directory, tempDirError := ioutil.TempDir("", "cordless-files-test")
+	if tempDirError != nil {
+		t.Fatal(tempDirError)
+	}
+	defer os.RemoveAll(directory)
+
+	path := filepath.Join(directory, "data.json")
+	for _, content := range []string{"first", "second"} {
+		if writeError := WriteFileAtomically(path, []byte(content), 0600); writeError != nil {
+			t.Fatalf("WriteFileAtomically() error = %v", writeError)
+		}
+
+		written, readError := ioutil.ReadFile(path)
+		if readError != nil {
+			t.Fatal(readError)
+		}
+		if string(written) != content {
+			t.Errorf("WriteFileAtomically() wrote '%s', want '%s'", written, content)
+		}
+	}
+
+	leftovers, _ := ioutil.ReadDir(directory)
+	if len(leftovers) != 1 {
+		t.Errorf("WriteFileAtomically() left %d files in the directory, want 1", len(leftovers))
+	}
+}